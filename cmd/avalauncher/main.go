@@ -5,24 +5,33 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/primal-host/avalauncher/internal/config"
 	"github.com/primal-host/avalauncher/internal/database"
+	"github.com/primal-host/avalauncher/internal/dns"
 	"github.com/primal-host/avalauncher/internal/docker"
+	"github.com/primal-host/avalauncher/internal/logging"
 	"github.com/primal-host/avalauncher/internal/manager"
+	"github.com/primal-host/avalauncher/internal/secrets"
 	"github.com/primal-host/avalauncher/internal/server"
+	"github.com/primal-host/avalauncher/internal/storage"
 )
 
 func main() {
-	slog.Info("avalauncher starting", "version", config.Version)
-
 	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("config load failed", "error", err)
 		os.Exit(1)
 	}
+	if err := logging.Init(cfg.LogLevel, cfg.LogFormat); err != nil {
+		slog.Error("logging setup failed", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("avalauncher starting", "version", config.Version)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	db, err := database.Open(ctx, cfg.DSN())
@@ -53,10 +62,59 @@ func main() {
 
 	// Health interval.
 	healthInterval, err := time.ParseDuration(cfg.HealthInterval)
+	if err != nil || healthInterval <= 0 {
+		slog.Error("invalid health interval", "value", cfg.HealthInterval)
+		os.Exit(1)
+	}
+
+	// Docker API throttling for remote hosts.
+	maxConcurrent, err := strconv.Atoi(cfg.DockerRemoteMaxConcurrent)
+	if err != nil {
+		slog.Error("invalid DOCKER_REMOTE_MAX_CONCURRENT", "error", err)
+		os.Exit(1)
+	}
+	rate, err := strconv.ParseFloat(cfg.DockerRemoteRate, 64)
 	if err != nil {
-		slog.Error("invalid health interval", "error", err)
+		slog.Error("invalid DOCKER_REMOTE_RATE", "error", err)
 		os.Exit(1)
 	}
+	remoteLimits := docker.Limits{MaxConcurrent: maxConcurrent, RatePerSec: rate}
+
+	// Secret encryption.
+	newSalt, err := secrets.NewSalt()
+	if err != nil {
+		slog.Error("generate secrets salt failed", "error", err)
+		os.Exit(1)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	salt, err := db.SecretsSalt(ctx, newSalt)
+	cancel()
+	if err != nil {
+		slog.Error("secrets salt failed", "error", err)
+		os.Exit(1)
+	}
+	secretBox, err := secrets.New(cfg.SecretsKey, cfg.SecretsKeyPrevious, salt)
+	if err != nil {
+		slog.Error("invalid secrets key", "error", err)
+		os.Exit(1)
+	}
+	if !secretBox.Enabled() {
+		slog.Warn("SECRETS_KEY not set; staking keys are stored unencrypted")
+	}
+
+	// Image allowlist policy.
+	imagePolicy, err := manager.NewImagePolicy(cfg.ImageAllowlist, cfg.ImageAllowlistRegex)
+	if err != nil {
+		slog.Error("invalid image policy", "error", err)
+		os.Exit(1)
+	}
+	if !imagePolicy.Allows(cfg.AvagoImage) {
+		slog.Warn("default image is not allowed by the image policy", "image", cfg.AvagoImage)
+	}
+
+	if cfg.TraefikDomain != "" && cfg.TraefikAuth == "" {
+		slog.Warn("AVAGO_TRAEFIK_AUTH not set; expose_http nodes get no Traefik RPC router")
+	}
 
 	// Manager.
 	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
@@ -64,18 +122,410 @@ func main() {
 		Domain:  cfg.TraefikDomain,
 		Network: cfg.TraefikNetwork,
 		Auth:    cfg.TraefikAuth,
+
+		CertResolver: cfg.TraefikCertResolver,
 	}
-	mgr, err := manager.New(ctx, dc, db.Pool, cfg.AvagoImage, cfg.AvagoNetwork, cfg.AvaxDockerNet, healthInterval, traefik)
+	mgr, err := manager.New(ctx, dc, db.Pool, cfg.AvagoImage, cfg.AvagoImages, cfg.AvagoNetwork, cfg.AvaxDockerNet, healthInterval, traefik, imagePolicy, remoteLimits, secretBox)
 	cancel()
 	if err != nil {
 		slog.Error("manager init failed", "error", err)
 		os.Exit(1)
 	}
+	healthWorkers, err := strconv.Atoi(cfg.HealthWorkers)
+	if err != nil || healthWorkers <= 0 {
+		slog.Error("invalid HEALTH_WORKERS", "value", cfg.HealthWorkers)
+		os.Exit(1)
+	}
+	healthTimeout, err := time.ParseDuration(cfg.HealthTimeout)
+	if err != nil || healthTimeout <= 0 {
+		slog.Error("invalid HEALTH_CHECK_TIMEOUT", "value", cfg.HealthTimeout)
+		os.Exit(1)
+	}
+	mgr.SetHealthConcurrency(healthWorkers, healthTimeout)
+	bootstrapAlert, err := time.ParseDuration(cfg.BootstrapAlert)
+	if err != nil {
+		slog.Error("invalid BOOTSTRAP_ALERT_AFTER", "value", cfg.BootstrapAlert)
+		os.Exit(1)
+	}
+	mgr.SetBootstrapAlertAfter(bootstrapAlert)
+
+	// Node log request caps.
+	logTailMax, err := strconv.Atoi(cfg.LogTailMax)
+	if err != nil {
+		slog.Error("invalid LOG_TAIL_MAX", "error", err)
+		os.Exit(1)
+	}
+	logPerNode, err := strconv.Atoi(cfg.LogStreamsPerNode)
+	if err != nil {
+		slog.Error("invalid LOG_STREAMS_PER_NODE", "error", err)
+		os.Exit(1)
+	}
+	logPerHost, err := strconv.Atoi(cfg.LogStreamsPerHost)
+	if err != nil {
+		slog.Error("invalid LOG_STREAMS_PER_HOST", "error", err)
+		os.Exit(1)
+	}
+	mgr.SetLogLimits(manager.LogLimits{MaxTail: logTailMax, PerNode: logPerNode, PerHost: logPerHost})
+
+	// Node exec policy.
+	execAllowAny, err := strconv.ParseBool(cfg.ExecAllowAny)
+	if err != nil {
+		slog.Error("invalid EXEC_ALLOW_ANY", "error", err)
+		os.Exit(1)
+	}
+	execPolicy := manager.ExecPolicy{AllowAny: execAllowAny}
+	for _, name := range strings.Split(cfg.ExecAllowCommands, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			execPolicy.Allowed = append(execPolicy.Allowed, name)
+		}
+	}
+	mgr.SetExecPolicy(execPolicy)
+
+	if err := mgr.SetIdentityPolicy(cfg.DuplicateNodeID); err != nil {
+		slog.Error("invalid DUPLICATE_NODE_ID", "error", err)
+		os.Exit(1)
+	}
+
+	if cfg.PChainPrivateKey != "" {
+		if err := mgr.SetPChainKey(cfg.PChainPrivateKey); err != nil {
+			slog.Error("invalid PCHAIN_PRIVATE_KEY", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Node backups (optional): db snapshots and staking key exports.
+	if cfg.BackupTarget != "" {
+		var store storage.Store
+		if strings.HasPrefix(cfg.BackupTarget, "s3://") {
+			s3, err := storage.ParseS3URL(cfg.BackupTarget)
+			if err != nil {
+				slog.Error("invalid BACKUP_TARGET", "error", err)
+				os.Exit(1)
+			}
+			if cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+				slog.Error("s3:// BACKUP_TARGET requires S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY (or the AWS_ ones)")
+				os.Exit(1)
+			}
+			pathStyle, err := strconv.ParseBool(cfg.S3PathStyle)
+			if err != nil {
+				slog.Error("invalid S3_PATH_STYLE", "value", cfg.S3PathStyle)
+				os.Exit(1)
+			}
+			s3.Endpoint, s3.Region, s3.PathStyle = cfg.S3Endpoint, cfg.S3Region, pathStyle
+			s3.AccessKeyID, s3.SecretAccessKey = cfg.S3AccessKeyID, cfg.S3SecretAccessKey
+			if cfg.S3AccessKeyID == cfg.AWSAccessKeyID {
+				s3.SessionToken = cfg.AWSSessionToken
+			}
+			store = s3
+		} else {
+			local, err := storage.NewLocal(cfg.BackupTarget)
+			if err != nil {
+				slog.Error("invalid BACKUP_TARGET", "error", err)
+				os.Exit(1)
+			}
+			store = local
+		}
+		keepLast, err := strconv.Atoi(cfg.BackupKeepLast)
+		if err != nil || keepLast < 0 {
+			slog.Error("invalid BACKUP_KEEP_LAST", "value", cfg.BackupKeepLast)
+			os.Exit(1)
+		}
+		mgr.SetBackups(manager.BackupConfig{Store: store, KeepLast: keepLast})
+	}
+
+	// Health-based DNS for L1 RPC hostnames (optional).
+	if cfg.DNSProvider != "" {
+		var provider dns.Provider
+		switch cfg.DNSProvider {
+		case "cloudflare":
+			if cfg.CloudflareToken == "" || cfg.CloudflareZoneID == "" {
+				slog.Error("DNS_PROVIDER=cloudflare requires CLOUDFLARE_API_TOKEN and CLOUDFLARE_ZONE_ID")
+				os.Exit(1)
+			}
+			provider = &dns.Cloudflare{Token: cfg.CloudflareToken, ZoneID: cfg.CloudflareZoneID}
+		case "route53":
+			if cfg.AWSAccessKeyID == "" || cfg.AWSSecretAccessKey == "" || cfg.Route53ZoneID == "" {
+				slog.Error("DNS_PROVIDER=route53 requires AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and ROUTE53_ZONE_ID")
+				os.Exit(1)
+			}
+			provider = &dns.Route53{
+				AccessKeyID:     cfg.AWSAccessKeyID,
+				SecretAccessKey: cfg.AWSSecretAccessKey,
+				SessionToken:    cfg.AWSSessionToken,
+				ZoneID:          cfg.Route53ZoneID,
+			}
+		default:
+			slog.Error("invalid DNS_PROVIDER (want cloudflare or route53)", "value", cfg.DNSProvider)
+			os.Exit(1)
+		}
+		if cfg.TraefikDomain == "" {
+			slog.Error("DNS_PROVIDER requires AVAGO_TRAEFIK_DOMAIN")
+			os.Exit(1)
+		}
+		dnsTTL, err := strconv.Atoi(cfg.DNSTTL)
+		if err != nil || dnsTTL <= 0 {
+			slog.Error("invalid DNS_TTL", "value", cfg.DNSTTL)
+			os.Exit(1)
+		}
+		mgr.SetRPCDNS(manager.RPCDNSConfig{Provider: provider, TTL: dnsTTL, LocalAddress: cfg.DNSLocalAddress})
+	}
+
+	// Per-host edge proxies with DNS-01 certificates (optional). They reuse
+	// the DNS provider credentials above, passed to Traefik under lego's names.
+	if cfg.ACMEEmail != "" {
+		env := map[string]string{}
+		switch cfg.ACMEDNSProvider {
+		case "cloudflare":
+			if cfg.CloudflareToken == "" {
+				slog.Error("ACME_DNS_PROVIDER=cloudflare requires CLOUDFLARE_API_TOKEN")
+				os.Exit(1)
+			}
+			env["CF_DNS_API_TOKEN"] = cfg.CloudflareToken
+		case "route53":
+			if cfg.AWSAccessKeyID == "" || cfg.AWSSecretAccessKey == "" {
+				slog.Error("ACME_DNS_PROVIDER=route53 requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+				os.Exit(1)
+			}
+			env["AWS_ACCESS_KEY_ID"] = cfg.AWSAccessKeyID
+			env["AWS_SECRET_ACCESS_KEY"] = cfg.AWSSecretAccessKey
+			env["AWS_REGION"] = "us-east-1"
+			if cfg.AWSSessionToken != "" {
+				env["AWS_SESSION_TOKEN"] = cfg.AWSSessionToken
+			}
+			if cfg.Route53ZoneID != "" {
+				env["AWS_HOSTED_ZONE_ID"] = cfg.Route53ZoneID
+			}
+		default:
+			slog.Error("invalid ACME_DNS_PROVIDER (want cloudflare or route53)", "value", cfg.ACMEDNSProvider)
+			os.Exit(1)
+		}
+		if cfg.TraefikDomain == "" {
+			slog.Error("ACME_EMAIL requires AVAGO_TRAEFIK_DOMAIN")
+			os.Exit(1)
+		}
+		mgr.SetEdgeProxy(manager.EdgeProxyConfig{
+			Image:       cfg.TraefikImage,
+			Email:       cfg.ACMEEmail,
+			CAServer:    cfg.ACMECAServer,
+			DNSProvider: cfg.ACMEDNSProvider,
+			DNSEnv:      env,
+		})
+	}
+
+	// Startup recovery and the pollers run with the settings above.
+	if err := mgr.Start(); err != nil {
+		slog.Error("manager start failed", "error", err)
+		os.Exit(1)
+	}
 	mgr.StartHealthPoller()
 	mgr.StartHostPoller()
+	mgr.StartRetryPoller()
+
+	// Docker event streams; the health poller alone notices container
+	// exits when disabled.
+	dockerEvents, err := strconv.ParseBool(cfg.DockerEvents)
+	if err != nil {
+		slog.Error("invalid DOCKER_EVENTS", "error", err)
+		os.Exit(1)
+	}
+	if dockerEvents {
+		mgr.StartDockerEvents()
+	}
+
+	// Janitor for expiring nodes and L1s.
+	janitorInterval, err := time.ParseDuration(cfg.JanitorInterval)
+	if err != nil || janitorInterval <= 0 {
+		slog.Error("invalid janitor interval", "value", cfg.JanitorInterval)
+		os.Exit(1)
+	}
+	ttlWarnBefore, err := time.ParseDuration(cfg.TTLWarnBefore)
+	if err != nil {
+		slog.Error("invalid ttl warn before", "error", err)
+		os.Exit(1)
+	}
+	mgr.StartJanitor(janitorInterval, ttlWarnBefore)
+
+	// Node volume sizes.
+	diskUsageInterval, err := time.ParseDuration(cfg.DiskUsageInterval)
+	if err != nil || diskUsageInterval <= 0 {
+		slog.Error("invalid disk usage interval", "value", cfg.DiskUsageInterval)
+		os.Exit(1)
+	}
+	mgr.StartDiskUsagePoller(diskUsageInterval)
+
+	// Validator uptime history.
+	uptimeInterval, err := time.ParseDuration(cfg.UptimeInterval)
+	if err != nil || uptimeInterval <= 0 {
+		slog.Error("invalid uptime interval", "value", cfg.UptimeInterval)
+		os.Exit(1)
+	}
+	mgr.StartUptimePoller(uptimeInterval)
+
+	// AvalancheGo versions.
+	versionInterval, err := time.ParseDuration(cfg.VersionInterval)
+	if err != nil || versionInterval <= 0 {
+		slog.Error("invalid version interval", "value", cfg.VersionInterval)
+		os.Exit(1)
+	}
+	mgr.StartVersionPoller(versionInterval)
+
+	// Node state history.
+	nodeHistoryInterval, err := time.ParseDuration(cfg.NodeHistoryInterval)
+	if err != nil || nodeHistoryInterval <= 0 {
+		slog.Error("invalid node history interval", "value", cfg.NodeHistoryInterval)
+		os.Exit(1)
+	}
+	mgr.StartNodeHistoryPoller(nodeHistoryInterval)
+
+	// Nodes stuck creating or failed.
+	stuckNodeTimeout, err := time.ParseDuration(cfg.StuckNodeTimeout)
+	if err != nil || stuckNodeTimeout < 0 {
+		slog.Error("invalid STUCK_NODE_TIMEOUT", "value", cfg.StuckNodeTimeout)
+		os.Exit(1)
+	}
+	if stuckNodeTimeout > 0 {
+		mgr.StartStuckNodePoller(stuckNodeTimeout)
+	}
+
+	// Image digest drift.
+	imageDriftInterval, err := time.ParseDuration(cfg.ImageDriftInterval)
+	if err != nil || imageDriftInterval <= 0 {
+		slog.Error("invalid image drift interval", "value", cfg.ImageDriftInterval)
+		os.Exit(1)
+	}
+	mgr.StartImageDriftPoller(imageDriftInterval)
+
+	// Event hooks (optional).
+	if cfg.EventHooksFile != "" {
+		hookConfigs, err := config.LoadEventHooks(cfg.EventHooksFile)
+		if err != nil {
+			slog.Error("invalid EVENT_HOOKS_FILE", "error", err)
+			os.Exit(1)
+		}
+		hooks := make([]manager.EventHook, 0, len(hookConfigs))
+		for _, h := range hookConfigs {
+			var timeout time.Duration
+			if h.Timeout != "" {
+				if timeout, err = time.ParseDuration(h.Timeout); err != nil || timeout <= 0 {
+					slog.Error("invalid event hook timeout", "hook", h.Name, "value", h.Timeout)
+					os.Exit(1)
+				}
+			}
+			hooks = append(hooks, manager.EventHook{
+				Name:    h.Name,
+				Events:  h.Events,
+				Targets: h.Targets,
+				Command: h.Command,
+				Timeout: timeout,
+			})
+		}
+		if err := manager.CheckEventHooks(hooks); err != nil {
+			slog.Error("invalid EVENT_HOOKS_FILE", "error", err)
+			os.Exit(1)
+		}
+		mgr.StartEventHooks(hooks)
+	}
+
+	// Metrics push (optional).
+	if cfg.MetricsPushURL != "" {
+		pushInterval, err := time.ParseDuration(cfg.MetricsPushInterval)
+		if err != nil || pushInterval <= 0 {
+			slog.Error("invalid metrics push interval", "value", cfg.MetricsPushInterval)
+			os.Exit(1)
+		}
+		mgr.StartMetricsPusher(manager.MetricsPushConfig{
+			URL:      cfg.MetricsPushURL,
+			Job:      cfg.MetricsPushJob,
+			Interval: pushInterval,
+			Auth:     cfg.MetricsPushAuth,
+		})
+	}
+
+	// Email alerts (optional).
+	if cfg.SMTPHost != "" {
+		smtpPort, err := strconv.Atoi(cfg.SMTPPort)
+		if err != nil || smtpPort <= 0 {
+			slog.Error("invalid SMTP_PORT", "value", cfg.SMTPPort)
+			os.Exit(1)
+		}
+		var to []string
+		for _, addr := range strings.Split(cfg.AlertEmailTo, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				to = append(to, addr)
+			}
+		}
+		if cfg.SMTPFrom == "" || len(to) == 0 {
+			slog.Error("SMTP_HOST requires SMTP_FROM and ALERT_EMAIL_TO")
+			os.Exit(1)
+		}
+		if cfg.AlertEmailMode != "immediate" && cfg.AlertEmailMode != "digest" {
+			slog.Error("invalid ALERT_EMAIL_MODE (want immediate or digest)", "value", cfg.AlertEmailMode)
+			os.Exit(1)
+		}
+		threshold, err := time.ParseDuration(cfg.AlertEmailThreshold)
+		if err != nil {
+			slog.Error("invalid alert email threshold", "error", err)
+			os.Exit(1)
+		}
+		digestInterval, err := time.ParseDuration(cfg.AlertEmailDigest)
+		if err != nil || digestInterval <= 0 {
+			slog.Error("invalid alert email digest interval", "value", cfg.AlertEmailDigest)
+			os.Exit(1)
+		}
+		eventSeverity := cfg.AlertEmailEvents
+		if eventSeverity == "none" {
+			eventSeverity = ""
+		} else if err := manager.CheckSeverity(eventSeverity); err != nil {
+			slog.Error("invalid ALERT_EMAIL_EVENT_SEVERITY", "error", err)
+			os.Exit(1)
+		}
+		mgr.StartEmailAlerts(manager.EmailAlertConfig{
+			Host:           cfg.SMTPHost,
+			Port:           smtpPort,
+			Username:       cfg.SMTPUsername,
+			Password:       cfg.SMTPPassword,
+			From:           cfg.SMTPFrom,
+			To:             to,
+			Mode:           cfg.AlertEmailMode,
+			DigestInterval: digestInterval,
+			Threshold:      threshold,
+			EventSeverity:  eventSeverity,
+		})
+	}
 
 	srv := server.New(db, mgr, cfg.ListenAddr, cfg.AdminKey, cfg.TraefikDomain)
 
+	// Dashboard behaviour.
+	uiEventStream, err := strconv.ParseBool(cfg.UIEventStream)
+	if err != nil {
+		slog.Error("invalid UI_EVENT_STREAM", "error", err)
+		os.Exit(1)
+	}
+	uiConfig, err := server.ParseUIConfig(cfg.UIPollInterval, uiEventStream, cfg.UISections)
+	if err != nil {
+		slog.Error("invalid dashboard config", "error", err)
+		os.Exit(1)
+	}
+	uiConfig.Brand, err = server.ParseBranding(cfg.UIProductName, cfg.UILogoURL, cfg.UIAccentColor)
+	if err != nil {
+		slog.Error("invalid dashboard branding", "error", err)
+		os.Exit(1)
+	}
+	srv.SetUIConfig(uiConfig)
+
+	publicRateLimit, err := strconv.Atoi(cfg.PublicRateLimit)
+	if err != nil || publicRateLimit <= 0 {
+		slog.Error("invalid PUBLIC_RATE_LIMIT", "value", cfg.PublicRateLimit)
+		os.Exit(1)
+	}
+	srv.SetPublicRateLimit(publicRateLimit)
+	trustedProxies, err := server.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		slog.Error("invalid TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+	srv.SetTrustedProxies(trustedProxies)
+
 	go func() {
 		if err := srv.Start(); err != nil {
 			slog.Error("server error", "error", err)
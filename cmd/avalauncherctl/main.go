@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: avalauncherctl [-o table|json] <command> [args]
+
+Commands:
+  nodes list
+  nodes get <id>
+  nodes create -name NAME [-image IMG] [-network NET] [-host ID] [-staking-port N]
+               [-http-port N] [-expose-http] [-network-mode bridge|host] [-ttl DUR]
+  nodes start <id>
+  nodes stop <id>
+  nodes deps [-volumes] <id>
+  nodes delete [-volumes] [-ack KINDS] [-force] [-validators block|cascade|reassign]
+               [-reassign-to ID] <id>
+  nodes logs [-tail N] [-f] <id>
+  nodes wait [-for COND] [-timeout DUR] <id>
+  hosts list
+  hosts add -name NAME -ssh user@host
+  hosts remove <id>
+  l1s list
+  l1s get <id>
+  l1s create -name NAME [-vm VM] [-subnet-id ID] [-blockchain-id ID] [-ttl DUR]
+  l1s delete <id>
+  events [-limit N]
+  events add -type TYPE -message MSG [-target NAME]
+
+Environment:
+  AVALAUNCHER_URL  API base URL (default http://localhost:4321)
+  ADMIN_KEY        bearer token
+`
+
+// client calls the avalauncher API.
+type client struct {
+	base string
+	key  string
+	http *http.Client
+}
+
+func main() {
+	output := flag.String("o", "table", "output format: table or json")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if *output != "table" && *output != "json" {
+		fail("invalid output format %q", *output)
+	}
+
+	base := os.Getenv("AVALAUNCHER_URL")
+	if base == "" {
+		base = "http://localhost:4321"
+	}
+	c := &client{
+		base: strings.TrimRight(base, "/"),
+		key:  os.Getenv("ADMIN_KEY"),
+		http: &http.Client{},
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, args := args[0], args[1:]
+	sub := ""
+	if len(args) > 0 && (cmd != "events" || args[0] == "add") {
+		sub, args = args[0], args[1:]
+	}
+
+	var result any
+	var cols []string
+	switch cmd + " " + sub {
+	case "nodes list":
+		result, cols = c.get("/api/nodes"), []string{"id", "name", "network", "status", "staking_port", "http_port", "host_id", "node_id"}
+	case "nodes get":
+		result = c.get("/api/nodes/" + idArg(args))
+	case "nodes create":
+		fs := flag.NewFlagSet("nodes create", flag.ExitOnError)
+		name := fs.String("name", "", "node name")
+		image := fs.String("image", "", "AvalancheGo image")
+		network := fs.String("network", "", "Avalanche network (mainnet, fuji, local)")
+		host := fs.Int64("host", 0, "host ID (default local)")
+		stakingPort := fs.Int("staking-port", 0, "staking port (default 9651)")
+		httpPort := fs.Int("http-port", 0, "HTTP port")
+		exposeHTTP := fs.Bool("expose-http", false, "publish the HTTP port on the host")
+		networkMode := fs.String("network-mode", "", "bridge (default) or host")
+		ttl := fs.String("ttl", "", "tear down after this long, e.g. 24h")
+		fs.Parse(args)
+		result = c.send("POST", "/api/nodes", map[string]any{
+			"name": *name, "image": *image, "network": *network, "host_id": *host,
+			"staking_port": *stakingPort, "http_port": *httpPort, "expose_http": *exposeHTTP,
+			"network_mode": *networkMode, "ttl": *ttl,
+		})
+	case "nodes start", "nodes stop":
+		result = c.send("POST", "/api/nodes/"+idArg(args)+"/"+sub, nil)
+	case "nodes delete":
+		fs := flag.NewFlagSet("nodes delete", flag.ExitOnError)
+		volumes := fs.Bool("volumes", false, "also remove the node's volumes")
+		ack := fs.String("ack", "", "comma-separated dependency kinds to acknowledge (see nodes deps)")
+		force := fs.Bool("force", false, "acknowledge all dependencies")
+		validators := fs.String("validators", "block", "L1 validator assignments: block, cascade, or reassign")
+		reassignTo := fs.Int64("reassign-to", 0, "node taking over the assignments with -validators reassign")
+		fs.Parse(args)
+		result = c.send("DELETE", fmt.Sprintf("/api/nodes/%s?remove_volumes=%t&ack=%s&force=%t&validators=%s&reassign_to=%d",
+			idArg(fs.Args()), *volumes, url.QueryEscape(*ack), *force, url.QueryEscape(*validators), *reassignTo), nil)
+	case "nodes deps":
+		fs := flag.NewFlagSet("nodes deps", flag.ExitOnError)
+		volumes := fs.Bool("volumes", false, "include the node's volumes")
+		fs.Parse(args)
+		result = c.get(fmt.Sprintf("/api/nodes/%s/dependencies?remove_volumes=%t", idArg(fs.Args()), *volumes))
+	case "nodes logs":
+		fs := flag.NewFlagSet("nodes logs", flag.ExitOnError)
+		tail := fs.String("tail", "100", "lines from the end of the log")
+		follow := fs.Bool("f", false, "follow new output")
+		fs.Parse(args)
+		q := url.Values{"tail": {*tail}}
+		if *follow {
+			q.Set("follow", "true")
+		}
+		c.stream("/api/nodes/" + idArg(fs.Args()) + "/logs?" + q.Encode())
+		return
+	case "nodes wait":
+		fs := flag.NewFlagSet("nodes wait", flag.ExitOnError)
+		cond := fs.String("for", "healthy", "running, healthy, bootstrapped, or stopped")
+		timeout := fs.String("timeout", "300s", "how long to wait")
+		fs.Parse(args)
+		q := url.Values{"for": {*cond}, "timeout": {*timeout}}
+		result = c.get("/api/nodes/" + idArg(fs.Args()) + "/wait?" + q.Encode())
+	case "hosts list":
+		result, cols = c.get("/api/hosts"), []string{"id", "name", "ssh_addr", "status"}
+	case "hosts add":
+		fs := flag.NewFlagSet("hosts add", flag.ExitOnError)
+		name := fs.String("name", "", "host name")
+		ssh := fs.String("ssh", "", "SSH address, e.g. user@host")
+		fs.Parse(args)
+		result = c.send("POST", "/api/hosts", map[string]any{"name": *name, "ssh_addr": *ssh})
+	case "hosts remove":
+		result = c.send("DELETE", "/api/hosts/"+idArg(args), nil)
+	case "l1s list":
+		result, cols = c.get("/api/l1s"), []string{"id", "name", "vm", "status", "subnet_id", "blockchain_id", "validator_count"}
+	case "l1s get":
+		result = c.get("/api/l1s/" + idArg(args))
+	case "l1s create":
+		fs := flag.NewFlagSet("l1s create", flag.ExitOnError)
+		name := fs.String("name", "", "L1 name")
+		vm := fs.String("vm", "", "VM (default subnet-evm)")
+		subnetID := fs.String("subnet-id", "", "subnet ID")
+		blockchainID := fs.String("blockchain-id", "", "blockchain ID")
+		ttl := fs.String("ttl", "", "tear down after this long, e.g. 24h")
+		fs.Parse(args)
+		result = c.send("POST", "/api/l1s", map[string]any{
+			"name": *name, "vm": *vm, "subnet_id": *subnetID, "blockchain_id": *blockchainID, "ttl": *ttl,
+		})
+	case "l1s delete":
+		result = c.send("DELETE", "/api/l1s/"+idArg(args), nil)
+	case "events ":
+		fs := flag.NewFlagSet("events", flag.ExitOnError)
+		limit := fs.Int("limit", 50, "number of events")
+		fs.Parse(args)
+		result, cols = c.get(fmt.Sprintf("/api/events?limit=%d", *limit)), []string{"created_at", "event_type", "target", "message"}
+	case "events add":
+		fs := flag.NewFlagSet("events add", flag.ExitOnError)
+		typ := fs.String("type", "", "event type, stored as custom.<type>")
+		target := fs.String("target", "", "node, host, or L1 name")
+		message := fs.String("message", "", "event message")
+		fs.Parse(args)
+		result = c.send("POST", "/api/events", map[string]any{"type": *typ, "target": *target, "message": *message})
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *output == "json" || cols == nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+		return
+	}
+	printTable(result, cols)
+}
+
+// idArg returns the single positional ID argument.
+func idArg(args []string) string {
+	if len(args) != 1 {
+		fail("expected exactly one ID argument")
+	}
+	return args[0]
+}
+
+func (c *client) get(path string) any {
+	return c.send("GET", path, nil)
+}
+
+// send makes a JSON request and decodes the response, exiting on errors.
+func (c *client) send(method, path string, body any) any {
+	resp := c.do(method, path, body)
+	defer resp.Body.Close()
+
+	var out any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && err != io.EOF {
+		fail("%s %s: decode response: %v", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		msg := resp.Status
+		if m, ok := out.(map[string]any); ok && m["error"] != nil {
+			msg = fmt.Sprint(m["error"])
+		}
+		fail("%s %s: %s", method, path, msg)
+	}
+	return out
+}
+
+// stream copies a plain-text response to stdout as it arrives.
+func (c *client) stream(path string) {
+	resp := c.do("GET", path, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		fail("GET %s: %s", path, strings.TrimSpace(string(b)))
+	}
+	io.Copy(os.Stdout, resp.Body)
+}
+
+func (c *client) do(method, path string, body any) *http.Response {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			fail("encode request: %v", err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.base+path, r)
+	if err != nil {
+		fail("%v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.key != "" {
+		req.Header.Set("Authorization", "Bearer "+c.key)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		fail("%v", err)
+	}
+	return resp
+}
+
+// printTable renders a list of objects (or a single object) as columns.
+func printTable(v any, cols []string) {
+	var rows []any
+	switch t := v.(type) {
+	case []any:
+		rows = t
+	case nil:
+	default:
+		rows = []any{t}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(cols, "\t")))
+	for _, r := range rows {
+		m, _ := r.(map[string]any)
+		vals := make([]string, len(cols))
+		for i, col := range cols {
+			vals[i] = cell(m[col])
+		}
+		fmt.Fprintln(w, strings.Join(vals, "\t"))
+	}
+	w.Flush()
+}
+
+func cell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return fmt.Sprintf("%.0f", t)
+	case string:
+		if t == "" {
+			return "-"
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return ts.Local().Format("2006-01-02 15:04:05")
+		}
+		return t
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "avalauncherctl: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -21,17 +21,139 @@ type Config struct {
 	ListenAddr string
 	AdminKey   string
 
+	// Logging
+	LogLevel  string // LOG_LEVEL: debug, info (default), warn, error
+	LogFormat string // LOG_FORMAT: text (default) or json
+
 	// Docker / AvalancheGo
 	DockerHost     string // DOCKER_HOST, default empty (unix socket)
 	AvagoImage     string // AVAGO_IMAGE, default "avaplatform/avalanchego:latest"
 	AvagoNetwork   string // AVAGO_NETWORK, default "mainnet"
 	AvaxDockerNet  string // AVAX_DOCKER_NETWORK, default "avax"
 	HealthInterval string // HEALTH_INTERVAL, default "30s"
+	DockerEvents   string // DOCKER_EVENTS, follow Docker event streams, default "true"
+	HealthWorkers  string // HEALTH_WORKERS, concurrent node checks, default "8"
+	HealthTimeout  string // HEALTH_CHECK_TIMEOUT, per-node check timeout, default "10s"
+	BootstrapAlert string // BOOTSTRAP_ALERT_AFTER, bootstrapping longer than this alerts, default "6h" ("0" = never)
+
+	// Docker API throttling for remote (SSH) hosts; 0 disables a limit
+	DockerRemoteMaxConcurrent string // DOCKER_REMOTE_MAX_CONCURRENT, default "4"
+	DockerRemoteRate          string // DOCKER_REMOTE_RATE, requests/sec, default "10"
+
+	// Per-network default images, keyed by Avalanche network
+	AvagoImages map[string]string // AVAGO_IMAGE_MAINNET, AVAGO_IMAGE_FUJI, AVAGO_IMAGE_LOCAL
+
+	// Image allowlist policy (both empty = any image allowed)
+	ImageAllowlist      []string // AVAGO_IMAGE_ALLOWLIST, comma-separated repos or exact refs
+	ImageAllowlistRegex []string // AVAGO_IMAGE_ALLOWLIST_REGEX, whitespace-separated regexes
+
+	// Master keys for encrypting secrets at rest (empty = stored in plaintext)
+	SecretsKey         string // SECRETS_KEY
+	SecretsKeyPrevious string // SECRETS_KEY_PREVIOUS, set while rotating
+
+	// Metrics push for hosts Prometheus can't scrape (empty URL = disabled)
+	MetricsPushURL      string // METRICS_PUSH_URL, Pushgateway base URL
+	MetricsPushJob      string // METRICS_PUSH_JOB, default "avalauncher"
+	MetricsPushInterval string // METRICS_PUSH_INTERVAL, default "30s"
+	MetricsPushAuth     string // METRICS_PUSH_AUTH, basic auth "user:password"
+
+	// Email alerts over SMTP (empty host = disabled)
+	SMTPHost            string // SMTP_HOST
+	SMTPPort            string // SMTP_PORT, default "587"
+	SMTPUsername        string // SMTP_USERNAME
+	SMTPPassword        string // SMTP_PASSWORD
+	SMTPFrom            string // SMTP_FROM, sender address
+	AlertEmailTo        string // ALERT_EMAIL_TO, comma-separated recipients
+	AlertEmailMode      string // ALERT_EMAIL_MODE, "immediate" (default) or "digest"
+	AlertEmailThreshold string // ALERT_EMAIL_THRESHOLD, default "5m"
+	AlertEmailDigest    string // ALERT_EMAIL_DIGEST_INTERVAL, default "1h"
+	AlertEmailEvents    string // ALERT_EMAIL_EVENT_SEVERITY, lowest event severity emailed, default "critical" ("none" = no events)
+
+	// Node log request caps; 0 disables a limit
+	LogTailMax        string // LOG_TAIL_MAX, lines per request, default "10000"
+	LogStreamsPerNode string // LOG_STREAMS_PER_NODE, default "2"
+	LogStreamsPerHost string // LOG_STREAMS_PER_HOST, default "8"
+
+	// Node exec policy
+	ExecAllowAny      string // EXEC_ALLOW_ANY, skip the command allowlist, default "false"
+	ExecAllowCommands string // EXEC_ALLOWED_COMMANDS, comma-separated program names
+
+	// Node volume size measurements
+	DiskUsageInterval string // DISK_USAGE_INTERVAL, default "15m"
+
+	// Validator uptime sampling
+	UptimeInterval string // UPTIME_INTERVAL, default "10m"
+
+	// AvalancheGo version refresh of running nodes
+	VersionInterval string // VERSION_INTERVAL, default "5m"
+
+	// Checks for nodes whose image tag has moved to a new digest
+	ImageDriftInterval string // IMAGE_DRIFT_INTERVAL, default "1h"
+
+	// Repair of nodes left creating or failed ("0" = never)
+	StuckNodeTimeout string // STUCK_NODE_TIMEOUT, default "30m"
+
+	// Node state history snapshots, for point-in-time queries
+	NodeHistoryInterval string // NODE_HISTORY_INTERVAL, default "1m"
+
+	// Node backups: db snapshots and staking key exports (empty target = disabled)
+	BackupTarget   string // BACKUP_TARGET, a directory on the control plane or s3://bucket/prefix
+	BackupKeepLast string // BACKUP_KEEP_LAST, backups of each kind kept per node, default "0" (all)
+
+	// S3-compatible object storage for s3:// backup targets
+	S3Endpoint        string // S3_ENDPOINT, e.g. "https://minio.example.com:9000" (empty = AWS)
+	S3Region          string // S3_REGION, default "us-east-1"
+	S3AccessKeyID     string // S3_ACCESS_KEY_ID, default AWS_ACCESS_KEY_ID
+	S3SecretAccessKey string // S3_SECRET_ACCESS_KEY, default AWS_SECRET_ACCESS_KEY
+	S3PathStyle       string // S3_PATH_STYLE, bucket in the URL path (MinIO), default "false"
+
+	// Two nodes with one NodeID: "warn" (default) or "reject"
+	DuplicateNodeID string // DUPLICATE_NODE_ID
+
+	// Default key paying for on-chain L1 deployments (empty = per request only)
+	PChainPrivateKey string // PCHAIN_PRIVATE_KEY, "PrivateKey-..." or hex
+
+	// Janitor for nodes and L1s created with a TTL
+	JanitorInterval string // JANITOR_INTERVAL, default "1m"
+	TTLWarnBefore   string // TTL_WARN_BEFORE, default "1h"
 
 	// Traefik integration for AvalancheGo RPC access
-	TraefikDomain  string // AVAGO_TRAEFIK_DOMAIN, e.g. "avax.primal.host" (empty = disabled)
-	TraefikNetwork string // AVAGO_TRAEFIK_NETWORK, e.g. "infra"
-	TraefikAuth    string // AVAGO_TRAEFIK_AUTH, htpasswd format "user:bcrypt_hash"
+	TraefikDomain       string // AVAGO_TRAEFIK_DOMAIN, e.g. "avax.primal.host" (empty = disabled)
+	TraefikNetwork      string // AVAGO_TRAEFIK_NETWORK, e.g. "infra"
+	TraefikAuth         string // AVAGO_TRAEFIK_AUTH, htpasswd format "user:bcrypt_hash"
+	TraefikCertResolver string // AVAGO_TRAEFIK_CERT_RESOLVER, default "letsencrypt-dns"
+
+	// Per-host Traefik edge proxies with ACME DNS-01 certificates (empty email = disabled)
+	TraefikImage    string // TRAEFIK_IMAGE, default "traefik:v3.3"
+	ACMEEmail       string // ACME_EMAIL
+	ACMEDNSProvider string // ACME_DNS_PROVIDER, "cloudflare" or "route53", default DNS_PROVIDER
+	ACMECAServer    string // ACME_CA_SERVER, optional, e.g. the Let's Encrypt staging directory
+
+	// Health-based DNS for public L1 RPC hostnames (empty provider = disabled)
+	DNSProvider        string // DNS_PROVIDER, "cloudflare" or "route53"
+	DNSTTL             string // DNS_TTL, seconds, default "60"
+	DNSLocalAddress    string // DNS_LOCAL_ADDRESS, public address of the local host
+	CloudflareToken    string // CLOUDFLARE_API_TOKEN
+	CloudflareZoneID   string // CLOUDFLARE_ZONE_ID
+	Route53ZoneID      string // ROUTE53_ZONE_ID
+	AWSAccessKeyID     string // AWS_ACCESS_KEY_ID
+	AWSSecretAccessKey string // AWS_SECRET_ACCESS_KEY
+	AWSSessionToken    string // AWS_SESSION_TOKEN, optional
+
+	// Commands run on matching events (empty = none)
+	EventHooksFile string // EVENT_HOOKS_FILE, YAML list of hooks, see LoadEventHooks
+
+	// Token-less /api/public endpoints
+	PublicRateLimit string // PUBLIC_RATE_LIMIT, requests per minute per client IP, default "30"
+	TrustedProxies  string // TRUSTED_PROXIES, comma-separated CIDRs whose X-Forwarded-For is believed (empty = peer address)
+
+	// Dashboard behaviour, served at /api/ui-config
+	UIPollInterval string // UI_POLL_INTERVAL, default "10s"
+	UIEventStream  string // UI_EVENT_STREAM, "true" (default) or "false"
+	UISections     string // UI_SECTIONS, default "cards,actions,nodes"
+	UIProductName  string // UI_PRODUCT_NAME, default "Avalauncher"
+	UILogoURL      string // UI_LOGO_URL, optional
+	UIAccentColor  string // UI_ACCENT_COLOR, #rgb or #rrggbb, optional
 }
 
 // Load reads configuration from environment variables.
@@ -44,15 +166,102 @@ func Load() (*Config, error) {
 		DBUser:         envOrDefault("DB_USER", "dba_avalauncher"),
 		DBSSLMode:      envOrDefault("DB_SSLMODE", "disable"),
 		ListenAddr:     envOrDefault("LISTEN_ADDR", ":4321"),
+		LogLevel:       envOrDefault("LOG_LEVEL", "info"),
+		LogFormat:      envOrDefault("LOG_FORMAT", "text"),
 		DockerHost:     os.Getenv("DOCKER_HOST"),
 		AvagoImage:     envOrDefault("AVAGO_IMAGE", "avaplatform/avalanchego:latest"),
 		AvagoNetwork:   envOrDefault("AVAGO_NETWORK", "mainnet"),
 		AvaxDockerNet:  envOrDefault("AVAX_DOCKER_NETWORK", "avax"),
 		HealthInterval: envOrDefault("HEALTH_INTERVAL", "30s"),
+		DockerEvents:   envOrDefault("DOCKER_EVENTS", "true"),
+		HealthWorkers:  envOrDefault("HEALTH_WORKERS", "8"),
+		HealthTimeout:  envOrDefault("HEALTH_CHECK_TIMEOUT", "10s"),
+		BootstrapAlert: envOrDefault("BOOTSTRAP_ALERT_AFTER", "6h"),
 		TraefikDomain:  os.Getenv("AVAGO_TRAEFIK_DOMAIN"),
 		TraefikNetwork: envOrDefault("AVAGO_TRAEFIK_NETWORK", "infra"),
 	}
 
+	// Per-network default images (e.g. AVAGO_IMAGE_FUJI).
+	c.AvagoImages = make(map[string]string)
+	for _, network := range []string{"mainnet", "fuji", "local"} {
+		if v := os.Getenv("AVAGO_IMAGE_" + strings.ToUpper(network)); v != "" {
+			c.AvagoImages[network] = v
+		}
+	}
+
+	if v := os.Getenv("AVAGO_IMAGE_ALLOWLIST"); v != "" {
+		c.ImageAllowlist = strings.Split(v, ",")
+	}
+	c.ImageAllowlistRegex = strings.Fields(os.Getenv("AVAGO_IMAGE_ALLOWLIST_REGEX"))
+
+	c.DockerRemoteMaxConcurrent = envOrDefault("DOCKER_REMOTE_MAX_CONCURRENT", "4")
+	c.DockerRemoteRate = envOrDefault("DOCKER_REMOTE_RATE", "10")
+
+	c.MetricsPushURL = os.Getenv("METRICS_PUSH_URL")
+	c.MetricsPushJob = envOrDefault("METRICS_PUSH_JOB", "avalauncher")
+	c.MetricsPushInterval = envOrDefault("METRICS_PUSH_INTERVAL", "30s")
+
+	c.SMTPHost = os.Getenv("SMTP_HOST")
+	c.SMTPPort = envOrDefault("SMTP_PORT", "587")
+	c.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	c.SMTPFrom = os.Getenv("SMTP_FROM")
+	c.AlertEmailTo = os.Getenv("ALERT_EMAIL_TO")
+	c.AlertEmailMode = envOrDefault("ALERT_EMAIL_MODE", "immediate")
+	c.AlertEmailThreshold = envOrDefault("ALERT_EMAIL_THRESHOLD", "5m")
+	c.AlertEmailDigest = envOrDefault("ALERT_EMAIL_DIGEST_INTERVAL", "1h")
+	c.AlertEmailEvents = envOrDefault("ALERT_EMAIL_EVENT_SEVERITY", "critical")
+
+	c.LogTailMax = envOrDefault("LOG_TAIL_MAX", "10000")
+	c.LogStreamsPerNode = envOrDefault("LOG_STREAMS_PER_NODE", "2")
+	c.LogStreamsPerHost = envOrDefault("LOG_STREAMS_PER_HOST", "8")
+
+	c.ExecAllowAny = envOrDefault("EXEC_ALLOW_ANY", "false")
+	c.ExecAllowCommands = os.Getenv("EXEC_ALLOWED_COMMANDS")
+
+	c.DiskUsageInterval = envOrDefault("DISK_USAGE_INTERVAL", "15m")
+
+	c.UptimeInterval = envOrDefault("UPTIME_INTERVAL", "10m")
+
+	c.VersionInterval = envOrDefault("VERSION_INTERVAL", "5m")
+	c.ImageDriftInterval = envOrDefault("IMAGE_DRIFT_INTERVAL", "1h")
+	c.NodeHistoryInterval = envOrDefault("NODE_HISTORY_INTERVAL", "1m")
+	c.StuckNodeTimeout = envOrDefault("STUCK_NODE_TIMEOUT", "30m")
+	c.PublicRateLimit = envOrDefault("PUBLIC_RATE_LIMIT", "30")
+	c.TrustedProxies = os.Getenv("TRUSTED_PROXIES")
+	c.EventHooksFile = os.Getenv("EVENT_HOOKS_FILE")
+
+	c.BackupTarget = os.Getenv("BACKUP_TARGET")
+	c.BackupKeepLast = envOrDefault("BACKUP_KEEP_LAST", "0")
+	c.S3Endpoint = os.Getenv("S3_ENDPOINT")
+	c.S3Region = envOrDefault("S3_REGION", "us-east-1")
+	c.S3AccessKeyID = envOrDefault("S3_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID"))
+	c.S3PathStyle = envOrDefault("S3_PATH_STYLE", "false")
+
+	c.UIPollInterval = envOrDefault("UI_POLL_INTERVAL", "10s")
+	c.UIEventStream = envOrDefault("UI_EVENT_STREAM", "true")
+	c.UISections = envOrDefault("UI_SECTIONS", "cards,actions,nodes")
+	c.UIProductName = os.Getenv("UI_PRODUCT_NAME")
+	c.UILogoURL = os.Getenv("UI_LOGO_URL")
+	c.UIAccentColor = os.Getenv("UI_ACCENT_COLOR")
+
+	c.DuplicateNodeID = envOrDefault("DUPLICATE_NODE_ID", "warn")
+
+	c.DNSProvider = os.Getenv("DNS_PROVIDER")
+	c.DNSTTL = envOrDefault("DNS_TTL", "60")
+	c.DNSLocalAddress = os.Getenv("DNS_LOCAL_ADDRESS")
+	c.CloudflareZoneID = os.Getenv("CLOUDFLARE_ZONE_ID")
+	c.Route53ZoneID = os.Getenv("ROUTE53_ZONE_ID")
+	c.AWSAccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+
+	c.TraefikCertResolver = envOrDefault("AVAGO_TRAEFIK_CERT_RESOLVER", "letsencrypt-dns")
+	c.TraefikImage = envOrDefault("TRAEFIK_IMAGE", "traefik:v3.3")
+	c.ACMEEmail = os.Getenv("ACME_EMAIL")
+	c.ACMEDNSProvider = envOrDefault("ACME_DNS_PROVIDER", c.DNSProvider)
+	c.ACMECAServer = os.Getenv("ACME_CA_SERVER")
+
+	c.JanitorInterval = envOrDefault("JANITOR_INTERVAL", "1m")
+	c.TTLWarnBefore = envOrDefault("TTL_WARN_BEFORE", "1h")
+
 	pw, err := envOrFile("DB_PASSWORD")
 	if err != nil {
 		return nil, fmt.Errorf("DB_PASSWORD: %w", err)
@@ -71,6 +280,43 @@ func Load() (*Config, error) {
 	}
 	c.TraefikAuth = traefikAuth
 
+	if c.SecretsKey, err = envOrFile("SECRETS_KEY"); err != nil {
+		return nil, fmt.Errorf("SECRETS_KEY: %w", err)
+	}
+	if c.SecretsKeyPrevious, err = envOrFile("SECRETS_KEY_PREVIOUS"); err != nil {
+		return nil, fmt.Errorf("SECRETS_KEY_PREVIOUS: %w", err)
+	}
+
+	pushAuth, err := envOrFile("METRICS_PUSH_AUTH")
+	if err != nil {
+		return nil, fmt.Errorf("METRICS_PUSH_AUTH: %w", err)
+	}
+	c.MetricsPushAuth = pushAuth
+
+	if c.SMTPPassword, err = envOrFile("SMTP_PASSWORD"); err != nil {
+		return nil, fmt.Errorf("SMTP_PASSWORD: %w", err)
+	}
+
+	if c.PChainPrivateKey, err = envOrFile("PCHAIN_PRIVATE_KEY"); err != nil {
+		return nil, fmt.Errorf("PCHAIN_PRIVATE_KEY: %w", err)
+	}
+
+	if c.CloudflareToken, err = envOrFile("CLOUDFLARE_API_TOKEN"); err != nil {
+		return nil, fmt.Errorf("CLOUDFLARE_API_TOKEN: %w", err)
+	}
+	if c.AWSSecretAccessKey, err = envOrFile("AWS_SECRET_ACCESS_KEY"); err != nil {
+		return nil, fmt.Errorf("AWS_SECRET_ACCESS_KEY: %w", err)
+	}
+	if c.AWSSessionToken, err = envOrFile("AWS_SESSION_TOKEN"); err != nil {
+		return nil, fmt.Errorf("AWS_SESSION_TOKEN: %w", err)
+	}
+	if c.S3SecretAccessKey, err = envOrFile("S3_SECRET_ACCESS_KEY"); err != nil {
+		return nil, fmt.Errorf("S3_SECRET_ACCESS_KEY: %w", err)
+	}
+	if c.S3SecretAccessKey == "" {
+		c.S3SecretAccessKey = c.AWSSecretAccessKey
+	}
+
 	return c, nil
 }
 
@@ -102,9 +348,9 @@ type NodeConfig struct {
 }
 
 type L1Config struct {
-	Name         string   `yaml:"name"`
-	VM           string   `yaml:"vm"`
-	Validators   []string `yaml:"validators"`
+	Name       string   `yaml:"name"`
+	VM         string   `yaml:"vm"`
+	Validators []string `yaml:"validators"`
 }
 
 // LoadCluster reads and parses a cluster.yaml file.
@@ -120,6 +366,30 @@ func LoadCluster(path string) (*Cluster, error) {
 	return &c, nil
 }
 
+// EventHookConfig is one entry of the event hooks file.
+type EventHookConfig struct {
+	Name    string   `yaml:"name"`
+	Events  []string `yaml:"events"`  // exact types or prefixes like "node.*"
+	Targets []string `yaml:"targets"` // optional node, host or L1 names
+	Command []string `yaml:"command"` // program and arguments, no shell
+	Timeout string   `yaml:"timeout"` // Go duration, default "10s"
+}
+
+// LoadEventHooks reads an event hooks file: a top-level "hooks" list.
+func LoadEventHooks(path string) ([]EventHookConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read event hooks: %w", err)
+	}
+	var f struct {
+		Hooks []EventHookConfig `yaml:"hooks"`
+	}
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse event hooks: %w", err)
+	}
+	return f.Hooks, nil
+}
+
 func envOrDefault(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
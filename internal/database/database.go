@@ -39,3 +39,18 @@ func (db *DB) bootstrap(ctx context.Context) error {
 	_, err := db.Pool.Exec(ctx, schema)
 	return err
 }
+
+// SecretsSalt returns the installation's SECRETS_KEY derivation salt,
+// storing newSalt if none has been stored yet. The salt is not secret but
+// must never change, or sealed values no longer decrypt.
+func (db *DB) SecretsSalt(ctx context.Context, newSalt []byte) ([]byte, error) {
+	if _, err := db.Pool.Exec(ctx,
+		"INSERT INTO secrets_kdf (salt) VALUES ($1) ON CONFLICT (id) DO NOTHING", newSalt); err != nil {
+		return nil, fmt.Errorf("store secrets salt: %w", err)
+	}
+	var salt []byte
+	if err := db.Pool.QueryRow(ctx, "SELECT salt FROM secrets_kdf").Scan(&salt); err != nil {
+		return nil, fmt.Errorf("load secrets salt: %w", err)
+	}
+	return salt, nil
+}
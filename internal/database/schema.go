@@ -58,7 +58,242 @@ CREATE TABLE IF NOT EXISTS events (
 );
 
 CREATE INDEX IF NOT EXISTS idx_events_created_at ON events (created_at DESC);
-CREATE INDEX IF NOT EXISTS idx_events_target ON events (target);
+-- One target's or one type's history, newest first, without sorting the
+-- whole match (GET /api/events?target=, ?type=).
+DROP INDEX IF EXISTS idx_events_target;
+CREATE INDEX IF NOT EXISTS idx_events_target_created ON events (target, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_type_created ON events (event_type, created_at DESC);
 
 ALTER TABLE nodes ADD COLUMN IF NOT EXISTS network TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS staking_signer TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS network_mode TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS dns_aliases TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS health_check TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS throttle JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS entrypoint TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS cmd TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS env JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS node_configs JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS validator_tx_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS staking_end TIMESTAMPTZ;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS cpu_limit DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS memory_limit BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS restart_policy TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS auto_heal BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS expose_http BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS expiry_warned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE hosts ADD COLUMN IF NOT EXISTS cost_per_month DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE hosts ADD COLUMN IF NOT EXISTS address TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS contact TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS url TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS expiry_warned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS create_subnet_tx_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS create_chain_tx_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE l1_validators ADD COLUMN IF NOT EXISTS validation_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS node_latency (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    node_id     BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    method      TEXT NOT NULL,
+    latency_ms  DOUBLE PRECISION NOT NULL,
+    ok          BOOLEAN NOT NULL DEFAULT true,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_latency_node_created ON node_latency (node_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS operations (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    kind        TEXT NOT NULL,
+    node_id     BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    step        TEXT NOT NULL DEFAULT '',
+    state       TEXT NOT NULL DEFAULT 'running',
+    params      JSONB NOT NULL DEFAULT '{}',
+    error       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_operations_state ON operations (state);
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 1;
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS retry_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS pending_validators (
+    l1_id       BIGINT NOT NULL REFERENCES l1s(id) ON DELETE CASCADE,
+    node_id     BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    weight      BIGINT NOT NULL DEFAULT 100,
+    reason      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (l1_id, node_id)
+);
+
+CREATE TABLE IF NOT EXISTS host_metrics (
+    id               BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    host_id          BIGINT NOT NULL REFERENCES hosts(id) ON DELETE CASCADE,
+    disk_total_bytes BIGINT NOT NULL,
+    disk_free_bytes  BIGINT NOT NULL,
+    load1            DOUBLE PRECISION NOT NULL,
+    load5            DOUBLE PRECISION NOT NULL,
+    load15           DOUBLE PRECISION NOT NULL,
+    mem_total_bytes  BIGINT NOT NULL,
+    mem_used_bytes   BIGINT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_host_metrics_host_created ON host_metrics (host_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS secrets (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    value       TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS secrets_kdf (
+    id    BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    salt  BYTEA NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_audit (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    actor       TEXT NOT NULL,
+    method      TEXT NOT NULL,
+    path        TEXT NOT NULL,
+    payload     JSONB NOT NULL DEFAULT '{}',
+    status      INT NOT NULL,
+    error       TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_audit_created_at ON api_audit (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS upgrades (
+    id              BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    image           TEXT NOT NULL,
+    state           TEXT NOT NULL,
+    canary_node_id  BIGINT NOT NULL DEFAULT 0,
+    soak            TEXT NOT NULL DEFAULT '',
+    soak_until      TIMESTAMPTZ,
+    auto_proceed    BOOLEAN NOT NULL DEFAULT false,
+    node_timeout    TEXT NOT NULL DEFAULT '',
+    nodes           JSONB NOT NULL DEFAULT '[]',
+    error           TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS node_health (
+    node_id     BIGINT PRIMARY KEY REFERENCES nodes(id) ON DELETE CASCADE,
+    method      TEXT NOT NULL,
+    healthy     BOOLEAN NOT NULL,
+    checks      JSONB NOT NULL DEFAULT '[]',
+    error       TEXT NOT NULL DEFAULT '',
+    checked_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS node_uptime (
+    id                BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    node_id           BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    connected         BOOLEAN NOT NULL,
+    uptime            DOUBLE PRECISION NOT NULL,
+    rewarding_stake   DOUBLE PRECISION,
+    weighted_average  DOUBLE PRECISION,
+    validation_end    TIMESTAMPTZ,
+    observed_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_node_uptime_node_observed ON node_uptime (node_id, observed_at DESC);
+
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS rpc_url TEXT NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS l1_rpc_nodes (
+    l1_id    BIGINT NOT NULL REFERENCES l1s(id) ON DELETE CASCADE,
+    node_id  BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    PRIMARY KEY (l1_id, node_id)
+);
+
+ALTER TABLE hosts ADD COLUMN IF NOT EXISTS docker_api_version TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS l1_validator_weights (
+    id             BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    l1_id          BIGINT NOT NULL,
+    l1_name        TEXT NOT NULL DEFAULT '',
+    node_id        BIGINT NOT NULL,
+    node_name      TEXT NOT NULL,
+    avago_node_id  TEXT NOT NULL DEFAULT '',
+    weight         BIGINT NOT NULL,
+    reason         TEXT NOT NULL,
+    recorded_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE l1_validator_weights DROP CONSTRAINT IF EXISTS l1_validator_weights_l1_id_fkey;
+ALTER TABLE l1_validator_weights ADD COLUMN IF NOT EXISTS l1_name TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_l1_validator_weights_l1_recorded ON l1_validator_weights (l1_id, recorded_at);
+INSERT INTO l1_validator_weights (l1_id, l1_name, node_id, node_name, avago_node_id, weight, reason)
+SELECT v.l1_id, l.name, v.node_id, n.name, n.node_id, v.weight, 'backfill'
+FROM l1_validators v JOIN nodes n ON n.id = v.node_id JOIN l1s l ON l.id = v.l1_id
+WHERE NOT EXISTS (SELECT 1 FROM l1_validator_weights w WHERE w.l1_id = v.l1_id AND w.node_id = v.node_id);
+
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS rpc_dns_records TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS image_digest TEXT NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS avago_version TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS severity TEXT NOT NULL DEFAULT 'info';
+CREATE INDEX IF NOT EXISTS idx_events_severity ON events (severity, created_at DESC);
+
+ALTER TABLE upgrades ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ;
+ALTER TABLE upgrades ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS node_history (
+    id              BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    node_id         BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    host_id         BIGINT NOT NULL,
+    status          TEXT NOT NULL,
+    image           TEXT NOT NULL,
+    image_digest    TEXT NOT NULL DEFAULT '',
+    avago_version   TEXT NOT NULL DEFAULT '',
+    container_id    TEXT NOT NULL DEFAULT '',
+    healthy         BOOLEAN,
+    health_error    TEXT NOT NULL DEFAULT '',
+    failing_checks  TEXT[] NOT NULL DEFAULT '{}',
+    recorded_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_node_history_node_recorded ON node_history (node_id, recorded_at DESC);
+
+CREATE TABLE IF NOT EXISTS snapshots (
+    id            BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    node_id       BIGINT REFERENCES nodes(id) ON DELETE SET NULL,
+    node_name     TEXT NOT NULL,
+    network       TEXT NOT NULL,
+    image         TEXT NOT NULL DEFAULT '',
+    avago_version TEXT NOT NULL DEFAULT '',
+    store         TEXT NOT NULL,
+    key           TEXT NOT NULL DEFAULT '',
+    size_bytes    BIGINT NOT NULL DEFAULT 0,
+    state         TEXT NOT NULL DEFAULT 'creating',
+    error         TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_snapshots_node_created ON snapshots (node_id, created_at DESC);
+
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'db';
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+CREATE INDEX IF NOT EXISTS idx_nodes_tags ON nodes USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS l1_status_history (
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    l1_id        BIGINT NOT NULL REFERENCES l1s(id) ON DELETE CASCADE,
+    verdict      TEXT NOT NULL,
+    healthy      INT NOT NULL,
+    total        INT NOT NULL,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_l1_status_history_l1_recorded ON l1_status_history (l1_id, recorded_at DESC);
+
+ALTER TABLE l1s ADD COLUMN IF NOT EXISTS public BOOLEAN NOT NULL DEFAULT false;
 `
@@ -0,0 +1,126 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const cloudflareAPI = "https://api.cloudflare.com/client/v4"
+
+// Cloudflare manages records in a Cloudflare zone with an API token that
+// has DNS edit permission on it. Records are created unproxied so clients
+// reach the nodes' hosts directly.
+type Cloudflare struct {
+	Token  string
+	ZoneID string
+	API    string // base URL; empty = the public API
+}
+
+// cfRecord is a Cloudflare DNS record.
+type cfRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl"`
+	Proxied bool   `json:"proxied"`
+}
+
+// cfResponse is the envelope of every Cloudflare API response.
+type cfResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result json.RawMessage `json:"result"`
+}
+
+func (c *Cloudflare) Name() string { return "cloudflare" }
+
+// SetRecords lists the name's A/AAAA records, deletes the unwanted ones and
+// creates the missing ones. Records already pointing at a wanted IP are left
+// alone, so a partial failure never takes down a healthy address.
+func (c *Cloudflare) SetRecords(ctx context.Context, name string, ips []string, ttl int) error {
+	v4, v6, err := splitFamilies(ips)
+	if err != nil {
+		return err
+	}
+	for typ, want := range map[string][]string{"A": v4, "AAAA": v6} {
+		var have []cfRecord
+		q := url.Values{"type": {typ}, "name": {name}, "per_page": {"100"}}
+		if err := c.do(ctx, http.MethodGet, "/dns_records?"+q.Encode(), nil, &have); err != nil {
+			return fmt.Errorf("list %s records: %w", typ, err)
+		}
+		for _, ip := range want {
+			if slices.ContainsFunc(have, func(r cfRecord) bool { return r.Content == ip }) {
+				continue
+			}
+			rec := cfRecord{Type: typ, Name: name, Content: ip, TTL: ttl}
+			if err := c.do(ctx, http.MethodPost, "/dns_records", rec, nil); err != nil {
+				return fmt.Errorf("create %s %s: %w", typ, ip, err)
+			}
+		}
+		for _, r := range have {
+			if slices.Contains(want, r.Content) {
+				continue
+			}
+			if err := c.do(ctx, http.MethodDelete, "/dns_records/"+url.PathEscape(r.ID), nil, nil); err != nil {
+				return fmt.Errorf("delete %s %s: %w", typ, r.Content, err)
+			}
+		}
+	}
+	return nil
+}
+
+// do calls a zone endpoint and decodes the result into out (if non-nil).
+func (c *Cloudflare) do(ctx context.Context, method, path string, in, out any) error {
+	base := c.API
+	if base == "" {
+		base = cloudflareAPI
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base+"/zones/"+url.PathEscape(c.ZoneID)+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r cfResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("cloudflare: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if !r.Success {
+		msgs := make([]string, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("cloudflare: HTTP %d: %s", resp.StatusCode, strings.Join(msgs, "; "))
+	}
+	if out != nil {
+		return json.Unmarshal(r.Result, out)
+	}
+	return nil
+}
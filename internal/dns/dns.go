@@ -0,0 +1,43 @@
+// Package dns keeps address records at a DNS provider pointed at a set of
+// IPs, for health-based RPC failover. Only the provider REST APIs are used,
+// so no SDKs are needed.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Provider manages the A and AAAA records of names in one zone.
+type Provider interface {
+	// Name identifies the provider in logs and events.
+	Name() string
+	// SetRecords makes name resolve to exactly ips: IPv4 addresses become A
+	// records, IPv6 ones AAAA records. An empty list deletes both.
+	SetRecords(ctx context.Context, name string, ips []string, ttl int) error
+}
+
+// httpClient is shared by the providers.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// splitFamilies sorts ips into IPv4 and IPv6 addresses.
+func splitFamilies(ips []string) (v4, v6 []string, err error) {
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		switch {
+		case ip == nil:
+			return nil, nil, fmt.Errorf("invalid IP %q", s)
+		case ip.To4() != nil:
+			v4 = append(v4, ip.String())
+		default:
+			v6 = append(v6, ip.String())
+		}
+	}
+	slices.Sort(v4)
+	slices.Sort(v6)
+	return slices.Compact(v4), slices.Compact(v6), nil
+}
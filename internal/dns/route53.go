@@ -0,0 +1,168 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/awssig"
+)
+
+const (
+	route53API     = "https://route53.amazonaws.com"
+	route53Version = "2013-04-01"
+	route53Region  = "us-east-1" // Route 53 is global; requests are signed for us-east-1
+)
+
+// Route53 manages records in a Route 53 hosted zone with IAM credentials
+// allowed route53:ListResourceRecordSets and route53:ChangeResourceRecordSets.
+type Route53 struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+	ZoneID          string // hosted zone ID, with or without "/hostedzone/"
+	API             string // base URL; empty = the public API
+}
+
+type r53Value struct {
+	Value string `xml:"Value"`
+}
+
+type r53RecordSet struct {
+	Name            string     `xml:"Name"`
+	Type            string     `xml:"Type"`
+	TTL             int        `xml:"TTL"`
+	ResourceRecords []r53Value `xml:"ResourceRecords>ResourceRecord"`
+}
+
+type r53Change struct {
+	Action    string       `xml:"Action"`
+	RecordSet r53RecordSet `xml:"ResourceRecordSet"`
+}
+
+type r53ChangeRequest struct {
+	XMLName xml.Name    `xml:"https://route53.amazonaws.com/doc/2013-04-01/ ChangeResourceRecordSetsRequest"`
+	Comment string      `xml:"ChangeBatch>Comment"`
+	Changes []r53Change `xml:"ChangeBatch>Changes>Change"`
+}
+
+type r53ListResponse struct {
+	RecordSets []r53RecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+}
+
+type r53Error struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+func (r *Route53) Name() string { return "route53" }
+
+// SetRecords upserts the name's A and AAAA record sets, or deletes a set
+// when no IPs of its family remain (Route 53 can't hold an empty set).
+func (r *Route53) SetRecords(ctx context.Context, name string, ips []string, ttl int) error {
+	v4, v6, err := splitFamilies(ips)
+	if err != nil {
+		return err
+	}
+	fqdn := strings.TrimSuffix(name, ".") + "."
+	var changes []r53Change
+	for _, typ := range []string{"A", "AAAA"} {
+		want := v4
+		if typ == "AAAA" {
+			want = v6
+		}
+		if len(want) > 0 {
+			set := r53RecordSet{Name: fqdn, Type: typ, TTL: ttl}
+			for _, ip := range want {
+				set.ResourceRecords = append(set.ResourceRecords, r53Value{ip})
+			}
+			changes = append(changes, r53Change{Action: "UPSERT", RecordSet: set})
+			continue
+		}
+		// A delete must match the existing set exactly.
+		existing, err := r.recordSet(ctx, fqdn, typ)
+		if err != nil {
+			return fmt.Errorf("list %s records: %w", typ, err)
+		}
+		if existing != nil {
+			changes = append(changes, r53Change{Action: "DELETE", RecordSet: *existing})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	body, err := xml.Marshal(r53ChangeRequest{Comment: "avalauncher RPC failover", Changes: changes})
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodPost, "/rrset", nil, append([]byte(xml.Header), body...), nil)
+}
+
+// recordSet returns the name's record set of one type, or nil.
+func (r *Route53) recordSet(ctx context.Context, fqdn, typ string) (*r53RecordSet, error) {
+	var list r53ListResponse
+	q := url.Values{"name": {fqdn}, "type": {typ}, "maxitems": {"1"}}
+	if err := r.do(ctx, http.MethodGet, "/rrset", q, nil, &list); err != nil {
+		return nil, err
+	}
+	// Listing starts at name/type; the first set may be a later one.
+	i := slices.IndexFunc(list.RecordSets, func(s r53RecordSet) bool {
+		return strings.EqualFold(s.Name, fqdn) && s.Type == typ
+	})
+	if i < 0 {
+		return nil, nil
+	}
+	return &list.RecordSets[i], nil
+}
+
+// do sends a signed request to a hosted zone endpoint and decodes the XML
+// response into out (if non-nil).
+func (r *Route53) do(ctx context.Context, method, path string, query url.Values, body []byte, out any) error {
+	base := r.API
+	if base == "" {
+		base = route53API
+	}
+	zone := strings.TrimPrefix(r.ZoneID, "/hostedzone/")
+	u := base + "/" + route53Version + "/hostedzone/" + url.PathEscape(zone) + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/xml")
+	}
+	awssig.Sign(req, body, awssig.Credentials{
+		AccessKeyID: r.AccessKeyID, SecretAccessKey: r.SecretAccessKey, SessionToken: r.SessionToken,
+	}, route53Region, "route53", time.Now())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e r53Error
+		if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+			return fmt.Errorf("route53: HTTP %d: %s: %s", resp.StatusCode, e.Code, e.Message)
+		}
+		return fmt.Errorf("route53: HTTP %d", resp.StatusCode)
+	}
+	if out != nil {
+		return xml.Unmarshal(data, out)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// DBDir is where AvalancheGo keeps its database, the node's db volume.
+const DBDir = DataDir + "/db"
+
+// ArchiveDir streams dir of a container (stopped ones included) as an
+// uncompressed tar whose entries are prefixed with the base name of dir.
+func (c *Client) ArchiveDir(ctx context.Context, id, dir string) (io.ReadCloser, error) {
+	rc, _, err := c.cli.CopyFromContainer(ctx, id, dir)
+	if err != nil {
+		return nil, fmt.Errorf("copy %s: %w", dir, err)
+	}
+	return rc, nil
+}
+
+// ExtractArchive unpacks a tar stream into dir of a container, including
+// into volumes mounted below it. The container need not be running.
+func (c *Client) ExtractArchive(ctx context.Context, id, dir string, r io.Reader) error {
+	if err := c.cli.CopyToContainer(ctx, id, dir, r, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("copy to %s: %w", dir, err)
+	}
+	return nil
+}
+
+// EmptyVolume deletes everything in a volume by mounting it into a
+// short-lived probeImage (busybox or similar) container.
+func (c *Client) EmptyVolume(ctx context.Context, probeImage, volume string) error {
+	hc := &container.HostConfig{
+		Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: volume, Target: "/volume"}},
+	}
+	code, out, err := c.RunOnce(ctx, probeImage, []string{"find", "/volume", "-mindepth", "1", "-delete"}, hc)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("empty volume %s: exited %d: %s", volume, code, strings.TrimSpace(out))
+	}
+	return nil
+}
@@ -2,28 +2,116 @@ package docker
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/docker/docker/api/types/blkiodev"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/go-connections/nat"
 )
 
+// DataDir is AvalancheGo's data directory inside the container.
+const DataDir = "/root/.avalanchego"
+
+// L1ConfigDir, relative to DataDir, holds per-L1 files written by
+// avalauncher, e.g. <l1 id>/genesis.json.
+const L1ConfigDir = "configs/l1s"
+
+// StakingDir is where AvalancheGo reads its staking certificate, key, and
+// BLS signer key (staker.crt, staker.key, signer.key) inside the container.
+const StakingDir = "/root/.avalanchego/staking"
+
 // AvagoParams defines parameters for creating an AvalancheGo container.
 type AvagoParams struct {
-	Name        string // node name (used in container name and volume names)
-	Image       string // Docker image reference
-	NetworkName string // Docker network to attach to (e.g. "avax")
-	NetworkID   string // Avalanche network: mainnet, fuji, local
-	StakingPort   int      // host port for P2P staking (9651)
-	ExposeHTTP    bool     // whether to publish HTTP API port to host
-	TrackSubnets  []string // L1 subnet IDs for AVAGO_TRACK_SUBNETS
+	Name          string     // node name (used in container name and volume names)
+	Image         string     // Docker image reference
+	NetworkName   string     // Docker network to attach to (e.g. "avax")
+	NetworkID     string     // Avalanche network: mainnet, fuji, local
+	StakingPort   int        // host port for P2P staking (9651)
+	ExposeHTTP    bool       // whether to publish HTTP API port to host
+	HTTPBindIP    string     // host IP ExposeHTTP binds; default 127.0.0.1
+	HostNetwork   bool       // use the host's network stack instead of a bridge
+	HTTPPort      int        // HTTP API host port: bound in host network mode, published by ExposeHTTP
+	DNSAliases    []string   // extra DNS names on the avax network endpoint
+	TrackSubnets  []string   // L1 subnet IDs for AVAGO_TRACK_SUBNETS
+	Entrypoint    []string   // overrides the image ENTRYPOINT when non-empty
+	Cmd           []string   // overrides the image CMD when non-empty
+	Env           []string   // extra KEY=VALUE entries; override managed ones with the same key
+	Config        NodeConfig // AvalancheGo flag and chain config overrides
+	Throttle      Throttle   // disk and P2P bandwidth limits
+	CPULimit      float64    // max CPU cores; 0 = unlimited
+	MemoryLimit   int64      // max memory in bytes; 0 = unlimited
+	RestartPolicy string     // "unless-stopped" (default when empty), "on-failure[:N]" or "none"
 
 	// Traefik RPC routing (empty TraefikDomain disables)
-	TraefikDomain  string // domain suffix, e.g. "avax.primal.host" → <name>.avax.primal.host
-	TraefikNetwork string // Docker network Traefik can reach (e.g. "infra")
-	TraefikAuth    string // htpasswd entry for basicauth (e.g. "primal:$2y$...")
+	TraefikDomain   string    // domain suffix, e.g. "avax.primal.host" → <name>.avax.primal.host
+	TraefikNetwork  string    // Docker network Traefik can reach (e.g. "infra")
+	TraefikAuth     string    // htpasswd entry for basicauth (e.g. "primal:$2y$...")
+	TraefikResolver string    // ACME cert resolver; empty = DefaultCertResolver
+	L1Routes        []L1Route // public L1 RPC endpoints this node backs
+}
+
+// DefaultCertResolver is the Traefik ACME resolver routers use unless
+// another is configured. It must be defined in Traefik's static config, as
+// the edge proxies avalauncher deploys do (see EdgeProxyParams).
+const DefaultCertResolver = "letsencrypt-dns"
+
+// L1Route publishes an L1's chain RPC at <Name>-rpc.<TraefikDomain>. Every
+// node backing the route carries identical labels, so Traefik merges them
+// into one load-balanced service.
+type L1Route struct {
+	Name         string // L1 name
+	BlockchainID string // chain whose /ext/bc/<id>/rpc is served
+}
+
+// L1RPCHost returns the public hostname of an L1's RPC endpoint.
+func L1RPCHost(l1Name, traefikDomain string) string {
+	return l1Name + "-rpc." + traefikDomain
+}
+
+// Throttle limits a node's disk I/O and inbound P2P bandwidth so that a
+// bootstrapping node doesn't starve other nodes on the same host. Zero
+// values leave the Docker or AvalancheGo defaults in place.
+type Throttle struct {
+	BlkioWeight      uint16 `json:"blkio_weight,omitempty"`      // relative block I/O weight, 10-1000
+	BlkioDevice      string `json:"blkio_device,omitempty"`      // device for read/write limits, e.g. /dev/sda
+	ReadBps          uint64 `json:"read_bps,omitempty"`          // max read bytes/s from BlkioDevice
+	WriteBps         uint64 `json:"write_bps,omitempty"`         // max write bytes/s to BlkioDevice
+	InboundBandwidth uint64 `json:"inbound_bandwidth,omitempty"` // per-peer inbound bytes/s (throttler refill rate)
+	InboundBurst     uint64 `json:"inbound_burst,omitempty"`     // per-peer inbound burst bytes
+}
+
+// Validate checks that the throttle settings are consistent.
+func (t Throttle) Validate() error {
+	if t.BlkioWeight != 0 && (t.BlkioWeight < 10 || t.BlkioWeight > 1000) {
+		return fmt.Errorf("blkio_weight must be between 10 and 1000")
+	}
+	if (t.ReadBps > 0 || t.WriteBps > 0) && !strings.HasPrefix(t.BlkioDevice, "/dev/") {
+		return fmt.Errorf("read_bps/write_bps require blkio_device (e.g. /dev/sda)")
+	}
+	return nil
+}
+
+// ParseRestartPolicy turns a node's restart policy into Docker's:
+// "unless-stopped" (also the empty default), "on-failure" with an optional
+// ":N" retry limit, or "none" (Docker's "no").
+func ParseRestartPolicy(s string) (container.RestartPolicy, error) {
+	switch s {
+	case "", "unless-stopped":
+		return container.RestartPolicy{Name: container.RestartPolicyUnlessStopped}, nil
+	case "none", "no":
+		return container.RestartPolicy{Name: container.RestartPolicyDisabled}, nil
+	case "on-failure":
+		return container.RestartPolicy{Name: container.RestartPolicyOnFailure}, nil
+	}
+	if n, ok := strings.CutPrefix(s, "on-failure:"); ok {
+		if retries, err := strconv.Atoi(n); err == nil && retries > 0 {
+			return container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: retries}, nil
+		}
+	}
+	return container.RestartPolicy{}, fmt.Errorf("invalid restart_policy %q (want unless-stopped, on-failure, on-failure:N or none)", s)
 }
 
 // ContainerName returns the Docker container name for this node.
@@ -46,6 +134,13 @@ func (p *AvagoParams) VolumeLogs() string {
 	return "avax-" + p.Name + "-logs"
 }
 
+// RoutesNodeRPC reports whether the node's own RPC router is published
+// through Traefik: only for expose_http nodes, and only behind basic auth, so
+// a missing AVAGO_TRAEFIK_AUTH leaves the API unrouted rather than public.
+func (p *AvagoParams) RoutesNodeRPC() bool {
+	return p.ExposeHTTP && p.TraefikDomain != "" && p.TraefikAuth != "" && !p.HostNetwork
+}
+
 // BuildContainerConfig returns Docker container, host, and networking configs
 // for an AvalancheGo node.
 func (p *AvagoParams) BuildContainerConfig() (*container.Config, *container.HostConfig, *network.NetworkingConfig) {
@@ -70,6 +165,35 @@ func (p *AvagoParams) BuildContainerConfig() (*container.Config, *container.Host
 	if len(p.TrackSubnets) > 0 {
 		env = append(env, "AVAGO_TRACK_SUBNETS="+strings.Join(p.TrackSubnets, ","))
 	}
+	if p.Throttle.InboundBandwidth > 0 {
+		env = append(env, fmt.Sprintf("AVAGO_THROTTLER_INBOUND_BANDWIDTH_REFILL_RATE=%d", p.Throttle.InboundBandwidth))
+	}
+	if p.Throttle.InboundBurst > 0 {
+		env = append(env, fmt.Sprintf("AVAGO_THROTTLER_INBOUND_BANDWIDTH_MAX_BURST_SIZE=%d", p.Throttle.InboundBurst))
+	}
+	if p.HostNetwork {
+		// No port mapping in host mode: bind the configured ports directly.
+		env = append(env,
+			fmt.Sprintf("AVAGO_HTTP_PORT=%d", p.HTTPPort),
+			fmt.Sprintf("AVAGO_STAKING_PORT=%d", p.StakingPort),
+		)
+	}
+
+	// Flag overrides come before Env so an explicit env entry wins.
+	for _, e := range append(p.Config.env(), p.Env...) {
+		key, _, _ := strings.Cut(e, "=")
+		replaced := false
+		for i, cur := range env {
+			if strings.HasPrefix(cur, key+"=") {
+				env[i] = e
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			env = append(env, e)
+		}
+	}
 
 	exposedPorts := nat.PortSet{
 		"9650/tcp": struct{}{},
@@ -82,8 +206,16 @@ func (p *AvagoParams) BuildContainerConfig() (*container.Config, *container.Host
 		},
 	}
 	if p.ExposeHTTP {
+		bindIP := p.HTTPBindIP
+		if bindIP == "" {
+			bindIP = "127.0.0.1"
+		}
+		hostPort := p.HTTPPort
+		if hostPort == 0 {
+			hostPort = 9650
+		}
 		portBindings["9650/tcp"] = []nat.PortBinding{
-			{HostIP: "127.0.0.1", HostPort: "9650"},
+			{HostIP: bindIP, HostPort: fmt.Sprintf("%d", hostPort)},
 		}
 	}
 
@@ -92,40 +224,68 @@ func (p *AvagoParams) BuildContainerConfig() (*container.Config, *container.Host
 		LabelNodeName:  p.Name,
 	}
 
-	// Traefik labels for RPC routing with basic auth.
-	if p.TraefikDomain != "" {
-		routerName := "avax-" + p.Name
-		host := p.Name + "." + p.TraefikDomain
-		localHost := p.Name + ".avax.localhost"
-
+	// Traefik labels for RPC routing. Host-network nodes aren't attached to
+	// the Traefik network, so they're not routed.
+	if p.TraefikDomain != "" && !p.HostNetwork && (p.RoutesNodeRPC() || len(p.L1Routes) > 0) {
+		resolver := p.TraefikResolver
+		if resolver == "" {
+			resolver = DefaultCertResolver
+		}
 		labels["traefik.enable"] = "true"
 		labels["traefik.docker.network"] = p.TraefikNetwork
 
-		// HTTPS router with basicauth.
-		labels["traefik.http.routers."+routerName+".rule"] = "Host(`" + host + "`)"
-		labels["traefik.http.routers."+routerName+".entrypoints"] = "https"
-		labels["traefik.http.routers."+routerName+".tls.certresolver"] = "letsencrypt-dns"
-		labels["traefik.http.routers."+routerName+".tls.domains[0].main"] = p.TraefikDomain
-		labels["traefik.http.routers."+routerName+".tls.domains[0].sans"] = "*." + p.TraefikDomain
-		labels["traefik.http.routers."+routerName+".middlewares"] = "avax-auth"
-
-		// HTTP → HTTPS redirect.
-		labels["traefik.http.routers."+routerName+"-redirect.rule"] = "Host(`" + host + "`)"
-		labels["traefik.http.routers."+routerName+"-redirect.entrypoints"] = "http"
-		labels["traefik.http.routers."+routerName+"-redirect.middlewares"] = "https-redirect"
-
-		// Local HTTP router with basicauth.
-		labels["traefik.http.routers."+routerName+"-local.rule"] = "Host(`" + localHost + "`)"
-		labels["traefik.http.routers."+routerName+"-local.entrypoints"] = "http"
-		labels["traefik.http.routers."+routerName+"-local.middlewares"] = "avax-auth"
-
-		// Service.
-		labels["traefik.http.services."+routerName+".loadbalancer.server.port"] = "9650"
-
-		// Basicauth middleware (shared across all nodes).
-		if p.TraefikAuth != "" {
+		if p.RoutesNodeRPC() {
+			routerName := "avax-" + p.Name
+			host := p.Name + "." + p.TraefikDomain
+			localHost := p.Name + ".avax.localhost"
+
+			// HTTPS router with basicauth.
+			labels["traefik.http.routers."+routerName+".rule"] = "Host(`" + host + "`)"
+			labels["traefik.http.routers."+routerName+".entrypoints"] = "https"
+			labels["traefik.http.routers."+routerName+".tls.certresolver"] = resolver
+			labels["traefik.http.routers."+routerName+".tls.domains[0].main"] = p.TraefikDomain
+			labels["traefik.http.routers."+routerName+".tls.domains[0].sans"] = "*." + p.TraefikDomain
+			labels["traefik.http.routers."+routerName+".middlewares"] = "avax-auth"
+			labels["traefik.http.routers."+routerName+".service"] = routerName
+
+			// HTTP → HTTPS redirect.
+			labels["traefik.http.routers."+routerName+"-redirect.rule"] = "Host(`" + host + "`)"
+			labels["traefik.http.routers."+routerName+"-redirect.entrypoints"] = "http"
+			labels["traefik.http.routers."+routerName+"-redirect.middlewares"] = "https-redirect"
+			labels["traefik.http.routers."+routerName+"-redirect.service"] = routerName
+
+			// Local HTTP router with basicauth.
+			labels["traefik.http.routers."+routerName+"-local.rule"] = "Host(`" + localHost + "`)"
+			labels["traefik.http.routers."+routerName+"-local.entrypoints"] = "http"
+			labels["traefik.http.routers."+routerName+"-local.middlewares"] = "avax-auth"
+			labels["traefik.http.routers."+routerName+"-local.service"] = routerName
+
+			// Service.
+			labels["traefik.http.services."+routerName+".loadbalancer.server.port"] = "9650"
+
+			// Basicauth middleware (shared across all nodes).
 			labels["traefik.http.middlewares.avax-auth.basicauth.users"] = p.TraefikAuth
 		}
+
+		// Public L1 RPC endpoints, without basic auth. The path is rewritten
+		// to the chain's RPC handler, so nothing else on the node is reachable.
+		for _, r := range p.L1Routes {
+			l1Router := "l1-" + r.Name
+			l1Host := L1RPCHost(r.Name, p.TraefikDomain)
+			labels["traefik.http.routers."+l1Router+".rule"] = "Host(`" + l1Host + "`)"
+			labels["traefik.http.routers."+l1Router+".entrypoints"] = "https"
+			labels["traefik.http.routers."+l1Router+".tls.certresolver"] = resolver
+			labels["traefik.http.routers."+l1Router+".tls.domains[0].main"] = p.TraefikDomain
+			labels["traefik.http.routers."+l1Router+".tls.domains[0].sans"] = "*." + p.TraefikDomain
+			labels["traefik.http.routers."+l1Router+".middlewares"] = l1Router + "-path"
+			labels["traefik.http.routers."+l1Router+".service"] = l1Router
+			labels["traefik.http.routers."+l1Router+"-redirect.rule"] = "Host(`" + l1Host + "`)"
+			labels["traefik.http.routers."+l1Router+"-redirect.entrypoints"] = "http"
+			labels["traefik.http.routers."+l1Router+"-redirect.middlewares"] = "https-redirect"
+			labels["traefik.http.routers."+l1Router+"-redirect.service"] = l1Router
+			labels["traefik.http.middlewares."+l1Router+"-path.replacepath.path"] = "/ext/bc/" + r.BlockchainID + "/rpc"
+			labels["traefik.http.services."+l1Router+".loadbalancer.server.port"] = "9650"
+		}
 	}
 
 	cc := &container.Config{
@@ -134,19 +294,42 @@ func (p *AvagoParams) BuildContainerConfig() (*container.Config, *container.Host
 		ExposedPorts: exposedPorts,
 		Labels:       labels,
 	}
+	if len(p.Entrypoint) > 0 {
+		cc.Entrypoint = p.Entrypoint
+	}
+	if len(p.Cmd) > 0 {
+		cc.Cmd = p.Cmd
+	}
 
 	hc := &container.HostConfig{
 		PortBindings: portBindings,
 		Mounts: []mount.Mount{
 			{Type: mount.TypeVolume, Source: p.VolumeDB(), Target: "/root/.avalanchego/db"},
-			{Type: mount.TypeVolume, Source: p.VolumeStaking(), Target: "/root/.avalanchego/staking"},
+			{Type: mount.TypeVolume, Source: p.VolumeStaking(), Target: StakingDir},
 			{Type: mount.TypeVolume, Source: p.VolumeLogs(), Target: "/root/.avalanchego/logs"},
 		},
-		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+	}
+	// Validated when the policy was stored.
+	hc.RestartPolicy, _ = ParseRestartPolicy(p.RestartPolicy)
+	hc.BlkioWeight = p.Throttle.BlkioWeight
+	if p.Throttle.ReadBps > 0 {
+		hc.BlkioDeviceReadBps = []*blkiodev.ThrottleDevice{{Path: p.Throttle.BlkioDevice, Rate: p.Throttle.ReadBps}}
+	}
+	if p.Throttle.WriteBps > 0 {
+		hc.BlkioDeviceWriteBps = []*blkiodev.ThrottleDevice{{Path: p.Throttle.BlkioDevice, Rate: p.Throttle.WriteBps}}
+	}
+	hc.NanoCPUs = int64(p.CPULimit * 1e9)
+	hc.Memory = p.MemoryLimit
+
+	if p.HostNetwork {
+		hc.NetworkMode = network.NetworkHost
+		hc.PortBindings = nil
+		cc.ExposedPorts = nil
+		return cc, hc, &network.NetworkingConfig{}
 	}
 
 	endpoints := map[string]*network.EndpointSettings{
-		p.NetworkName: {},
+		p.NetworkName: {Aliases: p.DNSAliases},
 	}
 	// Add Traefik network so Traefik can route to the container.
 	if p.TraefikDomain != "" && p.TraefikNetwork != "" && p.TraefikNetwork != p.NetworkName {
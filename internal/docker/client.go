@@ -1,16 +1,26 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 )
 
 const (
@@ -21,7 +31,8 @@ const (
 
 // Client wraps the Docker SDK client.
 type Client struct {
-	cli *client.Client
+	cli  *client.Client
+	base http.RoundTripper // unwrapped transport when Limits apply
 }
 
 // New creates a Docker client. host may be empty for the default socket.
@@ -38,24 +49,39 @@ func New(host string) (*Client, error) {
 }
 
 // NewSSH creates a Docker client that connects over SSH using connhelper.
-func NewSSH(sshAddr string) (*Client, error) {
+// Requests are throttled by lim so bursts don't overwhelm small daemons, and
+// counted in stats (nil = not counted).
+func NewSSH(sshAddr string, lim Limits, stats *ConnStats) (*Client, error) {
 	helper, err := connhelper.GetConnectionHelper("ssh://" + sshAddr)
 	if err != nil {
 		return nil, fmt.Errorf("ssh connhelper: %w", err)
 	}
-	cli, err := client.NewClientWithOpts(
+	c := &Client{}
+	opts := []client.Opt{
 		client.WithHost(helper.Host),
 		client.WithDialContext(helper.Dialer),
 		client.WithAPIVersionNegotiation(),
-	)
+	}
+	if stats != nil {
+		opts = append(opts, withConnStats(stats, &c.base))
+	}
+	if lim.Enabled() {
+		opts = append(opts, withLimits(lim, &c.base))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("docker ssh client: %w", err)
 	}
-	return &Client{cli: cli}, nil
+	c.cli = cli
+	return c, nil
 }
 
 // Close releases Docker client resources.
 func (c *Client) Close() error {
+	// The SDK only closes idle connections of an unwrapped transport.
+	if t, ok := c.base.(*http.Transport); ok {
+		t.CloseIdleConnections()
+	}
 	return c.cli.Close()
 }
 
@@ -100,6 +126,79 @@ func (c *Client) HostInfo(ctx context.Context) (*HostInfo, error) {
 	}, nil
 }
 
+// HostUtilization is a point-in-time sample of a Docker host's load.
+type HostUtilization struct {
+	DiskTotalBytes int64   `json:"disk_total_bytes"` // filesystem holding the Docker data root
+	DiskFreeBytes  int64   `json:"disk_free_bytes"`
+	Load1          float64 `json:"load1"`
+	Load5          float64 `json:"load5"`
+	Load15         float64 `json:"load15"`
+	MemTotalBytes  int64   `json:"mem_total_bytes"`
+	MemUsedBytes   int64   `json:"mem_used_bytes"` // total minus MemAvailable
+}
+
+// HostUtilization samples disk space on the Docker data root, load average,
+// and memory by running probeImage (busybox or similar) with the data root
+// mounted read-only. /proc/loadavg and /proc/meminfo are host-wide.
+func (c *Client) HostUtilization(ctx context.Context, probeImage string) (*HostUtilization, error) {
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hc := &container.HostConfig{
+		Mounts: []mount.Mount{{Type: mount.TypeBind, Source: info.DockerRootDir, Target: "/docker-root", ReadOnly: true}},
+	}
+	script := "cat /proc/loadavg && grep -E '^(MemTotal|MemAvailable):' /proc/meminfo && df -Pk /docker-root | tail -n 1"
+	code, out, err := c.RunOnce(ctx, probeImage, []string{"sh", "-c", script}, hc)
+	if err != nil {
+		return nil, err
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("probe exited %d: %s", code, strings.TrimSpace(out))
+	}
+	return parseUtilization(out)
+}
+
+// parseUtilization parses the output of the HostUtilization probe script.
+func parseUtilization(out string) (*HostUtilization, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 4 {
+		return nil, fmt.Errorf("unexpected probe output: %q", out)
+	}
+	u := &HostUtilization{}
+	if _, err := fmt.Sscanf(lines[0], "%f %f %f", &u.Load1, &u.Load5, &u.Load15); err != nil {
+		return nil, fmt.Errorf("parse loadavg: %w", err)
+	}
+	var memAvail int64
+	for _, l := range lines[1:3] {
+		f := strings.Fields(l)
+		if len(f) < 2 {
+			continue
+		}
+		kb, _ := strconv.ParseInt(f[1], 10, 64)
+		switch f[0] {
+		case "MemTotal:":
+			u.MemTotalBytes = kb << 10
+		case "MemAvailable:":
+			memAvail = kb << 10
+		}
+	}
+	u.MemUsedBytes = u.MemTotalBytes - memAvail
+
+	// Filesystem 1024-blocks Used Available Capacity Mounted-on
+	f := strings.Fields(lines[len(lines)-1])
+	if len(f) < 4 {
+		return nil, fmt.Errorf("parse df: %q", lines[len(lines)-1])
+	}
+	total, err1 := strconv.ParseInt(f[1], 10, 64)
+	free, err2 := strconv.ParseInt(f[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("parse df: %q", lines[len(lines)-1])
+	}
+	u.DiskTotalBytes, u.DiskFreeBytes = total<<10, free<<10
+	return u, nil
+}
+
 // EnsureNetwork creates a bridge network if it doesn't exist.
 func (c *Client) EnsureNetwork(ctx context.Context, name string) error {
 	networks, err := c.cli.NetworkList(ctx, network.ListOptions{})
@@ -121,6 +220,32 @@ func (c *Client) EnsureNetwork(ctx context.Context, name string) error {
 	return nil
 }
 
+// NetworkGateway returns the IPv4 gateway address of a Docker network.
+func (c *Client) NetworkGateway(ctx context.Context, name string) (string, error) {
+	n, err := c.cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("inspect network %s: %w", name, err)
+	}
+	for _, cfg := range n.IPAM.Config {
+		if cfg.Gateway != "" && !strings.Contains(cfg.Gateway, ":") {
+			return cfg.Gateway, nil
+		}
+	}
+	return "", fmt.Errorf("network %s has no IPv4 gateway", name)
+}
+
+// SetNetworkAliases reconnects a container to a network with the given DNS
+// aliases, replacing any aliases it had before.
+func (c *Client) SetNetworkAliases(ctx context.Context, networkName, containerID string, aliases []string) error {
+	if err := c.cli.NetworkDisconnect(ctx, networkName, containerID, true); err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("disconnect %s: %w", networkName, err)
+	}
+	if err := c.cli.NetworkConnect(ctx, networkName, containerID, &network.EndpointSettings{Aliases: aliases}); err != nil {
+		return fmt.Errorf("connect %s: %w", networkName, err)
+	}
+	return nil
+}
+
 // PullImage pulls a container image. The caller should read and close the
 // returned reader to follow progress.
 func (c *Client) PullImage(ctx context.Context, ref string) (io.ReadCloser, error) {
@@ -167,21 +292,217 @@ func (c *Client) ContainerRemove(ctx context.Context, id string, removeVolumes b
 	})
 }
 
+// SetRestartPolicy changes a container's restart policy in place.
+func (c *Client) SetRestartPolicy(ctx context.Context, id string, p container.RestartPolicy) error {
+	_, err := c.cli.ContainerUpdate(ctx, id, container.UpdateConfig{RestartPolicy: p})
+	return err
+}
+
 // ContainerInspect returns container details.
 func (c *Client) ContainerInspect(ctx context.Context, id string) (container.InspectResponse, error) {
 	return c.cli.ContainerInspect(ctx, id)
 }
 
-// ContainerLogs returns a reader for container log output.
-func (c *Client) ContainerLogs(ctx context.Context, id string, tail string) (io.ReadCloser, error) {
+// ContainerUsage is a point-in-time resource usage sample for a container.
+type ContainerUsage struct {
+	CPUPercent float64 `json:"cpu_percent"` // 100 = one full core
+	MemoryMB   float64 `json:"memory_mb"`
+	NetRxMB    float64 `json:"net_rx_mb"`
+	NetTxMB    float64 `json:"net_tx_mb"`
+}
+
+// ContainerStats takes a single resource usage sample from a running
+// container. The daemon waits for a second reading so CPU can be computed.
+func (c *Client) ContainerStats(ctx context.Context, id string) (*ContainerUsage, error) {
+	resp, err := c.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var st container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("decode stats: %w", err)
+	}
+
+	u := &ContainerUsage{}
+	cpuDelta := float64(st.CPUStats.CPUUsage.TotalUsage) - float64(st.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(st.CPUStats.SystemUsage) - float64(st.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && sysDelta > 0 {
+		cpus := float64(st.CPUStats.OnlineCPUs)
+		if cpus == 0 {
+			cpus = float64(len(st.CPUStats.CPUUsage.PercpuUsage))
+		}
+		u.CPUPercent = cpuDelta / sysDelta * cpus * 100
+	}
+	// Match `docker stats`: exclude reclaimable page cache.
+	mem := st.MemoryStats.Usage
+	if cache := st.MemoryStats.Stats["inactive_file"]; cache < mem {
+		mem -= cache
+	}
+	u.MemoryMB = float64(mem) / (1024 * 1024)
+	for _, n := range st.Networks {
+		u.NetRxMB += float64(n.RxBytes) / (1024 * 1024)
+		u.NetTxMB += float64(n.TxBytes) / (1024 * 1024)
+	}
+	return u, nil
+}
+
+// VolumeSizes returns the size in bytes of each named volume that exists,
+// as reported by `docker system df -v`. The daemon walks every volume, so
+// this is slow on hosts with large chain databases.
+func (c *Client) VolumeSizes(ctx context.Context, names []string) (map[string]int64, error) {
+	if err := c.Supports(ctx, FeatureVolumeSizes); err != nil {
+		return nil, err
+	}
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, err
+	}
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	sizes := make(map[string]int64, len(names))
+	for _, v := range du.Volumes {
+		if v == nil || !want[v.Name] || v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		sizes[v.Name] = v.UsageData.Size
+	}
+	return sizes, nil
+}
+
+// CopyFiles writes files (name -> content) into dir inside a container with
+// the given mode. It works on created containers, including volume mounts.
+func (c *Client) CopyFiles(ctx context.Context, id, dir string, files map[string][]byte, mode int64) error {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, data := range files {
+		hdr := &tar.Header{Name: name, Mode: mode, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return c.cli.CopyToContainer(ctx, id, dir, &buf, container.CopyToContainerOptions{})
+}
+
+// ContainerLogs returns a reader for container log output. With follow set
+// the reader stays open and yields new output until ctx is cancelled.
+func (c *Client) ContainerLogs(ctx context.Context, id string, tail string, follow bool) (io.ReadCloser, error) {
 	return c.cli.ContainerLogs(ctx, id, container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Tail:       tail,
 		Timestamps: true,
+		Follow:     follow,
 	})
 }
 
+// EnsureImage pulls an image if it is not already present locally.
+func (c *Client) EnsureImage(ctx context.Context, ref string) error {
+	exists, err := c.ImageExists(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	reader, err := c.PullImage(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	}
+	defer reader.Close()
+	_, err = io.Copy(io.Discard, reader)
+	return err
+}
+
+// RunOnce runs a short-lived container to completion and returns its exit
+// code and combined output. The container is always removed afterwards.
+func (c *Client) RunOnce(ctx context.Context, ref string, cmd []string, hc *container.HostConfig) (int64, string, error) {
+	if err := c.EnsureImage(ctx, ref); err != nil {
+		return -1, "", err
+	}
+	resp, err := c.cli.ContainerCreate(ctx, &container.Config{
+		Image:  ref,
+		Cmd:    cmd,
+		Labels: map[string]string{LabelManagedBy: ManagedByValue + "-probe"},
+	}, hc, nil, nil, "")
+	if err != nil {
+		return -1, "", fmt.Errorf("create probe container: %w", err)
+	}
+	defer c.cli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true})
+
+	if err := c.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return -1, "", fmt.Errorf("start probe container: %w", err)
+	}
+
+	var exitCode int64
+	waitC, errC := c.cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case w := <-waitC:
+		exitCode = w.StatusCode
+	case err := <-errC:
+		return -1, "", fmt.Errorf("wait probe container: %w", err)
+	}
+
+	logs, err := c.cli.ContainerLogs(ctx, resp.ID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return exitCode, "", nil
+	}
+	defer logs.Close()
+	var out bytes.Buffer
+	stdcopy.StdCopy(&out, &out, logs)
+	return exitCode, out.String(), nil
+}
+
+// Exec runs cmd inside a running container and returns its exit code and
+// combined output.
+func (c *Client) Exec(ctx context.Context, id string, cmd []string) (int, string, error) {
+	var out bytes.Buffer
+	code, err := c.ExecStream(ctx, id, cmd, &out)
+	return code, out.String(), err
+}
+
+// ExecStream runs cmd inside a running container, copying its combined
+// output to w as it is produced, and returns the exit code. Cancelling ctx
+// abandons the command's output.
+func (c *Client) ExecStream(ctx context.Context, id string, cmd []string, w io.Writer) (int, error) {
+	created, err := c.cli.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("exec create: %w", err)
+	}
+	attach, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return -1, fmt.Errorf("exec attach: %w", err)
+	}
+	defer attach.Close()
+	stop := context.AfterFunc(ctx, attach.Close)
+	defer stop()
+
+	if _, err := stdcopy.StdCopy(w, w, attach.Reader); err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("exec read: %w", err)
+	}
+	info, err := c.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return -1, fmt.Errorf("exec inspect: %w", err)
+	}
+	return info.ExitCode, nil
+}
+
 // ManagedContainer holds summary info for a managed container.
 type ManagedContainer struct {
 	ID    string
@@ -192,7 +513,7 @@ type ManagedContainer struct {
 // ListManagedContainers returns all containers with the managed-by=avalauncher label.
 func (c *Client) ListManagedContainers(ctx context.Context) ([]ManagedContainer, error) {
 	containers, err := c.cli.ContainerList(ctx, container.ListOptions{
-		All: true,
+		All:     true,
 		Filters: newFilterArgs(LabelManagedBy, ManagedByValue),
 	})
 	if err != nil {
@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// connWindow is how many one-minute buckets ConnStats keeps for its recent
+// error rate.
+const connWindow = 60
+
+// ConnStats counts requests and connection-level failures on one host's
+// Docker link. It outlives individual clients so reconnects keep history.
+// The zero value is ready to use.
+type ConnStats struct {
+	mu              sync.Mutex
+	requests        int64
+	errors          int64
+	reconnects      int64
+	lastError       string
+	lastErrorAt     time.Time
+	lastConnectedAt time.Time
+	buckets         [connWindow]connBucket
+}
+
+type connBucket struct {
+	minute   int64 // unix minute the counts belong to
+	requests int64
+	errors   int64
+}
+
+// ConnSnapshot is a point-in-time copy of ConnStats.
+type ConnSnapshot struct {
+	Requests        int64      `json:"requests_total"`
+	Errors          int64      `json:"errors_total"`
+	Reconnects      int64      `json:"reconnects_total"`
+	RecentRequests  int64      `json:"recent_requests"` // last hour
+	RecentErrors    int64      `json:"recent_errors"`   // last hour
+	RecentErrorRate float64    `json:"recent_error_rate"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+	LastConnectedAt *time.Time `json:"last_connected_at,omitempty"`
+}
+
+func (s *ConnStats) bucket(now time.Time) *connBucket {
+	min := now.Unix() / 60
+	b := &s.buckets[min%connWindow]
+	if b.minute != min {
+		*b = connBucket{minute: min}
+	}
+	return b
+}
+
+// record counts one request and, if err is set, one failure.
+func (s *ConnStats) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	b := s.bucket(now)
+	s.requests++
+	b.requests++
+	if err != nil {
+		s.errors++
+		b.errors++
+		s.lastError = err.Error()
+		s.lastErrorAt = now
+	}
+}
+
+// RecordError counts a failure that never reached the transport, such as an
+// SSH helper that could not be set up.
+func (s *ConnStats) RecordError(err error) {
+	s.record(err)
+}
+
+// RecordConnected notes a successful (re)connect; reconnect says whether a
+// previous connection was replaced.
+func (s *ConnStats) RecordConnected(reconnect bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastConnectedAt = time.Now()
+	if reconnect {
+		s.reconnects++
+	}
+}
+
+// Snapshot returns the current counters.
+func (s *ConnStats) Snapshot() ConnSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := ConnSnapshot{
+		Requests:   s.requests,
+		Errors:     s.errors,
+		Reconnects: s.reconnects,
+		LastError:  s.lastError,
+	}
+	oldest := time.Now().Unix()/60 - connWindow + 1
+	for _, b := range s.buckets {
+		if b.minute >= oldest {
+			snap.RecentRequests += b.requests
+			snap.RecentErrors += b.errors
+		}
+	}
+	if snap.RecentRequests > 0 {
+		snap.RecentErrorRate = float64(snap.RecentErrors) / float64(snap.RecentRequests)
+	}
+	if !s.lastErrorAt.IsZero() {
+		t := s.lastErrorAt
+		snap.LastErrorAt = &t
+	}
+	if !s.lastConnectedAt.IsZero() {
+		t := s.lastConnectedAt
+		snap.LastConnectedAt = &t
+	}
+	return snap
+}
+
+// countingTransport records every round trip in a ConnStats. Only
+// transport errors count as failures: an HTTP error status means the link
+// worked. Cancelled requests (e.g. a closed log stream) are not failures.
+type countingTransport struct {
+	base  http.RoundTripper
+	stats *ConnStats
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if errors.Is(err, context.Canceled) {
+		return resp, err
+	}
+	t.stats.record(err)
+	return resp, err
+}
+
+// withConnStats wraps the client's transport in a countingTransport. Like
+// withLimits it must come after the dialer and host options, and before
+// withLimits so requests waiting on a limit aren't counted as link time.
+func withConnStats(stats *ConnStats, base *http.RoundTripper) client.Opt {
+	return func(c *client.Client) error {
+		hc := c.HTTPClient()
+		*base = hc.Transport
+		hc.Transport = &countingTransport{base: hc.Transport, stats: stats}
+		return client.WithHTTPClient(hc)(c)
+	}
+}
@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// EdgeProxyName is the container name of a host's edge proxy.
+	EdgeProxyName = "avalauncher-traefik"
+	// EdgeProxyVolume holds the proxy's ACME account and certificates, so
+	// redeploying it doesn't request new ones.
+	EdgeProxyVolume = "avalauncher-traefik-acme"
+	// ManagedByEdgeProxy marks edge proxy containers. It differs from
+	// ManagedByValue so reconcile and the event watcher never mistake the
+	// proxy for a node.
+	ManagedByEdgeProxy = ManagedByValue + "-proxy"
+)
+
+// EdgeProxyParams defines a Traefik container that terminates TLS for a
+// host's nodes. Certificates come from ACME DNS-01 challenges, which need
+// only outbound access to the CA and the DNS provider's API, so hosts that
+// can't accept inbound port 80 still get valid certificates.
+type EdgeProxyParams struct {
+	Image        string            // Traefik v3 image
+	Network      string            // Docker network shared with the nodes (the Traefik network)
+	CertResolver string            // resolver name routers reference; empty = DefaultCertResolver
+	Email        string            // ACME account email
+	CAServer     string            // ACME directory URL; empty = Let's Encrypt production
+	DNSProvider  string            // lego DNS provider, e.g. "cloudflare" or "route53"
+	DNSEnv       map[string]string // provider credentials, e.g. CF_DNS_API_TOKEN
+}
+
+// BuildContainerConfig returns the Docker configs for the edge proxy. It
+// watches the host's Docker socket for the traefik.* labels nodes carry,
+// listens on 80 (redirects and the .avax.localhost routers) and 443, and
+// defines the https-redirect middleware node routers reference.
+func (p *EdgeProxyParams) BuildContainerConfig() (*container.Config, *container.HostConfig, *network.NetworkingConfig) {
+	resolver := p.CertResolver
+	if resolver == "" {
+		resolver = DefaultCertResolver
+	}
+	acme := "--certificatesresolvers." + resolver + ".acme"
+	cmd := []string{
+		"--providers.docker=true",
+		"--providers.docker.exposedbydefault=false",
+		"--providers.docker.network=" + p.Network,
+		"--entrypoints.http.address=:80",
+		"--entrypoints.https.address=:443",
+		acme + ".email=" + p.Email,
+		acme + ".storage=/acme/acme.json",
+		acme + ".dnschallenge.provider=" + p.DNSProvider,
+		// Check propagation against public resolvers, not the host's,
+		// which may be split-horizon behind the firewall.
+		acme + ".dnschallenge.resolvers=1.1.1.1:53,8.8.8.8:53",
+	}
+	if p.CAServer != "" {
+		cmd = append(cmd, acme+".caserver="+p.CAServer)
+	}
+
+	env := make([]string, 0, len(p.DNSEnv))
+	for k, v := range p.DNSEnv {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	cc := &container.Config{
+		Image: p.Image,
+		Cmd:   cmd,
+		Env:   env,
+		ExposedPorts: nat.PortSet{
+			"80/tcp":  struct{}{},
+			"443/tcp": struct{}{},
+		},
+		Labels: map[string]string{
+			LabelManagedBy:   ManagedByEdgeProxy,
+			"traefik.enable": "true",
+			"traefik.http.middlewares.https-redirect.redirectscheme.scheme":    "https",
+			"traefik.http.middlewares.https-redirect.redirectscheme.permanent": "true",
+		},
+	}
+	hc := &container.HostConfig{
+		PortBindings: nat.PortMap{
+			"80/tcp":  []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "80"}},
+			"443/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "443"}},
+		},
+		Mounts: []mount.Mount{
+			{Type: mount.TypeVolume, Source: EdgeProxyVolume, Target: "/acme"},
+			{Type: mount.TypeBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock", ReadOnly: true},
+		},
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+	}
+	nc := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{p.Network: {}},
+	}
+	return cc, hc, nc
+}
+
+// Validate checks that the proxy can obtain certificates.
+func (p *EdgeProxyParams) Validate() error {
+	switch {
+	case p.Image == "":
+		return fmt.Errorf("edge proxy image is required")
+	case p.Network == "":
+		return fmt.Errorf("edge proxy network is required")
+	case p.Email == "":
+		return fmt.Errorf("ACME email is required")
+	case p.DNSProvider == "":
+		return fmt.Errorf("ACME DNS provider is required")
+	}
+	return nil
+}
+
+// EdgeProxyInfo is the state of a host's edge proxy container.
+type EdgeProxyInfo struct {
+	ContainerID string    `json:"container_id"`
+	Image       string    `json:"image"`
+	State       string    `json:"state"` // Docker state, e.g. "running"
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// EdgeProxy returns the host's edge proxy, or nil when none is deployed.
+func (c *Client) EdgeProxy(ctx context.Context) (*EdgeProxyInfo, error) {
+	info, err := c.cli.ContainerInspect(ctx, EdgeProxyName)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("inspect %s: %w", EdgeProxyName, err)
+	}
+	if info.Config.Labels[LabelManagedBy] != ManagedByEdgeProxy {
+		return nil, fmt.Errorf("container %s exists but was not created by avalauncher", EdgeProxyName)
+	}
+	out := &EdgeProxyInfo{ContainerID: info.ID, Image: info.Config.Image}
+	if info.State != nil {
+		out.State = string(info.State.Status)
+		out.StartedAt, _ = time.Parse(time.RFC3339Nano, info.State.StartedAt)
+	}
+	return out, nil
+}
+
+// DeployEdgeProxy (re)creates the host's edge proxy: it ensures the shared
+// network, pulls the image, replaces any existing proxy and starts the new
+// one. The ACME volume is kept, so certificates survive a redeploy.
+func (c *Client) DeployEdgeProxy(ctx context.Context, p *EdgeProxyParams) (*EdgeProxyInfo, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := c.EdgeProxy(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.EnsureNetwork(ctx, p.Network); err != nil {
+		return nil, err
+	}
+	if err := c.Pull(ctx, p.Image); err != nil {
+		return nil, err
+	}
+	if err := c.RemoveEdgeProxy(ctx); err != nil {
+		return nil, err
+	}
+	cc, hc, nc := p.BuildContainerConfig()
+	id, err := c.ContainerCreate(ctx, EdgeProxyName, cc, hc, nc)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ContainerStart(ctx, id); err != nil {
+		c.ContainerRemove(context.Background(), id, false)
+		return nil, fmt.Errorf("start %s: %w", EdgeProxyName, err)
+	}
+	return c.EdgeProxy(ctx)
+}
+
+// RemoveEdgeProxy stops and removes the host's edge proxy, keeping its ACME
+// volume. It is a no-op when none is deployed.
+func (c *Client) RemoveEdgeProxy(ctx context.Context) error {
+	cur, err := c.EdgeProxy(ctx)
+	if err != nil || cur == nil {
+		return err
+	}
+	if err := c.ContainerRemove(ctx, cur.ContainerID, false); err != nil {
+		return fmt.Errorf("remove %s: %w", EdgeProxyName, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+// ContainerEvent is a lifecycle change of a managed container.
+type ContainerEvent struct {
+	ContainerID string
+	Action      string // "start", "die" or "oom"
+	ExitCode    string // set on "die"
+	Time        time.Time
+}
+
+// ContainerEvents streams start, die and oom events of managed containers
+// until ctx is cancelled or the stream breaks; the error channel then
+// receives the cause.
+func (c *Client) ContainerEvents(ctx context.Context) (<-chan ContainerEvent, <-chan error) {
+	f := newFilterArgs(LabelManagedBy, ManagedByValue)
+	f.Add("type", string(events.ContainerEventType))
+	for _, a := range []events.Action{events.ActionStart, events.ActionDie, events.ActionOOM} {
+		f.Add("event", string(a))
+	}
+	msgs, errs := c.cli.Events(ctx, events.ListOptions{Filters: f})
+
+	out := make(chan ContainerEvent)
+	outErr := make(chan error, 1)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case err := <-errs:
+				outErr <- err
+				return
+			case msg := <-msgs:
+				ev := ContainerEvent{
+					ContainerID: msg.Actor.ID,
+					Action:      string(msg.Action),
+					ExitCode:    msg.Actor.Attributes["exitCode"],
+					Time:        time.Unix(0, msg.TimeNano),
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					outErr <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+	return out, outErr
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+)
+
+// ImageInfo is an image present on a host.
+type ImageInfo struct {
+	ID         string    `json:"id"`
+	Tags       []string  `json:"tags"`              // e.g. "avaplatform/avalanchego:v1.13.0"; empty when dangling
+	Digests    []string  `json:"digests,omitempty"` // repo@sha256:... it was pulled as
+	Size       int64     `json:"size"`
+	Created    time.Time `json:"created"`
+	Containers int       `json:"containers"` // containers (in any state) using it
+}
+
+// ListImages returns the images on the host with how many containers use
+// each.
+func (c *Client) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	images, err := c.cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list images: %w", err)
+	}
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	used := make(map[string]int, len(containers))
+	for _, ctr := range containers {
+		used[ctr.ImageID]++
+	}
+
+	out := make([]ImageInfo, 0, len(images))
+	for _, img := range images {
+		info := ImageInfo{
+			ID:         img.ID,
+			Tags:       []string{},
+			Digests:    img.RepoDigests,
+			Size:       img.Size,
+			Created:    time.Unix(img.Created, 0).UTC(),
+			Containers: used[img.ID],
+		}
+		for _, t := range img.RepoTags {
+			if t != "<none>:<none>" {
+				info.Tags = append(info.Tags, t)
+			}
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// Pull pulls an image to completion. Unlike PullImage it reads the progress
+// stream itself, so errors reported mid-pull (e.g. a missing tag or a full
+// disk) are returned rather than silently discarded.
+func (c *Client) Pull(ctx context.Context, ref string) error {
+	reader, err := c.PullImage(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	}
+	defer reader.Close()
+	dec := json.NewDecoder(reader)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("pull %s: %w", ref, err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("pull %s: %s", ref, msg.Error)
+		}
+	}
+}
+
+// RemoveImage deletes an image by removing each of its tags (or its ID when
+// it has none), along with its untagged parents. Nothing is forced, so Docker
+// refuses images that a container, even a stopped one, still uses.
+func (c *Client) RemoveImage(ctx context.Context, img ImageInfo) error {
+	refs := img.Tags
+	if len(refs) == 0 {
+		refs = []string{img.ID}
+	}
+	for _, ref := range refs {
+		if _, err := c.cli.ImageRemove(ctx, ref, image.RemoveOptions{PruneChildren: true}); err != nil {
+			return fmt.Errorf("remove %s: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// ContainerImage returns the ID of the image a container runs and the repo
+// digests (repo@sha256:...) that image was pulled as. A tag moving later
+// doesn't change either.
+func (c *Client) ContainerImage(ctx context.Context, id string) (string, []string, error) {
+	info, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", nil, fmt.Errorf("inspect container: %w", err)
+	}
+	img, err := c.cli.ImageInspect(ctx, info.Image)
+	if err != nil {
+		return info.Image, nil, fmt.Errorf("inspect image: %w", err)
+	}
+	return img.ID, img.RepoDigests, nil
+}
+
+// ImageID returns the ID of the local image ref points at, or "" when the
+// host doesn't have it.
+func (c *Client) ImageID(ctx context.Context, ref string) (string, error) {
+	img, err := c.cli.ImageInspect(ctx, ref)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("inspect %s: %w", ref, err)
+	}
+	return img.ID, nil
+}
+
+// RegistryDigest asks the registry, through the daemon and its credentials,
+// which manifest digest ref resolves to now. Nothing is pulled.
+func (c *Client) RegistryDigest(ctx context.Context, ref string) (string, error) {
+	d, err := c.cli.DistributionInspect(ctx, ref, "")
+	if err != nil {
+		return "", fmt.Errorf("registry lookup %s: %w", ref, err)
+	}
+	return d.Descriptor.Digest.String(), nil
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// Limits throttles Docker API requests to a single daemon. Requests beyond
+// the limits wait in line (until their context is cancelled) instead of
+// failing. Zero values disable the corresponding limit.
+type Limits struct {
+	MaxConcurrent int     // max in-flight requests
+	RatePerSec    float64 // max request starts per second
+}
+
+// Enabled reports whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.MaxConcurrent > 0 || l.RatePerSec > 0
+}
+
+// limitedTransport is an http.RoundTripper that applies Limits. A slot is
+// held until the response headers arrive, so long streams (logs, pulls)
+// don't block other requests once they've started.
+type limitedTransport struct {
+	base     http.RoundTripper
+	sem      chan struct{} // nil = no concurrency limit
+	interval time.Duration // 0 = no rate limit
+
+	mu   sync.Mutex
+	next time.Time // earliest start time of the next request
+}
+
+func newLimitedTransport(base http.RoundTripper, l Limits) *limitedTransport {
+	t := &limitedTransport{base: base}
+	if l.MaxConcurrent > 0 {
+		t.sem = make(chan struct{}, l.MaxConcurrent)
+	}
+	if l.RatePerSec > 0 {
+		t.interval = time.Duration(float64(time.Second) / l.RatePerSec)
+	}
+	return t
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.sem != nil {
+		select {
+		case t.sem <- struct{}{}:
+			defer func() { <-t.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err := t.wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// wait reserves the next start slot and sleeps until it arrives.
+func (t *limitedTransport) wait(ctx context.Context) error {
+	if t.interval == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	now := time.Now()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(t.interval)
+	t.mu.Unlock()
+
+	d := time.Until(start)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withLimits wraps the client's configured transport in a limitedTransport.
+// It must be the last option so the dialer and host are already applied.
+// base keeps the innermost transport if another wrapper already set it.
+func withLimits(l Limits, base *http.RoundTripper) client.Opt {
+	return func(c *client.Client) error {
+		hc := c.HTTPClient()
+		if *base == nil {
+			*base = hc.Transport
+		}
+		hc.Transport = newLimitedTransport(hc.Transport, l)
+		return client.WithHTTPClient(hc)(c)
+	}
+}
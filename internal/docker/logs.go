@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// LogsDir is where AvalancheGo writes its per-chain log files.
+const LogsDir = DataDir + "/logs"
+
+// WriteContainerLogs writes a container's recent stdout and stderr to w as
+// plain timestamped lines. An empty tail means all lines; a zero since
+// means no start time.
+func (c *Client) WriteContainerLogs(ctx context.Context, id, tail string, since time.Time, w io.Writer) error {
+	opts := container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: tail, Timestamps: true}
+	if !since.IsZero() {
+		opts.Since = since.UTC().Format(time.RFC3339)
+	}
+	logs, err := c.cli.ContainerLogs(ctx, id, opts)
+	if err != nil {
+		return fmt.Errorf("container logs: %w", err)
+	}
+	defer logs.Close()
+	if _, err := stdcopy.StdCopy(w, w, logs); err != nil {
+		return fmt.Errorf("read container logs: %w", err)
+	}
+	return nil
+}
+
+// ReadContainerFiles calls fn with each regular file directly in dir of a
+// container (stopped ones included) for which want returns true. Files
+// larger than maxBytes are cut to their last maxBytes, which for logs are
+// the ones that matter.
+func (c *Client) ReadContainerFiles(ctx context.Context, id, dir string, maxBytes int64, want func(name string) bool,
+	fn func(name string, r io.Reader) error) error {
+	rc, _, err := c.cli.CopyFromContainer(ctx, id, dir)
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", dir, err)
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dir, err)
+		}
+		// Entries are named <base of dir>/<file>; skip subdirectories.
+		name := path.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || path.Dir(path.Clean(hdr.Name)) != path.Base(dir) || !want(name) {
+			continue
+		}
+		if hdr.Size > maxBytes {
+			if _, err := io.CopyN(io.Discard, tr, hdr.Size-maxBytes); err != nil {
+				return fmt.Errorf("read %s: %w", name, err)
+			}
+		}
+		if err := fn(name, tr); err != nil {
+			return err
+		}
+	}
+}
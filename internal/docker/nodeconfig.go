@@ -0,0 +1,119 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxChainConfigSize bounds the encoded chain configs, which are passed in a
+// single env var (Linux caps one at 128 KiB).
+const maxChainConfigSize = 64 << 10
+
+// NodeConfig holds per-node AvalancheGo overrides.
+type NodeConfig struct {
+	// Flags are AvalancheGo flags without the leading dashes, e.g.
+	// "index-enabled": "true". They are passed as AVAGO_* env vars.
+	Flags map[string]string `json:"flags,omitempty"`
+
+	// ChainConfigs are per-chain config documents keyed by chain alias
+	// ("C", "X", "P") or blockchain ID, e.g. C-Chain or subnet-evm configs.
+	ChainConfigs map[string]json.RawMessage `json:"chain_configs,omitempty"`
+
+	// Profile names a NodeProfile applied beneath Flags and ChainConfigs,
+	// e.g. "archival"; empty leaves AvalancheGo's defaults.
+	Profile string `json:"profile,omitempty"`
+}
+
+// flagName matches AvalancheGo flag names.
+var flagName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// chainKey matches chain aliases and CB58 blockchain IDs.
+var chainKey = regexp.MustCompile(`^[A-Za-z0-9]{1,64}$`)
+
+// managedFlags are set by avalauncher from node fields and can't be
+// overridden.
+var managedFlags = map[string]string{
+	"network-id":           "network",
+	"http-port":            "http_port",
+	"staking-port":         "staking_port",
+	"track-subnets":        "L1 validator assignments",
+	"chain-config-content": "chain_configs",
+	"chain-config-dir":     "chain_configs",
+}
+
+// Validate checks the profile, flag names and that every chain config is a
+// JSON object.
+func (c NodeConfig) Validate() error {
+	if c.Profile != "" {
+		if _, err := nodeProfile(c.Profile); err != nil {
+			return err
+		}
+	}
+	for name := range c.Flags {
+		if !flagName.MatchString(name) {
+			return fmt.Errorf("invalid flag %q (use the name without dashes, e.g. index-enabled)", name)
+		}
+		if field, ok := managedFlags[name]; ok {
+			return fmt.Errorf("flag %q is managed by avalauncher; set %s instead", name, field)
+		}
+	}
+	for chain, cfg := range c.ChainConfigs {
+		if !chainKey.MatchString(chain) {
+			return fmt.Errorf("invalid chain %q (want an alias like C or a blockchain ID)", chain)
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(cfg, &obj); err != nil || obj == nil {
+			return fmt.Errorf("chain_configs.%s must be a JSON object", chain)
+		}
+	}
+	if content := c.effective().chainConfigContent(); len(content) > maxChainConfigSize {
+		return fmt.Errorf("chain_configs exceed %d KiB encoded", maxChainConfigSize>>10)
+	}
+	return nil
+}
+
+// env renders the overrides, with the profile applied, as AVAGO_* env
+// entries sorted by flag name.
+func (c NodeConfig) env() []string {
+	c = c.effective()
+	names := make([]string, 0, len(c.Flags))
+	for name := range c.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var env []string
+	for _, name := range names {
+		env = append(env, flagEnv(name)+"="+c.Flags[name])
+	}
+	if content := c.chainConfigContent(); content != "" {
+		env = append(env, "AVAGO_CHAIN_CONFIG_CONTENT="+content)
+	}
+	return env
+}
+
+// chainConfigContent encodes ChainConfigs in the format AvalancheGo expects
+// for --chain-config-content: base64 JSON of chain -> {"Config": base64}.
+func (c NodeConfig) chainConfigContent() string {
+	if len(c.ChainConfigs) == 0 {
+		return ""
+	}
+	type chainConfig struct {
+		Config []byte
+	}
+	configs := make(map[string]chainConfig, len(c.ChainConfigs))
+	for chain, cfg := range c.ChainConfigs {
+		configs[chain] = chainConfig{Config: cfg}
+	}
+	b, _ := json.Marshal(configs)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// flagEnv returns the env var AvalancheGo reads for a flag.
+func flagEnv(name string) string {
+	return "AVAGO_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
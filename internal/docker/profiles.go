@@ -0,0 +1,113 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NodeProfile is a named preset of AvalancheGo flags and chain configs for
+// a kind of node. A node's own flags and chain config keys override it.
+type NodeProfile struct {
+	Name         string                     `json:"name"`
+	Description  string                     `json:"description"`
+	Flags        map[string]string          `json:"flags,omitempty"`
+	ChainConfigs map[string]json.RawMessage `json:"chain_configs,omitempty"`
+}
+
+// nodeProfiles are the presets selectable as NodeConfig.Profile.
+var nodeProfiles = []NodeProfile{
+	{
+		Name:        "archival",
+		Description: "Full C-Chain history: bootstraps from genesis and keeps every state trie",
+		ChainConfigs: map[string]json.RawMessage{
+			"C": json.RawMessage(`{"pruning-enabled":false,"state-sync-enabled":false}`),
+		},
+	},
+	{
+		Name:        "pruned",
+		Description: "Bootstraps from genesis, keeping only recent C-Chain state",
+		ChainConfigs: map[string]json.RawMessage{
+			"C": json.RawMessage(`{"pruning-enabled":true,"state-sync-enabled":false}`),
+		},
+	},
+	{
+		Name:        "api",
+		Description: "Public RPC node: C-Chain state sync and pruning, with the eth APIs dapps use but no debug APIs",
+		ChainConfigs: map[string]json.RawMessage{
+			"C": json.RawMessage(`{"pruning-enabled":true,"state-sync-enabled":true,` +
+				`"eth-apis":["eth","eth-filter","net","web3","internal-eth","internal-blockchain","internal-transaction"]}`),
+		},
+	},
+	{
+		Name:        "validator-minimal",
+		Description: "Validator with the smallest footprint: C-Chain state sync and pruning, no indexer, admin API or extra eth APIs",
+		Flags: map[string]string{
+			"index-enabled":     "false",
+			"api-admin-enabled": "false",
+		},
+		ChainConfigs: map[string]json.RawMessage{
+			"C": json.RawMessage(`{"pruning-enabled":true,"state-sync-enabled":true,"eth-apis":["eth","net","web3"]}`),
+		},
+	},
+}
+
+// NodeProfiles returns the available node profiles.
+func NodeProfiles() []NodeProfile {
+	return nodeProfiles
+}
+
+// nodeProfile looks up a profile by name.
+func nodeProfile(name string) (NodeProfile, error) {
+	names := make([]string, len(nodeProfiles))
+	for i, p := range nodeProfiles {
+		if p.Name == name {
+			return p, nil
+		}
+		names[i] = p.Name
+	}
+	return NodeProfile{}, fmt.Errorf("unknown profile %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// effective returns the config with its profile applied beneath it: the
+// node's flags replace profile flags, and its chain config keys replace the
+// profile's keys for the same chain.
+func (c NodeConfig) effective() NodeConfig {
+	if c.Profile == "" {
+		return c
+	}
+	p, err := nodeProfile(c.Profile)
+	if err != nil {
+		return c
+	}
+	out := NodeConfig{
+		Flags:        make(map[string]string, len(p.Flags)+len(c.Flags)),
+		ChainConfigs: make(map[string]json.RawMessage, len(p.ChainConfigs)+len(c.ChainConfigs)),
+	}
+	for k, v := range p.Flags {
+		out.Flags[k] = v
+	}
+	for k, v := range c.Flags {
+		out.Flags[k] = v
+	}
+	for chain, cfg := range p.ChainConfigs {
+		out.ChainConfigs[chain] = cfg
+	}
+	for chain, cfg := range c.ChainConfigs {
+		base, ok := out.ChainConfigs[chain]
+		if !ok {
+			out.ChainConfigs[chain] = cfg
+			continue
+		}
+		var merged, over map[string]json.RawMessage
+		if json.Unmarshal(base, &merged) != nil || json.Unmarshal(cfg, &over) != nil {
+			out.ChainConfigs[chain] = cfg
+			continue
+		}
+		for k, v := range over {
+			merged[k] = v
+		}
+		out.ChainConfigs[chain], _ = json.Marshal(merged)
+	}
+	return out
+}
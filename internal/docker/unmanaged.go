@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// UnmanagedContainer is an AvalancheGo container started outside
+// avalauncher (no managed-by label).
+type UnmanagedContainer struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Image   string   `json:"image"`
+	State   string   `json:"state"`
+	Command string   `json:"command"`
+	Ports   []string `json:"ports,omitempty"` // e.g. "9651->9651/tcp"
+}
+
+// ListUnmanagedAvago returns containers whose image or command mentions
+// avalanchego and that lack the managed-by label.
+func (c *Client) ListUnmanagedAvago(ctx context.Context) ([]UnmanagedContainer, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	result := []UnmanagedContainer{}
+	for _, ctr := range containers {
+		if ctr.Labels[LabelManagedBy] == ManagedByValue || !LooksLikeAvago(ctr.Image, ctr.Command) {
+			continue
+		}
+		u := UnmanagedContainer{
+			ID:      ctr.ID,
+			Image:   ctr.Image,
+			State:   ctr.State,
+			Command: ctr.Command,
+		}
+		if len(ctr.Names) > 0 {
+			u.Name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		for _, p := range ctr.Ports {
+			if p.PublicPort != 0 {
+				u.Ports = append(u.Ports, fmt.Sprintf("%d->%d/%s", p.PublicPort, p.PrivatePort, p.Type))
+			}
+		}
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// LooksLikeAvago reports whether a container image or command is
+// AvalancheGo.
+func LooksLikeAvago(image, command string) bool {
+	return strings.Contains(strings.ToLower(image), "avalanchego") ||
+		strings.Contains(strings.ToLower(command), "avalanchego")
+}
+
+// ReadFiles reads the named files from dir inside a container. Missing
+// files are left out of the result; a missing dir yields an empty map.
+func (c *Client) ReadFiles(ctx context.Context, id, dir string, names []string) (map[string][]byte, error) {
+	rc, _, err := c.cli.CopyFromContainer(ctx, id, dir)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return map[string][]byte{}, nil
+		}
+		return nil, fmt.Errorf("copy from container: %w", err)
+	}
+	defer rc.Close()
+
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		name := path.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !want[name] {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, 1<<20))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		files[name] = data
+	}
+	return files, nil
+}
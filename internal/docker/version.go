@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/versions"
+)
+
+// MinAPIVersion is the oldest Docker API avalauncher is built against
+// (Docker 20.10). Older hosts are flagged; what still works is used.
+const MinAPIVersion = "1.41"
+
+// Feature is a Docker API capability that needs a newer API than
+// MinAPIVersion.
+type Feature struct {
+	Name       string
+	APIVersion string
+}
+
+// FeatureVolumeSizes is `docker system df` restricted to volumes, which
+// older daemons ignore and answer by sizing every image and container.
+var FeatureVolumeSizes = Feature{Name: "volume sizes", APIVersion: "1.42"}
+
+// UnsupportedError reports a feature the host's Docker API is too old for.
+type UnsupportedError struct {
+	Feature string
+	Need    string
+	Have    string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s needs Docker API %s, host has %s", e.Feature, e.Need, e.Have)
+}
+
+// APIVersion returns the API version negotiated with the daemon,
+// negotiating first if no request has been made yet.
+func (c *Client) APIVersion(ctx context.Context) string {
+	c.cli.NegotiateAPIVersion(ctx)
+	return c.cli.ClientVersion()
+}
+
+// Supports returns an *UnsupportedError if the daemon's API is older than
+// the feature needs.
+func (c *Client) Supports(ctx context.Context, f Feature) error {
+	if have := c.APIVersion(ctx); versions.LessThan(have, f.APIVersion) {
+		return &UnsupportedError{Feature: f.Name, Need: f.APIVersion, Have: have}
+	}
+	return nil
+}
+
+// APIVersionOutdated reports whether v is older than MinAPIVersion.
+func APIVersionOutdated(v string) bool {
+	return v != "" && versions.LessThan(v, MinAPIVersion)
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	level = new(slog.LevelVar)
+
+	mu       sync.Mutex
+	base     slog.Level  // configured level, restored after a temporary change
+	revert   *time.Timer // pending restore, if any
+	revertAt time.Time
+)
+
+// Init installs the default slog logger writing to stderr in the given
+// format ("text" or "json") at the given level.
+func Init(lvl, format string) error {
+	l, err := parseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	base = l
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (want text or json)", format)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// Status is the current log level and, during a temporary change, when the
+// configured level comes back.
+type Status struct {
+	Level    string     `json:"level"`
+	Base     string     `json:"base"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// Current returns the logging status.
+func Current() Status {
+	mu.Lock()
+	defer mu.Unlock()
+	s := Status{Level: strings.ToLower(level.Level().String()), Base: strings.ToLower(base.String())}
+	if revert != nil {
+		t := revertAt
+		s.RevertAt = &t
+	}
+	return s
+}
+
+// SetLevel changes the level at runtime. With d > 0 the configured level is
+// restored after d; otherwise the change lasts until the next restart.
+func SetLevel(lvl string, d time.Duration) (Status, error) {
+	l, err := parseLevel(lvl)
+	if err != nil {
+		return Status{}, err
+	}
+
+	mu.Lock()
+	if revert != nil {
+		revert.Stop()
+		revert = nil
+	}
+	level.Set(l)
+	if d > 0 {
+		revertAt = time.Now().Add(d)
+		revert = time.AfterFunc(d, func() {
+			mu.Lock()
+			level.Set(base)
+			revert = nil
+			mu.Unlock()
+			slog.Info("log level restored", "level", base)
+		})
+	} else {
+		base = l
+	}
+	mu.Unlock()
+
+	slog.Info("log level changed", "level", l, "duration", d)
+	return Current(), nil
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (want debug, info, warn, or error)", s)
+	}
+	return l, nil
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Alert is a currently firing condition derived from fleet state.
+type Alert struct {
+	Kind     string    `json:"kind"`
+	Severity string    `json:"severity"`
+	Target   string    `json:"target"`
+	HostID   int64     `json:"host_id,omitempty"`
+	Message  string    `json:"message"`
+	Since    time.Time `json:"since"`
+
+	// Owner metadata of the affected L1, for routing notifications.
+	Owner   string `json:"owner,omitempty"`
+	Contact string `json:"contact,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// SetBootstrapAlertAfter sets how long a node may stay bootstrapping before
+// it raises a node.bootstrapping alert; 0 disables the alert. Call it
+// before Start.
+func (m *Manager) SetBootstrapAlertAfter(d time.Duration) {
+	m.bootstrapAlertAfter = d
+}
+
+// FiringAlerts returns all alert conditions that currently hold: unreachable
+// hosts, unhealthy or failed nodes, nodes bootstrapping for longer than the
+// bootstrap alert threshold, and degraded or down L1s.
+func (m *Manager) FiringAlerts(ctx context.Context) ([]Alert, error) {
+	alerts := []Alert{}
+
+	hrows, err := m.pool.Query(ctx, `
+		SELECT id, name, updated_at FROM hosts
+		WHERE status = 'unreachable'
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer hrows.Close()
+	for hrows.Next() {
+		var a Alert
+		if err := hrows.Scan(&a.HostID, &a.Target, &a.Since); err != nil {
+			return nil, err
+		}
+		a.Kind = "host.unreachable"
+		a.Severity = SeverityCritical
+		a.Message = "Host unreachable"
+		alerts = append(alerts, a)
+	}
+	if err := hrows.Err(); err != nil {
+		return nil, err
+	}
+	hrows.Close()
+
+	bootstrapAfter := m.bootstrapAlertAfter
+	nrows, err := m.pool.Query(ctx, `
+		SELECT name, host_id, status, updated_at FROM nodes
+		WHERE (status IN ('unhealthy', 'failed')
+		       OR (status = 'bootstrapping' AND $1 > 0 AND updated_at < now() - make_interval(secs => $1)))
+		  AND host_id NOT IN (SELECT id FROM hosts WHERE status = 'maintenance')
+		ORDER BY id`, bootstrapAfter.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer nrows.Close()
+	for nrows.Next() {
+		var a Alert
+		var status string
+		if err := nrows.Scan(&a.Target, &a.HostID, &status, &a.Since); err != nil {
+			return nil, err
+		}
+		a.Kind = "node." + status
+		a.Severity = SeverityWarning
+		if status == "failed" {
+			a.Severity = SeverityCritical
+		}
+		a.Message = "Node " + status
+		if status == "bootstrapping" {
+			a.Message = fmt.Sprintf("Node still bootstrapping after %s", time.Since(a.Since).Round(time.Minute))
+		}
+		alerts = append(alerts, a)
+	}
+	if err := nrows.Err(); err != nil {
+		return nil, err
+	}
+	nrows.Close()
+
+	l1s, err := m.L1StatusSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range l1s {
+		if l.Verdict != L1Degraded && l.Verdict != L1Down {
+			continue
+		}
+		a := Alert{
+			Kind:     "l1." + l.Verdict,
+			Severity: SeverityWarning,
+			Target:   l.Name,
+			Message:  fmt.Sprintf("L1 %s: %d/%d validators running", l.Verdict, l.Healthy, l.Total),
+			Since:    l.UpdatedAt,
+			Owner:    l.Owner,
+			Contact:  l.Contact,
+			URL:      l.URL,
+		}
+		if l.Verdict == L1Down {
+			a.Severity = SeverityCritical
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dnsAliasRe matches a lowercase DNS name of one or more labels.
+var dnsAliasRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// validateDNSAliases checks alias syntax and that no other node on the same
+// host (and thus the same Docker network) already claims an alias or uses it
+// as its container name. excludeNodeID skips the node being updated (0 = none).
+func (m *Manager) validateDNSAliases(ctx context.Context, hostID, excludeNodeID int64, aliases []string) error {
+	seen := map[string]bool{}
+	for _, a := range aliases {
+		if len(a) > 253 || !dnsAliasRe.MatchString(a) {
+			return fmt.Errorf("invalid dns alias %q", a)
+		}
+		if seen[a] {
+			return fmt.Errorf("duplicate dns alias %q", a)
+		}
+		seen[a] = true
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT name, dns_aliases FROM nodes
+		WHERE host_id=$1 AND id != $2`,
+		hostID, excludeNodeID)
+	if err != nil {
+		return fmt.Errorf("check dns aliases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var taken []string
+		if err := rows.Scan(&name, &taken); err != nil {
+			return fmt.Errorf("check dns aliases: %w", err)
+		}
+		taken = append(taken, "avax-"+name)
+		for _, t := range taken {
+			if seen[t] {
+				return fmt.Errorf("dns alias %q already used by node %q", t, name)
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// SetNodeAliases replaces a node's DNS aliases. A running container is
+// reconnected to the avax network so the new names resolve immediately; to
+// move an alias to a replacement node, remove it from the old node first.
+func (m *Manager) SetNodeAliases(ctx context.Context, id int64, aliases []string) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.NetworkMode == "host" {
+		return nil, fmt.Errorf("dns_aliases are not supported in host network mode")
+	}
+	for i, a := range aliases {
+		aliases[i] = strings.ToLower(strings.TrimSpace(a))
+	}
+	if err := m.validateDNSAliases(ctx, node.HostID, node.ID, aliases); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET dns_aliases=$1, updated_at=now() WHERE id=$2",
+		nonNil(aliases), id); err != nil {
+		return nil, fmt.Errorf("update dns aliases: %w", err)
+	}
+
+	if node.ContainerID != "" {
+		dc := m.clientFor(node.HostID)
+		if dc == nil {
+			return nil, fmt.Errorf("host %d not connected", node.HostID)
+		}
+		if err := dc.SetNetworkAliases(ctx, m.avaxDockerNet, node.ContainerID, aliases); err != nil {
+			return nil, fmt.Errorf("set network aliases: %w", err)
+		}
+	}
+
+	m.logEvent(ctx, "node.aliases_updated", node.Name, "DNS aliases updated",
+		map[string]any{"dns_aliases": aliases})
+	return m.GetNode(ctx, id)
+}
@@ -0,0 +1,113 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// auditRetention is how long API audit rows are kept.
+const auditRetention = 90 * 24 * time.Hour
+
+// AuditEntry is one authenticated mutating API call.
+type AuditEntry struct {
+	ID         int64          `json:"id"`
+	Actor      string         `json:"actor"` // who called: "admin-key" or the forward-auth user
+	Method     string         `json:"method"`
+	Path       string         `json:"path"`
+	Payload    map[string]any `json:"payload,omitempty"` // request body summary, secrets redacted
+	Status     int            `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// AuditFilter narrows ListAudit. Zero fields match everything.
+type AuditFilter struct {
+	Actor  string
+	Method string
+	Path   string // prefix, e.g. "/api/nodes"
+	Failed bool   // only calls answered with status >= 400
+	Since  time.Time
+	Until  time.Time
+	Limit  int // default 100, max 1000
+}
+
+// RecordAudit stores an API audit entry. Failures are logged, not returned:
+// auditing must not fail the request it describes.
+func (m *Manager) RecordAudit(ctx context.Context, e AuditEntry) {
+	payload, _ := json.Marshal(e.Payload)
+	if e.Payload == nil {
+		payload = []byte("{}")
+	}
+	if _, err := m.pool.Exec(ctx, `
+		INSERT INTO api_audit (actor, method, path, payload, status, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		e.Actor, e.Method, e.Path, payload, e.Status, e.Error, e.DurationMS); err != nil {
+		slog.Warn("record api audit", "method", e.Method, "path", e.Path, "error", err)
+	}
+}
+
+// ListAudit returns audit entries matching f, newest first.
+func (m *Manager) ListAudit(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	if f.Limit > 1000 {
+		f.Limit = 1000
+	}
+	query := `SELECT id, actor, method, path, payload, status, error, duration_ms, created_at FROM api_audit WHERE true`
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.Actor != "" {
+		query += " AND actor = " + arg(f.Actor)
+	}
+	if f.Method != "" {
+		query += " AND method = " + arg(f.Method)
+	}
+	if f.Path != "" {
+		query += " AND starts_with(path, " + arg(f.Path) + ")"
+	}
+	if f.Failed {
+		query += " AND status >= 400"
+	}
+	if !f.Since.IsZero() {
+		query += " AND created_at >= " + arg(f.Since)
+	}
+	if !f.Until.IsZero() {
+		query += " AND created_at < " + arg(f.Until)
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT " + arg(f.Limit)
+
+	rows, err := m.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Method, &e.Path, &payload, &e.Status, &e.Error, &e.DurationMS, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			json.Unmarshal(payload, &e.Payload)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// pruneAudit removes audit entries older than the retention period.
+func (m *Manager) pruneAudit(ctx context.Context) {
+	if _, err := m.pool.Exec(ctx, "DELETE FROM api_audit WHERE created_at < $1", time.Now().Add(-auditRetention)); err != nil {
+		slog.Warn("prune api audit", "error", err)
+	}
+}
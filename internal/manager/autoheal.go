@@ -0,0 +1,152 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// Auto-heal backoff. The nth restart waits autoHealBaseDelay·2^(n-1), at
+// most autoHealMaxDelay; after autoHealMaxAttempts the node is left stopped.
+// A node that stays up for autoHealResetAfter starts counting afresh.
+const (
+	autoHealBaseDelay   = 10 * time.Second
+	autoHealMaxDelay    = 5 * time.Minute
+	autoHealMaxAttempts = 5
+	autoHealResetAfter  = 30 * time.Minute
+)
+
+// autoHeal tracks restart attempts of auto-healing nodes.
+type autoHeal struct {
+	mu    sync.Mutex
+	nodes map[int64]*healState // nodeID -> attempts since the node last stayed up
+}
+
+type healState struct {
+	attempts int
+	last     time.Time // when the last restart was attempted
+	running  bool      // a heal loop is waiting or restarting
+}
+
+// SetNodeRestartPolicy changes a node's Docker restart policy and whether
+// the manager auto-heals it. The policy is applied to the existing
+// container in place; no recreate is needed.
+func (m *Manager) SetNodeRestartPolicy(ctx context.Context, id int64, policy string, autoHeal bool) (*Node, error) {
+	rp, err := docker.ParseRestartPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Status == "creating" {
+		return nil, fmt.Errorf("node %q is still being provisioned", node.Name)
+	}
+
+	if node.ContainerID != "" {
+		dc := m.clientFor(node.HostID)
+		if dc == nil || m.hostOffline(ctx, node.HostID) {
+			return nil, fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
+		}
+		if err := dc.SetRestartPolicy(ctx, node.ContainerID, rp); err != nil {
+			return nil, fmt.Errorf("update container: %w", err)
+		}
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET restart_policy=$1, auto_heal=$2, updated_at=now() WHERE id=$3", policy, autoHeal, id); err != nil {
+		return nil, fmt.Errorf("update restart policy: %w", err)
+	}
+	m.logEvent(ctx, "node.restart_policy_updated", node.Name, "Restart policy updated",
+		map[string]any{"restart_policy": policy, "auto_heal": autoHeal})
+	return m.GetNode(ctx, id)
+}
+
+// scheduleAutoHeal starts restarting an auto-healing node whose container
+// exited unexpectedly, unless a restart is already underway. Exits come from
+// the Docker event stream or, when it is off or down, the health poller.
+func (m *Manager) scheduleAutoHeal(node *Node) {
+	m.autoHeal.mu.Lock()
+	defer m.autoHeal.mu.Unlock()
+	if m.autoHeal.nodes == nil {
+		m.autoHeal.nodes = make(map[int64]*healState)
+	}
+	s := m.autoHeal.nodes[node.ID]
+	if s == nil || time.Since(s.last) > autoHealResetAfter {
+		s = &healState{}
+		m.autoHeal.nodes[node.ID] = s
+	}
+	if s.running {
+		return
+	}
+	s.running = true
+	m.pollerWg.Add(1)
+	go m.healNode(node.ID, node.Name, s)
+}
+
+// healNode restarts a stopped node with exponential backoff until it
+// starts, is started or removed by someone else, has auto-heal turned off,
+// or runs out of attempts. Each attempt is logged as node.autoheal.
+func (m *Manager) healNode(id int64, name string, s *healState) {
+	defer m.pollerWg.Done()
+	defer func() {
+		m.autoHeal.mu.Lock()
+		s.running = false
+		m.autoHeal.mu.Unlock()
+	}()
+
+	for {
+		m.autoHeal.mu.Lock()
+		attempt := s.attempts + 1
+		m.autoHeal.mu.Unlock()
+		if attempt > autoHealMaxAttempts {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			m.logEvent(ctx, "node.autoheal_failed", name,
+				fmt.Sprintf("Gave up after %d restart attempts; start it with POST /api/nodes/%d/start", autoHealMaxAttempts, id), nil)
+			cancel()
+			return
+		}
+		delay := min(autoHealBaseDelay<<(attempt-1), autoHealMaxDelay)
+		if !m.sleepOrStop(delay) {
+			return
+		}
+		if !m.healAttempt(id, attempt, delay) {
+			return
+		}
+	}
+}
+
+// healAttempt makes one restart attempt. It reports whether another is
+// needed.
+func (m *Manager) healAttempt(id int64, attempt int, delay time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Docker's own restart policy, or an operator, may have got there first.
+	node, err := m.GetNode(ctx, id)
+	if err != nil || !node.AutoHeal || node.Status != "stopped" {
+		return false
+	}
+	m.autoHeal.mu.Lock()
+	if s := m.autoHeal.nodes[id]; s != nil {
+		s.attempts = attempt
+		s.last = time.Now()
+	}
+	m.autoHeal.mu.Unlock()
+
+	details := map[string]any{"attempt": attempt, "max_attempts": autoHealMaxAttempts, "delay": delay.String()}
+	m.logEvent(ctx, "node.autoheal", node.Name,
+		fmt.Sprintf("Restarting exited container (attempt %d of %d, after %s)", attempt, autoHealMaxAttempts, delay), details)
+	if err := m.StartNode(ctx, id); err != nil {
+		slog.Warn("auto-heal restart", "error", err, "node", node.Name, "attempt", attempt)
+		details["error"] = err.Error()
+		m.logEventAt(ctx, SeverityError, "node.autoheal", node.Name,
+			fmt.Sprintf("Restart attempt %d failed: %v", attempt, err), details)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/storage"
+)
+
+// Backup kinds.
+const (
+	BackupDB          = "db"           // gzipped tar of the db volume, restorable with RestoreNode
+	BackupStakingKeys = "staking_keys" // JSON export of the stored staking identity
+)
+
+// BackupConfig sets where backups go and how many are kept.
+type BackupConfig struct {
+	Store storage.Store
+
+	// KeepLast is how many finished backups of each kind are kept per
+	// node; older ones are deleted after each new one. 0 keeps all.
+	KeepLast int
+}
+
+// StakingKeysExport is the content of a staking_keys backup. Private keys
+// are exported as stored: sealed under SECRETS_KEY when one is set, and then
+// bound to the node's ID.
+type StakingKeysExport struct {
+	ID         int64     `json:"id"` // avalauncher node ID
+	Node       string    `json:"node"`
+	NodeID     string    `json:"node_id,omitempty"` // AvalancheGo NodeID
+	Network    string    `json:"network"`
+	Sealed     bool      `json:"sealed"`
+	Cert       string    `json:"staker_crt"`
+	Key        string    `json:"staker_key"`
+	Signer     string    `json:"signer_key,omitempty"` // hex
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// ExportStakingKeys writes a node's staking certificate, key and BLS signer
+// key to the backup store.
+func (m *Manager) ExportStakingKeys(ctx context.Context, id int64) (*Snapshot, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	store, err := m.backupStore()
+	if err != nil {
+		return nil, err
+	}
+	exp := StakingKeysExport{ID: node.ID, Node: node.Name, NodeID: node.NodeID, Network: node.Network,
+		Sealed: m.secrets.Enabled(), ExportedAt: time.Now().UTC()}
+	if err := m.pool.QueryRow(ctx,
+		"SELECT staking_cert, staking_key, staking_signer FROM nodes WHERE id=$1", id).
+		Scan(&exp.Cert, &exp.Key, &exp.Signer); err != nil {
+		return nil, fmt.Errorf("load staking keys: %w", err)
+	}
+	if exp.Cert == "" || exp.Key == "" {
+		return nil, fmt.Errorf("node %q has no managed staking keys", node.Name)
+	}
+	data, _ := json.MarshalIndent(exp, "", "  ")
+
+	var backupID int64
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO snapshots (kind, node_id, node_name, network, image, avago_version, store, state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		BackupStakingKeys, node.ID, node.Name, node.Network, node.Image, node.Version, store.Name(), SnapshotCreating).
+		Scan(&backupID)
+	if err != nil {
+		return nil, fmt.Errorf("insert backup: %w", err)
+	}
+	key := fmt.Sprintf("staking/%s/%s-%d.json", node.Name, exp.ExportedAt.Format("20060102T150405Z"), backupID)
+	size, err := store.Put(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		m.pool.Exec(ctx, "UPDATE snapshots SET key=$1, state=$2, error=$3, finished_at=now() WHERE id=$4",
+			key, SnapshotFailed, err.Error(), backupID)
+		m.logEvent(ctx, "node.staking_export_failed", node.Name, "Staking key export failed: "+err.Error(),
+			map[string]any{"snapshot_id": backupID})
+		return nil, fmt.Errorf("write %s: %w", key, err)
+	}
+	m.pool.Exec(ctx, "UPDATE snapshots SET key=$1, state=$2, size_bytes=$3, finished_at=now() WHERE id=$4",
+		key, SnapshotDone, size, backupID)
+	m.logEvent(ctx, "node.staking_exported", node.Name, "Staking keys exported to "+store.Name(),
+		map[string]any{"snapshot_id": backupID, "key": key, "sealed": exp.Sealed})
+	m.pruneBackups(ctx, store, node.Name, BackupStakingKeys)
+	return m.GetSnapshot(ctx, backupID)
+}
+
+// pruneBackups deletes a node's finished backups of a kind beyond the
+// newest KeepLast, with failed attempts older than the oldest one kept.
+func (m *Manager) pruneBackups(ctx context.Context, store storage.Store, nodeName, kind string) {
+	keep := m.snapshots.cfg.KeepLast
+	if keep <= 0 {
+		return
+	}
+	rows, err := m.pool.Query(ctx, "SELECT "+snapshotColumns+` FROM snapshots
+		WHERE node_name = $1 AND kind = $2 AND state <> $3
+		  AND created_at < (
+			SELECT min(created_at) FROM (
+				SELECT created_at FROM snapshots
+				WHERE node_name = $1 AND kind = $2 AND state = $4
+				ORDER BY created_at DESC LIMIT $5
+			) kept
+		  )
+		  AND (SELECT count(*) FROM snapshots WHERE node_name = $1 AND kind = $2 AND state = $4) >= $5
+		ORDER BY created_at`, nodeName, kind, SnapshotCreating, SnapshotDone, keep)
+	if err != nil {
+		slog.Warn("prune backups", "node", nodeName, "error", err)
+		return
+	}
+	var old []Snapshot
+	for rows.Next() {
+		s, err := scanSnapshot(rows)
+		if err != nil {
+			rows.Close()
+			slog.Warn("prune backups", "node", nodeName, "error", err)
+			return
+		}
+		old = append(old, *s)
+	}
+	rows.Close()
+
+	pruned := 0
+	for i := range old {
+		if err := m.removeBackup(ctx, store, &old[i]); err != nil {
+			slog.Warn("prune backup", "snapshot_id", old[i].ID, "error", err)
+			continue
+		}
+		pruned++
+	}
+	if pruned > 0 {
+		m.logEvent(ctx, "node.backups_pruned", nodeName, fmt.Sprintf("Deleted %d old %s backup(s), keeping the last %d", pruned, kind, keep),
+			map[string]any{"kind": kind, "pruned": pruned, "keep_last": keep})
+	}
+}
+
+// removeBackup deletes a backup's object, when it lives in store, and its
+// row. Objects in a store no longer configured are left where they are.
+func (m *Manager) removeBackup(ctx context.Context, store storage.Store, s *Snapshot) error {
+	if s.Key != "" && store != nil && store.Name() == s.Store {
+		if err := store.Delete(ctx, s.Key); err != nil {
+			return fmt.Errorf("delete %s: %w", s.Key, err)
+		}
+	}
+	_, err := m.pool.Exec(ctx, "DELETE FROM snapshots WHERE id=$1", s.ID)
+	return err
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// HostConnection reports the health of the manager's SSH/Docker link to a
+// remote host.
+type HostConnection struct {
+	HostID    int64  `json:"host_id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Connected bool   `json:"connected"`
+	docker.ConnSnapshot
+}
+
+// connStats returns the link stats for a host, creating them on first use.
+func (m *Manager) connStats(hostID int64) *docker.ConnStats {
+	m.clientsMu.Lock()
+	defer m.clientsMu.Unlock()
+	s, ok := m.conns[hostID]
+	if !ok {
+		s = &docker.ConnStats{}
+		m.conns[hostID] = s
+	}
+	return s
+}
+
+// HostConnections returns link stats for every remote host. Counters cover
+// the time since the manager started.
+func (m *Manager) HostConnections(ctx context.Context) ([]HostConnection, error) {
+	rows, err := m.pool.Query(ctx, "SELECT id, name, status FROM hosts WHERE ssh_addr != '' ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []HostConnection{}
+	for rows.Next() {
+		var hc HostConnection
+		if err := rows.Scan(&hc.HostID, &hc.Name, &hc.Status); err != nil {
+			return nil, err
+		}
+		hc.Connected = m.clientFor(hc.HostID) != nil
+		hc.ConnSnapshot = m.connStats(hc.HostID).Snapshot()
+		out = append(out, hc)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,149 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CostReport attributes monthly host costs to nodes and L1s. A host's cost
+// is split evenly across its nodes, and a node's share is split evenly
+// across the L1s it validates.
+type CostReport struct {
+	Total        float64    `json:"total"`
+	Unattributed float64    `json:"unattributed"` // idle hosts and nodes validating no L1
+	Hosts        []HostCost `json:"hosts"`
+	Nodes        []NodeCost `json:"nodes"`
+	L1s          []L1Cost   `json:"l1s"`
+}
+
+// HostCost is a host's monthly cost and how many nodes share it.
+type HostCost struct {
+	HostID       int64   `json:"host_id"`
+	Name         string  `json:"name"`
+	CostPerMonth float64 `json:"cost_per_month"`
+	Nodes        int     `json:"nodes"`
+}
+
+// NodeCost is a node's share of its host's monthly cost.
+type NodeCost struct {
+	NodeID      int64   `json:"node_id"`
+	Name        string  `json:"name"`
+	HostID      int64   `json:"host_id"`
+	MonthlyCost float64 `json:"monthly_cost"`
+	L1s         int     `json:"l1s"`
+}
+
+// L1Cost is the summed share of an L1's validator nodes.
+type L1Cost struct {
+	L1ID        int64   `json:"l1_id"`
+	Name        string  `json:"name"`
+	Validators  int     `json:"validators"`
+	MonthlyCost float64 `json:"monthly_cost"`
+}
+
+// SetHostCost sets a host's monthly cost used for cost attribution.
+func (m *Manager) SetHostCost(ctx context.Context, id int64, cost float64) (*Host, error) {
+	if cost < 0 {
+		return nil, fmt.Errorf("cost_per_month must not be negative")
+	}
+	host, err := m.GetHost(ctx, id)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE hosts SET cost_per_month=$1, updated_at=now() WHERE id=$2", cost, id); err != nil {
+		return nil, fmt.Errorf("update cost: %w", err)
+	}
+	m.logEvent(ctx, "host.cost_updated", host.Name, fmt.Sprintf("Cost set to %.2f/month", cost), nil)
+	return m.GetHost(ctx, id)
+}
+
+// Costs builds the current cost attribution report.
+func (m *Manager) Costs(ctx context.Context) (*CostReport, error) {
+	hosts, err := m.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT l.id, l.name, v.node_id
+		FROM l1s l LEFT JOIN l1_validators v ON v.l1_id = l.id
+		ORDER BY l.id`)
+	if err != nil {
+		return nil, err
+	}
+	var l1s []L1Cost
+	l1Index := map[int64]int{}
+	nodeL1s := map[int64][]int{} // node ID -> indexes into l1s
+	for rows.Next() {
+		var id int64
+		var name string
+		var nodeID *int64
+		if err := rows.Scan(&id, &name, &nodeID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		i, ok := l1Index[id]
+		if !ok {
+			i = len(l1s)
+			l1Index[id] = i
+			l1s = append(l1s, L1Cost{L1ID: id, Name: name})
+		}
+		if nodeID != nil {
+			l1s[i].Validators++
+			nodeL1s[*nodeID] = append(nodeL1s[*nodeID], i)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nodesPerHost := map[int64]int{}
+	for _, n := range nodes {
+		nodesPerHost[n.HostID]++
+	}
+
+	r := &CostReport{Hosts: []HostCost{}, Nodes: []NodeCost{}, L1s: []L1Cost{}}
+	hostCost := map[int64]float64{}
+	for _, h := range hosts {
+		hostCost[h.ID] = h.CostPerMonth
+		r.Total += h.CostPerMonth
+		r.Hosts = append(r.Hosts, HostCost{HostID: h.ID, Name: h.Name, CostPerMonth: h.CostPerMonth, Nodes: nodesPerHost[h.ID]})
+		if nodesPerHost[h.ID] == 0 {
+			r.Unattributed += h.CostPerMonth
+		}
+	}
+
+	for _, n := range nodes {
+		share := hostCost[n.HostID] / float64(nodesPerHost[n.HostID])
+		validated := nodeL1s[n.ID]
+		r.Nodes = append(r.Nodes, NodeCost{NodeID: n.ID, Name: n.Name, HostID: n.HostID, MonthlyCost: roundCents(share), L1s: len(validated)})
+		if len(validated) == 0 {
+			r.Unattributed += share
+			continue
+		}
+		for _, i := range validated {
+			l1s[i].MonthlyCost += share / float64(len(validated))
+		}
+	}
+	for _, l := range l1s {
+		l.MonthlyCost = roundCents(l.MonthlyCost)
+		r.L1s = append(r.L1s, l)
+	}
+	sort.Slice(r.L1s, func(i, j int) bool { return r.L1s[i].MonthlyCost > r.L1s[j].MonthlyCost })
+	r.Total = roundCents(r.Total)
+	r.Unattributed = roundCents(r.Unattributed)
+	return r, nil
+}
+
+// roundCents rounds a cost to two decimals.
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
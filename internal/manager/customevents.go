@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// customEventPrefix namespaces operator-posted event types so they can't be
+// mistaken for events the manager logs itself.
+const customEventPrefix = "custom."
+
+// Limits on operator-posted events.
+const (
+	maxCustomMessage = 1000
+	maxCustomDetails = 16 << 10
+)
+
+var customEventType = regexp.MustCompile(`^[a-z0-9_-]+(\.[a-z0-9_-]+)*$`)
+
+// CustomEventRequest is an annotation posted by an operator or external
+// tooling, e.g. {"type":"maintenance.begin","target":"host-2",
+// "message":"Provider maintenance window started"}.
+type CustomEventRequest struct {
+	Type     string         `json:"type"`     // lower-case dotted name; stored as custom.<type>
+	Severity string         `json:"severity"` // info (default), warning, error or critical
+	Target   string         `json:"target"`   // node, host or L1 name, or free text
+	Message  string         `json:"message"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// RecordCustomEvent adds an operator event to the event log and live stream.
+// source names who posted it and is stored in details.source.
+func (m *Manager) RecordCustomEvent(ctx context.Context, req CustomEventRequest, source string) (*Event, error) {
+	typ := strings.TrimPrefix(req.Type, customEventPrefix)
+	if !customEventType.MatchString(typ) {
+		return nil, fmt.Errorf("invalid type %q (want lower-case dotted words, e.g. maintenance.begin)", req.Type)
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if len(req.Message) > maxCustomMessage {
+		return nil, fmt.Errorf("message longer than %d bytes", maxCustomMessage)
+	}
+	if req.Severity == "" {
+		req.Severity = SeverityInfo
+	}
+	if err := CheckSeverity(req.Severity); err != nil {
+		return nil, err
+	}
+
+	details := req.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["source"] = source
+	detailJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("invalid details: %w", err)
+	}
+	if len(detailJSON) > maxCustomDetails {
+		return nil, fmt.Errorf("details larger than %d bytes", maxCustomDetails)
+	}
+
+	e := Event{EventType: customEventPrefix + typ, Severity: req.Severity, Target: req.Target, Message: req.Message, Details: details}
+	if err := m.insertEvent(ctx, &e, detailJSON); err != nil {
+		return nil, fmt.Errorf("insert event: %w", err)
+	}
+	m.publish(e)
+	return &e, nil
+}
@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// What DeleteNode does with the node's L1 validator assignments.
+const (
+	ValidatorsBlock    = "block"    // refuse while the node validates (default)
+	ValidatorsCascade  = "cascade"  // drop the assignments with the node
+	ValidatorsReassign = "reassign" // move them to DeleteNodeOptions.ReassignTo
+)
+
+// nodeAssignment is one active or queued validator assignment of a node.
+type nodeAssignment struct {
+	l1ID         int64
+	l1Name       string
+	weight       int64
+	validationID string
+	pending      bool
+}
+
+// nodeAssignments returns a node's validator assignments, queued ones
+// included.
+func (m *Manager) nodeAssignments(ctx context.Context, nodeID int64) ([]nodeAssignment, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT v.l1_id, l.name, v.weight, v.validation_id, false FROM l1_validators v JOIN l1s l ON l.id = v.l1_id
+		WHERE v.node_id=$1
+		UNION ALL
+		SELECT p.l1_id, l.name, p.weight, '', true FROM pending_validators p JOIN l1s l ON l.id = p.l1_id
+		WHERE p.node_id=$1
+		ORDER BY 1`, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("validator assignments: %w", err)
+	}
+	defer rows.Close()
+	var out []nodeAssignment
+	for rows.Next() {
+		var a nodeAssignment
+		if err := rows.Scan(&a.l1ID, &a.l1Name, &a.weight, &a.validationID, &a.pending); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// checkValidatorHandoff validates the validator option of a delete before
+// anything is changed.
+func (m *Manager) checkValidatorHandoff(ctx context.Context, id int64, opts DeleteNodeOptions) error {
+	switch opts.Validators {
+	case "", ValidatorsBlock, ValidatorsCascade:
+		return nil
+	case ValidatorsReassign:
+	default:
+		return fmt.Errorf("invalid validators option %q (want block, cascade, or reassign)", opts.Validators)
+	}
+	if opts.ReassignTo == 0 || opts.ReassignTo == id {
+		return fmt.Errorf("reassign needs reassign_to set to another node")
+	}
+	target, err := m.GetNode(ctx, opts.ReassignTo)
+	if err != nil {
+		return fmt.Errorf("reassign_to node %d not found", opts.ReassignTo)
+	}
+	assignments, err := m.nodeAssignments(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		var exists bool
+		if err := m.pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM l1_validators WHERE l1_id=$1 AND node_id=$2)
+			    OR EXISTS(SELECT 1 FROM pending_validators WHERE l1_id=$1 AND node_id=$2)`,
+			a.l1ID, target.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("check target: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("node %q already validates L1 %q", target.Name, a.l1Name)
+		}
+	}
+	return nil
+}
+
+// handOffValidators cascades or reassigns a node's validator assignments
+// ahead of its deletion. Reassigned validators go through the readiness
+// gate, so they are queued if the target isn't ready yet. avalauncher
+// doesn't remove validators on-chain: assignments with a validation ID stay
+// registered on the P-Chain until removed there.
+func (m *Manager) handOffValidators(ctx context.Context, node *Node, opts DeleteNodeOptions) error {
+	if opts.Validators != ValidatorsCascade && opts.Validators != ValidatorsReassign {
+		return nil
+	}
+	assignments, err := m.nodeAssignments(ctx, node.ID)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if _, err := m.pool.Exec(ctx, "DELETE FROM l1_validators WHERE l1_id=$1 AND node_id=$2", a.l1ID, node.ID); err != nil {
+			return fmt.Errorf("remove validator: %w", err)
+		}
+		if !a.pending {
+			m.recordWeight(ctx, a.l1ID, node.ID, 0, "node_deleted")
+		}
+		if _, err := m.pool.Exec(ctx, "DELETE FROM pending_validators WHERE l1_id=$1 AND node_id=$2", a.l1ID, node.ID); err != nil {
+			return fmt.Errorf("remove pending validator: %w", err)
+		}
+		details := map[string]any{"node": node.Name, "weight": a.weight}
+		if a.validationID != "" {
+			details["validation_id"] = a.validationID // still registered on-chain
+		}
+
+		if opts.Validators == ValidatorsCascade {
+			m.logEvent(ctx, "l1.validator.removed", a.l1Name, "Validator removed with node "+node.Name, details)
+			continue
+		}
+		v, err := m.AddValidator(ctx, a.l1ID, AddValidatorRequest{NodeID: opts.ReassignTo, Weight: a.weight, WhenReady: true})
+		if err != nil {
+			return fmt.Errorf("reassign L1 %q: %w", a.l1Name, err)
+		}
+		details["to_node"] = v.NodeName
+		m.logEvent(ctx, "l1.validator.reassigned", a.l1Name,
+			fmt.Sprintf("Validator moved from %s to %s", node.Name, v.NodeName), details)
+	}
+	return nil
+}
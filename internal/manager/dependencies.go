@@ -0,0 +1,205 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// NodeDependency is something a node deletion would affect.
+type NodeDependency struct {
+	Kind   string `json:"kind"` // validator, pending_validator, operation, traefik_route, dns_alias, ttl, volumes
+	Target string `json:"target"`
+	Detail string `json:"detail,omitempty"`
+
+	// Blocking dependencies must be resolved before the node can be
+	// deleted; the rest only need acknowledging.
+	Blocking bool `json:"blocking,omitempty"`
+}
+
+// NodeDependencies is the dry-run report for deleting a node.
+type NodeDependencies struct {
+	Node         string           `json:"node"`
+	Dependencies []NodeDependency `json:"dependencies"`
+	Acknowledge  []string         `json:"acknowledge"` // kinds to pass as ack= (or use force)
+}
+
+// DeleteNodeOptions controls DeleteNode.
+type DeleteNodeOptions struct {
+	RemoveVolumes bool
+	Ack           []string // dependency kinds the caller has acknowledged
+	Force         bool     // acknowledge everything
+
+	// Validators is block (default), cascade or reassign; see
+	// handOffValidators. ReassignTo is the node taking over with reassign.
+	Validators string
+	ReassignTo int64
+}
+
+// DependencyError is returned by DeleteNode when dependencies are blocking
+// or not acknowledged.
+type DependencyError struct {
+	Report *NodeDependencies
+}
+
+func (e *DependencyError) Error() string {
+	var blocking []string
+	for _, d := range e.Report.Dependencies {
+		if d.Blocking {
+			blocking = append(blocking, d.Kind+" "+d.Target)
+		}
+	}
+	if len(blocking) > 0 {
+		return fmt.Sprintf("node %q is still in use: %s — remove them first, or set validators=cascade or reassign",
+			e.Report.Node, strings.Join(blocking, ", "))
+	}
+	return fmt.Sprintf("deleting node %q affects %s; acknowledge with ack=%s or force=true",
+		e.Report.Node, strings.Join(e.Report.Acknowledge, ", "), strings.Join(e.Report.Acknowledge, ","))
+}
+
+// NodeDependencies lists what deleting a node would affect. Volumes are
+// only listed when removeVolumes is set, since they are kept otherwise.
+func (m *Manager) NodeDependencies(ctx context.Context, id int64, removeVolumes bool) (*NodeDependencies, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	r := &NodeDependencies{Node: node.Name, Dependencies: []NodeDependency{}}
+	add := func(d NodeDependency) { r.Dependencies = append(r.Dependencies, d) }
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT l.name, v.weight FROM l1_validators v JOIN l1s l ON l.id = v.l1_id
+		WHERE v.node_id=$1 ORDER BY l.name`, id)
+	if err != nil {
+		return nil, fmt.Errorf("validators: %w", err)
+	}
+	for rows.Next() {
+		var l1 string
+		var weight int64
+		if err := rows.Scan(&l1, &weight); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		add(NodeDependency{Kind: "validator", Target: l1, Detail: fmt.Sprintf("weight %d", weight), Blocking: true})
+	}
+	rows.Close()
+
+	rows, err = m.pool.Query(ctx, `
+		SELECT l.name FROM pending_validators p JOIN l1s l ON l.id = p.l1_id
+		WHERE p.node_id=$1 ORDER BY l.name`, id)
+	if err != nil {
+		return nil, fmt.Errorf("pending validators: %w", err)
+	}
+	for rows.Next() {
+		var l1 string
+		if err := rows.Scan(&l1); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		add(NodeDependency{Kind: "pending_validator", Target: l1, Detail: "queued validator addition is dropped"})
+	}
+	rows.Close()
+
+	// Running operations on the node, and rotations it takes part in.
+	rows, err = m.pool.Query(ctx, `
+		SELECT id, kind, step, params FROM operations
+		WHERE state=$1 AND (node_id=$2 OR (kind=$3 AND ((params->>'from_node_id')::BIGINT=$2 OR (params->>'to_node_id')::BIGINT=$2)))
+		ORDER BY id`, OpRunning, id, OpRotate)
+	if err != nil {
+		return nil, fmt.Errorf("operations: %w", err)
+	}
+	for rows.Next() {
+		var opID int64
+		var kind, step string
+		var params json.RawMessage
+		if err := rows.Scan(&opID, &kind, &step, &params); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		add(NodeDependency{Kind: "operation", Target: fmt.Sprintf("%s #%d", kind, opID), Detail: "interrupted at step " + step})
+	}
+	rows.Close()
+
+	if node.ExposeHTTP && m.traefikDomain != "" && m.traefikAuth != "" && node.NetworkMode != "host" {
+		add(NodeDependency{Kind: "traefik_route", Target: node.Name + "." + m.traefikDomain, Detail: "RPC route is removed"})
+	}
+	for _, a := range node.DNSAliases {
+		add(NodeDependency{Kind: "dns_alias", Target: a, Detail: "name stops resolving on the avax network"})
+	}
+	if node.ExpiresAt != nil {
+		add(NodeDependency{Kind: "ttl", Target: node.ExpiresAt.Format(time.RFC3339), Detail: "scheduled teardown is cancelled"})
+	}
+	if removeVolumes {
+		p := docker.AvagoParams{Name: node.Name}
+		for _, vol := range []string{p.VolumeDB(), p.VolumeStaking(), p.VolumeLogs()} {
+			add(NodeDependency{Kind: "volumes", Target: vol, Detail: "data is deleted"})
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, d := range r.Dependencies {
+		if !d.Blocking && !seen[d.Kind] {
+			seen[d.Kind] = true
+			r.Acknowledge = append(r.Acknowledge, d.Kind)
+		}
+	}
+	sort.Strings(r.Acknowledge)
+	if r.Acknowledge == nil {
+		r.Acknowledge = []string{}
+	}
+	return r, nil
+}
+
+// checkDeleteAllowed returns a DependencyError unless every dependency is
+// acknowledged and none is blocking.
+func (m *Manager) checkDeleteAllowed(ctx context.Context, id int64, opts DeleteNodeOptions) error {
+	if err := m.checkValidatorHandoff(ctx, id, opts); err != nil {
+		return err
+	}
+	r, err := m.NodeDependencies(ctx, id, opts.RemoveVolumes)
+	if err != nil {
+		return err
+	}
+	if opts.Validators == ValidatorsCascade || opts.Validators == ValidatorsReassign {
+		// Handled by handOffValidators, and implied by the option.
+		kept := r.Dependencies[:0]
+		for _, d := range r.Dependencies {
+			if d.Kind != "validator" && d.Kind != "pending_validator" {
+				kept = append(kept, d)
+			}
+		}
+		r.Dependencies = kept
+		acks := r.Acknowledge[:0]
+		for _, k := range r.Acknowledge {
+			if k != "pending_validator" {
+				acks = append(acks, k)
+			}
+		}
+		r.Acknowledge = acks
+	}
+	acked := map[string]bool{}
+	for _, k := range opts.Ack {
+		acked[strings.TrimSpace(k)] = true
+	}
+	var missing []string
+	for _, d := range r.Dependencies {
+		if d.Blocking {
+			return &DependencyError{Report: r}
+		}
+	}
+	for _, k := range r.Acknowledge {
+		if !acked[k] && !opts.Force {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		r.Acknowledge = missing
+		return &DependencyError{Report: r}
+	}
+	return nil
+}
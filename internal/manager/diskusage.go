@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// NodeDiskUsage reports the size of a node's Docker volumes.
+type NodeDiskUsage struct {
+	NodeID     int64            `json:"node_id"`
+	Name       string           `json:"name"`
+	HostID     int64            `json:"host_id"`
+	Volumes    map[string]int64 `json:"volumes"` // "db", "staking", "logs" -> bytes
+	TotalBytes int64            `json:"total_bytes"`
+	MeasuredAt time.Time        `json:"measured_at"`
+}
+
+// HostDiskUsage totals the volume sizes of a host's nodes.
+type HostDiskUsage struct {
+	HostID     int64     `json:"host_id"`
+	Nodes      int       `json:"nodes"`
+	TotalBytes int64     `json:"total_bytes"`
+	MeasuredAt time.Time `json:"measured_at"` // oldest node measurement included
+}
+
+// StartDiskUsagePoller measures node volume sizes now and then every
+// interval. Sizes are cached; measuring walks every volume on the host.
+func (m *Manager) StartDiskUsagePoller(interval time.Duration) {
+	go m.pollDiskUsage()
+	m.startPoller("disk_usage", interval, m.pollDiskUsage)
+}
+
+// pollDiskUsage measures every node's volumes, one Docker call per host.
+func (m *Manager) pollDiskUsage() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return 0, 1
+	}
+	byHost := map[int64][]Node{}
+	for _, n := range nodes {
+		byHost[n.HostID] = append(byHost[n.HostID], n)
+	}
+
+	fresh := make(map[int64]NodeDiskUsage, len(nodes))
+	for hostID, hostNodes := range byHost {
+		checked++
+		usage, err := m.measureDiskUsage(ctx, hostID, hostNodes)
+		var unsupported *docker.UnsupportedError
+		if errors.As(err, &unsupported) {
+			slog.Debug("disk usage skipped", "error", err, "host_id", hostID)
+			continue
+		}
+		if err != nil {
+			failed++
+			slog.Warn("disk usage", "error", err, "host_id", hostID)
+			// Keep the previous measurements for an unreachable host.
+			m.diskUsageMu.Lock()
+			for _, n := range hostNodes {
+				if u, ok := m.diskUsage[n.ID]; ok {
+					fresh[n.ID] = u
+				}
+			}
+			m.diskUsageMu.Unlock()
+			continue
+		}
+		for _, u := range usage {
+			fresh[u.NodeID] = u
+		}
+	}
+
+	m.diskUsageMu.Lock()
+	m.diskUsage = fresh
+	m.diskUsageMu.Unlock()
+	return checked, failed
+}
+
+// measureDiskUsage reads the volume sizes of nodes on one host.
+func (m *Manager) measureDiskUsage(ctx context.Context, hostID int64, nodes []Node) ([]NodeDiskUsage, error) {
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", hostID)
+	}
+	var names []string
+	for _, n := range nodes {
+		p := &docker.AvagoParams{Name: n.Name}
+		names = append(names, p.VolumeDB(), p.VolumeStaking(), p.VolumeLogs())
+	}
+	sizes, err := dc.VolumeSizes(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("volume sizes: %w", err)
+	}
+
+	now := time.Now()
+	out := make([]NodeDiskUsage, 0, len(nodes))
+	for _, n := range nodes {
+		p := &docker.AvagoParams{Name: n.Name}
+		u := NodeDiskUsage{NodeID: n.ID, Name: n.Name, HostID: hostID, Volumes: map[string]int64{}, MeasuredAt: now}
+		for kind, vol := range map[string]string{"db": p.VolumeDB(), "staking": p.VolumeStaking(), "logs": p.VolumeLogs()} {
+			if size, ok := sizes[vol]; ok {
+				u.Volumes[kind] = size
+				u.TotalBytes += size
+			}
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+// NodeUsage returns a node's volume sizes from the cache, measuring
+// them first when there is no measurement yet or refresh is set.
+func (m *Manager) NodeUsage(ctx context.Context, id int64, refresh bool) (*NodeDiskUsage, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if !refresh {
+		m.diskUsageMu.Lock()
+		u, ok := m.diskUsage[id]
+		m.diskUsageMu.Unlock()
+		if ok {
+			return &u, nil
+		}
+	}
+
+	usage, err := m.measureDiskUsage(ctx, node.HostID, []Node{*node})
+	if err != nil {
+		return nil, err
+	}
+	u := usage[0]
+	m.diskUsageMu.Lock()
+	m.diskUsage[id] = u
+	m.diskUsageMu.Unlock()
+	return &u, nil
+}
+
+// HostDiskUsages totals the cached node measurements per host.
+func (m *Manager) HostDiskUsages() []HostDiskUsage {
+	m.diskUsageMu.Lock()
+	defer m.diskUsageMu.Unlock()
+
+	byHost := map[int64]*HostDiskUsage{}
+	for _, u := range m.diskUsage {
+		h, ok := byHost[u.HostID]
+		if !ok {
+			h = &HostDiskUsage{HostID: u.HostID, MeasuredAt: u.MeasuredAt}
+			byHost[u.HostID] = h
+		}
+		h.Nodes++
+		h.TotalBytes += u.TotalBytes
+		if u.MeasuredAt.Before(h.MeasuredAt) {
+			h.MeasuredAt = u.MeasuredAt
+		}
+	}
+	out := make([]HostDiskUsage, 0, len(byHost))
+	for _, h := range byHost {
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].HostID < out[j].HostID })
+	return out
+}
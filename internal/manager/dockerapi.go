@@ -0,0 +1,28 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// recordAPIVersion stores the Docker API version negotiated with a host and,
+// when it changed, warns if it is older than docker.MinAPIVersion.
+func (m *Manager) recordAPIVersion(ctx context.Context, hostID int64, name string, dc *docker.Client) {
+	v := dc.APIVersion(ctx)
+	tag, err := m.pool.Exec(ctx,
+		"UPDATE hosts SET docker_api_version=$1, updated_at=now() WHERE id=$2 AND docker_api_version != $1", v, hostID)
+	if err != nil {
+		slog.Warn("record docker api version", "error", err, "host", name)
+		return
+	}
+	if tag.RowsAffected() == 0 || !docker.APIVersionOutdated(v) {
+		return
+	}
+	m.logEvent(ctx, "host.docker_outdated", name,
+		fmt.Sprintf("Docker API %s is older than %s; some features are unavailable", v, docker.MinAPIVersion),
+		map[string]any{"api_version": v, "min_api_version": docker.MinAPIVersion})
+	slog.Warn("docker api outdated", "host", name, "api_version", v, "min", docker.MinAPIVersion)
+}
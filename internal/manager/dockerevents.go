@@ -0,0 +1,230 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// eventWatchRetry is how long a host's Docker event stream waits before
+// resubscribing after it broke. The health poller covers the gap.
+const eventWatchRetry = 30 * time.Second
+
+// eventWatch is the state of one host's Docker event stream.
+type eventWatch struct {
+	live  bool
+	since time.Time // when live last changed
+	err   string    // why the stream last ended
+}
+
+// EventStreamState reports whether a host's Docker event stream is live.
+type EventStreamState struct {
+	Live  bool      `json:"live"`
+	Since time.Time `json:"since"`
+	Error string    `json:"error,omitempty"`
+}
+
+// StartDockerEvents subscribes to the Docker event stream of every
+// connected host, so crashed, restarted and OOM-killed containers are
+// noticed within seconds instead of at the next health check. Hosts are
+// picked up as they connect; a host whose stream is unavailable is left to
+// the health poller until a resubscribe succeeds.
+func (m *Manager) StartDockerEvents() {
+	m.startPoller("docker_events", eventWatchRetry, m.superviseEventWatches)
+	slog.Info("docker event watcher started")
+}
+
+// superviseEventWatches starts a watcher for each connected host that has
+// none. It reports hosts watched and those without a live stream.
+func (m *Manager) superviseEventWatches() (checked, failed int) {
+	m.clientsMu.RLock()
+	hostIDs := []int64{m.localHostID}
+	for id := range m.clients {
+		if id != m.localHostID {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+	m.clientsMu.RUnlock()
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	for _, id := range hostIDs {
+		checked++
+		w, ok := m.eventWatches[id]
+		if !ok {
+			w = &eventWatch{since: time.Now()}
+			m.eventWatches[id] = w
+			m.pollerWg.Add(1)
+			go m.watchHostEvents(id)
+		}
+		if !w.live {
+			failed++
+		}
+	}
+	return checked, failed
+}
+
+// watchHostEvents follows a host's event stream, resubscribing after
+// failures, until the host is removed or the manager stops.
+func (m *Manager) watchHostEvents(hostID int64) {
+	defer m.pollerWg.Done()
+	defer func() {
+		m.eventsMu.Lock()
+		delete(m.eventWatches, hostID)
+		m.eventsMu.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-m.stopPoller:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		dc := m.clientFor(hostID)
+		if dc == nil {
+			return
+		}
+		err := m.followHostEvents(ctx, hostID, dc)
+		if ctx.Err() != nil {
+			return
+		}
+		m.setEventWatch(hostID, false, err)
+		slog.Debug("docker event stream ended", "error", err, "host_id", hostID)
+		if !m.sleepOrStop(eventWatchRetry) {
+			return
+		}
+	}
+}
+
+// followHostEvents handles events from one subscription until it ends.
+func (m *Manager) followHostEvents(ctx context.Context, hostID int64, dc *docker.Client) error {
+	evs, errs := dc.ContainerEvents(ctx)
+	m.setEventWatch(hostID, true, nil)
+	for {
+		select {
+		case err := <-errs:
+			return err
+		case ev, ok := <-evs:
+			if !ok {
+				return <-errs
+			}
+			m.handleContainerEvent(ctx, ev)
+		}
+	}
+}
+
+func (m *Manager) setEventWatch(hostID int64, live bool, err error) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	w, ok := m.eventWatches[hostID]
+	if !ok {
+		return
+	}
+	if w.live != live {
+		w.since = time.Now()
+	}
+	w.live = live
+	w.err = ""
+	if err != nil {
+		w.err = err.Error()
+	}
+}
+
+// eventStreams returns the event stream state of each watched host.
+func (m *Manager) eventStreams() map[int64]EventStreamState {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	out := make(map[int64]EventStreamState, len(m.eventWatches))
+	for id, w := range m.eventWatches {
+		out[id] = EventStreamState{Live: w.live, Since: w.since, Error: w.err}
+	}
+	return out
+}
+
+// expectContainerEvents marks a container as being started or stopped by
+// the manager, which records the status change itself. The returned
+// function ends the mark.
+func (m *Manager) expectContainerEvents(containerID string) func() {
+	m.eventsMu.Lock()
+	m.expectedEvents[containerID]++
+	m.eventsMu.Unlock()
+	return func() {
+		m.eventsMu.Lock()
+		if m.expectedEvents[containerID]--; m.expectedEvents[containerID] <= 0 {
+			delete(m.expectedEvents, containerID)
+		}
+		m.eventsMu.Unlock()
+	}
+}
+
+func (m *Manager) containerEventsExpected(containerID string) bool {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	return m.expectedEvents[containerID] > 0
+}
+
+// handleContainerEvent applies a container event to its node. Exits of
+// running nodes mark them stopped; starts of stopped nodes (Docker's
+// restart policy) mark them running again, and the health poller takes over
+// from there; exits of auto-healing nodes schedule a restart. Transitions
+// the manager is driving itself, and nodes being created or reconfigured,
+// are left alone.
+func (m *Manager) handleContainerEvent(ctx context.Context, ev docker.ContainerEvent) {
+	node, err := scanNode(m.pool.QueryRow(ctx, `SELECT `+nodeColumns+` FROM nodes WHERE container_id=$1`, ev.ContainerID))
+	if err != nil {
+		return // not a node's current container
+	}
+
+	if ev.Action == "oom" {
+		m.logEvent(ctx, "node.oom", node.Name, "Container ran out of memory",
+			map[string]any{"memory_limit": node.MemoryLimit})
+		return
+	}
+	if m.containerEventsExpected(ev.ContainerID) {
+		return
+	}
+
+	var from []string
+	var to, msg string
+	switch ev.Action {
+	case "die":
+		from, to = []string{"bootstrapping", "running", "unhealthy"}, "stopped"
+		msg = "container exited"
+		if ev.ExitCode != "" {
+			msg = fmt.Sprintf("container exited with code %s", ev.ExitCode)
+		}
+	case "start":
+		from, to = []string{"stopped"}, "bootstrapping"
+		msg = "container restarted"
+	default:
+		return
+	}
+	tag, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2 AND status = ANY($3)", to, node.ID, from)
+	if err != nil {
+		slog.Error("docker event: update node status", "error", err, "node", node.Name)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		return
+	}
+	// An exit nobody asked for is an error; a restart by Docker is good news.
+	severity := SeverityInfo
+	if to == "stopped" {
+		severity = SeverityError
+	}
+	m.logEventAt(ctx, severity, "node.health", node.Name, fmt.Sprintf("Status changed: %s → %s (%s)", node.Status, to, msg),
+		map[string]any{"source": "docker_events", "exit_code": ev.ExitCode})
+	m.recordNodeHistory(ctx, node.ID)
+	if to == "stopped" && node.AutoHeal {
+		m.scheduleAutoHeal(node)
+	}
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// HostMaintenance is the status of a drained host. It takes no new nodes,
+// its node alerts are muted, and the host poller leaves the status alone
+// until the host is resumed.
+const HostMaintenance = "maintenance"
+
+// DrainRequest controls what happens to a drained host's nodes.
+type DrainRequest struct {
+	// StopNodes stops the host's running nodes gracefully. Otherwise they
+	// keep running and the host only stops taking new nodes. Nodes can't be
+	// migrated: their volumes and staking identity live on the host.
+	StopNodes bool `json:"stop_nodes"`
+}
+
+// DrainResult reports the outcome of DrainHost.
+type DrainResult struct {
+	Host    *Host             `json:"host"`
+	Stopped []string          `json:"stopped"`
+	Failed  map[string]string `json:"failed,omitempty"` // node name -> error
+}
+
+// DrainHost puts a host into maintenance, e.g. before a kernel upgrade,
+// and optionally stops its nodes.
+func (m *Manager) DrainHost(ctx context.Context, id int64, req DrainRequest) (*DrainResult, error) {
+	host, err := m.GetHost(ctx, id)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	if host.Status != HostMaintenance {
+		if _, err := m.pool.Exec(ctx,
+			"UPDATE hosts SET status=$1, updated_at=now() WHERE id=$2", HostMaintenance, id); err != nil {
+			return nil, fmt.Errorf("update status: %w", err)
+		}
+		m.logEvent(ctx, "host.drained", host.Name, "Host in maintenance", map[string]any{"stop_nodes": req.StopNodes})
+	}
+
+	res := &DrainResult{Stopped: []string{}}
+	if req.StopNodes {
+		nodes, err := m.ListNodes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes {
+			if n.HostID != id || n.ContainerID == "" || !containerUp(n.Status) {
+				continue
+			}
+			if err := m.StopNode(ctx, n.ID); err != nil {
+				slog.Warn("drain: stop node", "host", host.Name, "node", n.Name, "error", err)
+				if res.Failed == nil {
+					res.Failed = map[string]string{}
+				}
+				res.Failed[n.Name] = err.Error()
+				continue
+			}
+			res.Stopped = append(res.Stopped, n.Name)
+		}
+	}
+
+	res.Host, err = m.GetHost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ResumeHost takes a host out of maintenance and runs the operations queued
+// for it. Nodes stopped by the drain stay stopped until started.
+func (m *Manager) ResumeHost(ctx context.Context, id int64) (*Host, error) {
+	host, err := m.GetHost(ctx, id)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	if host.Status != HostMaintenance {
+		return nil, fmt.Errorf("host %q is not in maintenance", host.Name)
+	}
+
+	status := "online"
+	if dc := m.clientFor(id); dc == nil || dc.Ping(ctx) != nil {
+		status = "unreachable" // the host poller reconnects it
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE hosts SET status=$1, updated_at=now() WHERE id=$2", status, id); err != nil {
+		return nil, fmt.Errorf("update status: %w", err)
+	}
+	m.logEvent(ctx, "host.resumed", host.Name, "Host out of maintenance", map[string]any{"status": status})
+	if status == "online" {
+		go m.runQueuedOps(id)
+	}
+	return m.GetHost(ctx, id)
+}
+
+// checkHostSchedulable rejects new nodes on hosts in maintenance.
+func (m *Manager) checkHostSchedulable(ctx context.Context, hostID int64) error {
+	var name, status string
+	if err := m.pool.QueryRow(ctx, "SELECT name, status FROM hosts WHERE id=$1", hostID).Scan(&name, &status); err != nil {
+		return fmt.Errorf("host %d not found", hostID)
+	}
+	if status == HostMaintenance {
+		return fmt.Errorf("host %q is in maintenance", name)
+	}
+	return nil
+}
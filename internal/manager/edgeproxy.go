@@ -0,0 +1,141 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// EdgeProxyConfig enables per-host Traefik edge proxies that obtain
+// certificates with ACME DNS-01 challenges, for hosts behind firewalls that
+// can't accept inbound port 80.
+type EdgeProxyConfig struct {
+	Image       string            // Traefik image
+	Email       string            // ACME account email (empty = disabled)
+	CAServer    string            // ACME directory URL; empty = Let's Encrypt production
+	DNSProvider string            // "cloudflare" or "route53"
+	DNSEnv      map[string]string // provider credentials in lego's variable names
+}
+
+// EdgeProxyStatus is a host's edge proxy as seen through its Docker daemon.
+type EdgeProxyStatus struct {
+	HostID       int64  `json:"host_id"`
+	HostName     string `json:"host_name"`
+	Deployed     bool   `json:"deployed"`
+	CertResolver string `json:"cert_resolver"`
+	DNSProvider  string `json:"dns_provider,omitempty"`
+	*docker.EdgeProxyInfo
+}
+
+// SetEdgeProxy enables PUT /api/hosts/:id/proxy. Call it before Start.
+func (m *Manager) SetEdgeProxy(cfg EdgeProxyConfig) {
+	m.edgeProxy = cfg
+	slog.Info("edge proxies enabled", "image", cfg.Image, "dns_provider", cfg.DNSProvider)
+}
+
+// edgeProxyConfig returns the configuration set by SetEdgeProxy.
+func (m *Manager) edgeProxyConfig() EdgeProxyConfig {
+	return m.edgeProxy
+}
+
+// edgeProxyParams returns the proxy definition for the configured Traefik
+// network and cert resolver, so node router labels line up with it.
+func (m *Manager) edgeProxyParams() (*docker.EdgeProxyParams, error) {
+	cfg := m.edgeProxyConfig()
+	if cfg.Email == "" {
+		return nil, fmt.Errorf("edge proxies are not configured (set ACME_EMAIL and ACME_DNS_PROVIDER)")
+	}
+	if m.traefikDomain == "" {
+		return nil, fmt.Errorf("edge proxies require AVAGO_TRAEFIK_DOMAIN")
+	}
+	return &docker.EdgeProxyParams{
+		Image:        cfg.Image,
+		Network:      m.traefikNetwork,
+		CertResolver: m.traefikResolver,
+		Email:        cfg.Email,
+		CAServer:     cfg.CAServer,
+		DNSProvider:  cfg.DNSProvider,
+		DNSEnv:       cfg.DNSEnv,
+	}, nil
+}
+
+// EdgeProxy returns a host's edge proxy status.
+func (m *Manager) EdgeProxy(ctx context.Context, hostID int64) (*EdgeProxyStatus, error) {
+	host, err := m.GetHost(ctx, hostID)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %q not connected", host.Name)
+	}
+	info, err := dc.EdgeProxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.edgeProxyStatus(host, info), nil
+}
+
+// DeployEdgeProxy (re)deploys a host's edge proxy. Nodes already on the
+// host are picked up from their Traefik labels; nodes created before the
+// Traefik network existed there need a recreate to join it.
+func (m *Manager) DeployEdgeProxy(ctx context.Context, hostID int64) (*EdgeProxyStatus, error) {
+	p, err := m.edgeProxyParams()
+	if err != nil {
+		return nil, err
+	}
+	host, err := m.GetHost(ctx, hostID)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %q not connected", host.Name)
+	}
+	info, err := dc.DeployEdgeProxy(ctx, p)
+	if err != nil {
+		m.logEvent(ctx, "host.edge_proxy_failed", host.Name, fmt.Sprintf("Edge proxy deploy failed: %v", err), nil)
+		return nil, err
+	}
+	status := m.edgeProxyStatus(host, info)
+	m.logEvent(ctx, "host.edge_proxy_deployed", host.Name,
+		fmt.Sprintf("Edge proxy %s deployed with %s DNS-01 certificates", p.Image, p.DNSProvider),
+		map[string]any{"image": p.Image, "dns_provider": p.DNSProvider, "cert_resolver": status.CertResolver})
+	slog.Info("edge proxy deployed", "host", host.Name, "image", p.Image)
+	return status, nil
+}
+
+// RemoveEdgeProxy removes a host's edge proxy. Its certificates stay in the
+// ACME volume for a later deploy.
+func (m *Manager) RemoveEdgeProxy(ctx context.Context, hostID int64) error {
+	host, err := m.GetHost(ctx, hostID)
+	if err != nil {
+		return ErrHostNotFound
+	}
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return fmt.Errorf("host %q not connected", host.Name)
+	}
+	if err := dc.RemoveEdgeProxy(ctx); err != nil {
+		return err
+	}
+	m.logEvent(ctx, "host.edge_proxy_removed", host.Name, "Edge proxy removed", nil)
+	return nil
+}
+
+func (m *Manager) edgeProxyStatus(host *Host, info *docker.EdgeProxyInfo) *EdgeProxyStatus {
+	resolver := m.traefikResolver
+	if resolver == "" {
+		resolver = docker.DefaultCertResolver
+	}
+	return &EdgeProxyStatus{
+		HostID:        host.ID,
+		HostName:      host.Name,
+		Deployed:      info != nil,
+		CertResolver:  resolver,
+		DNSProvider:   m.edgeProxyConfig().DNSProvider,
+		EdgeProxyInfo: info,
+	}
+}
@@ -0,0 +1,264 @@
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailCheckInterval is how often immediate-mode email alerts are checked.
+const emailCheckInterval = 30 * time.Second
+
+// EmailAlertConfig holds SMTP settings for alert emails.
+type EmailAlertConfig struct {
+	Host     string // SMTP server (empty = disabled)
+	Port     int    // 465 = implicit TLS, anything else STARTTLS when offered
+	Username string // optional; PLAIN auth
+	Password string
+	From     string
+	To       []string
+
+	// Mode is "immediate" (one email per check with new and resolved
+	// alerts) or "digest" (one summary of firing alerts per DigestInterval).
+	Mode           string
+	DigestInterval time.Duration
+
+	// Threshold is how long an alert must keep firing before it is
+	// emailed, so short blips don't page anyone.
+	Threshold time.Duration
+
+	// EventSeverity is the lowest severity of logged events that are
+	// emailed too, e.g. an OOM kill or a failed deploy (empty = none).
+	// Events that mirror an emailed alert are left to the alert.
+	EventSeverity string
+}
+
+// emailAlertKinds are the alerts worth an email: hosts gone, nodes that
+// stay unhealthy or failed, and nodes stuck bootstrapping.
+var emailAlertKinds = map[string]bool{
+	"host.unreachable":   true,
+	"node.unhealthy":     true,
+	"node.failed":        true,
+	"node.bootstrapping": true,
+}
+
+// emailNotifier tracks which alerts and events have been emailed. It is
+// only used from its poller goroutine.
+type emailNotifier struct {
+	cfg       EmailAlertConfig
+	sent      map[string]Alert // kind/target -> alert as emailed
+	lastEvent int64            // highest event ID considered
+}
+
+// StartEmailAlerts begins a background loop that emails alerts which have
+// fired for longer than cfg.Threshold. It is a no-op if cfg.Host is empty.
+func (m *Manager) StartEmailAlerts(cfg EmailAlertConfig) {
+	if cfg.Host == "" {
+		return
+	}
+	n := &emailNotifier{cfg: cfg, sent: map[string]Alert{}}
+	// Only events logged from now on are emailed.
+	m.pool.QueryRow(context.Background(), "SELECT COALESCE(max(id), 0) FROM events").Scan(&n.lastEvent)
+	interval := emailCheckInterval
+	if cfg.Mode == "digest" {
+		interval = cfg.DigestInterval
+	}
+	m.startPoller("email_alerts", interval, func() (int, int) { return m.checkEmailAlerts(n) })
+	slog.Info("email alerts started", "smtp", cfg.Host, "mode", cfg.Mode, "threshold", cfg.Threshold, "event_severity", cfg.EventSeverity)
+}
+
+func (m *Manager) checkEmailAlerts(n *emailNotifier) (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	alerts, err := m.FiringAlerts(ctx)
+	if err != nil {
+		slog.Error("email alerts: firing alerts", "error", err)
+		return 0, 1
+	}
+	now := time.Now()
+	firing := map[string]Alert{}
+	for _, a := range alerts {
+		if emailAlertKinds[a.Kind] && now.Sub(a.Since) >= n.cfg.Threshold {
+			firing[a.Kind+"/"+a.Target] = a
+		}
+	}
+
+	events, lastEvent, err := m.emailEvents(ctx, n)
+	if err != nil {
+		slog.Error("email alerts: events", "error", err)
+		return 0, 1
+	}
+
+	var fresh, resolved []Alert
+	for key, a := range firing {
+		if _, ok := n.sent[key]; !ok {
+			fresh = append(fresh, a)
+		}
+	}
+	for key, a := range n.sent {
+		if _, ok := firing[key]; !ok {
+			resolved = append(resolved, a)
+		}
+	}
+
+	var subject string
+	var current []Alert
+	if n.cfg.Mode == "digest" {
+		if len(firing) == 0 && len(resolved) == 0 && len(events) == 0 {
+			n.sent, n.lastEvent = firing, lastEvent
+			return 0, 0
+		}
+		for _, a := range firing {
+			current = append(current, a)
+		}
+		subject = fmt.Sprintf("%d alert(s) firing", len(firing))
+	} else {
+		if len(fresh) == 0 && len(resolved) == 0 && len(events) == 0 {
+			n.lastEvent = lastEvent
+			return 0, 0
+		}
+		current = fresh
+		subject = fmt.Sprintf("%d new, %d resolved alert(s)", len(fresh), len(resolved))
+	}
+	if len(events) > 0 {
+		subject += fmt.Sprintf(", %d event(s)", len(events))
+	}
+
+	if err := sendMail(n.cfg, "[avalauncher] "+subject, alertEmailBody(current, resolved, events, now)); err != nil {
+		slog.Error("email alerts: send", "error", err)
+		return 1, 1 // retried next tick
+	}
+	n.sent, n.lastEvent = firing, lastEvent
+	m.logEvent(ctx, "alert.emailed", strings.Join(n.cfg.To, ","), subject, nil)
+	return 1, 0
+}
+
+// emailEvents returns the events logged since the last email at or above
+// the configured severity, oldest first, and the highest event ID seen.
+func (m *Manager) emailEvents(ctx context.Context, n *emailNotifier) ([]Event, int64, error) {
+	if n.cfg.EventSeverity == "" {
+		return nil, n.lastEvent, nil
+	}
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, event_type, severity, target, message, created_at
+		FROM events WHERE id > $1 ORDER BY id`, n.lastEvent)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	last := n.lastEvent
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		last = e.ID
+		if SeverityAtLeast(e.Severity, n.cfg.EventSeverity) && !emailAlertKinds[e.EventType] {
+			events = append(events, e)
+		}
+	}
+	return events, last, rows.Err()
+}
+
+// alertEmailBody renders alerts and events as plain text, oldest first.
+func alertEmailBody(firing, resolved []Alert, events []Event, now time.Time) string {
+	sortAlerts := func(as []Alert) {
+		sort.Slice(as, func(i, j int) bool { return as[i].Since.Before(as[j].Since) })
+	}
+	sortAlerts(firing)
+	sortAlerts(resolved)
+
+	var b strings.Builder
+	if len(firing) > 0 {
+		b.WriteString("Firing:\n")
+		for _, a := range firing {
+			fmt.Fprintf(&b, "  [%s] %s %s: %s (for %s)\n", a.Severity, a.Kind, a.Target, a.Message,
+				now.Sub(a.Since).Round(time.Second))
+		}
+	}
+	if len(resolved) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Resolved:\n")
+		for _, a := range resolved {
+			fmt.Fprintf(&b, "  %s %s: %s\n", a.Kind, a.Target, a.Message)
+		}
+	}
+	if len(events) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Events:\n")
+		for _, e := range events {
+			fmt.Fprintf(&b, "  [%s] %s %s %s: %s\n", e.Severity, e.CreatedAt.UTC().Format(time.RFC3339), e.EventType, e.Target, e.Message)
+		}
+	}
+	return b.String()
+}
+
+// sendMail delivers a plain-text email. Port 465 uses implicit TLS;
+// otherwise net/smtp upgrades with STARTTLS when the server offers it.
+func sendMail(cfg EmailAlertConfig, subject, body string) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	msg := "From: " + cfg.From + "\r\n" +
+		"To: " + strings.Join(cfg.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		strings.ReplaceAll(body, "\n", "\r\n")
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if cfg.Port != 465 {
+		return smtp.SendMail(addr, auth, cfg.From, cfg.To, []byte(msg))
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
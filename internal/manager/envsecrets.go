@@ -0,0 +1,275 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Secret is a managed secret. Its value is never returned by the API.
+type Secret struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// secretRef matches a ${secret:NAME} reference in a node env value or cmd
+// argument.
+var secretRef = regexp.MustCompile(`\$\{secret:([^}]*)\}`)
+
+// secretName restricts secret names to something safe in URLs and env.
+var secretName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// envKey is a valid environment variable name.
+var envKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// managedSecretAAD binds a sealed managed secret to its name.
+func managedSecretAAD(name string) string {
+	return "secrets/" + name + "/value"
+}
+
+// SetSecret creates or replaces a managed secret. The value is sealed with
+// SECRETS_KEY before it is stored. Running containers keep the old value
+// until their node is next reconfigured.
+func (m *Manager) SetSecret(ctx context.Context, name, value string) (*Secret, error) {
+	if !secretName.MatchString(name) {
+		return nil, fmt.Errorf("secret name must be 1-64 letters, digits, '_', '.' or '-'")
+	}
+	if value == "" {
+		return nil, fmt.Errorf("value is required")
+	}
+	sealed, err := m.secrets.Seal(value, managedSecretAAD(name))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt secret: %w", err)
+	}
+
+	var s Secret
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO secrets (name, value) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET value=EXCLUDED.value, updated_at=now()
+		RETURNING name, created_at, updated_at`, name, sealed).Scan(&s.Name, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("store secret: %w", err)
+	}
+	m.logEvent(ctx, "secret.set", name, "Secret set", nil)
+	return &s, nil
+}
+
+// ListSecrets returns the names of all managed secrets.
+func (m *Manager) ListSecrets(ctx context.Context) ([]Secret, error) {
+	rows, err := m.pool.Query(ctx, "SELECT name, created_at, updated_at FROM secrets ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Secret{}
+	for rows.Next() {
+		var s Secret
+		if err := rows.Scan(&s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSecret removes a managed secret. Secrets still referenced by a
+// node's env or cmd cannot be deleted.
+func (m *Manager) DeleteSecret(ctx context.Context, name string) error {
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, n := range nodes {
+		for _, ref := range nodeSecretRefs(n.Env, n.Cmd) {
+			if ref == name {
+				users = append(users, n.Name)
+				break
+			}
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("secret %q is used by node(s) %s", name, strings.Join(users, ", "))
+	}
+
+	tag, err := m.pool.Exec(ctx, "DELETE FROM secrets WHERE name=$1", name)
+	if err != nil {
+		return fmt.Errorf("delete secret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("secret %q not found", name)
+	}
+	m.logEvent(ctx, "secret.deleted", name, "Secret deleted", nil)
+	return nil
+}
+
+// SetNodeEnv replaces a node's extra environment variables and recreates
+// its container to apply them.
+func (m *Manager) SetNodeEnv(ctx context.Context, id int64, env map[string]string) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Status == "creating" {
+		return nil, fmt.Errorf("node %q is still being provisioned", node.Name)
+	}
+	if err := m.validateEnv(ctx, env, node.Cmd); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET env=$1, updated_at=now() WHERE id=$2", nonNilMap(env), id); err != nil {
+		return nil, fmt.Errorf("update env: %w", err)
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	m.logEvent(ctx, "node.env_updated", node.Name, "Environment updated",
+		map[string]any{"keys": keys})
+
+	if node.ContainerID != "" && node.Status != "stopped" {
+		m.requestReconfigure(id)
+	}
+	return m.GetNode(ctx, id)
+}
+
+// validateEnv checks env keys and that every secret referenced from env or
+// cmd exists.
+func (m *Manager) validateEnv(ctx context.Context, env map[string]string, cmd []string) error {
+	for k := range env {
+		if !envKey.MatchString(k) {
+			return fmt.Errorf("invalid env name %q", k)
+		}
+	}
+	refs := nodeSecretRefs(env, cmd)
+	if len(refs) == 0 {
+		return nil
+	}
+	rows, err := m.pool.Query(ctx, "SELECT name FROM secrets WHERE name = ANY($1)", refs)
+	if err != nil {
+		return fmt.Errorf("check secrets: %w", err)
+	}
+	found := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			found[name] = true
+		}
+	}
+	rows.Close()
+	for _, ref := range refs {
+		if !found[ref] {
+			return fmt.Errorf("secret %q not found", ref)
+		}
+	}
+	return nil
+}
+
+// renderEnv resolves secret references in a node's env and cmd for
+// container creation. Env entries come back as KEY=VALUE sorted by key.
+func (m *Manager) renderEnv(ctx context.Context, env map[string]string, cmd []string) ([]string, []string, error) {
+	values := map[string]string{}
+	for _, ref := range nodeSecretRefs(env, cmd) {
+		var sealed string
+		if err := m.pool.QueryRow(ctx, "SELECT value FROM secrets WHERE name=$1", ref).Scan(&sealed); err != nil {
+			return nil, nil, fmt.Errorf("secret %q not found", ref)
+		}
+		plain, err := m.secrets.Open(sealed, managedSecretAAD(ref))
+		if err != nil {
+			return nil, nil, fmt.Errorf("decrypt secret %q: %w", ref, err)
+		}
+		values[ref] = plain
+	}
+	expand := func(s string) string {
+		return secretRef.ReplaceAllStringFunc(s, func(match string) string {
+			return values[secretRef.FindStringSubmatch(match)[1]]
+		})
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	outEnv := make([]string, 0, len(keys))
+	for _, k := range keys {
+		outEnv = append(outEnv, k+"="+expand(env[k]))
+	}
+
+	var outCmd []string
+	if len(cmd) > 0 {
+		outCmd = make([]string, len(cmd))
+		for i, arg := range cmd {
+			outCmd[i] = expand(arg)
+		}
+	}
+	return outEnv, outCmd, nil
+}
+
+// nodeSecretRefs returns the distinct secret names referenced from env
+// values and cmd arguments.
+func nodeSecretRefs(env map[string]string, cmd []string) []string {
+	seen := map[string]bool{}
+	var refs []string
+	add := func(s string) {
+		for _, m := range secretRef.FindAllStringSubmatch(s, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				refs = append(refs, m[1])
+			}
+		}
+	}
+	for _, v := range env {
+		add(v)
+	}
+	for _, arg := range cmd {
+		add(arg)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// resealManagedSecrets rewrites managed secrets under the current master key.
+func (m *Manager) resealManagedSecrets(ctx context.Context) error {
+	rows, err := m.pool.Query(ctx, "SELECT name, value FROM secrets")
+	if err != nil {
+		return err
+	}
+	stale := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		if m.secrets.NeedsReseal(value) {
+			stale[name] = value
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, value := range stale {
+		sealed, err := m.reseal(value, managedSecretAAD(name))
+		if err != nil {
+			return fmt.Errorf("secret %q: %w", name, err)
+		}
+		if _, err := m.pool.Exec(ctx, "UPDATE secrets SET value=$1 WHERE name=$2", sealed, name); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		slog.Info("managed secrets re-encrypted", "secrets", len(stale))
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event hook limits. A hook that outlives its timeout is killed; at most
+// hookWorkers hooks run at once and further runs wait their turn.
+const (
+	DefaultHookTimeout = 10 * time.Second
+	MaxHookTimeout     = 5 * time.Minute
+	hookWorkers        = 4
+	hookOutputMax      = 4096 // bytes of output kept for a failed hook's event
+	hookCheckInterval  = 2 * time.Second
+	hookBatch          = 500 // events read per check
+)
+
+// EventHook runs a command whenever a matching event is logged.
+type EventHook struct {
+	Name    string
+	Events  []string // exact types, or prefixes ending in "*", e.g. "node.*"
+	Targets []string // node, host or L1 names; empty = any
+	Command []string // program and arguments, run without a shell
+	Timeout time.Duration
+}
+
+// matches reports whether the hook runs for e.
+func (h EventHook) matches(e Event) bool {
+	if len(h.Targets) > 0 && !slices.Contains(h.Targets, e.Target) {
+		return false
+	}
+	for _, pattern := range h.Events {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(e.EventType, prefix) {
+				return true
+			}
+		} else if e.EventType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEventHooks validates hooks before they are started.
+func CheckEventHooks(hooks []EventHook) error {
+	names := map[string]bool{}
+	for i, h := range hooks {
+		switch {
+		case h.Name == "":
+			return fmt.Errorf("hook %d: name is required", i+1)
+		case names[h.Name]:
+			return fmt.Errorf("hook %q: duplicate name", h.Name)
+		case len(h.Events) == 0:
+			return fmt.Errorf("hook %q: events are required", h.Name)
+		case len(h.Command) == 0 || h.Command[0] == "":
+			return fmt.Errorf("hook %q: command is required", h.Name)
+		case h.Timeout < 0 || h.Timeout > MaxHookTimeout:
+			return fmt.Errorf("hook %q: timeout must be at most %s", h.Name, MaxHookTimeout)
+		}
+		for _, e := range h.Events {
+			if strings.HasPrefix(e, "hook.") {
+				return fmt.Errorf("hook %q: hook.* events can't trigger hooks", h.Name)
+			}
+		}
+		names[h.Name] = true
+	}
+	return nil
+}
+
+// eventHooks tracks the hooks and the last event considered. It is only
+// used from its poller goroutine.
+type eventHooks struct {
+	hooks     []EventHook
+	lastEvent int64 // highest event ID considered
+}
+
+// StartEventHooks runs hooks on the events they match until the manager
+// stops. Events are read from the events table after a cursor, as email
+// alerts are, so a burst can't drop any; runs for one target happen one at
+// a time in event order, different targets in parallel. Each run gets the
+// event as JSON on stdin and in AVALAUNCHER_EVENT_* variables. A failed or
+// timed-out run logs a hook.failed event; hook.* events never trigger
+// hooks, so a failing hook can't loop. It is a no-op without hooks.
+func (m *Manager) StartEventHooks(hooks []EventHook) {
+	if len(hooks) == 0 {
+		return
+	}
+	for i := range hooks {
+		if hooks[i].Timeout == 0 {
+			hooks[i].Timeout = DefaultHookTimeout
+		}
+	}
+	eh := &eventHooks{hooks: hooks}
+	// Only events logged from now on run hooks.
+	m.pool.QueryRow(context.Background(), "SELECT COALESCE(max(id), 0) FROM events").Scan(&eh.lastEvent)
+	m.startPoller("event_hooks", hookCheckInterval, func() (int, int) { return m.runEventHooks(eh) })
+	slog.Info("event hooks started", "hooks", len(hooks))
+}
+
+// runEventHooks runs the hooks matching events logged since the last
+// check and waits for them, so the next batch for a target follows this one.
+func (m *Manager) runEventHooks(eh *eventHooks) (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	events, lastEvent, err := m.hookEvents(ctx, eh.lastEvent)
+	cancel()
+	if err != nil {
+		slog.Error("event hooks: events", "error", err)
+		return 0, 1
+	}
+	eh.lastEvent = lastEvent
+
+	type run struct {
+		hook  EventHook
+		event Event
+	}
+	var targets []string
+	queues := map[string][]run{}
+	for _, e := range events {
+		for _, h := range eh.hooks {
+			if !h.matches(e) {
+				continue
+			}
+			if _, ok := queues[e.Target]; !ok {
+				targets = append(targets, e.Target)
+			}
+			queues[e.Target] = append(queues[e.Target], run{h, e})
+		}
+	}
+
+	sem := make(chan struct{}, hookWorkers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, target := range targets {
+		wg.Add(1)
+		go func(runs []run) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-m.stopPoller:
+				return
+			}
+			defer func() { <-sem }()
+			for _, r := range runs {
+				select {
+				case <-m.stopPoller:
+					return
+				default:
+				}
+				ok := m.runEventHook(r.hook, r.event)
+				mu.Lock()
+				checked++
+				if !ok {
+					failed++
+				}
+				mu.Unlock()
+			}
+		}(queues[target])
+	}
+	wg.Wait()
+	return checked, failed
+}
+
+// hookEvents returns up to hookBatch events logged after id that may
+// trigger hooks, oldest first, and the highest event ID seen.
+func (m *Manager) hookEvents(ctx context.Context, after int64) ([]Event, int64, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, event_type, severity, target, message, details, created_at
+		FROM events WHERE id > $1 ORDER BY id LIMIT $2`, after, hookBatch)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	last := after
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		last = e.ID
+		if strings.HasPrefix(e.EventType, "hook.") {
+			continue // our own reports
+		}
+		if len(details) > 0 {
+			json.Unmarshal(details, &e.Details)
+		}
+		events = append(events, e)
+	}
+	return events, last, rows.Err()
+}
+
+// runEventHook runs one hook for e, killing it at its timeout or when the
+// manager stops. It reports whether the hook succeeded.
+func (m *Manager) runEventHook(h EventHook, e Event) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), h.Timeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.stopPoller:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("event hook: encode event", "error", err, "hook", h.Name)
+		return false
+	}
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second // don't wait on pipes held by orphaned children
+	// Only PATH is inherited: the manager's environment holds the admin
+	// key, database password and secrets key.
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH")},
+		"AVALAUNCHER_EVENT_ID="+strconv.FormatInt(e.ID, 10),
+		"AVALAUNCHER_EVENT_TYPE="+e.EventType,
+		"AVALAUNCHER_EVENT_SEVERITY="+e.Severity,
+		"AVALAUNCHER_EVENT_TARGET="+e.Target,
+		"AVALAUNCHER_EVENT_MESSAGE="+e.Message,
+		"AVALAUNCHER_HOOK="+h.Name,
+	)
+
+	start := time.Now()
+	err = cmd.Run()
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err == nil {
+		slog.Info("event hook ran", "hook", h.Name, "event", e.EventType, "target", e.Target, "duration", elapsed)
+		return true
+	}
+
+	msg := fmt.Sprintf("Hook %s failed on %s: %v", h.Name, e.EventType, err)
+	if ctx.Err() == context.DeadlineExceeded {
+		msg = fmt.Sprintf("Hook %s timed out after %s on %s", h.Name, h.Timeout, e.EventType)
+	}
+	output := out.String()
+	if len(output) > hookOutputMax {
+		output = output[len(output)-hookOutputMax:]
+	}
+	slog.Warn("event hook failed", "hook", h.Name, "event", e.EventType, "target", e.Target, "error", err, "duration", elapsed)
+	lctx, lcancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer lcancel()
+	m.logEvent(lctx, "hook.failed", e.Target, msg, map[string]any{
+		"hook":     h.Name,
+		"event_id": e.ID,
+		"output":   output,
+	})
+	return false
+}
@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Event and alert severities, lowest first.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityError:    2,
+	SeverityCritical: 3,
+}
+
+// eventSeverities overrides the default severity of event types. Types not
+// listed are info, or error when they end in _failed.
+var eventSeverities = map[string]string{
+	"host.unreachable":                SeverityCritical,
+	"host.docker_outdated":            SeverityWarning,
+	"node.duplicate_identity":         SeverityCritical,
+	"node.duplicate_identity_stopped": SeverityWarning,
+	"node.failed":                     SeverityError,
+	"node.oom":                        SeverityError,
+	"node.rolled_back":                SeverityWarning,
+	"node.op_retry":                   SeverityWarning,
+	"node.stuck":                      SeverityWarning,
+	"node.autoheal":                   SeverityWarning,
+	"node.uptime_low":                 SeverityWarning,
+	"node.image_drift":                SeverityWarning,
+	"node.expiring":                   SeverityWarning,
+	"l1.expiring":                     SeverityWarning,
+	"l1.rpc_dns_no_healthy":           SeverityCritical,
+	"upgrade.paused":                  SeverityWarning,
+	"upgrade.deadline_missed":         SeverityCritical,
+	"poller.paused":                   SeverityWarning,
+	"hook.failed":                     SeverityError,
+}
+
+// eventSeverity returns the severity events of a type are logged with.
+func eventSeverity(eventType string) string {
+	if s, ok := eventSeverities[eventType]; ok {
+		return s
+	}
+	if strings.HasSuffix(eventType, "_failed") {
+		return SeverityError
+	}
+	return SeverityInfo
+}
+
+// statusSeverity is the severity of a node moving to status.
+func statusSeverity(status string) string {
+	switch status {
+	case "unhealthy":
+		return SeverityWarning
+	case "failed":
+		return SeverityError
+	}
+	return SeverityInfo
+}
+
+// CheckSeverity returns an error unless s is a known severity.
+func CheckSeverity(s string) error {
+	if _, ok := severityRank[s]; !ok {
+		return fmt.Errorf("invalid severity %q (want info, warning, error or critical)", s)
+	}
+	return nil
+}
+
+// SeverityAtLeast reports whether s is min or more severe. An empty min
+// matches everything.
+func SeverityAtLeast(s, min string) bool {
+	return min == "" || severityRank[s] >= severityRank[min]
+}
+
+// severitiesAtLeast lists min and every more severe level, for SQL filters.
+func severitiesAtLeast(min string) []string {
+	var out []string
+	for _, s := range []string{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical} {
+		if SeverityAtLeast(s, min) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
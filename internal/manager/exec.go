@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// Node exec limits.
+const (
+	defaultExecTimeout = time.Minute
+	maxExecTimeout     = 10 * time.Minute
+)
+
+// DefaultExecCommands are the programs POST /api/nodes/:id/exec runs when
+// no allowlist is configured: read-only inspection tools. ps is left out:
+// process arguments carry values filled from managed secrets.
+var DefaultExecCommands = []string{"df", "du", "ls", "free", "uptime", "cat", "grep", "head", "tail", "wc"}
+
+// execPathRoots are the directories, within AvalancheGo's data directory,
+// that allowlisted commands may name. The staking keys and everything
+// outside, /proc included (process environments and arguments hold resolved
+// secrets), stay out of reach.
+var execPathRoots = []string{
+	docker.DataDir + "/db",
+	docker.LogsDir,
+	docker.DataDir + "/configs",
+}
+
+// ErrExecNotAllowed is returned for commands outside the exec allowlist.
+var ErrExecNotAllowed = errors.New("command not allowed")
+
+// ExecPolicy decides which commands may run inside node containers.
+type ExecPolicy struct {
+	Allowed  []string // program names (argv[0], without directory)
+	AllowAny bool     // skip the allowlist
+}
+
+// ExecRequest runs a command inside a node's container.
+type ExecRequest struct {
+	Cmd     []string `json:"cmd"`
+	Timeout string   `json:"timeout"` // Go duration; default 1m, at most 10m
+}
+
+// SetExecPolicy sets which commands ExecNode accepts. Call it before Start.
+func (m *Manager) SetExecPolicy(p ExecPolicy) {
+	m.execPolicy = p
+}
+
+// execAllowed checks a command against the policy.
+func (m *Manager) execAllowed(cmd []string) error {
+	p := m.execPolicy
+	if p.AllowAny {
+		return nil
+	}
+	allowed := p.Allowed
+	if allowed == nil {
+		allowed = DefaultExecCommands
+	}
+	if !slices.Contains(allowed, cmd[0]) || strings.Contains(cmd[0], "/") {
+		return fmt.Errorf("%w: %q (allowed: %s)", ErrExecNotAllowed, cmd[0], strings.Join(allowed, ", "))
+	}
+	named, recursive := false, false
+	for _, arg := range cmd[1:] {
+		p := arg
+		if strings.HasPrefix(arg, "-") {
+			recursive = recursive || cmd[0] == "grep" && grepRecursive(arg)
+			if !strings.Contains(arg, "/") {
+				continue
+			}
+			// Only --flag=/absolute/path values are checked; anything
+			// else can't be told apart from a path in disguise.
+			_, v, ok := strings.Cut(arg, "=")
+			if !ok || !strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("%w: %q (pass paths as separate arguments)", ErrExecNotAllowed, arg)
+			}
+			p = v
+		}
+		if !strings.Contains(p, "/") {
+			continue // a pattern, count or field list
+		}
+		if !execPathAllowed(p) {
+			return fmt.Errorf("%w: %q is outside %s", ErrExecNotAllowed, p, strings.Join(execPathRoots, ", "))
+		}
+		named = true
+	}
+	if recursive && !named {
+		return fmt.Errorf("%w: recursive grep needs a path under %s", ErrExecNotAllowed, strings.Join(execPathRoots, ", "))
+	}
+	return nil
+}
+
+// execPathAllowed reports whether p is an absolute path within one of
+// execPathRoots, or the data directory itself.
+func execPathAllowed(p string) bool {
+	if !path.IsAbs(p) {
+		return false
+	}
+	p = path.Clean(p)
+	if p == docker.DataDir {
+		return true
+	}
+	for _, root := range execPathRoots {
+		if p == root || strings.HasPrefix(p, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// grepRecursive reports whether a grep argument turns on directory
+// recursion, alone or among combined short flags.
+func grepRecursive(arg string) bool {
+	if strings.HasPrefix(arg, "--") {
+		return arg == "--recursive" || arg == "--dereference-recursive" || strings.HasPrefix(arg, "--directories")
+	}
+	return strings.HasPrefix(arg, "-") && strings.ContainsAny(arg[1:], "rRd")
+}
+
+// ExecNode runs a command inside a running node's container, streaming its
+// combined output to w, and returns the exit code. The command runs without
+// a shell; run "sh -c" only where the policy allows any command.
+func (m *Manager) ExecNode(ctx context.Context, id int64, req ExecRequest, w io.Writer) (int, error) {
+	if len(req.Cmd) == 0 || req.Cmd[0] == "" {
+		return -1, fmt.Errorf("cmd is required")
+	}
+	if err := m.execAllowed(req.Cmd); err != nil {
+		return -1, err
+	}
+	timeout := defaultExecTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 || d > maxExecTimeout {
+			return -1, fmt.Errorf("invalid timeout %q (max %s)", req.Timeout, maxExecTimeout)
+		}
+		timeout = d
+	}
+
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	if node.ContainerID == "" || !containerUp(node.Status) {
+		return -1, fmt.Errorf("node %q is not running", node.Name)
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return -1, fmt.Errorf("host %d not connected", node.HostID)
+	}
+
+	m.logEvent(ctx, "node.exec", node.Name, "Exec: "+strings.Join(req.Cmd, " "),
+		map[string]any{"cmd": req.Cmd, "program": path.Base(req.Cmd[0])})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return dc.ExecStream(ctx, node.ContainerID, req.Cmd, w)
+}
@@ -0,0 +1,242 @@
+package manager
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// GenesisRequest holds parameters for a subnet-evm genesis. An empty
+// fee_config takes subnet-evm's defaults.
+type GenesisRequest struct {
+	ChainID     int64                 `json:"chain_id"`
+	FeeConfig   GenesisFeeConfig      `json:"fee_config"`
+	Alloc       map[string]string     `json:"alloc"`       // address -> balance in wei (decimal or 0x hex)
+	Precompiles map[string]Precompile `json:"precompiles"` // keyed by the names in precompileKeys
+}
+
+// GenesisFeeConfig is subnet-evm's dynamic fee configuration.
+type GenesisFeeConfig struct {
+	GasLimit                 uint64 `json:"gasLimit"`
+	TargetBlockRate          uint64 `json:"targetBlockRate"`
+	MinBaseFee               uint64 `json:"minBaseFee"`
+	TargetGas                uint64 `json:"targetGas"`
+	BaseFeeChangeDenominator uint64 `json:"baseFeeChangeDenominator"`
+	MinBlockGasCost          uint64 `json:"minBlockGasCost"`
+	MaxBlockGasCost          uint64 `json:"maxBlockGasCost"`
+	BlockGasCostStep         uint64 `json:"blockGasCostStep"`
+}
+
+// Precompile enables a stateful precompile at genesis. Allow-list
+// precompiles take admin/manager/enabled addresses; warp takes a quorum.
+type Precompile struct {
+	AdminAddresses   []string `json:"admin_addresses"`
+	ManagerAddresses []string `json:"manager_addresses"`
+	EnabledAddresses []string `json:"enabled_addresses"`
+	QuorumNumerator  uint64   `json:"quorum_numerator"` // warp only; default 67
+}
+
+// precompileKeys maps request names to subnet-evm's genesis config keys.
+var precompileKeys = map[string]string{
+	"contract_deployer_allow_list": "contractDeployerAllowListConfig",
+	"tx_allow_list":                "txAllowListConfig",
+	"native_minter":                "contractNativeMinterConfig",
+	"fee_manager":                  "feeManagerConfig",
+	"reward_manager":               "rewardManagerConfig",
+	"warp":                         "warpConfig",
+}
+
+// defaultFeeConfig is subnet-evm's default fee configuration.
+var defaultFeeConfig = GenesisFeeConfig{
+	GasLimit:                 8_000_000,
+	TargetBlockRate:          2,
+	MinBaseFee:               25_000_000_000,
+	TargetGas:                15_000_000,
+	BaseFeeChangeDenominator: 36,
+	MinBlockGasCost:          0,
+	MaxBlockGasCost:          1_000_000,
+	BlockGasCostStep:         200_000,
+}
+
+// SetL1Genesis builds a subnet-evm genesis, stores it in l1s.chain_config,
+// and copies it into the containers of the L1's validator nodes. A later
+// deployment uses it when no genesis is given.
+func (m *Manager) SetL1Genesis(ctx context.Context, id int64, req GenesisRequest) (json.RawMessage, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	if l1.VM != "subnet-evm" {
+		return nil, fmt.Errorf("L1 %q runs %s; genesis generation supports subnet-evm only", l1.Name, l1.VM)
+	}
+	if l1.BlockchainID != "" {
+		return nil, fmt.Errorf("L1 %q is already deployed; its genesis is fixed", l1.Name)
+	}
+
+	genesis, err := buildGenesis(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.pool.Exec(ctx, "UPDATE l1s SET chain_config=$1, updated_at=now() WHERE id=$2", genesis, id); err != nil {
+		return nil, fmt.Errorf("store genesis: %w", err)
+	}
+	m.logEvent(ctx, "l1.genesis", l1.Name, fmt.Sprintf("Genesis set (chain ID %d)", req.ChainID), nil)
+
+	for _, v := range l1.Validators {
+		node, err := m.GetNode(ctx, v.NodeID)
+		if err != nil || node.ContainerID == "" {
+			continue
+		}
+		dc := m.clientFor(node.HostID)
+		if dc == nil {
+			continue
+		}
+		if err := m.installL1Genesis(ctx, dc, node.ID, node.ContainerID); err != nil {
+			slog.Warn("install genesis", "error", err, "node", node.Name, "l1", l1.Name)
+		}
+	}
+	return genesis, nil
+}
+
+// buildGenesis renders a subnet-evm genesis from req.
+func buildGenesis(req GenesisRequest) (json.RawMessage, error) {
+	if req.ChainID <= 0 {
+		return nil, fmt.Errorf("chain_id must be positive")
+	}
+
+	fee := req.FeeConfig
+	if fee == (GenesisFeeConfig{}) {
+		fee = defaultFeeConfig
+	}
+	if fee.GasLimit == 0 || fee.TargetBlockRate == 0 || fee.MinBaseFee == 0 || fee.TargetGas == 0 || fee.BaseFeeChangeDenominator == 0 {
+		return nil, fmt.Errorf("fee_config needs gasLimit, targetBlockRate, minBaseFee, targetGas and baseFeeChangeDenominator")
+	}
+	if fee.MinBlockGasCost > fee.MaxBlockGasCost {
+		return nil, fmt.Errorf("fee_config minBlockGasCost exceeds maxBlockGasCost")
+	}
+
+	config := map[string]any{
+		"chainId":             req.ChainID,
+		"homesteadBlock":      0,
+		"eip150Block":         0,
+		"eip155Block":         0,
+		"eip158Block":         0,
+		"byzantiumBlock":      0,
+		"constantinopleBlock": 0,
+		"petersburgBlock":     0,
+		"istanbulBlock":       0,
+		"muirGlacierBlock":    0,
+		"feeConfig":           fee,
+	}
+	for name, p := range req.Precompiles {
+		key, ok := precompileKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown precompile %q", name)
+		}
+		pc := map[string]any{"blockTimestamp": 0}
+		if name == "warp" {
+			q := p.QuorumNumerator
+			if q == 0 {
+				q = 67
+			}
+			if q < 33 || q > 100 {
+				return nil, fmt.Errorf("warp quorum_numerator must be 33-100")
+			}
+			pc["quorumNumerator"] = q
+		} else {
+			for _, l := range []struct {
+				key   string
+				addrs []string
+			}{{"adminAddresses", p.AdminAddresses}, {"managerAddresses", p.ManagerAddresses}, {"enabledAddresses", p.EnabledAddresses}} {
+				if len(l.addrs) == 0 {
+					continue
+				}
+				norm := make([]string, len(l.addrs))
+				for i, a := range l.addrs {
+					n, err := normalizeAddress(a)
+					if err != nil {
+						return nil, fmt.Errorf("precompile %s: %w", name, err)
+					}
+					norm[i] = n
+				}
+				pc[l.key] = norm
+			}
+		}
+		config[key] = pc
+	}
+
+	alloc := map[string]any{}
+	for addr, bal := range req.Alloc {
+		a, err := normalizeAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("alloc: %w", err)
+		}
+		n, ok := new(big.Int).SetString(bal, 0)
+		if !ok || n.Sign() < 0 {
+			return nil, fmt.Errorf("alloc %s: invalid balance %q", addr, bal)
+		}
+		alloc[strings.TrimPrefix(a, "0x")] = map[string]string{"balance": "0x" + n.Text(16)}
+	}
+
+	zeroHash := "0x" + strings.Repeat("0", 64)
+	return json.Marshal(map[string]any{
+		"config":     config,
+		"alloc":      alloc,
+		"nonce":      "0x0",
+		"timestamp":  "0x0",
+		"extraData":  "0x",
+		"gasLimit":   fmt.Sprintf("0x%x", fee.GasLimit),
+		"difficulty": "0x0",
+		"mixHash":    zeroHash,
+		"coinbase":   "0x" + strings.Repeat("0", 40),
+		"number":     "0x0",
+		"gasUsed":    "0x0",
+		"parentHash": zeroHash,
+	})
+}
+
+// normalizeAddress validates a 20-byte hex EVM address and lowercases it.
+func normalizeAddress(a string) (string, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(a, "0x"), "0X"))
+	if err != nil || len(b) != 20 {
+		return "", fmt.Errorf("invalid address %q", a)
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// installL1Genesis copies the genesis of every L1 the node validates into its
+// container at <DataDir>/<L1ConfigDir>/<l1 id>/genesis.json.
+func (m *Manager) installL1Genesis(ctx context.Context, dc *docker.Client, nodeID int64, containerID string) error {
+	rows, err := m.pool.Query(ctx, `
+		SELECT l.id, l.chain_config
+		FROM l1_validators v
+		JOIN l1s l ON v.l1_id = l.id
+		WHERE v.node_id = $1 AND l.chain_config != '{}'::jsonb`, nodeID)
+	if err != nil {
+		return fmt.Errorf("load genesis: %w", err)
+	}
+	files := map[string][]byte{}
+	for rows.Next() {
+		var l1ID int64
+		var genesis []byte
+		if err := rows.Scan(&l1ID, &genesis); err != nil {
+			rows.Close()
+			return fmt.Errorf("load genesis: %w", err)
+		}
+		files[fmt.Sprintf("%s/%d/genesis.json", docker.L1ConfigDir, l1ID)] = genesis
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load genesis: %w", err)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return dc.CopyFiles(ctx, containerID, docker.DataDir, files, 0o644)
+}
@@ -0,0 +1,170 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Health check methods. Nodes without one use HealthAuto.
+const (
+	HealthAuto = "auto" // http where the control plane can reach the API, else exec
+	HealthHTTP = "http" // JSON-RPC health.health from the control plane
+	HealthExec = "exec" // health.health from inside the container
+	HealthTCP  = "tcp"  // TCP connect to the staking port on the host
+)
+
+// parseHealthCheck normalizes a health check method for storage; auto is
+// stored as "".
+func parseHealthCheck(method string) (string, error) {
+	switch method {
+	case "", HealthAuto:
+		return "", nil
+	case HealthHTTP, HealthExec, HealthTCP:
+		return method, nil
+	}
+	return "", fmt.Errorf("invalid health_check %q (want auto, http, exec, or tcp)", method)
+}
+
+// apiReachable reports whether the control plane can address a node's HTTP
+// API directly. Bridge nodes on remote hosts are only reachable when
+// exposed: their container names resolve on the local Docker network alone.
+func (m *Manager) apiReachable(node Node) bool {
+	return node.NetworkMode == "host" || node.ExposeHTTP || node.HostID == m.localHostID
+}
+
+// checkAutoHealth probes over HTTP when the node's API is reachable from the
+// control plane, and from inside the container when it isn't or the
+// connection fails (e.g. avalauncher isn't on the node's Docker network).
+func (m *Manager) checkAutoHealth(ctx context.Context, node Node) NodeHealth {
+	if m.apiReachable(node) {
+		if h := m.checkHTTPHealth(ctx, node); !h.unreachable {
+			return h
+		}
+	}
+	return m.checkExecHealth(ctx, node)
+}
+
+// checkExecHealth calls health.health on the node's HTTP API from inside its
+// own container, for APIs that aren't reachable from the control plane.
+func (m *Manager) checkExecHealth(ctx context.Context, node Node) NodeHealth {
+	h := NodeHealth{Method: HealthExec}
+	out, err := m.execNodeHTTP(ctx, node, "/ext/health", `{"jsonrpc":"2.0","id":1,"method":"health.health"}`)
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+	if err := h.parse([]byte(out)); err != nil {
+		h.Error = err.Error()
+	}
+	return h
+}
+
+// execHTTPScript POSTs $2 to path $1 on 127.0.0.1:$0 over bash's /dev/tcp.
+// HTTP/1.0 keeps the response unchunked, so the body is everything after
+// the headers.
+const execHTTPScript = `exec 3<>/dev/tcp/127.0.0.1/$0 && ` +
+	`printf 'POST %s HTTP/1.0\r\nHost: 127.0.0.1\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s' "$1" "${#2}" "$2" >&3 && ` +
+	`cat <&3`
+
+// execNodeHTTP POSTs a JSON body to a path on the node's HTTP API from
+// inside its container and returns the response body. It uses curl when the
+// image has it and bash otherwise; the stock AvalancheGo image ships bash
+// but not curl.
+func (m *Manager) execNodeHTTP(ctx context.Context, node Node, path, body string) (string, error) {
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return "", errors.New("host not connected")
+	}
+	port := 9650
+	if node.NetworkMode == "host" {
+		port = node.HTTPPort
+	}
+	// No -f: health.health answers 503 when unhealthy, and its body says why.
+	code, out, err := dc.Exec(ctx, node.ContainerID, []string{
+		"curl", "-s", "-m", "5",
+		"-H", "Content-Type: application/json",
+		"-d", body,
+		fmt.Sprintf("http://127.0.0.1:%d%s", port, path),
+	})
+	if err == nil && code == 0 {
+		return out, nil
+	}
+	if !commandMissing(code, out, err) {
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("curl exited %d", code)
+	}
+
+	code, out, err = dc.Exec(ctx, node.ContainerID, []string{"bash", "-c", execHTTPScript, strconv.Itoa(port), path, body})
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", fmt.Errorf("bash /dev/tcp exited %d: %s", code, strings.TrimSpace(out))
+	}
+	_, resp, ok := strings.Cut(out, "\r\n\r\n")
+	if !ok {
+		return "", errors.New("malformed HTTP response")
+	}
+	return resp, nil
+}
+
+// commandMissing reports whether an exec failed because the command isn't
+// in the image.
+func commandMissing(code int, out string, err error) bool {
+	if code == 126 || code == 127 {
+		return true
+	}
+	msg := out
+	if err != nil {
+		msg = err.Error()
+	}
+	return strings.Contains(msg, "executable file not found")
+}
+
+// checkTCPHealth verifies that the staking port accepts connections on the
+// node's host. It only proves the process is listening, not that it's healthy.
+func (m *Manager) checkTCPHealth(ctx context.Context, node Node) NodeHealth {
+	h := NodeHealth{Method: HealthTCP, Checks: []HealthCheckResult{}}
+	addr := m.hostAddress(ctx, node.HostID)
+	if addr == "" {
+		h.Error = "host address unknown"
+		return h
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(addr, strconv.Itoa(node.StakingPort)))
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+	conn.Close()
+	h.Healthy = true
+	return h
+}
+
+// SetNodeHealthCheck changes how a node's health is probed. It takes effect
+// on the next poll; the container is not touched.
+func (m *Manager) SetNodeHealthCheck(ctx context.Context, id int64, method string) (*Node, error) {
+	method, err := parseHealthCheck(method)
+	if err != nil {
+		return nil, err
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET health_check=$1, updated_at=now() WHERE id=$2", method, id); err != nil {
+		return nil, fmt.Errorf("update health check: %w", err)
+	}
+	if method == "" {
+		method = HealthAuto
+	}
+	m.logEvent(ctx, "node.health_check_updated", node.Name, "Health check method set to "+method, nil)
+	return m.GetNode(ctx, id)
+}
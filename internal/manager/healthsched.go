@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// stableChecks is how many consecutive healthy checks make a running node
+// stable, moving it to the slow health check interval.
+const stableChecks = 10
+
+// Health check fan-out defaults.
+const (
+	defaultHealthWorkers = 8
+	defaultHealthTimeout = 10 * time.Second
+)
+
+// healthSchedule tracks when each node is next due for a health check. It
+// is only used from the health poller goroutine.
+type healthSchedule struct {
+	next      map[int64]time.Time
+	streak    map[int64]int // consecutive healthy checks
+	lastSweep time.Time     // last pending-validator/latency maintenance
+	warm      bool          // first pass done; new nodes are then due at once
+}
+
+// SetHealthConcurrency sets how many nodes the health poller checks at once
+// and how long each check may take. Call it before Start; zero values keep
+// the defaults.
+func (m *Manager) SetHealthConcurrency(workers int, timeout time.Duration) {
+	m.healthWorkers = workers
+	m.healthTimeout = timeout
+}
+
+// healthIntervals derives the adaptive check intervals from HEALTH_INTERVAL:
+// unhealthy and just-changed nodes are checked at fast, new running nodes at
+// base, and stable ones at slow.
+func (m *Manager) healthIntervals() (fast, base, slow time.Duration) {
+	base = m.healthInterval
+	fast = base / 3
+	if fast < 5*time.Second {
+		fast = min(5*time.Second, base)
+	}
+	return fast, base, base * 4
+}
+
+// healthDue reports whether a node should be checked now. Unknown nodes are due,
+// except on the first pass after startup: then they are spread randomly
+// over one interval so a large fleet isn't checked in a single burst.
+func (m *Manager) healthDue(nodeID int64, now time.Time) bool {
+	s := &m.health
+	next, ok := s.next[nodeID]
+	if !ok && !s.warm {
+		next = now.Add(rand.N(m.healthInterval))
+		s.next[nodeID] = next
+	}
+	return !now.Before(next)
+}
+
+// recordHealthCheck schedules a node's next check from the result of this one.
+func (m *Manager) recordHealthCheck(nodeID int64, healthy, changed bool, now time.Time) {
+	fast, base, slow := m.healthIntervals()
+	s := &m.health
+	switch {
+	case !healthy || changed:
+		s.streak[nodeID] = 0
+		s.next[nodeID] = now.Add(jitter(fast, pollerJitter))
+	default:
+		s.streak[nodeID]++
+		if s.streak[nodeID] >= stableChecks {
+			s.next[nodeID] = now.Add(jitter(slow, pollerJitter))
+		} else {
+			s.next[nodeID] = now.Add(jitter(base, pollerJitter))
+		}
+	}
+}
+
+// forget drops a node's schedule so it is checked as soon as it is running
+// again.
+func (s *healthSchedule) forget(nodeID int64) {
+	delete(s.next, nodeID)
+	delete(s.streak, nodeID)
+}
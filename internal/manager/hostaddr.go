@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+)
+
+// dnsName matches a DNS hostname.
+var dnsName = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// validateHostAddress accepts an IP or DNS name without scheme or port.
+// Empty means "use the SSH host".
+func validateHostAddress(address string) error {
+	if address == "" || net.ParseIP(address) != nil || (len(address) <= 253 && dnsName.MatchString(address)) {
+		return nil
+	}
+	return fmt.Errorf("invalid address %q (want an IP or DNS name without scheme or port)", address)
+}
+
+// SetHostAddress sets the address the manager uses to reach node HTTP APIs
+// on a remote host. Empty reverts to the SSH host.
+func (m *Manager) SetHostAddress(ctx context.Context, id int64, address string) (*Host, error) {
+	if err := validateHostAddress(address); err != nil {
+		return nil, err
+	}
+	host, err := m.GetHost(ctx, id)
+	if err != nil {
+		return nil, ErrHostNotFound
+	}
+	if host.SSHAddr == "" {
+		return nil, fmt.Errorf("the local host is addressed through the Docker network")
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE hosts SET address=$1, updated_at=now() WHERE id=$2", address, id); err != nil {
+		return nil, fmt.Errorf("update address: %w", err)
+	}
+	m.logEvent(ctx, "host.address_updated", host.Name, fmt.Sprintf("Address set to %q", address), nil)
+	return m.GetHost(ctx, id)
+}
+
+// httpBindIP is the host IP an exposed node HTTP API binds on. Local nodes
+// stay on loopback; remote ones must be reachable from the manager, so
+// they bind on all interfaces and should be firewalled to it.
+func (m *Manager) httpBindIP(hostID int64) string {
+	if hostID == m.localHostID {
+		return "127.0.0.1"
+	}
+	return "0.0.0.0"
+}
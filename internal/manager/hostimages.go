@@ -0,0 +1,314 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// imageHostWorkers is how many hosts an image pull or prune works on at once.
+const imageHostWorkers = 4
+
+// HostImage is an image on a host, flagged for AvalancheGo.
+type HostImage struct {
+	docker.ImageInfo
+	Avalanchego bool `json:"avalanchego"`
+	Default     bool `json:"default,omitempty"` // a configured default node image
+}
+
+// HostImages lists the images on one host.
+type HostImages struct {
+	HostID   int64       `json:"host_id"`
+	HostName string      `json:"host_name"`
+	Error    string      `json:"error,omitempty"`
+	Images   []HostImage `json:"images"`
+}
+
+// PullImageRequest pre-pulls an image on several hosts.
+type PullImageRequest struct {
+	Image   string  `json:"image"`
+	HostIDs []int64 `json:"host_ids"` // empty = every online host
+}
+
+// PruneImagesRequest removes unused AvalancheGo images.
+type PruneImagesRequest struct {
+	HostIDs []int64 `json:"host_ids"` // empty = every online host
+	DryRun  bool    `json:"dry_run"`  // report what would be removed
+}
+
+// HostImageResult is the outcome of a pull or prune on one host.
+type HostImageResult struct {
+	HostID    int64    `json:"host_id"`
+	HostName  string   `json:"host_name"`
+	Status    string   `json:"status"` // pulled, pruned, would_prune (dry run), or failed
+	Error     string   `json:"error,omitempty"`
+	Removed   []string `json:"removed,omitempty"`   // prune: tags (or IDs of untagged images)
+	Reclaimed int64    `json:"reclaimed,omitempty"` // prune: bytes, not counting layers shared with kept images
+	Seconds   float64  `json:"seconds"`
+}
+
+// HostImageReport summarizes a pull or prune across hosts.
+type HostImageReport struct {
+	Image     string            `json:"image,omitempty"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Reclaimed int64             `json:"reclaimed,omitempty"`
+	Results   []HostImageResult `json:"results"`
+}
+
+// imageHosts returns the hosts named by ids, or every online host when ids
+// is empty.
+func (m *Manager) imageHosts(ctx context.Context, ids []int64) ([]Host, error) {
+	all, err := m.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		var hosts []Host
+		for _, h := range all {
+			if h.Status == "online" {
+				hosts = append(hosts, h)
+			}
+		}
+		return hosts, nil
+	}
+	var hosts []Host
+	for _, id := range ids {
+		i := slices.IndexFunc(all, func(h Host) bool { return h.ID == id })
+		if i < 0 {
+			return nil, fmt.Errorf("host %d not found", id)
+		}
+		if !slices.ContainsFunc(hosts, func(h Host) bool { return h.ID == id }) {
+			hosts = append(hosts, all[i])
+		}
+	}
+	return hosts, nil
+}
+
+// eachImageHost runs fn for every host, a few at a time, and collects the
+// results in host order. Hosts without a connected Docker client fail
+// without calling fn.
+func (m *Manager) eachImageHost(hosts []Host, fn func(dc *docker.Client, r *HostImageResult)) *HostImageReport {
+	report := &HostImageReport{Results: make([]HostImageResult, len(hosts))}
+	sem := make(chan struct{}, imageHostWorkers)
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		r := &report.Results[i]
+		*r = HostImageResult{HostID: h.ID, HostName: h.Name}
+		dc := m.clientFor(h.ID)
+		if dc == nil {
+			r.Status, r.Error = "failed", "host not connected"
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			fn(dc, r)
+			r.Seconds = time.Since(start).Seconds()
+		}()
+	}
+	wg.Wait()
+	for _, r := range report.Results {
+		if r.Status == "failed" {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Reclaimed += r.Reclaimed
+	}
+	return report
+}
+
+// ListHostImages returns the images on every connected host (or one host),
+// optionally only the AvalancheGo ones.
+func (m *Manager) ListHostImages(ctx context.Context, hostID int64, avagoOnly bool) ([]HostImages, error) {
+	var ids []int64
+	if hostID != 0 {
+		ids = []int64{hostID}
+	}
+	hosts, err := m.imageHosts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	repos, defaults := m.avagoImageRefs(ctx)
+
+	out := make([]HostImages, len(hosts))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		hi := &out[i]
+		*hi = HostImages{HostID: h.ID, HostName: h.Name, Images: []HostImage{}}
+		dc := m.clientFor(h.ID)
+		if dc == nil {
+			hi.Error = "host not connected"
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			images, err := dc.ListImages(ctx)
+			if err != nil {
+				hi.Error = err.Error()
+				return
+			}
+			for _, img := range images {
+				isAvago := isAvagoImage(img, repos)
+				if avagoOnly && !isAvago {
+					continue
+				}
+				hi.Images = append(hi.Images, HostImage{
+					ImageInfo:   img,
+					Avalanchego: isAvago,
+					Default:     slices.ContainsFunc(img.Tags, func(t string) bool { return defaults[t] }),
+				})
+			}
+			slices.SortFunc(hi.Images, func(a, b HostImage) int { return b.Created.Compare(a.Created) })
+		}()
+	}
+	wg.Wait()
+	return out, nil
+}
+
+// PrePullImage pulls an image on several hosts at once, e.g. to warm new
+// hosts before creating many nodes. The image policy applies as for nodes.
+func (m *Manager) PrePullImage(ctx context.Context, req PullImageRequest) (*HostImageReport, error) {
+	req.Image = strings.TrimSpace(req.Image)
+	if req.Image == "" {
+		return nil, fmt.Errorf("image is required")
+	}
+	if err := m.checkImageAllowed(req.Image); err != nil {
+		return nil, err
+	}
+	hosts, err := m.imageHosts(ctx, req.HostIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no online hosts")
+	}
+
+	report := m.eachImageHost(hosts, func(dc *docker.Client, r *HostImageResult) {
+		if err := dc.Pull(ctx, req.Image); err != nil {
+			r.Status, r.Error = "failed", err.Error()
+			return
+		}
+		r.Status = "pulled"
+	})
+	report.Image = req.Image
+	m.logEvent(ctx, "image.pulled", req.Image,
+		fmt.Sprintf("Pre-pulled on %d host(s), %d failed", report.Succeeded, report.Failed),
+		map[string]any{"succeeded": report.Succeeded, "failed": report.Failed})
+	return report, nil
+}
+
+// PruneImages removes AvalancheGo images that no container uses. Configured
+// default images and the targets of unfinished upgrades are kept, since the
+// next node create or upgrade step would pull them again.
+func (m *Manager) PruneImages(ctx context.Context, req PruneImagesRequest) (*HostImageReport, error) {
+	hosts, err := m.imageHosts(ctx, req.HostIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no online hosts")
+	}
+	repos, keep := m.avagoImageRefs(ctx)
+	rows, err := m.pool.Query(ctx, "SELECT image FROM upgrades WHERE state <> ALL($1)",
+		[]string{UpgradeDone, UpgradeFailed, UpgradeCancelled})
+	if err != nil {
+		return nil, fmt.Errorf("list upgrades: %w", err)
+	}
+	for rows.Next() {
+		var img string
+		if err := rows.Scan(&img); err == nil {
+			keep[img] = true
+		}
+	}
+	rows.Close()
+
+	report := m.eachImageHost(hosts, func(dc *docker.Client, r *HostImageResult) {
+		images, err := dc.ListImages(ctx)
+		if err != nil {
+			r.Status, r.Error = "failed", err.Error()
+			return
+		}
+		r.Status = "pruned"
+		if req.DryRun {
+			r.Status = "would_prune"
+		}
+		for _, img := range images {
+			if img.Containers > 0 || !isAvagoImage(img, repos) ||
+				slices.ContainsFunc(img.Tags, func(t string) bool { return keep[t] }) {
+				continue
+			}
+			if !req.DryRun {
+				if err := dc.RemoveImage(ctx, img); err != nil {
+					r.Status, r.Error = "failed", err.Error()
+					continue
+				}
+			}
+			if len(img.Tags) > 0 {
+				r.Removed = append(r.Removed, img.Tags...)
+			} else {
+				r.Removed = append(r.Removed, img.ID)
+			}
+			r.Reclaimed += img.Size
+		}
+	})
+	if !req.DryRun {
+		for _, r := range report.Results {
+			if len(r.Removed) > 0 {
+				m.logEvent(ctx, "image.pruned", r.HostName,
+					fmt.Sprintf("Pruned %d AvalancheGo image(s), %d bytes", len(r.Removed), r.Reclaimed),
+					map[string]any{"removed": r.Removed, "reclaimed": r.Reclaimed})
+			}
+		}
+	}
+	return report, nil
+}
+
+// avagoImageRefs returns the repositories nodes run (configured defaults
+// and every node's image) and the configured default refs themselves.
+func (m *Manager) avagoImageRefs(ctx context.Context) (repos, defaults map[string]bool) {
+	repos, defaults = map[string]bool{}, map[string]bool{}
+	add := func(ref string) {
+		if ref != "" {
+			repos[imageRepository(ref)] = true
+		}
+	}
+	defaults[m.avagoImage] = true
+	add(m.avagoImage)
+	for _, ref := range m.avagoImages {
+		defaults[ref] = true
+		add(ref)
+	}
+	if rows, err := m.pool.Query(ctx, "SELECT DISTINCT image FROM nodes"); err == nil {
+		for rows.Next() {
+			var ref string
+			if rows.Scan(&ref) == nil {
+				add(ref)
+			}
+		}
+		rows.Close()
+	}
+	return repos, defaults
+}
+
+// isAvagoImage reports whether an image is AvalancheGo: one of its tags or
+// digests belongs to a repository nodes run, or names avalanchego. Digests
+// catch the untagged images left behind when a tag like :latest moves.
+func isAvagoImage(img docker.ImageInfo, repos map[string]bool) bool {
+	for _, ref := range append(slices.Clone(img.Tags), img.Digests...) {
+		if repos[imageRepository(ref)] || docker.LooksLikeAvago(ref, "") {
+			return true
+		}
+	}
+	return false
+}
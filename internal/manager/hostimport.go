@@ -0,0 +1,298 @@
+package manager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Host import formats.
+const (
+	ImportAnsible     = "ansible"      // INI inventory
+	ImportAnsibleYAML = "ansible_yaml" // YAML inventory
+	ImportSSHConfig   = "ssh_config"   // ~/.ssh/config
+)
+
+// hostImportWorkers is how many hosts an import connects to at once.
+const hostImportWorkers = 4
+
+// ImportHostsRequest adds the hosts listed in an inventory or SSH config.
+type ImportHostsRequest struct {
+	Format       string  `json:"format"`         // ansible, ansible_yaml, or ssh_config
+	Content      string  `json:"content"`        // the file's text
+	CostPerMonth float64 `json:"cost_per_month"` // applied to every imported host
+	DryRun       bool    `json:"dry_run"`        // parse and check names only
+}
+
+// HostImportResult is the outcome for one listed host.
+type HostImportResult struct {
+	Name    string `json:"name"`
+	SSHAddr string `json:"ssh_addr"`
+	Status  string `json:"status"` // added, failed, skipped, or valid (dry run)
+	Error   string `json:"error,omitempty"`
+	HostID  int64  `json:"host_id,omitempty"`
+}
+
+// HostImportReport summarizes an import.
+type HostImportReport struct {
+	Added   int                `json:"added"`
+	Failed  int                `json:"failed"`
+	Skipped int                `json:"skipped"`
+	Results []HostImportResult `json:"results"`
+}
+
+// ImportHosts parses an inventory and adds every listed host, validating
+// SSH access to several at a time. Hosts whose name already exists are
+// skipped; one host failing doesn't stop the others.
+func (m *Manager) ImportHosts(ctx context.Context, req ImportHostsRequest) (*HostImportReport, error) {
+	var hosts []AddHostRequest
+	var err error
+	switch req.Format {
+	case ImportAnsible:
+		hosts, err = parseAnsibleINI(req.Content)
+	case ImportAnsibleYAML:
+		hosts, err = parseAnsibleYAML(req.Content)
+	case ImportSSHConfig:
+		hosts, err = parseSSHConfig(req.Content)
+	default:
+		return nil, fmt.Errorf("invalid format %q (want ansible, ansible_yaml, or ssh_config)", req.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts found")
+	}
+	if req.CostPerMonth < 0 {
+		return nil, fmt.Errorf("cost_per_month must not be negative")
+	}
+
+	existing := map[string]bool{}
+	if all, err := m.ListHosts(ctx); err == nil {
+		for _, h := range all {
+			existing[h.Name] = true
+		}
+	}
+
+	report := &HostImportReport{Results: make([]HostImportResult, len(hosts))}
+	sem := make(chan struct{}, hostImportWorkers)
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		h.CostPerMonth = req.CostPerMonth
+		r := &report.Results[i]
+		*r = HostImportResult{Name: h.Name, SSHAddr: h.SSHAddr}
+		switch {
+		case existing[h.Name]:
+			r.Status, r.Error = "skipped", "host already exists"
+			continue
+		case req.DryRun:
+			r.Status = "valid"
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			host, err := m.AddHost(ctx, h)
+			if err != nil {
+				r.Status, r.Error = "failed", err.Error()
+				return
+			}
+			r.Status, r.HostID = "added", host.ID
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range report.Results {
+		switch r.Status {
+		case "added":
+			report.Added++
+		case "failed":
+			report.Failed++
+		case "skipped":
+			report.Skipped++
+		}
+	}
+	if !req.DryRun {
+		m.logEvent(ctx, "host.imported", "hosts",
+			fmt.Sprintf("Imported hosts: %d added, %d failed, %d skipped", report.Added, report.Failed, report.Skipped),
+			map[string]any{"added": report.Added, "failed": report.Failed, "skipped": report.Skipped})
+	}
+	return report, nil
+}
+
+// importHost builds an AddHostRequest from an inventory entry. The host
+// address doubles as the node API address.
+func importHost(name, host, user, port string) AddHostRequest {
+	if host == "" {
+		host = name
+	}
+	addr := host
+	if port != "" && port != "22" {
+		addr = net.JoinHostPort(host, port)
+	}
+	if user != "" {
+		addr = user + "@" + addr
+	}
+	return AddHostRequest{Name: name, SSHAddr: addr, Address: host}
+}
+
+// dedupHosts drops repeated names, keeping the first entry.
+func dedupHosts(hosts []AddHostRequest) []AddHostRequest {
+	seen := map[string]bool{}
+	out := hosts[:0]
+	for _, h := range hosts {
+		if !seen[h.Name] {
+			seen[h.Name] = true
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// parseAnsibleINI reads host lines ("name ansible_host=... ansible_user=...
+// ansible_port=...") from an INI inventory. Group headers are ignored, as
+// are [group:vars] and [group:children] sections.
+func parseAnsibleINI(content string) ([]AddHostRequest, error) {
+	var hosts []AddHostRequest
+	skip := false
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section := strings.Trim(line, "[]")
+			skip = strings.HasSuffix(section, ":vars") || strings.HasSuffix(section, ":children")
+			continue
+		}
+		if skip {
+			continue
+		}
+		fields := strings.Fields(line)
+		if strings.ContainsAny(fields[0], "[]") {
+			return nil, fmt.Errorf("line %d: host ranges are not supported", n)
+		}
+		vars := map[string]string{}
+		for _, f := range fields[1:] {
+			k, v, ok := strings.Cut(f, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value, got %q", n, f)
+			}
+			vars[k] = strings.Trim(v, `"'`)
+		}
+		hosts = append(hosts, importHost(fields[0], vars["ansible_host"], vars["ansible_user"], vars["ansible_port"]))
+	}
+	return dedupHosts(hosts), sc.Err()
+}
+
+// yamlGroup is a group of a YAML inventory.
+type yamlGroup struct {
+	Hosts    map[string]map[string]any `yaml:"hosts"`
+	Children map[string]yamlGroup      `yaml:"children"`
+}
+
+// parseAnsibleYAML reads the hosts of every group, nested ones included,
+// of a YAML inventory.
+func parseAnsibleYAML(content string) ([]AddHostRequest, error) {
+	var top map[string]yamlGroup
+	if err := yaml.Unmarshal([]byte(content), &top); err != nil {
+		return nil, fmt.Errorf("parse inventory: %w", err)
+	}
+	var hosts []AddHostRequest
+	var walk func(g yamlGroup)
+	walk = func(g yamlGroup) {
+		names := make([]string, 0, len(g.Hosts))
+		for name := range g.Hosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			vars := g.Hosts[name]
+			str := func(k string) string {
+				if v, ok := vars[k]; ok && v != nil {
+					return fmt.Sprint(v)
+				}
+				return ""
+			}
+			hosts = append(hosts, importHost(name, str("ansible_host"), str("ansible_user"), str("ansible_port")))
+		}
+		children := make([]string, 0, len(g.Children))
+		for name := range g.Children {
+			children = append(children, name)
+		}
+		sort.Strings(children)
+		for _, name := range children {
+			walk(g.Children[name])
+		}
+	}
+	groups := make([]string, 0, len(top))
+	for name := range top {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+	for _, name := range groups {
+		walk(top[name])
+	}
+	return dedupHosts(hosts), nil
+}
+
+// parseSSHConfig reads "Host" blocks with their HostName, User and Port.
+// Patterns (*, ?, !) are skipped; a block naming several aliases yields one
+// host per alias.
+func parseSSHConfig(content string) ([]AddHostRequest, error) {
+	type block struct {
+		aliases              []string
+		hostname, user, port string
+	}
+	var blocks []*block
+	var cur *block
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.Replace(line, "=", " ", 1), " ")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(key) {
+		case "host":
+			cur = &block{}
+			blocks = append(blocks, cur)
+			for _, a := range strings.Fields(value) {
+				if !strings.ContainsAny(a, "*?!") {
+					cur.aliases = append(cur.aliases, a)
+				}
+			}
+		case "match":
+			cur = nil // conditional blocks don't name hosts
+		case "hostname":
+			if cur != nil {
+				cur.hostname = value
+			}
+		case "user":
+			if cur != nil {
+				cur.user = value
+			}
+		case "port":
+			if cur != nil {
+				cur.port = value
+			}
+		}
+	}
+	var hosts []AddHostRequest
+	for _, b := range blocks {
+		for _, a := range b.aliases {
+			hosts = append(hosts, importHost(a, b.hostname, b.user, b.port))
+		}
+	}
+	return dedupHosts(hosts), sc.Err()
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+const (
+	// hostMetricsInterval is the minimum time between utilization samples
+	// of one host; each sample runs a probe container.
+	hostMetricsInterval = 5 * time.Minute
+
+	// hostMetricsRetention is how long utilization samples are kept.
+	hostMetricsRetention = 7 * 24 * time.Hour
+)
+
+// HostMetrics is a host's latest utilization sample.
+type HostMetrics struct {
+	docker.HostUtilization
+	SampledAt time.Time `json:"sampled_at"`
+}
+
+// collectHostMetrics samples every reachable host whose latest sample is
+// older than hostMetricsInterval, and prunes old samples.
+func (m *Manager) collectHostMetrics() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT h.id, h.name FROM hosts h
+		WHERE h.status IN ('online', 'maintenance') AND NOT EXISTS (
+			SELECT 1 FROM host_metrics hm WHERE hm.host_id = h.id AND hm.created_at > $1)`,
+		time.Now().Add(-hostMetricsInterval))
+	if err != nil {
+		return 0, 1
+	}
+	type hostRow struct {
+		id   int64
+		name string
+	}
+	var due []hostRow
+	for rows.Next() {
+		var h hostRow
+		if rows.Scan(&h.id, &h.name) == nil {
+			due = append(due, h)
+		}
+	}
+	rows.Close()
+
+	for _, h := range due {
+		dc := m.clientFor(h.id)
+		if dc == nil {
+			continue
+		}
+		checked++
+		u, err := dc.HostUtilization(ctx, probeImage)
+		if err != nil {
+			failed++
+			slog.Warn("host utilization", "error", err, "host", h.name)
+			continue
+		}
+		if _, err := m.pool.Exec(ctx, `
+			INSERT INTO host_metrics (host_id, disk_total_bytes, disk_free_bytes, load1, load5, load15, mem_total_bytes, mem_used_bytes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			h.id, u.DiskTotalBytes, u.DiskFreeBytes, u.Load1, u.Load5, u.Load15, u.MemTotalBytes, u.MemUsedBytes); err != nil {
+			failed++
+			slog.Warn("record host utilization", "error", err, "host", h.name)
+		}
+	}
+
+	if _, err := m.pool.Exec(ctx, "DELETE FROM host_metrics WHERE created_at < $1", time.Now().Add(-hostMetricsRetention)); err != nil {
+		slog.Warn("prune host metrics", "error", err)
+	}
+	return checked, failed
+}
+
+// latestHostMetrics returns each host's most recent utilization sample.
+func (m *Manager) latestHostMetrics(ctx context.Context) (map[int64]*HostMetrics, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT DISTINCT ON (host_id) host_id, disk_total_bytes, disk_free_bytes, load1, load5, load15,
+		       mem_total_bytes, mem_used_bytes, created_at
+		FROM host_metrics ORDER BY host_id, created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int64]*HostMetrics{}
+	for rows.Next() {
+		var id int64
+		var hm HostMetrics
+		if err := rows.Scan(&id, &hm.DiskTotalBytes, &hm.DiskFreeBytes, &hm.Load1, &hm.Load5, &hm.Load15,
+			&hm.MemTotalBytes, &hm.MemUsedBytes, &hm.SampledAt); err != nil {
+			return nil, err
+		}
+		out[id] = &hm
+	}
+	return out, rows.Err()
+}
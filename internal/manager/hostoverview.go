@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// HostOverview combines everything a host detail page needs in one call.
+type HostOverview struct {
+	Host      Host            `json:"host"`
+	Connected bool            `json:"connected"`
+	Usage     HostUsage       `json:"usage"`
+	Nodes     []HostNodeUsage `json:"nodes"`
+	Events    []Event         `json:"events"`
+	Alerts    []Alert         `json:"alerts"`
+}
+
+// HostUsage is the host's capacity alongside the summed usage of its node
+// containers.
+type HostUsage struct {
+	CPUs         int     `json:"cpus"`
+	MemoryMB     int64   `json:"memory_mb"`
+	CPUPercent   float64 `json:"cpu_percent"` // 100 = one full core
+	MemoryUsedMB float64 `json:"memory_used_mb"`
+}
+
+// HostNodeUsage is a node on the host with its container's resource usage.
+type HostNodeUsage struct {
+	Node
+	Usage      *docker.ContainerUsage `json:"usage,omitempty"`
+	UsageError string                 `json:"usage_error,omitempty"`
+}
+
+// HostOverview returns host info, resource usage, nodes, recent events and
+// firing alerts for a single host.
+func (m *Manager) HostOverview(ctx context.Context, id int64) (*HostOverview, error) {
+	h, err := m.GetHost(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get host: %w", err)
+	}
+	dc := m.clientFor(id)
+	o := &HostOverview{
+		Host:      *h,
+		Connected: dc != nil,
+		Nodes:     []HostNodeUsage{},
+		Events:    []Event{},
+		Alerts:    []Alert{},
+	}
+	if cpus, ok := h.Labels["cpus"].(float64); ok {
+		o.Usage.CPUs = int(cpus)
+	}
+	if mem, ok := h.Labels["memory_mb"].(float64); ok {
+		o.Usage.MemoryMB = int64(mem)
+	}
+
+	rows, err := m.pool.Query(ctx, `SELECT `+nodeColumns+` FROM nodes WHERE host_id=$1 ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		o.Nodes = append(o.Nodes, HostNodeUsage{Node: *n})
+		names = append(names, n.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Stats take about a second per container, so sample them in parallel.
+	if dc != nil {
+		statsCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		var wg sync.WaitGroup
+		for i := range o.Nodes {
+			n := &o.Nodes[i]
+			if n.ContainerID == "" || !containerUp(n.Status) {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				u, err := dc.ContainerStats(statsCtx, n.ContainerID)
+				if err != nil {
+					n.UsageError = err.Error()
+					return
+				}
+				n.Usage = u
+			}()
+		}
+		wg.Wait()
+		for _, n := range o.Nodes {
+			if n.Usage != nil {
+				o.Usage.CPUPercent += n.Usage.CPUPercent
+				o.Usage.MemoryUsedMB += n.Usage.MemoryMB
+			}
+		}
+	}
+
+	erows, err := m.pool.Query(ctx, `
+		SELECT id, event_type, severity, target, message, details, created_at
+		FROM events
+		WHERE (event_type LIKE 'host.%' AND target = $1)
+		   OR (event_type LIKE 'node.%' AND target = ANY($2))
+		ORDER BY created_at DESC LIMIT 20`, h.Name, names)
+	if err != nil {
+		return nil, err
+	}
+	defer erows.Close()
+	for erows.Next() {
+		var e Event
+		var details []byte
+		if err := erows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			json.Unmarshal(details, &e.Details)
+		}
+		o.Events = append(o.Events, e)
+	}
+	if err := erows.Err(); err != nil {
+		return nil, err
+	}
+
+	alerts, err := m.FiringAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range alerts {
+		if a.HostID == id {
+			o.Alerts = append(o.Alerts, a)
+		}
+	}
+	return o, nil
+}
@@ -3,6 +3,7 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -12,19 +13,29 @@ import (
 
 // Host represents a host row from the database.
 type Host struct {
-	ID        int64          `json:"id"`
-	Name      string         `json:"name"`
-	SSHAddr   string         `json:"ssh_addr"`
-	Labels    map[string]any `json:"labels"`
-	Status    string         `json:"status"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID           int64          `json:"id"`
+	Name         string         `json:"name"`
+	SSHAddr      string         `json:"ssh_addr"`
+	Address      string         `json:"address,omitempty"` // reachable address for exposed node APIs
+	Labels       map[string]any `json:"labels"`
+	Status       string         `json:"status"`
+	CostPerMonth float64        `json:"cost_per_month"`
+	// DockerAPIVersion is the API version negotiated with the host's daemon;
+	// DockerOutdated flags one older than docker.MinAPIVersion.
+	DockerAPIVersion string            `json:"docker_api_version"`
+	DockerOutdated   bool              `json:"docker_outdated,omitempty"`
+	Utilization      *HostMetrics      `json:"utilization,omitempty"`  // latest sample (ListHosts only)
+	EventStream      *EventStreamState `json:"event_stream,omitempty"` // Docker event stream (ListHosts only, when DOCKER_EVENTS is on)
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 // AddHostRequest holds parameters for adding a remote host.
 type AddHostRequest struct {
-	Name    string `json:"name"`
-	SSHAddr string `json:"ssh_addr"`
+	Name         string  `json:"name"`
+	SSHAddr      string  `json:"ssh_addr"`
+	Address      string  `json:"address"`        // IP or DNS name for node HTTP APIs; default: the SSH host
+	CostPerMonth float64 `json:"cost_per_month"` // used for cost attribution; 0 = unknown
 }
 
 // AddHost validates the SSH connection, gathers host info, and inserts a row.
@@ -35,6 +46,12 @@ func (m *Manager) AddHost(ctx context.Context, req AddHostRequest) (*Host, error
 	if req.SSHAddr == "" {
 		return nil, fmt.Errorf("ssh_addr is required")
 	}
+	if req.CostPerMonth < 0 {
+		return nil, fmt.Errorf("cost_per_month must not be negative")
+	}
+	if err := validateHostAddress(req.Address); err != nil {
+		return nil, err
+	}
 
 	// Check name uniqueness.
 	var exists bool
@@ -45,8 +62,9 @@ func (m *Manager) AddHost(ctx context.Context, req AddHostRequest) (*Host, error
 		return nil, fmt.Errorf("host %q already exists", req.Name)
 	}
 
-	// Connect via SSH.
-	dc, err := docker.NewSSH(req.SSHAddr)
+	// Connect via SSH. Stats are attached to the host once it has an ID.
+	stats := &docker.ConnStats{}
+	dc, err := docker.NewSSH(req.SSHAddr, m.remoteLimits, stats)
 	if err != nil {
 		return nil, fmt.Errorf("ssh connect: %w", err)
 	}
@@ -85,11 +103,11 @@ func (m *Manager) AddHost(ctx context.Context, req AddHostRequest) (*Host, error
 	var host Host
 	var labelsRaw []byte
 	err = m.pool.QueryRow(ctx, `
-		INSERT INTO hosts (name, ssh_addr, status, labels)
-		VALUES ($1, $2, 'online', $3)
-		RETURNING id, name, ssh_addr, labels, status, created_at, updated_at`,
-		req.Name, req.SSHAddr, labelsJSON,
-	).Scan(&host.ID, &host.Name, &host.SSHAddr, &labelsRaw, &host.Status, &host.CreatedAt, &host.UpdatedAt)
+		INSERT INTO hosts (name, ssh_addr, address, status, labels, cost_per_month)
+		VALUES ($1, $2, $3, 'online', $4, $5)
+		RETURNING id, name, ssh_addr, address, labels, status, cost_per_month, created_at, updated_at`,
+		req.Name, req.SSHAddr, req.Address, labelsJSON, req.CostPerMonth,
+	).Scan(&host.ID, &host.Name, &host.SSHAddr, &host.Address, &labelsRaw, &host.Status, &host.CostPerMonth, &host.CreatedAt, &host.UpdatedAt)
 	if err != nil {
 		dc.Close()
 		return nil, fmt.Errorf("insert host: %w", err)
@@ -97,7 +115,14 @@ func (m *Manager) AddHost(ctx context.Context, req AddHostRequest) (*Host, error
 	json.Unmarshal(labelsRaw, &host.Labels)
 
 	// Register the client.
+	stats.RecordConnected(false)
+	m.clientsMu.Lock()
+	m.conns[host.ID] = stats
+	m.clientsMu.Unlock()
 	m.registerClient(host.ID, dc)
+	m.recordAPIVersion(ctx, host.ID, host.Name, dc)
+	host.DockerAPIVersion = dc.APIVersion(ctx)
+	host.DockerOutdated = docker.APIVersionOutdated(host.DockerAPIVersion)
 
 	m.logEvent(ctx, "host.added", host.Name, fmt.Sprintf("Host added: %s (%s)", info.Hostname, req.SSHAddr), labels)
 	slog.Info("host added", "name", host.Name, "ssh", req.SSHAddr, "hostname", info.Hostname)
@@ -128,6 +153,9 @@ func (m *Manager) RemoveHost(ctx context.Context, id int64) error {
 
 	// Close and unregister client.
 	m.unregisterClient(id)
+	m.clientsMu.Lock()
+	delete(m.conns, id)
+	m.clientsMu.Unlock()
 
 	// Delete DB row.
 	_, err := m.pool.Exec(ctx, "DELETE FROM hosts WHERE id=$1", id)
@@ -143,7 +171,7 @@ func (m *Manager) RemoveHost(ctx context.Context, id int64) error {
 // ListHosts returns all hosts with their labels.
 func (m *Manager) ListHosts(ctx context.Context) ([]Host, error) {
 	rows, err := m.pool.Query(ctx, `
-		SELECT id, name, ssh_addr, labels, status, created_at, updated_at
+		SELECT id, name, ssh_addr, address, labels, status, cost_per_month, docker_api_version, created_at, updated_at
 		FROM hosts ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -154,31 +182,52 @@ func (m *Manager) ListHosts(ctx context.Context) ([]Host, error) {
 	for rows.Next() {
 		var h Host
 		var labelsRaw []byte
-		if err := rows.Scan(&h.ID, &h.Name, &h.SSHAddr, &labelsRaw, &h.Status, &h.CreatedAt, &h.UpdatedAt); err != nil {
+		if err := rows.Scan(&h.ID, &h.Name, &h.SSHAddr, &h.Address, &labelsRaw, &h.Status, &h.CostPerMonth, &h.DockerAPIVersion, &h.CreatedAt, &h.UpdatedAt); err != nil {
 			return nil, err
 		}
+		h.DockerOutdated = docker.APIVersionOutdated(h.DockerAPIVersion)
 		if len(labelsRaw) > 0 {
 			json.Unmarshal(labelsRaw, &h.Labels)
 		}
 		hosts = append(hosts, h)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	if hosts == nil {
 		hosts = []Host{}
 	}
-	return hosts, rows.Err()
+	rows.Close()
+
+	metrics, err := m.latestHostMetrics(ctx)
+	if err != nil {
+		slog.Warn("load host metrics", "error", err)
+	}
+	streams := m.eventStreams()
+	for i := range hosts {
+		hosts[i].Utilization = metrics[hosts[i].ID]
+		if s, ok := streams[hosts[i].ID]; ok {
+			hosts[i].EventStream = &s
+		}
+	}
+	return hosts, nil
 }
 
+// ErrHostNotFound is returned by host operations given an unknown host ID.
+var ErrHostNotFound = errors.New("host not found")
+
 // GetHost returns a single host by ID.
 func (m *Manager) GetHost(ctx context.Context, id int64) (*Host, error) {
 	var h Host
 	var labelsRaw []byte
 	err := m.pool.QueryRow(ctx, `
-		SELECT id, name, ssh_addr, labels, status, created_at, updated_at
+		SELECT id, name, ssh_addr, address, labels, status, cost_per_month, docker_api_version, created_at, updated_at
 		FROM hosts WHERE id=$1`, id).
-		Scan(&h.ID, &h.Name, &h.SSHAddr, &labelsRaw, &h.Status, &h.CreatedAt, &h.UpdatedAt)
+		Scan(&h.ID, &h.Name, &h.SSHAddr, &h.Address, &labelsRaw, &h.Status, &h.CostPerMonth, &h.DockerAPIVersion, &h.CreatedAt, &h.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	h.DockerOutdated = docker.APIVersionOutdated(h.DockerAPIVersion)
 	if len(labelsRaw) > 0 {
 		json.Unmarshal(labelsRaw, &h.Labels)
 	}
@@ -212,31 +261,28 @@ func (m *Manager) HostLabelsMap(ctx context.Context) map[int64]string {
 
 // StartHostPoller begins a background loop that pings remote hosts.
 func (m *Manager) StartHostPoller() {
-	m.pollerWg.Add(1)
-	go func() {
-		defer m.pollerWg.Done()
-		ticker := time.NewTicker(m.healthInterval * 2) // host checks at 2x node interval
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-m.stopPoller:
-				return
-			case <-ticker.C:
-				m.pollHosts()
-			}
-		}
-	}()
+	m.startPoller("hosts", m.healthInterval*2, m.pollHosts) // host checks at 2x node interval
 	slog.Info("host poller started")
 }
 
-func (m *Manager) pollHosts() {
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+func (m *Manager) pollHosts() (checked, failed int) {
+	// Pings are spread over the first half of the 2x interval so a large
+	// fleet doesn't open every SSH session at once.
+	window := m.healthInterval
+	ctx, cancel := context.WithTimeout(context.Background(), window+20*time.Second)
 	defer cancel()
 
+	// Utilization covers the local host too; it samples each host at most
+	// every hostMetricsInterval.
+	defer func() {
+		c, f := m.collectHostMetrics()
+		checked += c
+		failed += f
+	}()
+
 	rows, err := m.pool.Query(ctx, "SELECT id, name, ssh_addr, status FROM hosts WHERE ssh_addr != ''")
 	if err != nil {
-		return
+		return 0, 1
 	}
 	defer rows.Close()
 
@@ -256,24 +302,32 @@ func (m *Manager) pollHosts() {
 	}
 	rows.Close()
 
-	for _, h := range hosts {
+	for i, h := range hosts {
+		if i > 0 && !m.sleepOrStop(jitter(window/time.Duration(len(hosts)), 0.5)) {
+			return checked, failed
+		}
+		checked++
 		dc := m.clientFor(h.id)
+		maintenance := h.status == HostMaintenance
 
 		if dc != nil {
 			// Try ping.
 			if err := dc.Ping(ctx); err == nil {
 				// Host is reachable.
-				if h.status != "online" {
+				m.recordAPIVersion(ctx, h.id, h.name, dc)
+				if h.status != "online" && !maintenance {
 					m.pool.Exec(ctx, "UPDATE hosts SET status='online', updated_at=now() WHERE id=$1", h.id)
 					m.logEvent(ctx, "host.online", h.name, "Host reconnected", nil)
 					slog.Info("host reconnected", "host", h.name)
+					go m.runQueuedOps(h.id)
 				}
 				continue
 			}
 		}
 
-		// Unreachable — attempt reconnect.
-		if h.status != "unreachable" {
+		// Unreachable — attempt reconnect. Hosts in maintenance are expected
+		// to go away (reboots), so they keep their status.
+		if h.status != "unreachable" && !maintenance {
 			m.pool.Exec(ctx, "UPDATE hosts SET status='unreachable', updated_at=now() WHERE id=$1", h.id)
 			m.logEvent(ctx, "host.unreachable", h.name, "Host unreachable", nil)
 			slog.Warn("host unreachable", "host", h.name)
@@ -281,18 +335,30 @@ func (m *Manager) pollHosts() {
 
 		// Try to reconnect.
 		m.unregisterClient(h.id)
-		newDC, err := docker.NewSSH(h.sshAddr)
+		stats := m.connStats(h.id)
+		newDC, err := docker.NewSSH(h.sshAddr, m.remoteLimits, stats)
 		if err != nil {
+			stats.RecordError(err)
+			failed++
 			continue
 		}
 		if err := newDC.Ping(ctx); err != nil {
 			newDC.Close()
+			failed++
 			continue
 		}
 
 		m.registerClient(h.id, newDC)
+		stats.RecordConnected(true)
+		m.recordAPIVersion(ctx, h.id, h.name, newDC)
+		if maintenance {
+			// Queued operations wait for ResumeHost.
+			continue
+		}
 		m.pool.Exec(ctx, "UPDATE hosts SET status='online', updated_at=now() WHERE id=$1", h.id)
 		m.logEvent(ctx, "host.online", h.name, "Host reconnected", nil)
 		slog.Info("host reconnected", "host", h.name)
+		go m.runQueuedOps(h.id)
 	}
+	return checked, failed
 }
@@ -0,0 +1,226 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Duplicate NodeID policies.
+const (
+	IdentityPolicyWarn   = "warn"   // log an error and a node.duplicate_identity event
+	IdentityPolicyReject = "reject" // also refuse to start, and stop, the later holder
+)
+
+// IdentityHolder is a node that runs with a given NodeID.
+type IdentityHolder struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	HostID int64  `json:"host_id"`
+	Status string `json:"status"`
+}
+
+// NodeIdentity is one NodeID and the managed nodes holding it. More than one
+// holder means the same staking key is deployed twice, which costs both
+// nodes uptime on the network.
+type NodeIdentity struct {
+	NodeID    string           `json:"node_id"`
+	Duplicate bool             `json:"duplicate"`
+	Nodes     []IdentityHolder `json:"nodes"`
+}
+
+// DuplicateIdentityError is returned when starting a node would put a second
+// container on the network with an already running NodeID.
+type DuplicateIdentityError struct {
+	Node     string
+	Identity NodeIdentity
+}
+
+func (e *DuplicateIdentityError) Error() string {
+	var others []string
+	for _, h := range e.Identity.Nodes {
+		if h.Name != e.Node {
+			others = append(others, h.Name)
+		}
+	}
+	return fmt.Sprintf("node %q has NodeID %s, already running on %s", e.Node, e.Identity.NodeID, strings.Join(others, ", "))
+}
+
+// SetIdentityPolicy sets what happens when two nodes share a NodeID. Call
+// it before Start.
+func (m *Manager) SetIdentityPolicy(policy string) error {
+	switch policy {
+	case IdentityPolicyWarn:
+		m.rejectDupIDs = false
+	case IdentityPolicyReject:
+		m.rejectDupIDs = true
+	default:
+		return fmt.Errorf("invalid duplicate NodeID policy %q (want warn or reject)", policy)
+	}
+	return nil
+}
+
+// IdentityPolicy returns the current duplicate NodeID policy.
+func (m *Manager) IdentityPolicy() string {
+	if m.rejectDupIDs {
+		return IdentityPolicyReject
+	}
+	return IdentityPolicyWarn
+}
+
+// NodeIdentities lists every known NodeID with its holders, duplicates
+// first. With duplicatesOnly, NodeIDs held by a single node are left out.
+func (m *Manager) NodeIdentities(ctx context.Context, duplicatesOnly bool) ([]NodeIdentity, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT node_id, id, name, host_id, status FROM nodes
+		WHERE node_id != ''
+		ORDER BY (count(*) OVER (PARTITION BY node_id)) DESC, node_id, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []NodeIdentity{}
+	for rows.Next() {
+		var nodeID string
+		var h IdentityHolder
+		if err := rows.Scan(&nodeID, &h.ID, &h.Name, &h.HostID, &h.Status); err != nil {
+			return nil, err
+		}
+		if n := len(out); n > 0 && out[n-1].NodeID == nodeID {
+			out[n-1].Nodes = append(out[n-1].Nodes, h)
+			out[n-1].Duplicate = true
+			continue
+		}
+		out = append(out, NodeIdentity{NodeID: nodeID, Nodes: []IdentityHolder{h}})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if duplicatesOnly {
+		dups := []NodeIdentity{}
+		for _, id := range out {
+			if id.Duplicate {
+				dups = append(dups, id)
+			}
+		}
+		out = dups
+	}
+	return out, nil
+}
+
+// nodeIdentity returns the holders of one NodeID.
+func (m *Manager) nodeIdentity(ctx context.Context, nodeID string) (NodeIdentity, error) {
+	id := NodeIdentity{NodeID: nodeID, Nodes: []IdentityHolder{}}
+	rows, err := m.pool.Query(ctx,
+		"SELECT id, name, host_id, status FROM nodes WHERE node_id=$1 ORDER BY id", nodeID)
+	if err != nil {
+		return id, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var h IdentityHolder
+		if err := rows.Scan(&h.ID, &h.Name, &h.HostID, &h.Status); err != nil {
+			return id, err
+		}
+		id.Nodes = append(id.Nodes, h)
+	}
+	id.Duplicate = len(id.Nodes) > 1
+	return id, rows.Err()
+}
+
+// checkIdentityFree returns a DuplicateIdentityError in reject mode when
+// another node with the same NodeID is running.
+func (m *Manager) checkIdentityFree(ctx context.Context, node Node) error {
+	if node.NodeID == "" || !m.rejectDupIDs {
+		return nil
+	}
+	id, err := m.nodeIdentity(ctx, node.NodeID)
+	if err != nil {
+		return fmt.Errorf("check node identity: %w", err)
+	}
+	for _, h := range id.Nodes {
+		if h.ID != node.ID && h.Status != "stopped" {
+			return &DuplicateIdentityError{Node: node.Name, Identity: id}
+		}
+	}
+	return nil
+}
+
+// checkNewIdentity is called when a node's NodeID is first discovered. If
+// another node already holds it, the conflict is logged loudly and, in
+// reject mode, the newly identified node is stopped.
+func (m *Manager) checkNewIdentity(ctx context.Context, node Node) {
+	id, err := m.nodeIdentity(ctx, node.NodeID)
+	if err != nil || !id.Duplicate {
+		return
+	}
+	m.logIdentityConflict(ctx, id)
+	if !m.rejectDupIDs {
+		return
+	}
+	if err := m.StopNode(ctx, node.ID); err != nil {
+		slog.Error("stop duplicate identity", "error", err, "node", node.Name)
+		return
+	}
+	m.logEvent(ctx, "node.duplicate_identity_stopped", node.Name,
+		"Stopped: NodeID "+node.NodeID+" is already in use", map[string]any{"node_id": node.NodeID})
+}
+
+// IdentifyNode asks a node for its NodeID now instead of at its next
+// healthy check, so a node created moments ago can be wired into an L1
+// conversion at once. A NodeID that changed since (new staking keys) is
+// replaced. It returns the updated node.
+func (m *Manager) IdentifyNode(ctx context.Context, id int64) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !containerUp(node.Status) {
+		return nil, fmt.Errorf("node %q is %s; only running nodes can be identified", node.Name, node.Status)
+	}
+	nodeID, err := m.queryNodeID(ctx, *node)
+	if err != nil {
+		if m.hostOffline(ctx, node.HostID) {
+			return nil, ErrHostOffline
+		}
+		return nil, fmt.Errorf("query node ID: %w", err)
+	}
+	if nodeID == "" {
+		return nil, fmt.Errorf("node %q reported no NodeID", node.Name)
+	}
+	if nodeID != node.NodeID {
+		if err := m.storeNodeID(ctx, *node, nodeID); err != nil {
+			return nil, fmt.Errorf("store node ID: %w", err)
+		}
+	}
+	return m.GetNode(ctx, id)
+}
+
+// reportIdentityConflicts logs every NodeID held by more than one node.
+func (m *Manager) reportIdentityConflicts(ctx context.Context) {
+	dups, err := m.NodeIdentities(ctx, true)
+	if err != nil {
+		slog.Warn("check node identities", "error", err)
+		return
+	}
+	for _, id := range dups {
+		m.logIdentityConflict(ctx, id)
+	}
+}
+
+func (m *Manager) logIdentityConflict(ctx context.Context, id NodeIdentity) {
+	names := make([]string, len(id.Nodes))
+	ids := make([]int64, len(id.Nodes))
+	for i, h := range id.Nodes {
+		names[i] = h.Name
+		ids[i] = h.ID
+	}
+	slog.Error("DUPLICATE NODE ID: the same staking identity is deployed on several nodes",
+		"node_id", id.NodeID, "nodes", names)
+	m.logEvent(ctx, "node.duplicate_identity", strings.Join(names, ","),
+		fmt.Sprintf("NodeID %s is used by %d nodes (%s); duplicates cost validator uptime",
+			id.NodeID, len(id.Nodes), strings.Join(names, ", ")),
+		map[string]any{"node_id": id.NodeID, "nodes": ids})
+}
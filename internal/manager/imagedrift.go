@@ -0,0 +1,260 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// Image drift reasons.
+const (
+	DriftRegistry = "registry" // the tag points at a newer manifest upstream
+	DriftLocal    = "local"    // the tag was re-pulled on the host since the container was created
+)
+
+// ImageDrift compares the image a node runs with what its tag points at now.
+type ImageDrift struct {
+	NodeID        int64  `json:"node_id"`
+	Name          string `json:"name"`
+	HostID        int64  `json:"host_id"`
+	Image         string `json:"image"`
+	RunningDigest string `json:"running_digest"`
+	LatestDigest  string `json:"latest_digest,omitempty"` // what the tag resolves to now
+	Pinned        bool   `json:"pinned,omitempty"`        // image is referenced by digest, so it can't drift
+	Drifted       bool   `json:"drifted"`
+	Reason        string `json:"reason,omitempty"` // registry or local
+	Error         string `json:"error,omitempty"`
+}
+
+// imageDrift remembers which nodes were last seen drifting, and to which
+// digest, so each new upstream digest is reported once.
+type imageDrift struct {
+	mu     sync.Mutex
+	latest map[int64]string // nodeID -> LatestDigest when drift was last logged
+}
+
+// StartImageDriftPoller periodically checks every running node for drift,
+// logging node.image_drift when a node's tag has moved on.
+func (m *Manager) StartImageDriftPoller(interval time.Duration) {
+	m.startPoller("image_drift", interval, m.pollImageDrift)
+}
+
+func (m *Manager) pollImageDrift() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := m.CheckImageDrift(ctx)
+	if err != nil {
+		return 0, 1
+	}
+	m.imageDrift.mu.Lock()
+	if m.imageDrift.latest == nil {
+		m.imageDrift.latest = map[int64]string{}
+	}
+	var newly []ImageDrift
+	seen := map[int64]bool{}
+	for _, d := range report {
+		checked++
+		if d.Error != "" && !d.Drifted {
+			failed++
+		}
+		if !d.Drifted {
+			continue
+		}
+		seen[d.NodeID] = true
+		if m.imageDrift.latest[d.NodeID] != d.LatestDigest {
+			m.imageDrift.latest[d.NodeID] = d.LatestDigest
+			newly = append(newly, d)
+		}
+	}
+	for id := range m.imageDrift.latest {
+		if !seen[id] {
+			delete(m.imageDrift.latest, id)
+		}
+	}
+	m.imageDrift.mu.Unlock()
+
+	for _, d := range newly {
+		m.logEvent(ctx, "node.image_drift", d.Name,
+			fmt.Sprintf("%s now resolves to %s; node runs %s", d.Image, shortDigest(d.LatestDigest), shortDigest(d.RunningDigest)),
+			map[string]any{"image": d.Image, "running_digest": d.RunningDigest, "latest_digest": d.LatestDigest, "reason": d.Reason})
+	}
+	return checked, failed
+}
+
+// DriftedDigest returns the digest a node's image tag moved to, as of the
+// last drift poll, or "" when the node was up to date.
+func (m *Manager) DriftedDigest(nodeID int64) string {
+	m.imageDrift.mu.Lock()
+	defer m.imageDrift.mu.Unlock()
+	return m.imageDrift.latest[nodeID]
+}
+
+// CheckImageDrift records the digest every running node's container runs
+// and compares it with what the node's image tag resolves to now. The
+// registry is asked first (once per image); when it can't be reached the
+// host's local tag is compared instead, which catches a tag re-pulled on
+// the host but not an upstream move.
+func (m *Manager) CheckImageDrift(ctx context.Context) ([]ImageDrift, error) {
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	type lookup struct {
+		digest string
+		err    error
+	}
+	registry := map[string]lookup{}
+
+	out := []ImageDrift{}
+	for _, n := range nodes {
+		if n.ContainerID == "" || !containerUp(n.Status) {
+			continue
+		}
+		d := ImageDrift{NodeID: n.ID, Name: n.Name, HostID: n.HostID, Image: n.Image, RunningDigest: n.ImageDigest}
+		dc := m.clientFor(n.HostID)
+		if dc == nil {
+			d.Error = "host not connected"
+			out = append(out, d)
+			continue
+		}
+		imageID, digests, err := dc.ContainerImage(ctx, n.ContainerID)
+		if err != nil {
+			d.Error = err.Error()
+			out = append(out, d)
+			continue
+		}
+		d.RunningDigest = runningDigest(n.Image, imageID, digests)
+		if d.RunningDigest != n.ImageDigest {
+			m.pool.Exec(ctx, "UPDATE nodes SET image_digest=$1 WHERE id=$2", d.RunningDigest, n.ID)
+		}
+
+		if _, pinned, ok := strings.Cut(n.Image, "@"); ok {
+			d.Pinned, d.LatestDigest = true, pinned
+			out = append(out, d)
+			continue
+		}
+		l, ok := registry[n.Image]
+		if !ok {
+			l.digest, l.err = dc.RegistryDigest(ctx, n.Image)
+			registry[n.Image] = l
+		}
+		if l.err == nil {
+			d.LatestDigest = l.digest
+			d.Drifted = !hasDigest(digests, l.digest)
+			if d.Drifted {
+				d.Reason = DriftRegistry
+			}
+			out = append(out, d)
+			continue
+		}
+		d.Error = l.err.Error()
+		localID, err := dc.ImageID(ctx, n.Image)
+		if err == nil && localID != "" && localID != imageID {
+			d.LatestDigest, d.Drifted, d.Reason = localID, true, DriftLocal
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// RepullNode pulls a node's image tag again and, if it now points at a
+// different image than the container runs, recreates the node on it. The
+// recreate runs in the background like a reconfigure.
+func (m *Manager) RepullNode(ctx context.Context, id int64) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if !containerUp(node.Status) {
+		return nil, fmt.Errorf("node %q is %s; only running nodes can be recreated on a new digest", node.Name, node.Status)
+	}
+	if strings.Contains(node.Image, "@") {
+		return nil, fmt.Errorf("node %q is pinned to %s; start an upgrade to change it", node.Name, node.Image)
+	}
+	if err := m.checkImageAllowed(node.Image); err != nil {
+		return nil, err
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host not connected")
+	}
+	if err := dc.Pull(ctx, node.Image); err != nil {
+		return nil, err
+	}
+	runningID, _, err := dc.ContainerImage(ctx, node.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+	latestID, err := dc.ImageID(ctx, node.Image)
+	if err != nil {
+		return nil, err
+	}
+	if latestID == runningID {
+		return nil, fmt.Errorf("node %q already runs the latest %s", node.Name, node.Image)
+	}
+
+	if _, err := m.pool.Exec(ctx, "UPDATE nodes SET status='creating', updated_at=now() WHERE id=$1", id); err != nil {
+		return nil, fmt.Errorf("update status: %w", err)
+	}
+	m.logEvent(ctx, "node.repulled", node.Name,
+		fmt.Sprintf("Recreating on the current %s", node.Image),
+		map[string]any{"image": node.Image, "from_digest": node.ImageDigest})
+	m.requestReconfigure(id)
+	m.imageDrift.mu.Lock()
+	delete(m.imageDrift.latest, id)
+	m.imageDrift.mu.Unlock()
+	node.Status = "creating"
+	return node, nil
+}
+
+// recordImageDigest stores the digest a freshly created container runs.
+// It is best-effort: the drift poller fills in anything missed.
+func (m *Manager) recordImageDigest(ctx context.Context, dc *docker.Client, nodeID int64, image, containerID string) {
+	imageID, digests, err := dc.ContainerImage(ctx, containerID)
+	if err != nil {
+		slog.Warn("record image digest", "error", err, "node_id", nodeID)
+		return
+	}
+	m.pool.Exec(ctx, "UPDATE nodes SET image_digest=$1 WHERE id=$2", runningDigest(image, imageID, digests), nodeID)
+}
+
+// runningDigest picks the manifest digest of the image's repository from
+// its repo digests, falling back to any repo digest, then the image ID.
+func runningDigest(image, imageID string, repoDigests []string) string {
+	repo := imageRepository(image)
+	for _, rd := range repoDigests {
+		if r, digest, ok := strings.Cut(rd, "@"); ok && (r == repo || strings.HasSuffix(r, "/"+repo)) {
+			return digest
+		}
+	}
+	for _, rd := range repoDigests {
+		if _, digest, ok := strings.Cut(rd, "@"); ok {
+			return digest
+		}
+	}
+	return imageID
+}
+
+// hasDigest reports whether any repo digest carries digest.
+func hasDigest(repoDigests []string, digest string) bool {
+	for _, rd := range repoDigests {
+		if strings.HasSuffix(rd, "@"+digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortDigest abbreviates sha256:... for messages.
+func shortDigest(d string) string {
+	if _, hex, ok := strings.Cut(d, ":"); ok && len(hex) > 12 {
+		return hex[:12]
+	}
+	return d
+}
@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ImagePolicy restricts which container images nodes may run. An empty
+// policy allows every image.
+type ImagePolicy struct {
+	allowed  []string         // explicit image refs or repositories
+	patterns []*regexp.Regexp // full-reference regexes
+}
+
+// NewImagePolicy builds a policy from explicit entries and regex patterns.
+// An explicit entry without a tag (e.g. "avaplatform/avalanchego") allows any
+// tag or digest of that repository; with a tag it must match exactly.
+func NewImagePolicy(allowed, patterns []string) (*ImagePolicy, error) {
+	p := &ImagePolicy{}
+	for _, a := range allowed {
+		if a = strings.TrimSpace(a); a != "" {
+			p.allowed = append(p.allowed, a)
+		}
+	}
+	for _, expr := range patterns {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("image pattern %q: %w", expr, err)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p *ImagePolicy) Enabled() bool {
+	return p != nil && (len(p.allowed) > 0 || len(p.patterns) > 0)
+}
+
+// Allows reports whether ref is permitted by the policy.
+func (p *ImagePolicy) Allows(ref string) bool {
+	if !p.Enabled() {
+		return true
+	}
+	repo := imageRepository(ref)
+	for _, a := range p.allowed {
+		if a == ref || a == repo {
+			return true
+		}
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImageAllowed returns an error if the image policy forbids ref.
+func (m *Manager) checkImageAllowed(ref string) error {
+	if !m.imagePolicy.Allows(ref) {
+		return fmt.Errorf("image %q is not allowed by the image policy", ref)
+	}
+	return nil
+}
+
+// imageRepository strips the tag and digest from an image reference.
+func imageRepository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	// A colon after the last slash is a tag separator, not a registry port.
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
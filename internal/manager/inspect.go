@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// redacted replaces sensitive values in inspect output.
+const redacted = "<redacted>"
+
+// sensitiveWords mark env vars and labels whose values are redacted.
+var sensitiveWords = []string{"KEY", "SECRET", "PASSWORD", "TOKEN", "AUTH", "CREDENTIAL"}
+
+// NodeInspect returns the node's raw Docker inspect output with secrets in
+// env vars and labels (e.g. the Traefik basicauth hash) redacted. Env vars
+// and cmd arguments filled from managed secrets are redacted too.
+func (m *Manager) NodeInspect(ctx context.Context, id int64) (*container.InspectResponse, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.ContainerID == "" {
+		return nil, fmt.Errorf("node %q has no container", node.Name)
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", node.HostID)
+	}
+	info, err := dc.ContainerInspect(ctx, node.ContainerID)
+	if err != nil {
+		return nil, fmt.Errorf("inspect container: %w", err)
+	}
+
+	if info.Config != nil {
+		for i, kv := range info.Config.Env {
+			if k, _, ok := strings.Cut(kv, "="); ok && (isSensitive(k) || secretRef.MatchString(node.Env[k])) {
+				info.Config.Env[i] = k + "=" + redacted
+			}
+		}
+		for i, arg := range node.Cmd {
+			if secretRef.MatchString(arg) && i < len(info.Config.Cmd) {
+				info.Config.Cmd[i] = redacted
+			}
+		}
+		for k := range info.Config.Labels {
+			if isSensitive(k) || strings.Contains(k, "basicauth") {
+				info.Config.Labels[k] = redacted
+			}
+		}
+	}
+	if info.ContainerJSONBase != nil {
+		// The process command line is Path followed by Args and ends
+		// with Cmd, so Cmd's secret positions are counted from the end.
+		argv := append([]string{info.Path}, info.Args...)
+		for i, arg := range node.Cmd {
+			if j := len(argv) - len(node.Cmd) + i; j >= 0 && secretRef.MatchString(arg) {
+				argv[j] = redacted
+			}
+		}
+		info.Path, info.Args = argv[0], argv[1:]
+	}
+	return &info, nil
+}
+
+// isSensitive reports whether a variable or label name suggests a secret.
+func isSensitive(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, w := range sensitiveWords {
+		if strings.Contains(upper, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package manager
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/primal-host/avalauncher/internal/pchain"
+)
+
+// defaultValidatorBalance is the nAVAX prepaid per initial validator (1 AVAX).
+const defaultValidatorBalance = 1_000_000_000
+
+// ConvertL1Request holds parameters for converting an L1's subnet into a
+// sovereign L1.
+type ConvertL1Request struct {
+	NodeID         int64  `json:"node_id"`         // node whose P-Chain API issues the transaction
+	PrivateKey     string `json:"private_key"`     // subnet owner paying the fee; default PCHAIN_PRIVATE_KEY
+	ManagerAddress string `json:"manager_address"` // hex address of the validator manager on the L1's chain
+	Balance        uint64 `json:"balance"`         // nAVAX prepaid per validator; default 1 AVAX
+}
+
+// conversion is a validated ConvertL1Request.
+type conversion struct {
+	l1ID       int64
+	l1Name     string
+	subnetID   pchain.ID
+	chainID    pchain.ID
+	address    []byte
+	node       Node
+	key        *pchain.PrivateKey
+	rows       []int64 // l1_validators IDs, parallel to validators
+	validators []pchain.L1Validator
+}
+
+// ConvertL1 issues a ConvertSubnetToL1Tx making the L1's assigned
+// validators its initial validator set. Each validator's NodeID, BLS public
+// key and proof of possession come from its node's info.getNodeID. The
+// transaction is issued in the background; the L1 is "converting" until it
+// commits, then "active" with the tx ID and validation IDs recorded on its
+// validators.
+func (m *Manager) ConvertL1(ctx context.Context, id int64, req ConvertL1Request) (*L1Detail, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	if l1.SubnetID == "" || l1.BlockchainID == "" {
+		return nil, fmt.Errorf("L1 %q needs a subnet_id and blockchain_id before conversion", l1.Name)
+	}
+	if l1.Status != "configured" {
+		return nil, fmt.Errorf("L1 %q is %s, not configured", l1.Name, l1.Status)
+	}
+	if len(l1.Validators) == 0 {
+		return nil, fmt.Errorf("L1 %q has no validators", l1.Name)
+	}
+	if len(l1.Pending) > 0 {
+		return nil, fmt.Errorf("L1 %q has %d queued validator(s); wait for them or remove them", l1.Name, len(l1.Pending))
+	}
+
+	c := &conversion{l1ID: id, l1Name: l1.Name, key: m.defaultPChainKey()}
+	if req.PrivateKey != "" {
+		if c.key, err = pchain.ParseKey(req.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+	if c.key == nil {
+		return nil, fmt.Errorf("private_key is required (PCHAIN_PRIVATE_KEY is not set)")
+	}
+	if c.subnetID, err = pchain.ParseID(l1.SubnetID); err != nil {
+		return nil, fmt.Errorf("invalid subnet_id: %w", err)
+	}
+	if c.chainID, err = pchain.ParseID(l1.BlockchainID); err != nil {
+		return nil, fmt.Errorf("invalid blockchain_id: %w", err)
+	}
+	if c.address, err = hex.DecodeString(strings.TrimPrefix(req.ManagerAddress, "0x")); err != nil {
+		return nil, fmt.Errorf("invalid manager_address: %w", err)
+	}
+	if len(c.address) > 4096 {
+		return nil, fmt.Errorf("manager_address exceeds 4096 bytes")
+	}
+	balance := req.Balance
+	if balance == 0 {
+		balance = defaultValidatorBalance
+	}
+
+	if req.NodeID == 0 {
+		return nil, fmt.Errorf("node_id is required")
+	}
+	node, err := m.GetNode(ctx, req.NodeID)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if ready, reason := m.nodeReady(ctx, *node); !ready {
+		return nil, fmt.Errorf("node %q cannot issue transactions: %s", node.Name, reason)
+	}
+	c.node = *node
+
+	for _, v := range l1.Validators {
+		vn, err := m.GetNode(ctx, v.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("validator node %d not found", v.NodeID)
+		}
+		pv, err := m.validatorSigner(ctx, *vn)
+		if err != nil {
+			return nil, fmt.Errorf("validator %q: %w", vn.Name, err)
+		}
+		pv.Weight = uint64(v.Weight)
+		pv.Balance = balance
+		c.rows = append(c.rows, v.ID)
+		c.validators = append(c.validators, pv)
+	}
+
+	tag, err := m.pool.Exec(ctx, "UPDATE l1s SET status='converting', updated_at=now() WHERE id=$1 AND status='configured'", id)
+	if err != nil {
+		return nil, fmt.Errorf("update L1: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("L1 %q is already being converted", l1.Name)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deployTimeout)
+		defer cancel()
+		if err := m.runConversion(ctx, c); err != nil {
+			slog.Error("convert L1", "error", err, "l1", c.l1Name)
+			m.pool.Exec(ctx, "UPDATE l1s SET status='configured', updated_at=now() WHERE id=$1", c.l1ID)
+			m.logEvent(ctx, "l1.convert_failed", c.l1Name, fmt.Sprintf("Conversion failed: %v", err), nil)
+		}
+	}()
+
+	l1.Status = "converting"
+	return l1, nil
+}
+
+// validatorSigner reads a node's NodeID and BLS proof of possession.
+func (m *Manager) validatorSigner(ctx context.Context, node Node) (pchain.L1Validator, error) {
+	var v pchain.L1Validator
+	var res struct {
+		NodeID  string `json:"nodeID"`
+		NodePOP *struct {
+			PublicKey         string `json:"publicKey"`
+			ProofOfPossession string `json:"proofOfPossession"`
+		} `json:"nodePOP"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/info", "info.getNodeID", nil, &res); err != nil {
+		return v, err
+	}
+	if res.NodePOP == nil {
+		return v, fmt.Errorf("node reports no BLS key")
+	}
+	var err error
+	if v.NodeID, err = pchain.ParseNodeID(res.NodeID); err != nil {
+		return v, err
+	}
+	v.PublicKey, v.ProofOfPossession, err = pchain.ParseProofOfPossession(res.NodePOP.PublicKey, res.NodePOP.ProofOfPossession)
+	return v, err
+}
+
+// runConversion issues the ConvertSubnetToL1Tx and records its result.
+func (m *Manager) runConversion(ctx context.Context, c *conversion) error {
+	rpc := func(ctx context.Context, endpoint, method string, params, out any) error {
+		return m.callNodeRPC(ctx, c.node, endpoint, method, params, out)
+	}
+	w, err := pchain.NewWallet(ctx, c.key, rpc)
+	if err != nil {
+		return err
+	}
+	m.logEvent(ctx, "l1.converting", c.l1Name,
+		fmt.Sprintf("Converting to L1 with %d validator(s) via node %s, paid by %s", len(c.validators), c.node.Name, w.Address()), nil)
+
+	txID, validationIDs, err := w.ConvertSubnetToL1(ctx, c.subnetID, c.chainID, c.address, c.validators)
+	if err != nil {
+		return err
+	}
+
+	for i, rowID := range c.rows {
+		if _, err := m.pool.Exec(ctx, "UPDATE l1_validators SET tx_id=$1, validation_id=$2 WHERE id=$3",
+			txID.String(), validationIDs[i].String(), rowID); err != nil {
+			slog.Error("convert L1: record validation id", "error", err, "l1", c.l1Name)
+		}
+	}
+	m.pool.Exec(ctx, "UPDATE l1s SET status='active', updated_at=now() WHERE id=$1", c.l1ID)
+	m.logEvent(ctx, "l1.converted", c.l1Name, fmt.Sprintf("Converted to L1 (tx %s)", txID), nil)
+	return nil
+}
+
+// recoverConversions returns conversions interrupted by a restart to
+// configured. If the transaction had already committed, the P-Chain refuses
+// a retry; platform.getSubnet shows whether the subnet was converted.
+func (m *Manager) recoverConversions(ctx context.Context) {
+	rows, err := m.pool.Query(ctx, `
+		UPDATE l1s SET status='configured', updated_at=now() WHERE status='converting' RETURNING name`)
+	if err != nil {
+		slog.Warn("recover conversions", "error", err)
+		return
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+	for _, name := range names {
+		m.logEvent(ctx, "l1.convert_failed", name, "Conversion interrupted by restart", nil)
+	}
+}
@@ -0,0 +1,257 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/primal-host/avalauncher/internal/pchain"
+)
+
+// subnetEVMVMID is subnet-evm's VM ID, used for L1s whose VM is "subnet-evm".
+const subnetEVMVMID = "srEXiWaHuhNyGwPUi444Tu47ZEDwxTWrbQiuD7FmgSAQ6X7Dy"
+
+// deployTimeout bounds an on-chain deployment, including waiting for commits.
+const deployTimeout = 10 * time.Minute
+
+// DeployL1Request holds parameters for creating an L1's subnet and chain on
+// the P-Chain.
+type DeployL1Request struct {
+	NodeID     int64           `json:"node_id"`     // node whose P-Chain API issues the transactions
+	PrivateKey string          `json:"private_key"` // pays the fees and owns the subnet; default PCHAIN_PRIVATE_KEY
+	Genesis    json.RawMessage `json:"genesis"`     // chain genesis; default: the one set via SetL1Genesis
+	ChainName  string          `json:"chain_name"`  // default: the L1 name without punctuation
+	VMID       string          `json:"vm_id"`       // default: subnet-evm's VM ID
+}
+
+// deployment is a validated DeployL1Request.
+type deployment struct {
+	l1ID      int64
+	l1Name    string
+	subnetID  string // existing subnet to add the chain to; empty = create one
+	node      Node
+	key       *pchain.PrivateKey
+	genesis   []byte
+	chainName string
+	vmID      pchain.ID
+}
+
+// SetPChainKey sets the default key that pays for on-chain deployments.
+// Call it before Start, which resumes interrupted deployments.
+func (m *Manager) SetPChainKey(key string) error {
+	k, err := pchain.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	m.pchainKey = k
+	return nil
+}
+
+// defaultPChainKey returns the key set by SetPChainKey, or nil.
+func (m *Manager) defaultPChainKey() *pchain.PrivateKey {
+	return m.pchainKey
+}
+
+// DeployL1 creates the L1's subnet (unless it already has one) and its chain
+// on the P-Chain. The transactions are issued in the background; the L1 is
+// "deploying" until they commit, then "configured" (or "failed").
+func (m *Manager) DeployL1(ctx context.Context, id int64, req DeployL1Request) (*L1Detail, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	if l1.BlockchainID != "" {
+		return nil, fmt.Errorf("L1 %q already has a blockchain", l1.Name)
+	}
+	if l1.Status == "deploying" {
+		return nil, fmt.Errorf("L1 %q is already being deployed", l1.Name)
+	}
+
+	if len(req.Genesis) == 0 {
+		req.Genesis = l1.Genesis
+	}
+	d, err := m.prepareDeploy(ctx, l1.Name, l1.VM, l1.SubnetID, req)
+	if err != nil {
+		return nil, err
+	}
+	d.l1ID = id
+
+	tag, err := m.pool.Exec(ctx, "UPDATE l1s SET status='deploying', updated_at=now() WHERE id=$1 AND status != 'deploying'", id)
+	if err != nil {
+		return nil, fmt.Errorf("update L1: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("L1 %q is already being deployed", l1.Name)
+	}
+	m.startDeploy(d)
+
+	l1.Status = "deploying"
+	return l1, nil
+}
+
+// prepareDeploy validates a deployment request for an L1.
+func (m *Manager) prepareDeploy(ctx context.Context, name, vm, subnetID string, req DeployL1Request) (*deployment, error) {
+	d := &deployment{l1Name: name, subnetID: subnetID, key: m.defaultPChainKey()}
+
+	if req.PrivateKey != "" {
+		k, err := pchain.ParseKey(req.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		d.key = k
+	}
+	if d.key == nil {
+		return nil, fmt.Errorf("private_key is required (PCHAIN_PRIVATE_KEY is not set)")
+	}
+
+	if len(req.Genesis) == 0 || !json.Valid(req.Genesis) {
+		return nil, fmt.Errorf("genesis must be a JSON document")
+	}
+	if len(req.Genesis) > 1<<20 {
+		return nil, fmt.Errorf("genesis exceeds 1 MiB")
+	}
+	d.genesis = req.Genesis
+
+	vmID := req.VMID
+	if vmID == "" {
+		if vm != "subnet-evm" {
+			return nil, fmt.Errorf("vm_id is required for VM %q", vm)
+		}
+		vmID = subnetEVMVMID
+	}
+	id, err := pchain.ParseID(vmID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vm_id: %w", err)
+	}
+	d.vmID = id
+
+	d.chainName = req.ChainName
+	if d.chainName == "" {
+		d.chainName = strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return r
+			}
+			return -1
+		}, name)
+	}
+	if d.chainName == "" || len(d.chainName) > 128 {
+		return nil, fmt.Errorf("chain_name must be 1-128 characters")
+	}
+	for _, r := range d.chainName {
+		if r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ') {
+			return nil, fmt.Errorf("chain_name may only contain letters, digits and spaces")
+		}
+	}
+
+	if req.NodeID == 0 {
+		return nil, fmt.Errorf("node_id is required")
+	}
+	node, err := m.GetNode(ctx, req.NodeID)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if ready, reason := m.nodeReady(ctx, *node); !ready {
+		return nil, fmt.Errorf("node %q cannot issue transactions: %s", node.Name, reason)
+	}
+	d.node = *node
+	return d, nil
+}
+
+// startDeploy runs a deployment in the background.
+func (m *Manager) startDeploy(d *deployment) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deployTimeout)
+		defer cancel()
+		if err := m.runDeploy(ctx, d); err != nil {
+			slog.Error("deploy L1", "error", err, "l1", d.l1Name)
+			// ctx may be what ran out; the failure must still be recorded.
+			fctx, fcancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer fcancel()
+			m.pool.Exec(fctx, "UPDATE l1s SET status='failed', updated_at=now() WHERE id=$1", d.l1ID)
+			m.logEvent(fctx, "l1.deploy_failed", d.l1Name, fmt.Sprintf("Deployment failed: %v", err), nil)
+		}
+	}()
+}
+
+// runDeploy issues the CreateSubnetTx and CreateChainTx and records the
+// resulting IDs as each commits.
+func (m *Manager) runDeploy(ctx context.Context, d *deployment) error {
+	rpc := func(ctx context.Context, endpoint, method string, params, out any) error {
+		return m.callNodeRPC(ctx, d.node, endpoint, method, params, out)
+	}
+	w, err := pchain.NewWallet(ctx, d.key, rpc)
+	if err != nil {
+		return err
+	}
+	m.logEvent(ctx, "l1.deploying", d.l1Name,
+		fmt.Sprintf("Deploying via node %s, paid by %s", d.node.Name, w.Address()), nil)
+
+	var subnetID pchain.ID
+	if d.subnetID != "" {
+		if subnetID, err = pchain.ParseID(d.subnetID); err != nil {
+			return fmt.Errorf("invalid subnet_id: %w", err)
+		}
+	} else {
+		subnetID, err = w.CreateSubnet(ctx)
+		if err != nil {
+			return fmt.Errorf("create subnet: %w", err)
+		}
+		if _, err := m.pool.Exec(ctx, `
+			UPDATE l1s SET subnet_id=$1, create_subnet_tx_id=$1, updated_at=now() WHERE id=$2`,
+			subnetID.String(), d.l1ID); err != nil {
+			return fmt.Errorf("record subnet: %w", err)
+		}
+		m.logEvent(ctx, "l1.subnet.created", d.l1Name, fmt.Sprintf("Subnet created: %s", subnetID), nil)
+	}
+
+	chainID, err := w.CreateChain(ctx, subnetID, d.chainName, d.vmID, d.genesis)
+	if err != nil {
+		return fmt.Errorf("create chain: %w", err)
+	}
+	if _, err := m.pool.Exec(ctx, `
+		UPDATE l1s SET blockchain_id=$1, create_chain_tx_id=$1, status='configured', updated_at=now()
+		WHERE id=$2`, chainID.String(), d.l1ID); err != nil {
+		return fmt.Errorf("record blockchain: %w", err)
+	}
+	m.logEvent(ctx, "l1.deployed", d.l1Name,
+		fmt.Sprintf("Blockchain %s created on subnet %s", chainID, subnetID), nil)
+
+	// Validators assigned before the subnet existed can track it now.
+	if d.subnetID == "" {
+		vals, err := m.ListValidators(ctx, d.l1ID)
+		if err != nil {
+			slog.Warn("deploy L1: list validators", "error", err, "l1", d.l1Name)
+		}
+		for _, v := range vals {
+			m.requestReconfigure(v.NodeID)
+		}
+	}
+	return nil
+}
+
+// recoverDeployments fails deployments interrupted by a restart. Keys given
+// per request are never stored, so they cannot be resumed; redeploying
+// reuses a subnet that was already created.
+func (m *Manager) recoverDeployments(ctx context.Context) {
+	rows, err := m.pool.Query(ctx, `
+		UPDATE l1s SET status='failed', updated_at=now() WHERE status='deploying' RETURNING name`)
+	if err != nil {
+		slog.Warn("recover deployments", "error", err)
+		return
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+	for _, name := range names {
+		m.logEvent(ctx, "l1.deploy_failed", name, "Deployment interrupted by restart", nil)
+	}
+}
@@ -0,0 +1,171 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// L1 health verdicts.
+const (
+	L1Healthy  = "healthy"
+	L1Degraded = "degraded"
+	L1Down     = "down"
+	L1Unknown  = "unknown"
+)
+
+// l1HeightLagThreshold is how many blocks a validator may trail the highest
+// observed height before it counts against the L1 verdict.
+const l1HeightLagThreshold = 10
+
+// L1Health is the aggregated health verdict for an L1.
+type L1Health struct {
+	L1ID      int64          `json:"l1_id"`
+	Name      string         `json:"name"`
+	Verdict   string         `json:"verdict"`
+	Healthy   int            `json:"healthy"`
+	Total     int            `json:"total"`
+	MaxHeight uint64         `json:"max_height,omitempty"`
+	Nodes     []L1NodeHealth `json:"nodes"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// L1NodeHealth is the per-validator breakdown of an L1 health check.
+type L1NodeHealth struct {
+	NodeID       int64  `json:"node_id"`
+	NodeName     string `json:"node_name"`
+	Status       string `json:"status"`
+	Healthy      bool   `json:"healthy"`
+	Bootstrapped bool   `json:"bootstrapped"`
+	Height       uint64 `json:"height,omitempty"`
+	HeightLag    uint64 `json:"height_lag,omitempty"`
+	OK           bool   `json:"ok"`
+	Error        string `json:"error,omitempty"`
+}
+
+// L1Health probes every validator of an L1 and combines health, bootstrap
+// state, and block height into a single verdict.
+func (m *Manager) L1Health(ctx context.Context, id int64) (*L1Health, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &L1Health{
+		L1ID:      l1.ID,
+		Name:      l1.Name,
+		Total:     len(l1.Validators),
+		Nodes:     make([]L1NodeHealth, len(l1.Validators)),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, v := range l1.Validators {
+		wg.Add(1)
+		go func(i int, v L1Validator) {
+			defer wg.Done()
+			h.Nodes[i] = m.probeL1Node(probeCtx, &l1.L1, v)
+		}(i, v)
+	}
+	wg.Wait()
+
+	for _, n := range h.Nodes {
+		if n.Height > h.MaxHeight {
+			h.MaxHeight = n.Height
+		}
+	}
+	for i := range h.Nodes {
+		n := &h.Nodes[i]
+		if n.Height > 0 {
+			n.HeightLag = h.MaxHeight - n.Height
+		}
+		n.OK = n.Healthy && n.Bootstrapped && n.HeightLag <= l1HeightLagThreshold
+		if n.OK {
+			h.Healthy++
+		}
+	}
+
+	h.Verdict = l1Verdict(h.Healthy, h.Total)
+	return h, nil
+}
+
+// l1Verdict maps healthy/total validator counts to a verdict.
+func l1Verdict(healthy, total int) string {
+	switch {
+	case total == 0:
+		return L1Unknown
+	case healthy == total:
+		return L1Healthy
+	case healthy > 0:
+		return L1Degraded
+	default:
+		return L1Down
+	}
+}
+
+// probeL1Node checks a single validator's health, bootstrap state for the
+// L1's chain, and current block height.
+func (m *Manager) probeL1Node(ctx context.Context, l1 *L1, v L1Validator) L1NodeHealth {
+	nh := L1NodeHealth{NodeID: v.NodeID, NodeName: v.NodeName}
+
+	node, err := m.GetNode(ctx, v.NodeID)
+	if err != nil {
+		nh.Error = "node not found"
+		return nh
+	}
+	nh.Status = node.Status
+	if node.ContainerID == "" || !containerUp(node.Status) {
+		nh.Error = "node not running"
+		return nh
+	}
+
+	nh.Healthy = m.checkNodeHealth(ctx, *node)
+
+	// Bootstrap state of the L1 chain, or the P-Chain until the L1 has a
+	// blockchain ID.
+	chain := "P"
+	if l1.BlockchainID != "" {
+		chain = l1.BlockchainID
+	}
+	var boot struct {
+		IsBootstrapped bool `json:"isBootstrapped"`
+	}
+	if err := m.callNodeRPC(ctx, *node, "/ext/info", "info.isBootstrapped", map[string]string{"chain": chain}, &boot); err != nil {
+		nh.Error = err.Error()
+		return nh
+	}
+	nh.Bootstrapped = boot.IsBootstrapped
+
+	height, err := m.chainHeight(ctx, *node, l1)
+	if err != nil {
+		nh.Error = err.Error()
+		return nh
+	}
+	nh.Height = height
+	return nh
+}
+
+// chainHeight returns the latest accepted block height for the L1's chain
+// (EVM chains via eth_blockNumber), falling back to the P-Chain height.
+func (m *Manager) chainHeight(ctx context.Context, node Node, l1 *L1) (uint64, error) {
+	if l1.BlockchainID != "" && strings.Contains(l1.VM, "evm") {
+		var hex string
+		if err := m.callNodeRPC(ctx, node, "/ext/bc/"+l1.BlockchainID+"/rpc", "eth_blockNumber", []any{}, &hex); err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimPrefix(hex, "0x"), 16, 64)
+	}
+
+	var res struct {
+		Height string `json:"height"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/bc/P", "platform.getHeight", map[string]any{}, &res); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(res.Height, 10, 64)
+}
@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// L1Overview combines everything an L1 detail page needs in one call.
+type L1Overview struct {
+	L1          L1              `json:"l1"`
+	Health      *L1Health       `json:"health"`
+	Validators  []L1Validator   `json:"validators"`
+	RPC         []L1RPCEndpoint `json:"rpc_endpoints"`
+	LatestBlock *L1Block        `json:"latest_block,omitempty"`
+	Artifacts   L1Artifacts     `json:"artifacts"`
+	Events      []Event         `json:"events"`
+}
+
+// L1RPCEndpoint is where the L1's chain RPC can be reached on one validator.
+type L1RPCEndpoint struct {
+	NodeName string `json:"node_name"`
+	Internal string `json:"internal"`         // from containers on the avax network
+	Public   string `json:"public,omitempty"` // via Traefik (basic auth)
+}
+
+// L1Block is the latest accepted block on the L1's chain.
+type L1Block struct {
+	Height    uint64 `json:"height"`
+	Hash      string `json:"hash,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"` // unix seconds
+	Source    string `json:"source"`              // node it was read from
+}
+
+// L1Artifacts are the on-chain identifiers produced by deploying the L1.
+type L1Artifacts struct {
+	SubnetID       string   `json:"subnet_id,omitempty"`
+	BlockchainID   string   `json:"blockchain_id,omitempty"`
+	VM             string   `json:"vm"`
+	ValidatorTxIDs []string `json:"validator_tx_ids"`
+}
+
+// L1Overview returns an L1 with validator health, RPC endpoints, the latest
+// block, deployment artifacts and recent events.
+func (m *Manager) L1Overview(ctx context.Context, id int64) (*L1Overview, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	health, err := m.L1Health(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &L1Overview{
+		L1:         l1.L1,
+		Health:     health,
+		Validators: l1.Validators,
+		RPC:        []L1RPCEndpoint{},
+		Artifacts: L1Artifacts{
+			SubnetID:       l1.SubnetID,
+			BlockchainID:   l1.BlockchainID,
+			VM:             l1.VM,
+			ValidatorTxIDs: []string{},
+		},
+		Events: []Event{},
+	}
+	for _, v := range l1.Validators {
+		if v.TxID != "" {
+			o.Artifacts.ValidatorTxIDs = append(o.Artifacts.ValidatorTxIDs, v.TxID)
+		}
+	}
+
+	chainPath := "/ext/bc/P"
+	if l1.BlockchainID != "" {
+		chainPath = "/ext/bc/" + l1.BlockchainID + "/rpc"
+	}
+	var source *Node
+	for _, nh := range health.Nodes {
+		node, err := m.GetNode(ctx, nh.NodeID)
+		if err != nil {
+			continue
+		}
+		ep := L1RPCEndpoint{NodeName: node.Name, Internal: m.nodeBaseURL(ctx, *node) + chainPath}
+		if m.traefikDomain != "" && node.NetworkMode != "host" {
+			ep.Public = fmt.Sprintf("https://%s.%s%s", node.Name, m.traefikDomain, chainPath)
+		}
+		o.RPC = append(o.RPC, ep)
+		if source == nil && nh.Height > 0 && nh.Height == health.MaxHeight {
+			source = node
+		}
+	}
+	if source != nil {
+		o.LatestBlock = m.latestBlock(ctx, *source, &l1.L1, health.MaxHeight)
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, event_type, severity, target, message, details, created_at
+		FROM events
+		WHERE event_type LIKE 'l1.%' AND target = $1
+		ORDER BY created_at DESC LIMIT 20`, l1.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Event
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			json.Unmarshal(details, &e.Details)
+		}
+		o.Events = append(o.Events, e)
+	}
+	return o, rows.Err()
+}
+
+// latestBlock reads the head block from node. For EVM chains it includes the
+// hash and timestamp; otherwise only the height already observed is returned.
+func (m *Manager) latestBlock(ctx context.Context, node Node, l1 *L1, height uint64) *L1Block {
+	b := &L1Block{Height: height, Source: node.Name}
+	if l1.BlockchainID == "" || !strings.Contains(l1.VM, "evm") {
+		return b
+	}
+	var blk struct {
+		Number    string `json:"number"`
+		Hash      string `json:"hash"`
+		Timestamp string `json:"timestamp"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/bc/"+l1.BlockchainID+"/rpc", "eth_getBlockByNumber", []any{"latest", false}, &blk); err != nil {
+		return b
+	}
+	if n, err := strconv.ParseUint(strings.TrimPrefix(blk.Number, "0x"), 16, 64); err == nil {
+		b.Height = n
+	}
+	b.Hash = blk.Hash
+	if ts, err := strconv.ParseInt(strings.TrimPrefix(blk.Timestamp, "0x"), 16, 64); err == nil {
+		b.Timestamp = ts
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// UpdateL1Request changes an L1's ownership metadata and whether it is in
+// the public uptime feed. Nil fields are left as they are; empty strings
+// clear them.
+type UpdateL1Request struct {
+	Owner   *string `json:"owner"`
+	Contact *string `json:"contact"`
+	URL     *string `json:"url"`
+	Public  *bool   `json:"public"`
+}
+
+// validateL1Owner checks owner metadata lengths and that url is http(s).
+func validateL1Owner(owner, contact, link string) error {
+	for name, v := range map[string]string{"owner": owner, "contact": contact, "url": link} {
+		if len(v) > 256 {
+			return fmt.Errorf("%s exceeds 256 characters", name)
+		}
+	}
+	if link != "" {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http(s) URL")
+		}
+	}
+	return nil
+}
+
+// UpdateL1 sets an L1's owner, contact and URL, which are attached to its
+// alerts so notifications reach the right customer, and whether its uptime
+// is published at /api/public.
+func (m *Manager) UpdateL1(ctx context.Context, id int64, req UpdateL1Request) (*L1Detail, error) {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	owner, contact, link, public := l1.Owner, l1.Contact, l1.URL, l1.Public
+	var changed []string
+	if req.Owner != nil {
+		owner = strings.TrimSpace(*req.Owner)
+		changed = append(changed, "owner")
+	}
+	if req.Contact != nil {
+		contact = strings.TrimSpace(*req.Contact)
+		changed = append(changed, "contact")
+	}
+	if req.URL != nil {
+		link = strings.TrimSpace(*req.URL)
+		changed = append(changed, "url")
+	}
+	if req.Public != nil {
+		public = *req.Public
+		changed = append(changed, "public")
+	}
+	if len(changed) == 0 {
+		return l1, nil
+	}
+	if err := validateL1Owner(owner, contact, link); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE l1s SET owner=$1, contact=$2, url=$3, public=$4, updated_at=now() WHERE id=$5",
+		owner, contact, link, public, id); err != nil {
+		return nil, fmt.Errorf("update L1: %w", err)
+	}
+	m.logEvent(ctx, "l1.updated", l1.Name, "Updated "+strings.Join(changed, ", "),
+		map[string]any{"owner": owner, "contact": contact, "url": link, "public": public})
+	return m.GetL1(ctx, id)
+}
@@ -0,0 +1,153 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// ExposeL1RPCRequest picks the nodes serving an L1's public RPC endpoint.
+// An empty list withdraws the endpoint.
+type ExposeL1RPCRequest struct {
+	NodeIDs []int64 `json:"node_ids"`
+}
+
+// ExposeL1RPC publishes the L1's chain RPC (/ext/bc/<blockchainID>/rpc)
+// through Traefik at <name>-rpc.<domain>, load-balanced across the given
+// nodes, and stores the URL on the L1. Backing nodes must be bridge-network
+// validators of the L1 so they track its chain. Nodes joining or leaving
+// the set are recreated to pick up their new routing labels.
+func (m *Manager) ExposeL1RPC(ctx context.Context, id int64, req ExposeL1RPCRequest) (*L1Detail, error) {
+	if m.traefikDomain == "" {
+		return nil, fmt.Errorf("Traefik is not configured (AVAGO_TRAEFIK_DOMAIN)")
+	}
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	if len(req.NodeIDs) > 0 && l1.BlockchainID == "" {
+		return nil, fmt.Errorf("L1 %q has no blockchain_id yet", l1.Name)
+	}
+	if nodes, err := m.ListNodes(ctx); err == nil && len(req.NodeIDs) > 0 && l1RPCHostTaken(l1.Name, nodes) {
+		return nil, fmt.Errorf("a node named %q already owns %s", l1.Name+"-rpc", docker.L1RPCHost(l1.Name, m.traefikDomain))
+	}
+
+	want := []int64{}
+	for _, nid := range req.NodeIDs {
+		if slices.Contains(want, nid) {
+			continue
+		}
+		node, err := m.GetNode(ctx, nid)
+		if err != nil {
+			return nil, fmt.Errorf("node %d not found", nid)
+		}
+		if node.NetworkMode == "host" {
+			return nil, fmt.Errorf("node %q uses host networking and can't be routed by Traefik", node.Name)
+		}
+		if !slices.ContainsFunc(l1.Validators, func(v L1Validator) bool { return v.NodeID == nid }) {
+			return nil, fmt.Errorf("node %q is not a validator of L1 %q", node.Name, l1.Name)
+		}
+		want = append(want, nid)
+	}
+
+	rpcURL := ""
+	if len(want) > 0 {
+		rpcURL = "https://" + docker.L1RPCHost(l1.Name, m.traefikDomain)
+	}
+	changed := symmetricDiff(l1.RPCNodes, want)
+	if _, err := m.pool.Exec(ctx, "DELETE FROM l1_rpc_nodes WHERE l1_id=$1", id); err != nil {
+		return nil, fmt.Errorf("update RPC nodes: %w", err)
+	}
+	for _, nid := range want {
+		if _, err := m.pool.Exec(ctx, "INSERT INTO l1_rpc_nodes (l1_id, node_id) VALUES ($1, $2)", id, nid); err != nil {
+			return nil, fmt.Errorf("update RPC nodes: %w", err)
+		}
+	}
+	if _, err := m.pool.Exec(ctx, "UPDATE l1s SET rpc_url=$1, updated_at=now() WHERE id=$2", rpcURL, id); err != nil {
+		return nil, fmt.Errorf("update L1: %w", err)
+	}
+	for _, nid := range changed {
+		m.requestReconfigure(nid)
+	}
+
+	if rpcURL == "" {
+		m.logEvent(ctx, "l1.rpc_withdrawn", l1.Name, "Public RPC endpoint removed", nil)
+	} else {
+		m.logEvent(ctx, "l1.rpc_exposed", l1.Name,
+			fmt.Sprintf("Public RPC at %s via %d node(s)", rpcURL, len(want)),
+			map[string]any{"rpc_url": rpcURL, "node_ids": want})
+	}
+	return m.GetL1(ctx, id)
+}
+
+// l1RoutesForNode returns the public L1 RPC routes a node backs.
+func (m *Manager) l1RoutesForNode(ctx context.Context, nodeID int64) ([]docker.L1Route, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT l.name, l.blockchain_id
+		FROM l1_rpc_nodes r
+		JOIN l1s l ON r.l1_id = l.id
+		WHERE r.node_id = $1 AND l.blockchain_id != ''
+		ORDER BY l.name`, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routes []docker.L1Route
+	for rows.Next() {
+		var r docker.L1Route
+		if err := rows.Scan(&r.Name, &r.BlockchainID); err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+	return routes, rows.Err()
+}
+
+// symmetricDiff returns the IDs in exactly one of a and b.
+func symmetricDiff(a, b []int64) []int64 {
+	var out []int64
+	for _, x := range a {
+		if !slices.Contains(b, x) {
+			out = append(out, x)
+		}
+	}
+	for _, x := range b {
+		if !slices.Contains(a, x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// l1RPCNodes returns the IDs of the nodes backing an L1's RPC endpoint.
+func (m *Manager) l1RPCNodes(ctx context.Context, l1ID int64) ([]int64, error) {
+	rows, err := m.pool.Query(ctx, "SELECT node_id FROM l1_rpc_nodes WHERE l1_id=$1 ORDER BY node_id", l1ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// l1RPCHostTaken reports whether name's RPC hostname would shadow a node's
+// own <name>.<domain> route; both share the Traefik domain.
+func l1RPCHostTaken(name string, nodes []Node) bool {
+	for _, n := range nodes {
+		if strings.EqualFold(n.Name, name+"-rpc") {
+			return true
+		}
+	}
+	return false
+}
@@ -2,30 +2,45 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/docker/docker/client"
 	"github.com/primal-host/avalauncher/internal/docker"
 )
 
 // L1 represents an L1 row from the database.
 type L1 struct {
-	ID           int64     `json:"id"`
-	Name         string    `json:"name"`
-	SubnetID     string    `json:"subnet_id"`
-	BlockchainID string    `json:"blockchain_id"`
-	VM           string    `json:"vm"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID               int64      `json:"id"`
+	Name             string     `json:"name"`
+	SubnetID         string     `json:"subnet_id"`
+	BlockchainID     string     `json:"blockchain_id"`
+	VM               string     `json:"vm"`
+	Status           string     `json:"status"`
+	CreateSubnetTxID string     `json:"create_subnet_tx_id,omitempty"`
+	CreateChainTxID  string     `json:"create_chain_tx_id,omitempty"`
+	Owner            string     `json:"owner,omitempty"`   // customer or team running the L1
+	Contact          string     `json:"contact,omitempty"` // who to notify, e.g. an email address
+	URL              string     `json:"url,omitempty"`     // customer link, e.g. a runbook or status page
+	RPCURL           string     `json:"rpc_url,omitempty"` // public chain RPC endpoint, see ExposeL1RPC
+	Public           bool       `json:"public"`            // listed in the token-less uptime feed
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 // L1Detail includes the L1 plus its validators.
 type L1Detail struct {
 	L1
-	Validators []L1Validator `json:"validators"`
+	Validators []L1Validator      `json:"validators"`
+	Pending    []PendingValidator `json:"pending_validators"`
+	RPCNodes   []int64            `json:"rpc_nodes"`         // nodes backing RPCURL
+	RPCDNS     *RPCDNSState       `json:"rpc_dns,omitempty"` // health-based DNS for RPCURL, if enabled
+	Genesis    json.RawMessage    `json:"genesis,omitempty"` // stored chain_config, if generated
 }
 
 // L1WithCount includes the L1 plus a validator count.
@@ -36,11 +51,13 @@ type L1WithCount struct {
 
 // L1Validator represents a validator assignment row.
 type L1Validator struct {
-	ID       int64  `json:"id"`
-	NodeID   int64  `json:"node_id"`
-	NodeName string `json:"node_name"`
-	Weight   int64  `json:"weight"`
-	TxID     string `json:"tx_id"`
+	ID           int64  `json:"id"`
+	NodeID       int64  `json:"node_id"`
+	NodeName     string `json:"node_name"`
+	Weight       int64  `json:"weight"`
+	TxID         string `json:"tx_id"`
+	ValidationID string `json:"validation_id,omitempty"` // set once the subnet is converted to an L1
+	Pending      bool   `json:"pending,omitempty"`       // queued until the node is ready
 }
 
 // L1DashboardItem is the L1 representation for the dashboard status endpoint.
@@ -51,16 +68,23 @@ type L1DashboardItem struct {
 
 // CreateL1Request holds parameters for creating an L1.
 type CreateL1Request struct {
-	Name         string `json:"name"`
-	VM           string `json:"vm"`
-	SubnetID     string `json:"subnet_id"`
-	BlockchainID string `json:"blockchain_id"`
+	Name         string           `json:"name"`
+	VM           string           `json:"vm"`
+	SubnetID     string           `json:"subnet_id"`
+	BlockchainID string           `json:"blockchain_id"`
+	TTL          string           `json:"ttl"`              // tear down after this long, e.g. "24h"
+	Deploy       *DeployL1Request `json:"deploy,omitempty"` // also create the subnet and chain on-chain
+	Owner        string           `json:"owner"`
+	Contact      string           `json:"contact"`
+	URL          string           `json:"url"`
 }
 
 // AddValidatorRequest holds parameters for adding a validator to an L1.
 type AddValidatorRequest struct {
-	NodeID int64 `json:"node_id"`
-	Weight int64 `json:"weight"`
+	NodeID    int64 `json:"node_id"`
+	Weight    int64 `json:"weight"`
+	WhenReady bool  `json:"when_ready"` // queue until the node is ready instead of refusing
+	Force     bool  `json:"force"`      // skip the readiness check
 }
 
 // CreateL1 creates a new L1 record.
@@ -81,37 +105,97 @@ func (m *Manager) CreateL1(ctx context.Context, req CreateL1Request) (*L1, error
 		return nil, fmt.Errorf("L1 %q already exists", req.Name)
 	}
 
+	expiresAt, err := parseTTL(req.TTL)
+	if err != nil {
+		return nil, err
+	}
+	req.Owner, req.Contact, req.URL = strings.TrimSpace(req.Owner), strings.TrimSpace(req.Contact), strings.TrimSpace(req.URL)
+	if err := validateL1Owner(req.Owner, req.Contact, req.URL); err != nil {
+		return nil, err
+	}
+
 	status := "pending"
 	if req.SubnetID != "" {
 		status = "configured"
 	}
 
+	var d *deployment
+	if req.Deploy != nil {
+		if req.BlockchainID != "" {
+			return nil, fmt.Errorf("blockchain_id and deploy are mutually exclusive")
+		}
+		if d, err = m.prepareDeploy(ctx, req.Name, req.VM, req.SubnetID, *req.Deploy); err != nil {
+			return nil, err
+		}
+		status = "deploying"
+	}
+
 	var l1 L1
-	err := m.pool.QueryRow(ctx, `
-		INSERT INTO l1s (name, vm, subnet_id, blockchain_id, status)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, name, subnet_id, blockchain_id, vm, status, created_at, updated_at`,
-		req.Name, req.VM, req.SubnetID, req.BlockchainID, status,
-	).Scan(&l1.ID, &l1.Name, &l1.SubnetID, &l1.BlockchainID, &l1.VM, &l1.Status, &l1.CreatedAt, &l1.UpdatedAt)
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO l1s (name, vm, subnet_id, blockchain_id, status, expires_at, owner, contact, url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, name, subnet_id, blockchain_id, vm, status, create_subnet_tx_id, create_chain_tx_id,
+		          owner, contact, url, public, expires_at, created_at, updated_at`,
+		req.Name, req.VM, req.SubnetID, req.BlockchainID, status, expiresAt, req.Owner, req.Contact, req.URL,
+	).Scan(&l1.ID, &l1.Name, &l1.SubnetID, &l1.BlockchainID, &l1.VM, &l1.Status, &l1.CreateSubnetTxID, &l1.CreateChainTxID,
+		&l1.Owner, &l1.Contact, &l1.URL, &l1.Public, &l1.ExpiresAt, &l1.CreatedAt, &l1.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("insert L1: %w", err)
 	}
 
 	m.logEvent(ctx, "l1.created", l1.Name, fmt.Sprintf("L1 created (vm=%s, status=%s)", l1.VM, l1.Status), nil)
+	if d != nil {
+		d.l1ID = l1.ID
+		m.startDeploy(d)
+	}
 	return &l1, nil
 }
 
-// ListL1s returns all L1s with validator counts.
-func (m *Manager) ListL1s(ctx context.Context) ([]L1WithCount, error) {
-	rows, err := m.pool.Query(ctx, `
+// L1Filter narrows ListL1s. Zero fields match everything.
+type L1Filter struct {
+	Status string
+	VM     string
+	Limit  int // 0 = no limit
+	Offset int
+}
+
+// ListL1s returns the L1s matching f with validator counts, ordered by ID,
+// and how many match in all.
+func (m *Manager) ListL1s(ctx context.Context, f L1Filter) ([]L1WithCount, int, error) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := " WHERE true"
+	if f.Status != "" {
+		where += " AND l.status = " + arg(f.Status)
+	}
+	if f.VM != "" {
+		where += " AND l.vm = " + arg(f.VM)
+	}
+
+	var total int
+	if err := m.pool.QueryRow(ctx, "SELECT count(*) FROM l1s l"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query := `
 		SELECT l.id, l.name, l.subnet_id, l.blockchain_id, l.vm, l.status,
-		       l.created_at, l.updated_at, COUNT(v.id)::int AS validator_count
+		       l.create_subnet_tx_id, l.create_chain_tx_id, l.owner, l.contact, l.url, l.rpc_url, l.public,
+		       l.expires_at, l.created_at, l.updated_at, COUNT(v.id)::int AS validator_count
 		FROM l1s l
-		LEFT JOIN l1_validators v ON v.l1_id = l.id
+		LEFT JOIN l1_validators v ON v.l1_id = l.id` + where + `
 		GROUP BY l.id
-		ORDER BY l.id`)
+		ORDER BY l.id`
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += " OFFSET " + arg(f.Offset)
+	}
+	rows, err := m.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -119,30 +203,36 @@ func (m *Manager) ListL1s(ctx context.Context) ([]L1WithCount, error) {
 	for rows.Next() {
 		var l L1WithCount
 		if err := rows.Scan(&l.ID, &l.Name, &l.SubnetID, &l.BlockchainID, &l.VM, &l.Status,
-			&l.CreatedAt, &l.UpdatedAt, &l.ValidatorCount); err != nil {
-			return nil, err
+			&l.CreateSubnetTxID, &l.CreateChainTxID, &l.Owner, &l.Contact, &l.URL, &l.RPCURL, &l.Public,
+			&l.ExpiresAt, &l.CreatedAt, &l.UpdatedAt, &l.ValidatorCount); err != nil {
+			return nil, 0, err
 		}
 		l1s = append(l1s, l)
 	}
 	if l1s == nil {
 		l1s = []L1WithCount{}
 	}
-	return l1s, rows.Err()
+	return l1s, total, rows.Err()
 }
 
+// ErrL1NotFound is returned by L1 operations given an unknown L1 ID.
+var ErrL1NotFound = errors.New("L1 not found")
+
 // GetL1 returns an L1 with its validators.
 func (m *Manager) GetL1(ctx context.Context, id int64) (*L1Detail, error) {
 	var d L1Detail
 	err := m.pool.QueryRow(ctx, `
-		SELECT id, name, subnet_id, blockchain_id, vm, status, created_at, updated_at
+		SELECT id, name, subnet_id, blockchain_id, vm, status, create_subnet_tx_id, create_chain_tx_id,
+		       owner, contact, url, rpc_url, public, expires_at, created_at, updated_at, NULLIF(chain_config, '{}'::jsonb)
 		FROM l1s WHERE id=$1`, id).
-		Scan(&d.ID, &d.Name, &d.SubnetID, &d.BlockchainID, &d.VM, &d.Status, &d.CreatedAt, &d.UpdatedAt)
+		Scan(&d.ID, &d.Name, &d.SubnetID, &d.BlockchainID, &d.VM, &d.Status, &d.CreateSubnetTxID, &d.CreateChainTxID,
+			&d.Owner, &d.Contact, &d.URL, &d.RPCURL, &d.Public, &d.ExpiresAt, &d.CreatedAt, &d.UpdatedAt, &d.Genesis)
 	if err != nil {
 		return nil, err
 	}
 
 	rows, err := m.pool.Query(ctx, `
-		SELECT v.id, v.node_id, n.name, v.weight, v.tx_id
+		SELECT v.id, v.node_id, n.name, v.weight, v.tx_id, v.validation_id
 		FROM l1_validators v
 		JOIN nodes n ON v.node_id = n.id
 		WHERE v.l1_id = $1
@@ -154,7 +244,7 @@ func (m *Manager) GetL1(ctx context.Context, id int64) (*L1Detail, error) {
 
 	for rows.Next() {
 		var v L1Validator
-		if err := rows.Scan(&v.ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID); err != nil {
+		if err := rows.Scan(&v.ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID, &v.ValidationID); err != nil {
 			return nil, err
 		}
 		d.Validators = append(d.Validators, v)
@@ -162,14 +252,26 @@ func (m *Manager) GetL1(ctx context.Context, id int64) (*L1Detail, error) {
 	if d.Validators == nil {
 		d.Validators = []L1Validator{}
 	}
-	return &d, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if d.Pending, err = m.pendingValidators(ctx, id); err != nil {
+		return nil, err
+	}
+	if d.RPCNodes, err = m.l1RPCNodes(ctx, id); err != nil {
+		return nil, err
+	}
+	d.RPCDNS = m.rpcDNSState(id)
+	return &d, nil
 }
 
 // DeleteL1 removes an L1 if it has no validators.
 func (m *Manager) DeleteL1(ctx context.Context, id int64) error {
 	var name string
-	if err := m.pool.QueryRow(ctx, "SELECT name FROM l1s WHERE id=$1", id).Scan(&name); err != nil {
-		return fmt.Errorf("L1 not found")
+	var published []string
+	if err := m.pool.QueryRow(ctx, "SELECT name, rpc_dns_records FROM l1s WHERE id=$1", id).Scan(&name, &published); err != nil {
+		return ErrL1NotFound
 	}
 
 	var count int64
@@ -183,6 +285,7 @@ func (m *Manager) DeleteL1(ctx context.Context, id int64) error {
 	if _, err := m.pool.Exec(ctx, "DELETE FROM l1s WHERE id=$1", id); err != nil {
 		return fmt.Errorf("delete L1: %w", err)
 	}
+	m.withdrawRPCDNS(ctx, name, published)
 
 	m.logEvent(ctx, "l1.deleted", name, "L1 deleted", nil)
 	return nil
@@ -197,14 +300,14 @@ func (m *Manager) AddValidator(ctx context.Context, l1ID int64, req AddValidator
 	// Verify L1 exists.
 	var l1Name, subnetID string
 	if err := m.pool.QueryRow(ctx, "SELECT name, subnet_id FROM l1s WHERE id=$1", l1ID).Scan(&l1Name, &subnetID); err != nil {
-		return nil, fmt.Errorf("L1 not found")
+		return nil, ErrL1NotFound
 	}
 
 	// Verify node exists.
 	var nodeName string
 	var hostID int64
 	if err := m.pool.QueryRow(ctx, "SELECT name, host_id FROM nodes WHERE id=$1", req.NodeID).Scan(&nodeName, &hostID); err != nil {
-		return nil, fmt.Errorf("node not found")
+		return nil, ErrNodeNotFound
 	}
 
 	// Check for duplicate assignment.
@@ -216,23 +319,43 @@ func (m *Manager) AddValidator(ctx context.Context, l1ID int64, req AddValidator
 		return nil, fmt.Errorf("node %q is already a validator for L1 %q", nodeName, l1Name)
 	}
 
+	// A node that is still syncing would only drag the L1 down.
+	if !req.Force {
+		node, err := m.GetNode(ctx, req.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		if ready, reason := m.nodeReady(ctx, *node); !ready {
+			if !req.WhenReady {
+				return nil, fmt.Errorf("node %q is not ready: %s (set when_ready to queue, or force to skip the check)", nodeName, reason)
+			}
+			return m.queueValidator(ctx, l1ID, l1Name, *node, req.Weight, reason)
+		}
+	}
+
+	return m.insertValidator(ctx, l1ID, l1Name, subnetID, req.NodeID, nodeName, req.Weight)
+}
+
+// insertValidator records a validator assignment and reconfigures the node.
+func (m *Manager) insertValidator(ctx context.Context, l1ID int64, l1Name, subnetID string, nodeID int64, nodeName string, weight int64) (*L1Validator, error) {
 	var v L1Validator
 	err := m.pool.QueryRow(ctx, `
 		INSERT INTO l1_validators (l1_id, node_id, weight)
 		VALUES ($1, $2, $3)
 		RETURNING id, node_id, weight, tx_id`,
-		l1ID, req.NodeID, req.Weight,
+		l1ID, nodeID, weight,
 	).Scan(&v.ID, &v.NodeID, &v.Weight, &v.TxID)
 	if err != nil {
 		return nil, fmt.Errorf("insert validator: %w", err)
 	}
 	v.NodeName = nodeName
+	m.recordWeight(ctx, l1ID, nodeID, weight, "added")
 
-	m.logEvent(ctx, "l1.validator.added", l1Name, fmt.Sprintf("Validator added: node %s (weight %d)", nodeName, req.Weight), nil)
+	m.logEvent(ctx, "l1.validator.added", l1Name, fmt.Sprintf("Validator added: node %s (weight %d)", nodeName, weight), nil)
 
 	// Reconfigure node container if L1 has a subnet_id.
 	if subnetID != "" {
-		go m.reconfigureNode(req.NodeID)
+		m.requestReconfigure(nodeID)
 	}
 
 	return &v, nil
@@ -242,7 +365,7 @@ func (m *Manager) AddValidator(ctx context.Context, l1ID int64, req AddValidator
 func (m *Manager) RemoveValidator(ctx context.Context, l1ID, nodeID int64) error {
 	var l1Name, subnetID string
 	if err := m.pool.QueryRow(ctx, "SELECT name, subnet_id FROM l1s WHERE id=$1", l1ID).Scan(&l1Name, &subnetID); err != nil {
-		return fmt.Errorf("L1 not found")
+		return ErrL1NotFound
 	}
 
 	tag, err := m.pool.Exec(ctx, "DELETE FROM l1_validators WHERE l1_id=$1 AND node_id=$2", l1ID, nodeID)
@@ -250,14 +373,30 @@ func (m *Manager) RemoveValidator(ctx context.Context, l1ID, nodeID int64) error
 		return fmt.Errorf("delete validator: %w", err)
 	}
 	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("validator assignment not found")
+		// Not active; it may still be queued.
+		tag, err = m.pool.Exec(ctx, "DELETE FROM pending_validators WHERE l1_id=$1 AND node_id=$2", l1ID, nodeID)
+		if err != nil {
+			return fmt.Errorf("delete pending validator: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("validator assignment not found")
+		}
+		m.logEvent(ctx, "l1.validator.dequeued", l1Name, "Queued validator removed", nil)
+		return nil
 	}
 
+	m.recordWeight(ctx, l1ID, nodeID, 0, "removed")
 	m.logEvent(ctx, "l1.validator.removed", l1Name, "Validator removed", nil)
 
+	// A former validator no longer tracks the chain, so it can't serve its RPC.
+	if tag, err := m.pool.Exec(ctx, "DELETE FROM l1_rpc_nodes WHERE l1_id=$1 AND node_id=$2", l1ID, nodeID); err == nil && tag.RowsAffected() > 0 {
+		m.pool.Exec(ctx, `UPDATE l1s SET rpc_url='', updated_at=now()
+			WHERE id=$1 AND NOT EXISTS (SELECT 1 FROM l1_rpc_nodes WHERE l1_id=$1)`, l1ID)
+	}
+
 	// Reconfigure node container if L1 has a subnet_id.
 	if subnetID != "" {
-		go m.reconfigureNode(nodeID)
+		m.requestReconfigure(nodeID)
 	}
 
 	return nil
@@ -266,7 +405,7 @@ func (m *Manager) RemoveValidator(ctx context.Context, l1ID, nodeID int64) error
 // ListValidators returns all validators for an L1.
 func (m *Manager) ListValidators(ctx context.Context, l1ID int64) ([]L1Validator, error) {
 	rows, err := m.pool.Query(ctx, `
-		SELECT v.id, v.node_id, n.name, v.weight, v.tx_id
+		SELECT v.id, v.node_id, n.name, v.weight, v.tx_id, v.validation_id
 		FROM l1_validators v
 		JOIN nodes n ON v.node_id = n.id
 		WHERE v.l1_id = $1
@@ -279,7 +418,7 @@ func (m *Manager) ListValidators(ctx context.Context, l1ID int64) ([]L1Validator
 	var vals []L1Validator
 	for rows.Next() {
 		var v L1Validator
-		if err := rows.Scan(&v.ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID); err != nil {
+		if err := rows.Scan(&v.ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID, &v.ValidationID); err != nil {
 			return nil, err
 		}
 		vals = append(vals, v)
@@ -294,7 +433,8 @@ func (m *Manager) ListValidators(ctx context.Context, l1ID int64) ([]L1Validator
 func (m *Manager) ListL1sForDashboard(ctx context.Context) ([]L1DashboardItem, error) {
 	// Fetch all L1s.
 	rows, err := m.pool.Query(ctx, `
-		SELECT id, name, subnet_id, blockchain_id, vm, status, created_at, updated_at
+		SELECT id, name, subnet_id, blockchain_id, vm, status, create_subnet_tx_id, create_chain_tx_id,
+		       owner, contact, url, public, expires_at, created_at, updated_at
 		FROM l1s ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -306,7 +446,8 @@ func (m *Manager) ListL1sForDashboard(ctx context.Context) ([]L1DashboardItem, e
 	for rows.Next() {
 		var item L1DashboardItem
 		if err := rows.Scan(&item.ID, &item.Name, &item.SubnetID, &item.BlockchainID,
-			&item.VM, &item.Status, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			&item.VM, &item.Status, &item.CreateSubnetTxID, &item.CreateChainTxID, &item.Owner, &item.Contact, &item.URL,
+			&item.Public, &item.ExpiresAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, err
 		}
 		item.Validators = []L1Validator{}
@@ -323,7 +464,7 @@ func (m *Manager) ListL1sForDashboard(ctx context.Context) ([]L1DashboardItem, e
 
 	// Fetch all validators.
 	vrows, err := m.pool.Query(ctx, `
-		SELECT v.id, v.l1_id, v.node_id, n.name, v.weight, v.tx_id
+		SELECT v.id, v.l1_id, v.node_id, n.name, v.weight, v.tx_id, v.validation_id
 		FROM l1_validators v
 		JOIN nodes n ON v.node_id = n.id
 		ORDER BY v.id`)
@@ -335,7 +476,7 @@ func (m *Manager) ListL1sForDashboard(ctx context.Context) ([]L1DashboardItem, e
 	for vrows.Next() {
 		var v L1Validator
 		var l1ID int64
-		if err := vrows.Scan(&v.ID, &l1ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID); err != nil {
+		if err := vrows.Scan(&v.ID, &l1ID, &v.NodeID, &v.NodeName, &v.Weight, &v.TxID, &v.ValidationID); err != nil {
 			return nil, err
 		}
 		if idx, ok := idxMap[l1ID]; ok {
@@ -382,7 +523,11 @@ func (m *Manager) reconfigureNode(nodeID int64) {
 
 	dc := m.clientFor(node.HostID)
 	if dc == nil {
-		slog.Error("reconfigure: no client for host", "host_id", node.HostID, "node", node.Name)
+		// Run it once the host poller reconnects the host.
+		slog.Warn("reconfigure: host not connected, queueing", "host_id", node.HostID, "node", node.Name)
+		if _, err := m.queueOp(ctx, node, OpReconfigure); err != nil {
+			slog.Error("reconfigure: queue", "error", err, "node", node.Name)
+		}
 		return
 	}
 
@@ -395,41 +540,81 @@ func (m *Manager) reconfigureNode(nodeID int64) {
 	m.logEvent(ctx, "node.reconfiguring", node.Name,
 		fmt.Sprintf("Reconfiguring with subnets: %s", strings.Join(subnetIDs, ",")), nil)
 
+	opID := m.beginOp(ctx, OpReconfigure, nodeID, map[string]any{"subnets": subnetIDs})
+
 	// Set status to creating (shows yellow pulse in dashboard).
 	m.pool.Exec(ctx, "UPDATE nodes SET status='creating', updated_at=now() WHERE id=$1", nodeID)
 
+	// Failure writes get their own context, in case ctx is what ran out.
 	setFailed := func(msg string) {
-		m.pool.Exec(ctx, "UPDATE nodes SET status='failed', updated_at=now() WHERE id=$1", nodeID)
-		m.logEvent(ctx, "node.failed", node.Name, msg, nil)
+		fctx, fcancel := context.WithTimeout(context.Background(), opFailTimeout)
+		defer fcancel()
+		m.pool.Exec(fctx, "UPDATE nodes SET status='failed', updated_at=now() WHERE id=$1", nodeID)
+		m.logEvent(fctx, "node.failed", node.Name, msg, nil)
+		m.finishOp(fctx, opID, msg)
+	}
+	fail := func(msg string) {
+		fctx, fcancel := context.WithTimeout(context.Background(), opFailTimeout)
+		defer fcancel()
+		if !m.retryOp(fctx, opID, node.Name, msg) {
+			setFailed(msg)
+		}
 	}
 
 	// Stop container if running.
 	if node.ContainerID != "" {
 		_ = dc.ContainerStop(ctx, node.ContainerID, 30)
 		if err := dc.ContainerRemove(ctx, node.ContainerID, false); err != nil {
-			if !strings.Contains(err.Error(), "No such container") {
+			if !client.IsErrNotFound(err) {
 				slog.Error("reconfigure: remove container", "error", err, "node", node.Name)
-				setFailed(fmt.Sprintf("Container remove failed: %v", err))
+				fail(fmt.Sprintf("Container remove failed: %v", err))
 				return
 			}
 		}
 	}
 
-	// Build new container config with TrackSubnets.
+	m.opStep(ctx, opID, "removed")
+
+	// Build new container config with TrackSubnets and L1 RPC routes.
+	l1Routes, err := m.l1RoutesForNode(ctx, nodeID)
+	if err != nil {
+		slog.Warn("reconfigure: L1 RPC routes", "error", err, "node", node.Name)
+	}
 	networkID := node.Network
 	if networkID == "" {
 		networkID = m.avagoNetwork
 	}
+	env, cmd, err := m.renderEnv(ctx, node.Env, node.Cmd)
+	if err != nil {
+		slog.Error("reconfigure: render env", "error", err, "node", node.Name)
+		setFailed(fmt.Sprintf("Env render failed: %v", err))
+		return
+	}
 	params := &docker.AvagoParams{
-		Name:           node.Name,
-		Image:          node.Image,
-		NetworkName:    m.avaxDockerNet,
-		NetworkID:      networkID,
-		StakingPort:    node.StakingPort,
-		TrackSubnets:   subnetIDs,
-		TraefikDomain:  m.traefikDomain,
-		TraefikNetwork: m.traefikNetwork,
-		TraefikAuth:    m.traefikAuth,
+		Name:            node.Name,
+		Image:           node.Image,
+		NetworkName:     m.avaxDockerNet,
+		NetworkID:       networkID,
+		StakingPort:     node.StakingPort,
+		ExposeHTTP:      node.ExposeHTTP,
+		HTTPBindIP:      m.httpBindIP(node.HostID),
+		HostNetwork:     node.NetworkMode == "host",
+		HTTPPort:        node.HTTPPort,
+		DNSAliases:      node.DNSAliases,
+		TrackSubnets:    subnetIDs,
+		L1Routes:        l1Routes,
+		Entrypoint:      node.Entrypoint,
+		Cmd:             cmd,
+		Env:             env,
+		Config:          node.Config,
+		Throttle:        node.Throttle,
+		CPULimit:        node.CPULimit,
+		MemoryLimit:     node.MemoryLimit,
+		RestartPolicy:   node.RestartPolicy,
+		TraefikDomain:   m.traefikDomain,
+		TraefikNetwork:  m.traefikNetwork,
+		TraefikAuth:     m.traefikAuth,
+		TraefikResolver: m.traefikResolver,
 	}
 	cc, hc, nc := params.BuildContainerConfig()
 
@@ -438,21 +623,33 @@ func (m *Manager) reconfigureNode(nodeID int64) {
 	containerID, err := dc.ContainerCreate(ctx, containerName, cc, hc, nc)
 	if err != nil {
 		slog.Error("reconfigure: create container", "error", err, "node", node.Name)
-		setFailed(fmt.Sprintf("Container create failed: %v", err))
+		fail(fmt.Sprintf("Container create failed: %v", err))
 		return
 	}
+	if err := m.installStakingKeys(ctx, dc, nodeID, containerID); err != nil {
+		slog.Error("reconfigure: install staking keys", "error", err, "node", node.Name)
+		fail(fmt.Sprintf("Staking key install failed: %v", err))
+		return
+	}
+	if err := m.installL1Genesis(ctx, dc, nodeID, containerID); err != nil {
+		slog.Warn("reconfigure: install L1 genesis", "error", err, "node", node.Name)
+	}
 
 	// Update container_id.
-	m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, updated_at=now() WHERE id=$2", containerID, nodeID)
+	m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, avago_version='', updated_at=now() WHERE id=$2", containerID, nodeID)
+	m.recordImageDigest(ctx, dc, nodeID, node.Image, containerID)
+	m.opStep(ctx, opID, "created")
 
 	// Start container.
 	if err := dc.ContainerStart(ctx, containerID); err != nil {
 		slog.Error("reconfigure: start container", "error", err, "node", node.Name)
-		setFailed(fmt.Sprintf("Container start failed: %v", err))
+		fail(fmt.Sprintf("Container start failed: %v", err))
 		return
 	}
 
-	m.pool.Exec(ctx, "UPDATE nodes SET status='running', updated_at=now() WHERE id=$1", nodeID)
+	m.pool.Exec(ctx, "UPDATE nodes SET status='bootstrapping', updated_at=now() WHERE id=$1", nodeID)
+	m.opStep(ctx, opID, "started")
+	m.finishOp(ctx, opID, "")
 	m.logEvent(ctx, "node.reconfigured", node.Name,
 		fmt.Sprintf("Node reconfigured with %d subnet(s)", len(subnetIDs)), nil)
 	slog.Info("node reconfigured", "node", node.Name, "subnets", subnetIDs, "container", containerID[:12])
@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// l1UptimeWindows are the rolling windows of the public uptime feed, keyed
+// by their label. The longest must not exceed nodeHistoryRetention.
+var l1UptimeWindows = []struct {
+	label string
+	d     time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+	{"90d", 90 * 24 * time.Hour},
+}
+
+// L1Uptime is an L1's current status and rolling availability, for external
+// monitors. An L1 counts as up while it is healthy or degraded (at least
+// one validator running); time without validators is left out.
+type L1Uptime struct {
+	L1ID      int64               `json:"l1_id"`
+	Name      string              `json:"name"`
+	Status    string              `json:"status"` // healthy, degraded, down or unknown
+	Up        bool                `json:"up"`
+	Healthy   int                 `json:"healthy"`
+	Total     int                 `json:"total"`
+	Since     *time.Time          `json:"since,omitempty"` // when the current status began, if recorded
+	Uptime    map[string]*float64 `json:"uptime"`          // window -> percent up; null without data
+	CheckedAt time.Time           `json:"checked_at"`
+}
+
+// l1StatusChange is one row of l1_status_history.
+type l1StatusChange struct {
+	verdict string
+	at      time.Time
+}
+
+// l1Up reports whether a verdict counts as available.
+func l1Up(verdict string) bool {
+	return verdict == L1Healthy || verdict == L1Degraded
+}
+
+// recordL1History stores each L1's verdict and running validator count
+// when they differ from its latest record. It runs with the node history,
+// so status changes seen as they happen reach the L1 feed at once.
+func (m *Manager) recordL1History(ctx context.Context) error {
+	l1s, err := m.L1StatusSummaries(ctx)
+	if err != nil {
+		return err
+	}
+	for _, l := range l1s {
+		if _, err := m.pool.Exec(ctx, `
+			INSERT INTO l1_status_history (l1_id, verdict, healthy, total)
+			SELECT $1, $2, $3, $4
+			WHERE NOT EXISTS (
+				SELECT 1 FROM (
+					SELECT verdict, healthy, total FROM l1_status_history
+					WHERE l1_id = $1 ORDER BY recorded_at DESC, id DESC LIMIT 1
+				) last
+				WHERE (last.verdict, last.healthy, last.total) = ($2, $3, $4))`,
+			l.ID, l.Verdict, l.Healthy, l.Total); err != nil {
+			return fmt.Errorf("record L1 %d status: %w", l.ID, err)
+		}
+	}
+	return nil
+}
+
+// L1Uptime returns a public L1's current status and the share of each
+// rolling window it was up, from the recorded status history. An L1 not
+// marked public is reported as not found, so the feed doesn't reveal it.
+func (m *Manager) L1Uptime(ctx context.Context, id int64) (*L1Uptime, error) {
+	var u L1Uptime
+	err := m.pool.QueryRow(ctx, `
+		SELECT l.id, l.name,
+		       COUNT(v.id)::int,
+		       COUNT(v.id) FILTER (WHERE n.status = 'running')::int
+		FROM l1s l
+		LEFT JOIN l1_validators v ON v.l1_id = l.id
+		LEFT JOIN nodes n ON n.id = v.node_id
+		WHERE l.id = $1 AND l.public
+		GROUP BY l.id`, id).Scan(&u.L1ID, &u.Name, &u.Total, &u.Healthy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrL1NotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query L1: %w", err)
+	}
+	now := time.Now().UTC()
+	u.Status = l1Verdict(u.Healthy, u.Total)
+	u.Up = l1Up(u.Status)
+	u.CheckedAt = now
+
+	from := now.Add(-l1UptimeWindows[len(l1UptimeWindows)-1].d)
+	rows, err := m.pool.Query(ctx, `
+		SELECT verdict, recorded_at FROM (
+			(SELECT verdict, recorded_at, id FROM l1_status_history
+			 WHERE l1_id = $1 AND recorded_at < $2 ORDER BY recorded_at DESC, id DESC LIMIT 1)
+			UNION ALL
+			SELECT verdict, recorded_at, id FROM l1_status_history WHERE l1_id = $1 AND recorded_at >= $2
+		) h ORDER BY recorded_at, id`, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("query L1 status history: %w", err)
+	}
+	defer rows.Close()
+	var changes []l1StatusChange
+	for rows.Next() {
+		var c l1StatusChange
+		if err := rows.Scan(&c.verdict, &c.at); err != nil {
+			return nil, fmt.Errorf("scan L1 status: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := len(changes) - 1; i >= 0 && changes[i].verdict == u.Status; i-- {
+		u.Since = &changes[i].at
+	}
+	u.Uptime = make(map[string]*float64, len(l1UptimeWindows))
+	for _, w := range l1UptimeWindows {
+		u.Uptime[w.label] = uptimePercent(changes, now.Add(-w.d), now)
+	}
+	return &u, nil
+}
+
+// uptimePercent returns the percentage of [from, to) with a known verdict
+// that was up, or nil when none of it is known. Each change holds until the
+// next one; the last holds until to.
+func uptimePercent(changes []l1StatusChange, from, to time.Time) *float64 {
+	var up, known time.Duration
+	for i, c := range changes {
+		start, end := c.at, to
+		if i+1 < len(changes) {
+			end = changes[i+1].at
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) || c.verdict == L1Unknown {
+			continue
+		}
+		known += end.Sub(start)
+		if l1Up(c.verdict) {
+			up += end.Sub(start)
+		}
+	}
+	if known == 0 {
+		return nil
+	}
+	pct := math.Round(float64(up)/float64(known)*100_000) / 1000
+	return &pct
+}
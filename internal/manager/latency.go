@@ -0,0 +1,130 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// latencyRetention is how long raw RPC latency samples are kept.
+const latencyRetention = 7 * 24 * time.Hour
+
+// LatencyStats holds latency percentiles for one RPC method over a window.
+type LatencyStats struct {
+	Method  string  `json:"method"`
+	Samples int64   `json:"samples"`
+	Errors  int64   `json:"errors"`
+	P50Ms   float64 `json:"p50_ms"`
+	P95Ms   float64 `json:"p95_ms"`
+	MaxMs   float64 `json:"max_ms"`
+}
+
+// LatencyBucket holds latency percentiles for one time bucket.
+type LatencyBucket struct {
+	Start   time.Time `json:"start"`
+	Method  string    `json:"method"`
+	Samples int64     `json:"samples"`
+	P50Ms   float64   `json:"p50_ms"`
+	P95Ms   float64   `json:"p95_ms"`
+}
+
+// NodeLatency is the latency report for a node.
+type NodeLatency struct {
+	NodeID  int64           `json:"node_id"`
+	Window  string          `json:"window"`
+	Bucket  string          `json:"bucket"`
+	Summary []LatencyStats  `json:"summary"`
+	Series  []LatencyBucket `json:"series"`
+}
+
+// recordLatency stores a single RPC latency sample for a node.
+func (m *Manager) recordLatency(ctx context.Context, nodeID int64, method string, d time.Duration, ok bool) {
+	_, err := m.pool.Exec(ctx, `
+		INSERT INTO node_latency (node_id, method, latency_ms, ok)
+		VALUES ($1, $2, $3, $4)`,
+		nodeID, method, float64(d.Microseconds())/1000, ok)
+	if err != nil {
+		slog.Debug("record latency", "error", err, "node_id", nodeID, "method", method)
+	}
+}
+
+// pruneLatency removes latency samples older than the retention period.
+func (m *Manager) pruneLatency(ctx context.Context) {
+	_, err := m.pool.Exec(ctx, "DELETE FROM node_latency WHERE created_at < $1", time.Now().Add(-latencyRetention))
+	if err != nil {
+		slog.Warn("prune latency samples", "error", err)
+	}
+}
+
+// NodeLatency returns p50/p95 RPC latency for a node over the given window,
+// both as a per-method summary and as a time series split into buckets.
+func (m *Manager) NodeLatency(ctx context.Context, nodeID int64, window, bucket time.Duration) (*NodeLatency, error) {
+	if window <= 0 {
+		window = time.Hour
+	}
+	if bucket <= 0 {
+		bucket = window / 12
+	}
+	if bucket < time.Minute {
+		bucket = time.Minute
+	}
+	if _, err := m.GetNode(ctx, nodeID); err != nil {
+		return nil, ErrNodeNotFound
+	}
+	since := time.Now().Add(-window)
+
+	report := &NodeLatency{
+		NodeID:  nodeID,
+		Window:  window.String(),
+		Bucket:  bucket.String(),
+		Summary: []LatencyStats{},
+		Series:  []LatencyBucket{},
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT method, count(*), count(*) FILTER (WHERE NOT ok),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms),
+		       percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms),
+		       max(latency_ms)
+		FROM node_latency
+		WHERE node_id = $1 AND created_at >= $2
+		GROUP BY method
+		ORDER BY method`, nodeID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s LatencyStats
+		if err := rows.Scan(&s.Method, &s.Samples, &s.Errors, &s.P50Ms, &s.P95Ms, &s.MaxMs); err != nil {
+			return nil, err
+		}
+		report.Summary = append(report.Summary, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	brows, err := m.pool.Query(ctx, `
+		SELECT to_timestamp(floor(extract(epoch FROM created_at) / $3) * $3) AS bucket,
+		       method, count(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms),
+		       percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms)
+		FROM node_latency
+		WHERE node_id = $1 AND created_at >= $2 AND ok
+		GROUP BY bucket, method
+		ORDER BY bucket, method`, nodeID, since, bucket.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer brows.Close()
+	for brows.Next() {
+		var b LatencyBucket
+		if err := brows.Scan(&b.Start, &b.Method, &b.Samples, &b.P50Ms, &b.P95Ms); err != nil {
+			return nil, err
+		}
+		report.Series = append(report.Series, b)
+	}
+	return report, brows.Err()
+}
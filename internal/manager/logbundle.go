@@ -0,0 +1,234 @@
+package manager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+const (
+	// defaultBundleTail is how many container log lines a bundle takes per
+	// node unless asked otherwise (and LOG_TAIL_MAX allows).
+	defaultBundleTail = 5000
+	// bundleFileMaxBytes caps each AvalancheGo log file in a bundle; larger
+	// files keep their end.
+	bundleFileMaxBytes = 8 << 20
+	// bundleNodeTimeout bounds collecting one node's logs.
+	bundleNodeTimeout = 2 * time.Minute
+)
+
+// LogBundleRequest selects what an L1 log bundle contains.
+type LogBundleRequest struct {
+	Tail      string `json:"tail"`       // container log lines per node (default 5000, at most LOG_TAIL_MAX)
+	Since     string `json:"since"`      // only container logs this recent, e.g. "6h"
+	SkipFiles bool   `json:"skip_files"` // leave out AvalancheGo's main, P-Chain and L1 chain log files
+}
+
+// LogBundleNode describes one node's part of a bundle.
+type LogBundleNode struct {
+	NodeID      int64    `json:"node_id"`
+	Name        string   `json:"name"`
+	AvagoNodeID string   `json:"avago_node_id,omitempty"`
+	Host        string   `json:"host"`
+	Status      string   `json:"status"`
+	Image       string   `json:"image"`
+	Version     string   `json:"version,omitempty"`
+	Files       []string `json:"files"` // paths within the archive
+	Error       string   `json:"error,omitempty"`
+
+	data [][]byte // contents of Files
+}
+
+// LogBundle holds the logs collected from an L1's validators. It is written
+// out as a .tar.gz with manifest.json at the root and one directory per
+// node.
+type LogBundle struct {
+	L1        L1              `json:"l1"`
+	CreatedAt time.Time       `json:"created_at"`
+	Tail      string          `json:"tail"`
+	Since     *time.Time      `json:"since,omitempty"`
+	Nodes     []LogBundleNode `json:"nodes"`
+}
+
+// Filename is the suggested download name of the bundle.
+func (b *LogBundle) Filename() string {
+	return fmt.Sprintf("%s-logs-%s.tar.gz", b.L1.Name, b.CreatedAt.UTC().Format("20060102T150405Z"))
+}
+
+// CollectL1Logs gathers the recent container logs, and AvalancheGo's own
+// log files for the primary network and the L1's chain, of every validator
+// of an L1. Hosts are collected in parallel, nodes on one host one after
+// another. A node that can't be read is listed in the manifest with its
+// error rather than failing the bundle.
+func (m *Manager) CollectL1Logs(ctx context.Context, l1ID int64, req LogBundleRequest) (*LogBundle, error) {
+	l1, err := m.GetL1(ctx, l1ID)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	tail := req.Tail
+	if tail == "" {
+		tail = strconv.Itoa(defaultBundleTail)
+		if max := m.logStreams.limits.MaxTail; max > 0 && max < defaultBundleTail {
+			tail = strconv.Itoa(max)
+		}
+	}
+	if tail, err = m.logStreams.checkTail(tail); err != nil {
+		return nil, err
+	}
+	b := &LogBundle{L1: l1.L1, CreatedAt: time.Now(), Tail: tail, Nodes: []LogBundleNode{}}
+	if req.Since != "" {
+		d, err := time.ParseDuration(req.Since)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid since %q", req.Since)
+		}
+		since := b.CreatedAt.Add(-d)
+		b.Since = &since
+	}
+
+	ids := []int64{}
+	seen := map[int64]bool{}
+	for _, v := range l1.Validators {
+		if !seen[v.NodeID] {
+			seen[v.NodeID] = true
+			ids = append(ids, v.NodeID)
+		}
+	}
+	for _, p := range l1.Pending {
+		if !seen[p.NodeID] {
+			seen[p.NodeID] = true
+			ids = append(ids, p.NodeID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("L1 %q has no validators", l1.Name)
+	}
+
+	byHost := map[int64][]int{}
+	for _, id := range ids {
+		node, err := m.GetNode(ctx, id)
+		if err != nil {
+			continue
+		}
+		bn := LogBundleNode{NodeID: node.ID, Name: node.Name, AvagoNodeID: node.NodeID, Status: node.Status,
+			Image: node.Image, Version: node.Version, Files: []string{}}
+		m.pool.QueryRow(ctx, "SELECT name FROM hosts WHERE id=$1", node.HostID).Scan(&bn.Host)
+		b.Nodes = append(b.Nodes, bn)
+		byHost[node.HostID] = append(byHost[node.HostID], len(b.Nodes)-1)
+	}
+
+	var wg sync.WaitGroup
+	for hostID, idx := range byHost {
+		dc := m.clientFor(hostID)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, i := range idx {
+				bn := &b.Nodes[i]
+				if dc == nil {
+					bn.Error = "host not connected"
+					continue
+				}
+				if err := m.collectNodeLogs(ctx, dc, bn, hostID, l1.BlockchainID, b, req.SkipFiles); err != nil {
+					bn.Error = err.Error()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, bn := range b.Nodes {
+		if bn.Error != "" {
+			failed++
+		}
+	}
+	m.logEvent(ctx, "l1.logs_bundled", l1.Name,
+		fmt.Sprintf("Collected logs of %d validator(s), %d failed", len(b.Nodes)-failed, failed),
+		map[string]any{"nodes": len(b.Nodes), "failed": failed, "tail": tail})
+	return b, nil
+}
+
+// collectNodeLogs reads one node's container log and, unless skipFiles,
+// its main, P-Chain and L1 chain log files into bn.
+func (m *Manager) collectNodeLogs(ctx context.Context, dc *docker.Client, bn *LogBundleNode, hostID int64,
+	blockchainID string, b *LogBundle, skipFiles bool) error {
+	var containerID string
+	m.pool.QueryRow(ctx, "SELECT container_id FROM nodes WHERE id=$1", bn.NodeID).Scan(&containerID)
+	if containerID == "" {
+		return fmt.Errorf("node has no container")
+	}
+	release, err := m.logStreams.acquire(bn.NodeID, hostID)
+	if err != nil {
+		return err
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(ctx, bundleNodeTimeout)
+	defer cancel()
+
+	var since time.Time
+	if b.Since != nil {
+		since = *b.Since
+	}
+	var buf bytes.Buffer
+	if err := dc.WriteContainerLogs(ctx, containerID, b.Tail, since, &buf); err != nil {
+		return err
+	}
+	bn.Files = append(bn.Files, bn.Name+"/container.log")
+	bn.data = append(bn.data, buf.Bytes())
+	if skipFiles {
+		return nil
+	}
+
+	want := func(name string) bool {
+		return name == "main.log" || name == "P.log" || (blockchainID != "" && name == blockchainID+".log")
+	}
+	return dc.ReadContainerFiles(ctx, containerID, docker.LogsDir, bundleFileMaxBytes, want,
+		func(name string, r io.Reader) error {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", name, err)
+			}
+			bn.Files = append(bn.Files, bn.Name+"/avalanchego/"+name)
+			bn.data = append(bn.data, data)
+			return nil
+		})
+}
+
+// Write writes the bundle as a gzipped tar archive.
+func (b *LogBundle) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: b.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	manifest, _ := json.MarshalIndent(b, "", "  ")
+	if err := add("manifest.json", manifest); err != nil {
+		return err
+	}
+	for _, bn := range b.Nodes {
+		for i, name := range bn.Files {
+			if err := add(name, bn.data[i]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,96 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// LogLimits caps node log requests so a large tail or many followers can't
+// pin a host's dockerd (or avalauncher's memory). Zero disables a limit.
+type LogLimits struct {
+	MaxTail int // lines per request; "all" is refused when set
+	PerNode int // concurrent log streams per node
+	PerHost int // concurrent log streams per host
+}
+
+// ErrLogStreamLimit is returned when a node or host has too many open log
+// streams.
+var ErrLogStreamLimit = errors.New("too many concurrent log streams")
+
+// logStreams counts open log streams per node and host.
+type logStreams struct {
+	limits LogLimits // set before Start
+
+	mu    sync.Mutex
+	nodes map[int64]int
+	hosts map[int64]int
+}
+
+// SetLogLimits sets the caps applied to NodeLogs. Call it before Start.
+func (m *Manager) SetLogLimits(l LogLimits) {
+	m.logStreams.limits = l
+}
+
+// checkTail validates a tail value against the limit, defaulting to 100.
+func (s *logStreams) checkTail(tail string) (string, error) {
+	max := s.limits.MaxTail
+	if tail == "" {
+		tail = "100"
+	}
+	if tail == "all" {
+		if max > 0 {
+			return "", fmt.Errorf("tail=all is not allowed; request at most %d lines", max)
+		}
+		return tail, nil
+	}
+	n, err := strconv.Atoi(tail)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid tail %q", tail)
+	}
+	if max > 0 && n > max {
+		return "", fmt.Errorf("tail %d exceeds the limit of %d lines", n, max)
+	}
+	return tail, nil
+}
+
+// acquire reserves a stream slot for a node, returning its release func.
+func (s *logStreams) acquire(nodeID, hostID int64) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nodes == nil {
+		s.nodes = make(map[int64]int)
+		s.hosts = make(map[int64]int)
+	}
+	if s.limits.PerNode > 0 && s.nodes[nodeID] >= s.limits.PerNode {
+		return nil, fmt.Errorf("%w: node has %d open (limit %d)", ErrLogStreamLimit, s.nodes[nodeID], s.limits.PerNode)
+	}
+	if s.limits.PerHost > 0 && s.hosts[hostID] >= s.limits.PerHost {
+		return nil, fmt.Errorf("%w: host has %d open (limit %d)", ErrLogStreamLimit, s.hosts[hostID], s.limits.PerHost)
+	}
+	s.nodes[nodeID]++
+	s.hosts[hostID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.nodes[nodeID]--
+			s.hosts[hostID]--
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+// releasingReader frees a stream slot when the log reader is closed.
+type releasingReader struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releasingReader) Close() error {
+	r.release()
+	return r.ReadCloser.Close()
+}
@@ -3,6 +3,7 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,28 +13,85 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/docker/client"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/primal-host/avalauncher/internal/docker"
+	"github.com/primal-host/avalauncher/internal/pchain"
+	"github.com/primal-host/avalauncher/internal/secrets"
 )
 
 // Manager handles node lifecycle, health polling, and event logging.
 type Manager struct {
-	localClient *docker.Client
-	pool        *pgxpool.Pool
-	avagoImage  string
-	avagoNetwork  string // avalanche network id (mainnet, fuji, local)
-	avaxDockerNet string // docker network name
+	localClient    *docker.Client
+	pool           *pgxpool.Pool
+	avagoImage     string
+	avagoImages    map[string]string // network -> default image override
+	imagePolicy    *ImagePolicy      // nil = any image allowed
+	localGateway   string            // gateway IP of the avax network on the local host
+	avagoNetwork   string            // avalanche network id (mainnet, fuji, local)
+	avaxDockerNet  string            // docker network name
 	healthInterval time.Duration
-	localHostID int64
+	localHostID    int64
+	remoteLimits   docker.Limits // Docker API throttling for SSH hosts
+	secrets        *secrets.Box  // encrypts staking keys at rest
+
+	// Settings from the Set* methods, which are called before Start and
+	// read-only afterwards.
+	pchainKey           *pchain.PrivateKey // default key for on-chain L1 deployments
+	bootstrapAlertAfter time.Duration      // nodes bootstrapping longer than this raise an alert
+	edgeProxy           EdgeProxyConfig    // per-host Traefik with DNS-01 certificates
+	healthWorkers       int                // concurrent health checks per pass
+	healthTimeout       time.Duration      // per-node health check timeout
 
 	// Traefik integration for AvalancheGo RPC routing.
-	traefikDomain  string // e.g. "avax.primal.host" (empty = disabled)
-	traefikNetwork string // e.g. "infra"
-	traefikAuth    string // htpasswd entry for basicauth
+	traefikDomain   string // e.g. "avax.primal.host" (empty = disabled)
+	traefikNetwork  string // e.g. "infra"
+	traefikAuth     string // htpasswd entry for basicauth
+	traefikResolver string // ACME cert resolver named in router labels
 
-	clients   map[int64]*docker.Client // hostID -> client
+	clients   map[int64]*docker.Client    // hostID -> client
+	conns     map[int64]*docker.ConnStats // hostID -> link stats, kept across reconnects
 	clientsMu sync.RWMutex
 
+	reconfigs   map[int64]bool // nodeID -> reconfigure in progress; true = rerun requested
+	reconfigsMu sync.Mutex
+
+	upgradeMu sync.Mutex // serializes upgrade runners
+
+	portsMu sync.Mutex // serializes host port checks through the node insert
+
+	rejectDupIDs bool // refuse to run two nodes with one staking identity
+
+	subs   map[chan Event]struct{} // live event stream subscribers
+	subsMu sync.Mutex
+
+	pollers   map[string]*poller // name -> background loop stats
+	pollersMu sync.Mutex
+
+	logStreams logStreams // open node log streams, capped by LogLimits
+
+	diskUsage   map[int64]NodeDiskUsage // nodeID -> last volume size measurement
+	diskUsageMu sync.Mutex
+
+	health healthSchedule // per-node health check due times
+
+	eventWatches   map[int64]*eventWatch // hostID -> Docker event stream
+	expectedEvents map[string]int        // containerID -> manager-driven starts/stops in flight
+	eventsMu       sync.Mutex
+
+	execPolicy ExecPolicy // which commands ExecNode accepts
+
+	rpcDNS rpcDNS // health-based DNS for L1 RPC hostnames
+
+	imageDrift imageDrift // nodes last reported as running an outdated digest
+
+	autoHeal autoHeal // restart attempts of auto-healing nodes
+
+	snapshots snapshots // backup store and nodes being snapshotted or restored
+
+	reconciled reconcileReport // per-host results of the last reconciliation
+
 	stopPoller chan struct{}
 	pollerWg   sync.WaitGroup
 }
@@ -43,28 +101,46 @@ type TraefikConfig struct {
 	Domain  string // domain suffix, e.g. "avax.primal.host" (empty = disabled)
 	Network string // Docker network Traefik can reach, e.g. "infra"
 	Auth    string // htpasswd entry for basicauth
+
+	CertResolver string // Traefik ACME resolver named in router labels (empty = "letsencrypt-dns")
 }
 
-// New creates a Manager, ensures the Docker network, upserts the local host
-// row, and runs startup reconciliation.
-func New(ctx context.Context, dc *docker.Client, pool *pgxpool.Pool, avagoImage, avagoNetwork, avaxDockerNet string, healthInterval time.Duration, traefik TraefikConfig) (*Manager, error) {
+// New creates a Manager, ensures the Docker network and upserts the local
+// host row. Configure it with the Set* methods, then call Start.
+func New(ctx context.Context, dc *docker.Client, pool *pgxpool.Pool, avagoImage string, avagoImages map[string]string, avagoNetwork, avaxDockerNet string, healthInterval time.Duration, traefik TraefikConfig, imagePolicy *ImagePolicy, remoteLimits docker.Limits, secretBox *secrets.Box) (*Manager, error) {
 	m := &Manager{
-		localClient:    dc,
-		pool:           pool,
-		avagoImage:     avagoImage,
-		avagoNetwork:   avagoNetwork,
-		avaxDockerNet:  avaxDockerNet,
-		healthInterval: healthInterval,
-		traefikDomain:  traefik.Domain,
-		traefikNetwork: traefik.Network,
-		traefikAuth:    traefik.Auth,
-		clients:        make(map[int64]*docker.Client),
-		stopPoller:     make(chan struct{}),
+		localClient:     dc,
+		pool:            pool,
+		avagoImage:      avagoImage,
+		avagoImages:     avagoImages,
+		imagePolicy:     imagePolicy,
+		avagoNetwork:    avagoNetwork,
+		avaxDockerNet:   avaxDockerNet,
+		healthInterval:  healthInterval,
+		remoteLimits:    remoteLimits,
+		secrets:         secretBox,
+		traefikDomain:   traefik.Domain,
+		traefikNetwork:  traefik.Network,
+		traefikAuth:     traefik.Auth,
+		traefikResolver: traefik.CertResolver,
+		clients:         make(map[int64]*docker.Client),
+		conns:           make(map[int64]*docker.ConnStats),
+		reconfigs:       make(map[int64]bool),
+		subs:            make(map[chan Event]struct{}),
+		pollers:         make(map[string]*poller),
+		diskUsage:       make(map[int64]NodeDiskUsage),
+		health:          healthSchedule{next: make(map[int64]time.Time), streak: make(map[int64]int)},
+		eventWatches:    make(map[int64]*eventWatch),
+		expectedEvents:  make(map[string]int),
+		stopPoller:      make(chan struct{}),
 	}
 
 	if err := dc.EnsureNetwork(ctx, avaxDockerNet); err != nil {
 		return nil, fmt.Errorf("ensure network: %w", err)
 	}
+	if gw, err := dc.NetworkGateway(ctx, avaxDockerNet); err == nil {
+		m.localGateway = gw
+	}
 
 	// Gather host info and resolve hostname.
 	// Inside a container, both Docker info and os.Hostname() return the
@@ -95,7 +171,8 @@ func New(ctx context.Context, dc *docker.Client, pool *pgxpool.Pool, avagoImage,
 	err = pool.QueryRow(ctx, `
 		INSERT INTO hosts (name, ssh_addr, status, labels)
 		VALUES ('local', '', 'online', $1)
-		ON CONFLICT (name) DO UPDATE SET status = 'online', labels = $1, updated_at = now()
+		ON CONFLICT (name) DO UPDATE SET status = CASE WHEN hosts.status = 'maintenance' THEN hosts.status ELSE 'online' END,
+		    labels = $1, updated_at = now()
 		RETURNING id`, labelsJSON).Scan(&m.localHostID)
 	if err != nil {
 		return nil, fmt.Errorf("upsert local host: %w", err)
@@ -103,15 +180,37 @@ func New(ctx context.Context, dc *docker.Client, pool *pgxpool.Pool, avagoImage,
 
 	// Register local client.
 	m.registerClient(m.localHostID, dc)
+	m.recordAPIVersion(ctx, m.localHostID, "local", dc)
 
-	// Connect to existing remote hosts.
-	m.connectRemoteHosts(ctx)
+	return m, nil
+}
 
-	if err := m.reconcile(ctx); err != nil {
+// Start connects remote hosts, runs startup reconciliation and resumes the
+// operations, deployments, upgrades and snapshots a restart interrupted.
+// It is called once, after the Set* methods and before the pollers start,
+// so recovery sees the full configuration.
+func (m *Manager) Start() error {
+	// Remote hosts are connected and reconciled under their own per-host
+	// timeouts rather than ctx, so a hung SSH host can't use up the time
+	// the steps after it need.
+	m.connectRemoteHosts()
+	if err := m.reconcile(); err != nil {
 		slog.Warn("reconciliation error", "error", err)
 	}
 
-	return m, nil
+	rctx, cancel := context.WithTimeout(context.Background(), startupRecoveryTimeout)
+	defer cancel()
+	if err := m.resealSecrets(rctx); err != nil {
+		return fmt.Errorf("re-encrypt secrets: %w", err)
+	}
+	m.recoverOperations(rctx)
+	m.resumeQueuedOps(rctx)
+	m.recoverDeployments(rctx)
+	m.recoverConversions(rctx)
+	m.recoverUpgrades(rctx)
+	m.recoverSnapshots(rctx)
+	m.reportIdentityConflicts(rctx)
+	return nil
 }
 
 // clientFor returns the Docker client for a given host ID.
@@ -152,54 +251,124 @@ func (m *Manager) CloseClients() {
 	}
 }
 
-// connectRemoteHosts connects to all non-local online hosts from the DB.
-func (m *Manager) connectRemoteHosts(ctx context.Context) {
+// connectRemoteHosts connects to all non-local online hosts from the DB,
+// concurrently and each under hostConnectTimeout.
+func (m *Manager) connectRemoteHosts() {
+	ctx, cancel := context.WithTimeout(context.Background(), hostConnectTimeout)
+	defer cancel()
 	rows, err := m.pool.Query(ctx, `
 		SELECT id, name, ssh_addr FROM hosts
-		WHERE ssh_addr != '' AND status = 'online'`)
+		WHERE ssh_addr != '' AND status IN ('online', 'maintenance')`)
 	if err != nil {
 		slog.Warn("query remote hosts", "error", err)
 		return
 	}
-	defer rows.Close()
-
+	type remoteHost struct {
+		id            int64
+		name, sshAddr string
+	}
+	var hosts []remoteHost
 	for rows.Next() {
-		var id int64
-		var name, sshAddr string
-		if err := rows.Scan(&id, &name, &sshAddr); err != nil {
-			continue
+		var h remoteHost
+		if err := rows.Scan(&h.id, &h.name, &h.sshAddr); err == nil {
+			hosts = append(hosts, h)
 		}
-		dc, err := docker.NewSSH(sshAddr)
-		if err != nil {
-			slog.Warn("ssh connect failed", "host", name, "error", err)
-			m.pool.Exec(ctx, "UPDATE hosts SET status='unreachable', updated_at=now() WHERE id=$1", id)
-			continue
-		}
-		if err := dc.Ping(ctx); err != nil {
-			slog.Warn("ssh ping failed", "host", name, "error", err)
-			dc.Close()
-			m.pool.Exec(ctx, "UPDATE hosts SET status='unreachable', updated_at=now() WHERE id=$1", id)
-			continue
-		}
-		m.registerClient(id, dc)
-		slog.Info("connected to remote host", "host", name, "ssh", sshAddr)
 	}
+	rows.Close()
+
+	var wg sync.WaitGroup
+	for _, h := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.connectRemoteHost(h.id, h.name, h.sshAddr)
+		}()
+	}
+	wg.Wait()
+}
+
+// connectRemoteHost connects to one remote host and registers its client,
+// marking the host unreachable when that fails.
+func (m *Manager) connectRemoteHost(id int64, name, sshAddr string) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostConnectTimeout)
+	defer cancel()
+
+	stats := m.connStats(id)
+	dc, err := docker.NewSSH(sshAddr, m.remoteLimits, stats)
+	if err != nil {
+		stats.RecordError(err)
+		slog.Warn("ssh connect failed", "host", name, "error", err)
+		m.pool.Exec(ctx, "UPDATE hosts SET status='unreachable', updated_at=now() WHERE id=$1", id)
+		return
+	}
+	if err := dc.Ping(ctx); err != nil {
+		slog.Warn("ssh ping failed", "host", name, "error", err)
+		dc.Close()
+		// The ping may have used up ctx.
+		m.pool.Exec(context.Background(), "UPDATE hosts SET status='unreachable', updated_at=now() WHERE id=$1", id)
+		return
+	}
+	m.registerClient(id, dc)
+	stats.RecordConnected(false)
+	m.recordAPIVersion(ctx, id, name, dc)
+	slog.Info("connected to remote host", "host", name, "ssh", sshAddr)
 }
 
 // Node represents a node row from the database.
 type Node struct {
-	ID           int64     `json:"id"`
-	Name         string    `json:"name"`
-	HostID       int64     `json:"host_id"`
-	Image        string    `json:"image"`
-	Network      string    `json:"network"`
-	NodeID       string    `json:"node_id,omitempty"`
-	ContainerID  string    `json:"container_id,omitempty"`
-	HTTPPort     int       `json:"http_port"`
-	StakingPort  int       `json:"staking_port"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	HostID      int64             `json:"host_id"`
+	Image       string            `json:"image"`
+	Network     string            `json:"network"`
+	NodeID      string            `json:"node_id,omitempty"`
+	ContainerID string            `json:"container_id,omitempty"`
+	HTTPPort    int               `json:"http_port"`
+	StakingPort int               `json:"staking_port"`
+	ExposeHTTP  bool              `json:"expose_http,omitempty"`
+	NetworkMode string            `json:"network_mode,omitempty"`
+	DNSAliases  []string          `json:"dns_aliases,omitempty"`
+	Throttle    docker.Throttle   `json:"throttle"`
+	CPULimit    float64           `json:"cpu_limit,omitempty"`
+	MemoryLimit int64             `json:"memory_limit,omitempty"` // bytes
+	HealthCheck string            `json:"health_check,omitempty"`
+	Entrypoint  []string          `json:"entrypoint,omitempty"`
+	Cmd         []string          `json:"cmd,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Config      docker.NodeConfig `json:"config"`
+	Tags        []string          `json:"tags"`
+	Status      string            `json:"status"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+
+	// Docker restart policy of the container ("" = unless-stopped), and
+	// whether the manager restarts it, with backoff, after an exit Docker
+	// left alone.
+	RestartPolicy string `json:"restart_policy,omitempty"`
+	AutoHeal      bool   `json:"auto_heal,omitempty"`
+
+	// Primary Network staking period, set by RegisterValidator or observed
+	// by the uptime poller.
+	ValidatorTxID string     `json:"validator_tx_id,omitempty"`
+	StakingEnd    *time.Time `json:"staking_end,omitempty"`
+
+	// Manifest digest (sha256:...) of the image the container runs, or its
+	// image ID when it wasn't pulled from a registry. Unlike Image it
+	// doesn't follow a moved tag.
+	ImageDigest string `json:"image_digest,omitempty"`
+
+	// AvalancheGo version the node last reported (info.getNodeVersion),
+	// e.g. "avalanchego/1.13.0". Cleared when the container is recreated.
+	Version string `json:"version,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// containerUp reports whether a node status means its container is running:
+// bootstrapping (started, P-Chain not yet bootstrapped), running or
+// unhealthy.
+func containerUp(status string) bool {
+	return status == "bootstrapping" || status == "running" || status == "unhealthy"
 }
 
 // CreateNodeRequest holds parameters for creating a new node.
@@ -210,6 +379,67 @@ type CreateNodeRequest struct {
 	StakingPort int    `json:"staking_port"`
 	ExposeHTTP  bool   `json:"expose_http"`
 	HostID      int64  `json:"host_id"`
+
+	// Placement guides host selection when HostID is 0. Without it the
+	// least loaded connected host is used.
+	Placement Placement `json:"placement"`
+
+	// NetworkMode is "bridge" (default) or "host". Host networking skips the
+	// Docker bridge and port publishing; AvalancheGo binds StakingPort and
+	// HTTPPort directly on the host.
+	NetworkMode string `json:"network_mode"`
+
+	// HTTPPort is the host port of the HTTP API in host network mode, or
+	// the one ExposeHTTP publishes it on. When omitted, the lowest port
+	// from 9650 up that is free on the host is used.
+	HTTPPort int `json:"http_port"`
+
+	// DNSAliases are extra names for the container on the avax network
+	// (e.g. "rpc.gamefi.internal").
+	DNSAliases []string `json:"dns_aliases"`
+
+	// Throttle limits disk I/O and inbound P2P bandwidth, e.g. while the
+	// node bootstraps next to running validators.
+	Throttle docker.Throttle `json:"throttle"`
+
+	// CPULimit caps the container at this many cores (e.g. 2.5) and
+	// MemoryLimit at this much memory (e.g. "16g"), so one misbehaving
+	// node can't starve the rest of the host. Zero/empty means unlimited.
+	CPULimit    float64 `json:"cpu_limit"`
+	MemoryLimit string  `json:"memory_limit"`
+
+	// HealthCheck selects how health is probed: "auto" (default), "http",
+	// "exec", or "tcp".
+	HealthCheck string `json:"health_check"`
+
+	// RestartPolicy is Docker's restart policy for the container:
+	// "unless-stopped" (default), "on-failure[:N]" or "none". AutoHeal has
+	// the manager restart the container after an unexpected exit that
+	// Docker didn't, with exponential backoff.
+	RestartPolicy string `json:"restart_policy"`
+	AutoHeal      bool   `json:"auto_heal"`
+
+	// Optional container entrypoint/command overrides, e.g. a tini wrapper
+	// or CLI flags not exposed via AVAGO_* env vars.
+	Entrypoint []string `json:"entrypoint"`
+	Cmd        []string `json:"cmd"`
+
+	// Env adds container environment variables. Values and cmd arguments
+	// may reference managed secrets as ${secret:NAME}; they are resolved
+	// when the container is created, so the database only holds references.
+	Env map[string]string `json:"env"`
+
+	// Config carries extra AvalancheGo flags and per-chain config JSON
+	// (C-Chain, subnet-evm), persisted in nodes.node_configs.
+	Config docker.NodeConfig `json:"config"`
+
+	// Tags group nodes by purpose (rpc, validator, testnet) for filtering
+	// and the dashboard.
+	Tags []string `json:"tags"`
+
+	// TTL tears the node down automatically after this long, e.g. "24h"
+	// for preview environments. Not allowed on mainnet.
+	TTL string `json:"ttl"`
 }
 
 // CreateNode validates inputs, pulls the image, creates and starts a container,
@@ -221,12 +451,15 @@ func (m *Manager) CreateNode(ctx context.Context, req CreateNodeRequest) (*Node,
 	if req.StakingPort == 0 {
 		req.StakingPort = 9651
 	}
-	if req.Image == "" {
-		req.Image = m.avagoImage
-	}
 	if req.Network == "" {
 		req.Network = m.avagoNetwork
 	}
+	if req.Image == "" {
+		req.Image = m.DefaultImage(req.Network)
+	}
+	if err := m.checkImageAllowed(req.Image); err != nil {
+		return nil, err
+	}
 
 	// Check name uniqueness.
 	var exists bool
@@ -238,63 +471,186 @@ func (m *Manager) CreateNode(ctx context.Context, req CreateNodeRequest) (*Node,
 		return nil, fmt.Errorf("node %q already exists", req.Name)
 	}
 
-	// Resolve host ID — default to local.
+	memoryLimit, err := parseMemoryLimit(req.MemoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := docker.ParseRestartPolicy(req.RestartPolicy); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve host ID — schedule automatically when omitted.
 	hostID := req.HostID
 	if hostID == 0 {
-		hostID = m.localHostID
+		if hostID, err = m.pickHost(ctx, req.Placement, req.StakingPort, req.CPULimit, memoryLimit); err != nil {
+			return nil, err
+		}
 	}
 	if dc := m.clientFor(hostID); dc == nil {
 		return nil, fmt.Errorf("host %d not connected", hostID)
 	}
+	if err := m.checkHostSchedulable(ctx, hostID); err != nil {
+		return nil, err
+	}
+
+	switch req.NetworkMode {
+	case "", "bridge":
+		req.NetworkMode = ""
+	case "host":
+		if err := m.checkHostNetworkSupported(ctx, hostID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid network_mode %q (want bridge or host)", req.NetworkMode)
+	}
+
+	// Host networking and expose_http bind the HTTP port on the host as
+	// well, so it must be free there; a free one is picked when omitted.
+	// The lock keeps a concurrent create from taking the same ports before
+	// this node's row is inserted.
+	m.portsMu.Lock()
+	defer m.portsMu.Unlock()
+	if req.HTTPPort < 0 || req.HTTPPort > 65535 {
+		return nil, fmt.Errorf("http_port must be between 1 and 65535")
+	}
+	hostPorts := []int{req.StakingPort}
+	if bindsHTTPPort(req.NetworkMode, req.ExposeHTTP) {
+		if req.HTTPPort == 0 {
+			if req.HTTPPort, err = m.freeHTTPPort(ctx, hostID, req.StakingPort); err != nil {
+				return nil, err
+			}
+		}
+		if req.HTTPPort == req.StakingPort {
+			return nil, fmt.Errorf("http_port and staking_port must differ")
+		}
+		hostPorts = append(hostPorts, req.HTTPPort)
+	}
+	if req.HTTPPort == 0 {
+		req.HTTPPort = 9650
+	}
 
-	// Check staking port conflicts scoped to host.
-	err = m.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM nodes WHERE host_id=$1 AND staking_port=$2 AND status NOT IN ('stopped','failed'))", hostID, req.StakingPort).Scan(&exists)
+	if len(req.DNSAliases) > 0 {
+		for i, a := range req.DNSAliases {
+			req.DNSAliases[i] = strings.ToLower(strings.TrimSpace(a))
+		}
+		if req.NetworkMode == "host" {
+			return nil, fmt.Errorf("dns_aliases are not supported in host network mode")
+		}
+		if err := m.validateDNSAliases(ctx, hostID, 0, req.DNSAliases); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := req.Throttle.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := m.checkResourceLimits(ctx, hostID, req.CPULimit, memoryLimit); err != nil {
+		return nil, err
+	}
+	if req.HealthCheck, err = parseHealthCheck(req.HealthCheck); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateEnv(ctx, req.Env, req.Cmd); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := parseTTL(req.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil && req.Network == "mainnet" {
+		return nil, fmt.Errorf("ttl is not allowed on mainnet nodes")
+	}
+
+	// Check host port conflicts scoped to host.
+	if err := m.checkPortConflicts(ctx, hostID, 0, hostPorts); err != nil {
+		return nil, err
+	}
+
+	// Generate the node's staking identity up front so it survives
+	// container recreation.
+	keys, err := generateStakingKeys()
 	if err != nil {
-		return nil, fmt.Errorf("check port: %w", err)
+		return nil, err
 	}
-	if exists {
-		return nil, fmt.Errorf("staking port %d already in use on this host", req.StakingPort)
+	id, err := m.reserveNodeID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("allocate node id: %w", err)
+	}
+	if err := m.sealKeys(id, keys); err != nil {
+		return nil, fmt.Errorf("encrypt staking keys: %w", err)
 	}
 
 	// Insert node in creating state.
-	var node Node
-	err = m.pool.QueryRow(ctx, `
-		INSERT INTO nodes (name, host_id, image, network, staking_port, status)
-		VALUES ($1, $2, $3, $4, $5, 'creating')
-		RETURNING id, name, host_id, image, network, node_id, container_id, http_port, staking_port, status, created_at, updated_at`,
-		req.Name, hostID, req.Image, req.Network, req.StakingPort,
-	).Scan(&node.ID, &node.Name, &node.HostID, &node.Image, &node.Network, &node.NodeID,
-		&node.ContainerID, &node.HTTPPort, &node.StakingPort, &node.Status,
-		&node.CreatedAt, &node.UpdatedAt)
+	node, err := scanNode(m.pool.QueryRow(ctx, `
+		INSERT INTO nodes (id, name, host_id, image, network, http_port, staking_port, expose_http, network_mode, dns_aliases, entrypoint, cmd, env, node_configs,
+		                   throttle, cpu_limit, memory_limit, health_check, staking_cert, staking_key, staking_signer, expires_at, tags,
+		                   restart_policy, auto_heal, status)
+		OVERRIDING SYSTEM VALUE
+		VALUES ($23, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $24, $25, 'creating')
+		RETURNING `+nodeColumns,
+		req.Name, hostID, req.Image, req.Network, req.HTTPPort, req.StakingPort, req.ExposeHTTP, req.NetworkMode,
+		nonNil(req.DNSAliases), nonNil(req.Entrypoint), nonNil(req.Cmd), nonNilMap(req.Env), req.Config, req.Throttle, req.CPULimit, memoryLimit,
+		req.HealthCheck, keys.Cert, keys.Key, keys.Signer, expiresAt, tags, id, req.RestartPolicy, req.AutoHeal,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("insert node: %w", err)
 	}
 
-	m.logEvent(ctx, "node.creating", node.Name, "Creating node", nil)
+	m.logEvent(ctx, "node.creating", node.Name, "Creating node", map[string]any{
+		"host_id": hostID, "staking_port": req.StakingPort, "http_port": req.HTTPPort, "network_mode": req.NetworkMode,
+	})
 
-	// Pull + create + start in background.
-	go m.provisionNode(node.ID, hostID, req)
+	// Pull + create + start in background. The operation is journaled
+	// first so a restart before it runs still finds and resumes it.
+	opID := m.beginOp(ctx, OpProvision, node.ID, req)
+	go m.provisionNode(opID, node.ID, hostID, req)
 
-	return &node, nil
+	return node, nil
 }
 
-// provisionNode pulls the image, creates and starts the container.
-func (m *Manager) provisionNode(nodeID int64, hostID int64, req CreateNodeRequest) {
+// provisionNode pulls the image, creates and starts the container, as
+// journaled operation opID. Docker failures are retried (see retryOp)
+// before the node is marked failed.
+func (m *Manager) provisionNode(opID, nodeID, hostID int64, req CreateNodeRequest) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	dc := m.clientFor(hostID)
-	if dc == nil {
-		slog.Error("no client for host", "host_id", hostID, "node", req.Name)
-		return
-	}
-
+	// Status and failure writes get their own context: a pull that used up
+	// ctx must still leave the node failed or its retry queued.
 	setStatus := func(status, msg string) {
-		_, err := m.pool.Exec(ctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2", status, nodeID)
+		sctx, scancel := context.WithTimeout(context.Background(), opFailTimeout)
+		defer scancel()
+		_, err := m.pool.Exec(sctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2", status, nodeID)
 		if err != nil {
 			slog.Error("update node status", "error", err, "node_id", nodeID)
 		}
-		m.logEvent(ctx, "node."+status, req.Name, msg, nil)
+		m.logEvent(sctx, "node."+status, req.Name, msg, nil)
+		if status == "failed" {
+			m.finishOp(sctx, opID, msg)
+		}
+	}
+	fail := func(msg string) {
+		fctx, fcancel := context.WithTimeout(context.Background(), opFailTimeout)
+		defer fcancel()
+		if !m.retryOp(fctx, opID, req.Name, msg) {
+			setStatus("failed", msg)
+		}
+	}
+
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		slog.Error("no client for host", "host_id", hostID, "node", req.Name)
+		fail("Host not connected")
+		return
 	}
 
 	// Pull image.
@@ -302,25 +658,49 @@ func (m *Manager) provisionNode(nodeID int64, hostID int64, req CreateNodeReques
 	reader, err := dc.PullImage(ctx, req.Image)
 	if err != nil {
 		slog.Error("pull image failed", "error", err, "node", req.Name)
-		setStatus("failed", fmt.Sprintf("Image pull failed: %v", err))
+		fail(fmt.Sprintf("Image pull failed: %v", err))
 		return
 	}
 	// Consume pull output to completion.
 	io.Copy(io.Discard, reader)
 	reader.Close()
 	slog.Info("image pulled", "image", req.Image, "node", req.Name)
+	m.opStep(ctx, opID, "pulled")
+
+	env, cmd, err := m.renderEnv(ctx, req.Env, req.Cmd)
+	if err != nil {
+		slog.Error("render env failed", "error", err, "node", req.Name)
+		setStatus("failed", fmt.Sprintf("Env render failed: %v", err))
+		return
+	}
+
+	// Already validated by CreateNode.
+	memoryLimit, _ := parseMemoryLimit(req.MemoryLimit)
 
 	// Build container config.
 	params := &docker.AvagoParams{
-		Name:           req.Name,
-		Image:          req.Image,
-		NetworkName:    m.avaxDockerNet,
-		NetworkID:      req.Network,
-		StakingPort:    req.StakingPort,
-		ExposeHTTP:     req.ExposeHTTP,
-		TraefikDomain:  m.traefikDomain,
-		TraefikNetwork: m.traefikNetwork,
-		TraefikAuth:    m.traefikAuth,
+		Name:            req.Name,
+		Image:           req.Image,
+		NetworkName:     m.avaxDockerNet,
+		NetworkID:       req.Network,
+		StakingPort:     req.StakingPort,
+		ExposeHTTP:      req.ExposeHTTP,
+		HTTPBindIP:      m.httpBindIP(hostID),
+		HostNetwork:     req.NetworkMode == "host",
+		HTTPPort:        req.HTTPPort,
+		DNSAliases:      req.DNSAliases,
+		Entrypoint:      req.Entrypoint,
+		Cmd:             cmd,
+		Env:             env,
+		Config:          req.Config,
+		Throttle:        req.Throttle,
+		CPULimit:        req.CPULimit,
+		MemoryLimit:     memoryLimit,
+		RestartPolicy:   req.RestartPolicy,
+		TraefikDomain:   m.traefikDomain,
+		TraefikNetwork:  m.traefikNetwork,
+		TraefikAuth:     m.traefikAuth,
+		TraefikResolver: m.traefikResolver,
 	}
 	cc, hc, nc := params.BuildContainerConfig()
 
@@ -329,32 +709,42 @@ func (m *Manager) provisionNode(nodeID int64, hostID int64, req CreateNodeReques
 	containerID, err := dc.ContainerCreate(ctx, containerName, cc, hc, nc)
 	if err != nil {
 		slog.Error("create container failed", "error", err, "node", req.Name)
-		setStatus("failed", fmt.Sprintf("Container create failed: %v", err))
+		fail(fmt.Sprintf("Container create failed: %v", err))
 		return
 	}
+	if err := m.installStakingKeys(ctx, dc, nodeID, containerID); err != nil {
+		slog.Error("install staking keys failed", "error", err, "node", req.Name)
+		fail(fmt.Sprintf("Staking key install failed: %v", err))
+		return
+	}
+	if err := m.installL1Genesis(ctx, dc, nodeID, containerID); err != nil {
+		slog.Warn("install L1 genesis failed", "error", err, "node", req.Name)
+	}
 
 	// Update container_id.
-	_, err = m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, updated_at=now() WHERE id=$2", containerID, nodeID)
+	_, err = m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, avago_version='', updated_at=now() WHERE id=$2", containerID, nodeID)
 	if err != nil {
 		slog.Error("update container_id", "error", err, "node_id", nodeID)
 	}
+	m.recordImageDigest(ctx, dc, nodeID, req.Image, containerID)
+	m.opStep(ctx, opID, "created")
 
 	// Start container.
 	if err := dc.ContainerStart(ctx, containerID); err != nil {
 		slog.Error("start container failed", "error", err, "node", req.Name)
-		setStatus("failed", fmt.Sprintf("Container start failed: %v", err))
+		fail(fmt.Sprintf("Container start failed: %v", err))
 		return
 	}
 
-	setStatus("running", "Node started")
+	setStatus("bootstrapping", "Node started, bootstrapping")
+	m.opStep(ctx, opID, "started")
+	m.finishOp(ctx, opID, "")
 	slog.Info("node started", "node", req.Name, "container", containerID[:12])
 }
 
 // ListNodes returns all nodes.
 func (m *Manager) ListNodes(ctx context.Context) ([]Node, error) {
-	rows, err := m.pool.Query(ctx, `
-		SELECT id, name, host_id, image, network, node_id, container_id, http_port, staking_port, status, created_at, updated_at
-		FROM nodes ORDER BY id`)
+	rows, err := m.pool.Query(ctx, `SELECT `+nodeColumns+` FROM nodes ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
@@ -362,32 +752,129 @@ func (m *Manager) ListNodes(ctx context.Context) ([]Node, error) {
 
 	var nodes []Node
 	for rows.Next() {
-		var n Node
-		if err := rows.Scan(&n.ID, &n.Name, &n.HostID, &n.Image, &n.Network, &n.NodeID,
-			&n.ContainerID, &n.HTTPPort, &n.StakingPort, &n.Status,
-			&n.CreatedAt, &n.UpdatedAt); err != nil {
+		n, err := scanNode(rows)
+		if err != nil {
 			return nil, err
 		}
-		nodes = append(nodes, n)
+		nodes = append(nodes, *n)
 	}
 	return nodes, rows.Err()
 }
 
+// NodeFilter narrows QueryNodes. Zero fields match everything.
+type NodeFilter struct {
+	Status  string
+	HostID  int64
+	Network string
+	Tags    []string // nodes must carry every tag
+	Limit   int      // 0 = no limit
+	Offset  int
+}
+
+// QueryNodes returns the nodes matching f, ordered by ID, and how many
+// match in all.
+func (m *Manager) QueryNodes(ctx context.Context, f NodeFilter) ([]Node, int, error) {
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := " WHERE true"
+	if f.Status != "" {
+		where += " AND status = " + arg(f.Status)
+	}
+	if f.HostID != 0 {
+		where += " AND host_id = " + arg(f.HostID)
+	}
+	if f.Network != "" {
+		where += " AND network = " + arg(f.Network)
+	}
+	if len(f.Tags) > 0 {
+		tags := make([]string, len(f.Tags))
+		for i, t := range f.Tags {
+			tags[i] = strings.ToLower(strings.TrimSpace(t))
+		}
+		want, _ := json.Marshal(tags)
+		where += " AND tags @> " + arg(string(want)) + "::jsonb"
+	}
+
+	var total int
+	if err := m.pool.QueryRow(ctx, "SELECT count(*) FROM nodes"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query := `SELECT ` + nodeColumns + ` FROM nodes` + where + " ORDER BY id"
+	if f.Limit > 0 {
+		query += " LIMIT " + arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += " OFFSET " + arg(f.Offset)
+	}
+	rows, err := m.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	nodes := []Node{}
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		nodes = append(nodes, *n)
+	}
+	return nodes, total, rows.Err()
+}
+
+// ErrNodeNotFound is returned by node operations given an unknown node ID.
+var ErrNodeNotFound = errors.New("node not found")
+
 // GetNode returns a single node by ID.
 func (m *Manager) GetNode(ctx context.Context, id int64) (*Node, error) {
+	n, err := scanNode(m.pool.QueryRow(ctx, `SELECT `+nodeColumns+` FROM nodes WHERE id=$1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNodeNotFound
+	}
+	return n, err
+}
+
+// nodeColumns is the column list matching scanNode.
+const nodeColumns = `id, name, host_id, image, network, node_id, container_id, http_port, staking_port, expose_http,
+	network_mode, dns_aliases, entrypoint, cmd, env, node_configs, tags, throttle, cpu_limit, memory_limit, health_check, restart_policy, auto_heal, status, expires_at, validator_tx_id, staking_end, image_digest, avago_version, created_at, updated_at`
+
+// rowScanner is satisfied by pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanNode scans a row selected with nodeColumns.
+func scanNode(row rowScanner) (*Node, error) {
 	var n Node
-	err := m.pool.QueryRow(ctx, `
-		SELECT id, name, host_id, image, network, node_id, container_id, http_port, staking_port, status, created_at, updated_at
-		FROM nodes WHERE id=$1`, id).
-		Scan(&n.ID, &n.Name, &n.HostID, &n.Image, &n.Network, &n.NodeID,
-			&n.ContainerID, &n.HTTPPort, &n.StakingPort, &n.Status,
-			&n.CreatedAt, &n.UpdatedAt)
+	err := row.Scan(&n.ID, &n.Name, &n.HostID, &n.Image, &n.Network, &n.NodeID,
+		&n.ContainerID, &n.HTTPPort, &n.StakingPort, &n.ExposeHTTP, &n.NetworkMode, &n.DNSAliases, &n.Entrypoint, &n.Cmd, &n.Env, &n.Config, &n.Tags, &n.Throttle, &n.CPULimit, &n.MemoryLimit, &n.HealthCheck, &n.RestartPolicy, &n.AutoHeal, &n.Status,
+		&n.ExpiresAt, &n.ValidatorTxID, &n.StakingEnd, &n.ImageDigest, &n.Version, &n.CreatedAt, &n.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return &n, nil
 }
 
+// nonNil returns s, or an empty slice if s is nil, for NOT NULL array columns.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nonNilMap returns an empty map for nil so JSONB columns get '{}'.
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
 // StartNode starts a stopped node's container.
 func (m *Manager) StartNode(ctx context.Context, id int64) error {
 	node, err := m.GetNode(ctx, id)
@@ -397,19 +884,26 @@ func (m *Manager) StartNode(ctx context.Context, id int64) error {
 	if node.ContainerID == "" {
 		return fmt.Errorf("node %q has no container", node.Name)
 	}
-	if node.Status == "running" {
+	if node.Status == "running" || node.Status == "bootstrapping" {
 		return fmt.Errorf("node %q is already running", node.Name)
 	}
+	if err := m.checkIdentityFree(ctx, *node); err != nil {
+		return err
+	}
+	if err := m.checkStartPorts(ctx, *node); err != nil {
+		return fmt.Errorf("start node %q: %w", node.Name, err)
+	}
 
 	dc := m.clientFor(node.HostID)
-	if dc == nil {
-		return fmt.Errorf("host %d not connected", node.HostID)
+	if dc == nil || m.hostOffline(ctx, node.HostID) {
+		return fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
 	}
+	defer m.expectContainerEvents(node.ContainerID)()
 	if err := dc.ContainerStart(ctx, node.ContainerID); err != nil {
 		return fmt.Errorf("start container: %w", err)
 	}
 
-	_, err = m.pool.Exec(ctx, "UPDATE nodes SET status='running', updated_at=now() WHERE id=$1", id)
+	_, err = m.pool.Exec(ctx, "UPDATE nodes SET status='bootstrapping', updated_at=now() WHERE id=$1", id)
 	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
@@ -431,9 +925,10 @@ func (m *Manager) StopNode(ctx context.Context, id int64) error {
 	}
 
 	dc := m.clientFor(node.HostID)
-	if dc == nil {
-		return fmt.Errorf("host %d not connected", node.HostID)
+	if dc == nil || m.hostOffline(ctx, node.HostID) {
+		return fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
 	}
+	defer m.expectContainerEvents(node.ContainerID)()
 	if err := dc.ContainerStop(ctx, node.ContainerID, 30); err != nil {
 		return fmt.Errorf("stop container: %w", err)
 	}
@@ -446,20 +941,22 @@ func (m *Manager) StopNode(ctx context.Context, id int64) error {
 	return nil
 }
 
-// DeleteNode stops and removes a node's container and DB row.
-func (m *Manager) DeleteNode(ctx context.Context, id int64, removeVolumes bool) error {
+// DeleteNode stops and removes a node's container and DB row. See
+// NodeDependencies for what must be resolved or acknowledged first.
+func (m *Manager) DeleteNode(ctx context.Context, id int64, opts DeleteNodeOptions) error {
 	node, err := m.GetNode(ctx, id)
 	if err != nil {
 		return fmt.Errorf("get node: %w", err)
 	}
+	removeVolumes := opts.RemoveVolumes
 
-	// Check for L1 validator assignments.
-	var valCount int64
-	if err := m.pool.QueryRow(ctx, "SELECT count(*) FROM l1_validators WHERE node_id=$1", id).Scan(&valCount); err != nil {
-		return fmt.Errorf("check validators: %w", err)
+	// L1 validator assignments block unless cascaded or reassigned;
+	// everything else must be acknowledged.
+	if err := m.checkDeleteAllowed(ctx, id, opts); err != nil {
+		return err
 	}
-	if valCount > 0 {
-		return fmt.Errorf("node has %d L1 validator assignment(s) — remove them first", valCount)
+	if err := m.handOffValidators(ctx, node, opts); err != nil {
+		return err
 	}
 
 	if node.ContainerID != "" {
@@ -468,10 +965,11 @@ func (m *Manager) DeleteNode(ctx context.Context, id int64, removeVolumes bool)
 			return fmt.Errorf("host %d not connected", node.HostID)
 		}
 		// Stop if running (ignore errors — may already be stopped).
+		defer m.expectContainerEvents(node.ContainerID)()
 		_ = dc.ContainerStop(ctx, node.ContainerID, 10)
 		if err := dc.ContainerRemove(ctx, node.ContainerID, removeVolumes); err != nil {
 			// If container not found, that's fine.
-			if !strings.Contains(err.Error(), "No such container") {
+			if !client.IsErrNotFound(err) {
 				return fmt.Errorf("remove container: %w", err)
 			}
 		}
@@ -487,8 +985,10 @@ func (m *Manager) DeleteNode(ctx context.Context, id int64, removeVolumes bool)
 	return nil
 }
 
-// NodeLogs returns a reader for the node's container logs.
-func (m *Manager) NodeLogs(ctx context.Context, id int64, tail string) (io.ReadCloser, error) {
+// NodeLogs returns a reader for the node's container logs. The tail and the
+// number of open streams are capped by LogLimits; closing the reader frees
+// its slot.
+func (m *Manager) NodeLogs(ctx context.Context, id int64, tail string, follow bool) (io.ReadCloser, error) {
 	node, err := m.GetNode(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get node: %w", err)
@@ -496,283 +996,408 @@ func (m *Manager) NodeLogs(ctx context.Context, id int64, tail string) (io.ReadC
 	if node.ContainerID == "" {
 		return nil, fmt.Errorf("node %q has no container", node.Name)
 	}
-	if tail == "" {
-		tail = "100"
+	tail, err = m.logStreams.checkTail(tail)
+	if err != nil {
+		return nil, err
 	}
 	dc := m.clientFor(node.HostID)
 	if dc == nil {
 		return nil, fmt.Errorf("host %d not connected", node.HostID)
 	}
-	return dc.ContainerLogs(ctx, node.ContainerID, tail)
+	release, err := m.logStreams.acquire(node.ID, node.HostID)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := dc.ContainerLogs(ctx, node.ContainerID, tail, follow)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releasingReader{ReadCloser: reader, release: release}, nil
 }
 
 // Event represents an audit event row.
 type Event struct {
 	ID        int64          `json:"id"`
 	EventType string         `json:"event_type"`
+	Severity  string         `json:"severity"` // info, warning, error or critical
 	Target    string         `json:"target"`
 	Message   string         `json:"message"`
 	Details   map[string]any `json:"details,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
 }
 
-// ListEvents returns recent events.
-func (m *Manager) ListEvents(ctx context.Context, limit int) ([]Event, error) {
-	if limit <= 0 {
-		limit = 50
+// EventFilter narrows ListEvents. Zero fields match everything.
+type EventFilter struct {
+	MinSeverity string
+	EventType   string // exact, or a prefix ending in "*", e.g. "node.*"
+	Target      string
+	Since       time.Time
+	Until       time.Time
+	Limit       int // default 50, max 1000
+	Offset      int
+}
+
+// ListEvents returns events matching f, newest first, and how many match
+// in all.
+func (m *Manager) ListEvents(ctx context.Context, f EventFilter) ([]Event, int, error) {
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	if f.Limit > 1000 {
+		f.Limit = 1000
+	}
+	if f.MinSeverity != "" {
+		if err := CheckSeverity(f.MinSeverity); err != nil {
+			return nil, 0, err
+		}
+	}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	where := " WHERE severity = ANY(" + arg(severitiesAtLeast(f.MinSeverity)) + ")"
+	if prefix, ok := strings.CutSuffix(f.EventType, "*"); ok {
+		where += " AND starts_with(event_type, " + arg(prefix) + ")"
+	} else if f.EventType != "" {
+		where += " AND event_type = " + arg(f.EventType)
+	}
+	if f.Target != "" {
+		where += " AND target = " + arg(f.Target)
+	}
+	if !f.Since.IsZero() {
+		where += " AND created_at >= " + arg(f.Since)
+	}
+	if !f.Until.IsZero() {
+		where += " AND created_at < " + arg(f.Until)
+	}
+
+	var total int
+	if err := m.pool.QueryRow(ctx, "SELECT count(*) FROM events"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 	rows, err := m.pool.Query(ctx, `
-		SELECT id, event_type, target, message, details, created_at
-		FROM events ORDER BY created_at DESC LIMIT $1`, limit)
+		SELECT id, event_type, severity, target, message, details, created_at
+		FROM events`+where+" ORDER BY created_at DESC, id DESC LIMIT "+arg(f.Limit)+" OFFSET "+arg(f.Offset),
+		args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var events []Event
+	events := []Event{}
 	for rows.Next() {
 		var e Event
 		var details []byte
-		if err := rows.Scan(&e.ID, &e.EventType, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
-			return nil, err
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
+			return nil, 0, err
 		}
 		if len(details) > 0 {
 			json.Unmarshal(details, &e.Details)
 		}
 		events = append(events, e)
 	}
-	return events, rows.Err()
+	return events, total, rows.Err()
+}
+
+// NodeEvents returns a node's events matching f, newest first, and how many
+// match in all. Events are joined by target name; those from before the
+// node was created belong to an earlier node of the same name and are left
+// out.
+func (m *Manager) NodeEvents(ctx context.Context, id int64, f EventFilter) ([]Event, int, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	f.Target = node.Name
+	if f.Since.Before(node.CreatedAt) {
+		f.Since = node.CreatedAt
+	}
+	return m.ListEvents(ctx, f)
 }
 
 // StartHealthPoller begins a background loop that checks running nodes.
+// It ticks at the fast interval; each node is checked when it is due (see
+// healthIntervals).
 func (m *Manager) StartHealthPoller() {
-	m.pollerWg.Add(1)
-	go func() {
-		defer m.pollerWg.Done()
-		ticker := time.NewTicker(m.healthInterval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-m.stopPoller:
-				return
-			case <-ticker.C:
-				m.pollHealth()
-			}
-		}
-	}()
-	slog.Info("health poller started", "interval", m.healthInterval)
+	fast, base, slow := m.healthIntervals()
+	m.startPoller("health", fast, m.pollHealth)
+	slog.Info("health poller started", "fast", fast, "interval", base, "stable", slow)
 }
 
 // StopHealthPoller stops the background health check loop.
 func (m *Manager) StopHealthPoller() {
 	close(m.stopPoller)
 	m.pollerWg.Wait()
+	m.closeSubscribers()
 	slog.Info("health poller stopped")
 }
 
-func (m *Manager) pollHealth() {
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
+// healthResult is one node's outcome in a health poll.
+type healthResult struct {
+	node      Node
+	health    NodeHealth
+	newStatus string
+}
 
-	nodes, err := m.ListNodes(ctx)
+func (m *Manager) pollHealth() (checked, failed int) {
+	listCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	nodes, err := m.ListNodes(listCtx)
+	cancel()
 	if err != nil {
 		slog.Error("poll health: list nodes", "error", err)
-		return
+		return 0, 1
 	}
+	now := time.Now()
 
+	listed := make(map[int64]bool, len(nodes))
+	var due []Node
 	for _, node := range nodes {
-		if node.Status != "running" && node.Status != "unhealthy" {
+		listed[node.ID] = true
+		if !containerUp(node.Status) {
+			m.health.forget(node.ID)
 			continue
 		}
 		if node.ContainerID == "" {
+			m.health.forget(node.ID)
 			continue
 		}
+		if m.healthDue(node.ID, now) {
+			due = append(due, node)
+		}
+	}
+
+	// Checks fan out over a bounded pool, each under its own timeout, so a
+	// few slow hosts don't stall the pass; results are written afterwards.
+	workers, timeout := m.healthWorkers, m.healthTimeout
+	if workers <= 0 {
+		workers = defaultHealthWorkers
+	}
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+	results := make([]healthResult, len(due))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, node := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			results[i] = m.probeHealthStatus(ctx, node)
+		}()
+	}
+	wg.Wait()
 
-		healthy := m.checkNodeHealth(ctx, node)
-		newStatus := node.Status
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	sweep := now.Sub(m.health.lastSweep) >= m.healthInterval
+	if sweep {
+		m.pruneLatency(ctx)
+	}
 
-		if healthy && node.Status == "unhealthy" {
-			newStatus = "running"
-		} else if !healthy && node.Status == "running" {
-			// Check if container is actually running.
-			dc := m.clientFor(node.HostID)
-			if dc == nil {
-				newStatus = "unhealthy"
-			} else {
-				info, err := dc.ContainerInspect(ctx, node.ContainerID)
-				if err != nil || !info.State.Running {
-					newStatus = "stopped"
-				} else {
-					newStatus = "unhealthy"
-				}
-			}
+	for _, r := range results {
+		node, healthy, newStatus := r.node, r.health.Healthy, r.newStatus
+		checked++
+		if !healthy {
+			failed++
 		}
+		m.recordHealth(ctx, node.ID, r.health)
 
 		if newStatus != node.Status {
-			_, err := m.pool.Exec(ctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2", newStatus, node.ID)
+			// The status may have moved on while the checks ran (a stop, a
+			// Docker event); only the status that was checked is replaced.
+			tag, err := m.pool.Exec(ctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2 AND status=$3", newStatus, node.ID, node.Status)
 			if err != nil {
 				slog.Error("update node health status", "error", err, "node", node.Name)
+			} else if tag.RowsAffected() > 0 {
+				m.logEventAt(ctx, statusSeverity(newStatus), "node.health", node.Name, fmt.Sprintf("Status changed: %s → %s", node.Status, newStatus), nil)
+				m.recordNodeHistory(ctx, node.ID)
+				// The fallback for exits the Docker event stream missed.
+				if newStatus == "stopped" && node.AutoHeal {
+					m.scheduleAutoHeal(&node)
+				}
 			}
-			m.logEvent(ctx, "node.health", node.Name, fmt.Sprintf("Status changed: %s → %s", node.Status, newStatus), nil)
 		}
+		m.recordHealthCheck(node.ID, healthy, newStatus != node.Status, now)
 
-		// Fetch node ID if we don't have it yet and the node is healthy.
+		// Fetch node ID and version if we don't have them yet and the node
+		// is healthy.
 		if healthy && node.NodeID == "" {
 			m.fetchAndStoreNodeID(ctx, node)
 		}
+		if healthy && node.Version == "" {
+			m.fetchAndStoreVersion(ctx, node)
+		}
 	}
-}
 
-func (m *Manager) checkNodeHealth(ctx context.Context, node Node) bool {
-	containerName := "avax-" + node.Name
-	url := fmt.Sprintf("http://%s:9650/ext/health", containerName)
-
-	body := `{"jsonrpc":"2.0","id":1,"method":"health.health"}`
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(body))
-	if err != nil {
-		return false
+	for id := range m.health.next {
+		if !listed[id] {
+			m.health.forget(id)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
+	m.health.warm = true
 
-	if resp.StatusCode != http.StatusOK {
-		return false
+	if sweep {
+		m.health.lastSweep = now
+		m.applyPendingValidators(ctx)
 	}
+	m.syncRPCDNS()
+	return checked, failed
+}
 
-	var result struct {
-		Result struct {
-			Healthy bool `json:"healthy"`
-		} `json:"result"`
+// probeHealthStatus checks a node and derives its new status. A
+// bootstrapping node becomes "running" (or "unhealthy") once its P-Chain is
+// bootstrapped; failing health checks don't count against it until then. A
+// failing running node is "unhealthy" while its container runs and "stopped"
+// once the container is gone.
+func (m *Manager) probeHealthStatus(ctx context.Context, node Node) healthResult {
+	r := healthResult{node: node, health: m.probeNodeHealth(ctx, node), newStatus: node.Status}
+	if node.Status == "bootstrapping" {
+		if booted, err := m.pChainBootstrapped(ctx, node); err == nil && booted {
+			r.newStatus = "running"
+			if !r.health.Healthy {
+				r.newStatus = "unhealthy"
+			}
+		} else if dc := m.clientFor(node.HostID); dc != nil {
+			if info, err := dc.ContainerInspect(ctx, node.ContainerID); err != nil || !info.State.Running {
+				r.newStatus = "stopped"
+			}
+		}
+	} else if r.health.Healthy && node.Status == "unhealthy" {
+		r.newStatus = "running"
+	} else if !r.health.Healthy && node.Status == "running" {
+		// Check if container is actually running.
+		dc := m.clientFor(node.HostID)
+		if dc == nil {
+			r.newStatus = "unhealthy"
+		} else {
+			info, err := dc.ContainerInspect(ctx, node.ContainerID)
+			if err != nil || !info.State.Running {
+				r.newStatus = "stopped"
+			} else {
+				r.newStatus = "unhealthy"
+			}
+		}
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false
+	return r
+}
+
+// checkNodeHealth probes a node with its health check method, stores the
+// full result in node_health, and reports whether it is healthy.
+func (m *Manager) checkNodeHealth(ctx context.Context, node Node) bool {
+	h := m.probeNodeHealth(ctx, node)
+	m.recordHealth(ctx, node.ID, h)
+	return h.Healthy
+}
+
+// probeNodeHealth runs the node's health check method.
+func (m *Manager) probeNodeHealth(ctx context.Context, node Node) NodeHealth {
+	switch node.HealthCheck {
+	case HealthExec:
+		return m.checkExecHealth(ctx, node)
+	case HealthTCP:
+		return m.checkTCPHealth(ctx, node)
+	case HealthHTTP:
+		return m.checkHTTPHealth(ctx, node)
+	default:
+		return m.checkAutoHealth(ctx, node)
 	}
-	return result.Result.Healthy
 }
 
-func (m *Manager) fetchAndStoreNodeID(ctx context.Context, node Node) {
-	containerName := "avax-" + node.Name
-	url := fmt.Sprintf("http://%s:9650/ext/info", containerName)
+// checkHTTPHealth calls health.health on the node's HTTP API.
+func (m *Manager) checkHTTPHealth(ctx context.Context, node Node) NodeHealth {
+	h := NodeHealth{Method: HealthHTTP}
+	url := m.nodeBaseURL(ctx, node) + "/ext/health"
 
-	body := `{"jsonrpc":"2.0","id":1,"method":"info.getNodeID"}`
+	body := `{"jsonrpc":"2.0","id":1,"method":"health.health"}`
 	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(body))
 	if err != nil {
-		return
+		h.Error = err.Error()
+		return h
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return
+		m.recordLatency(ctx, node.ID, "health.health", time.Since(start), false)
+		h.Error = err.Error()
+		h.unreachable = ctx.Err() == nil
+		return h
 	}
 	defer resp.Body.Close()
+	m.recordLatency(ctx, node.ID, "health.health", time.Since(start), true)
 
-	var result struct {
-		Result struct {
-			NodeID string `json:"nodeID"`
-		} `json:"result"`
+	// Unhealthy nodes answer 503 with the same body, so it is read either way.
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		h.Error = err.Error()
+		return h
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return
+	if err := h.parse(raw); err != nil {
+		h.Error = fmt.Sprintf("HTTP %d: %v", resp.StatusCode, err)
+		return h
 	}
-	if result.Result.NodeID == "" {
-		return
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		h.Healthy = false
+		h.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
 	}
+	return h
+}
 
-	_, err = m.pool.Exec(ctx, "UPDATE nodes SET node_id=$1, updated_at=now() WHERE id=$2", result.Result.NodeID, node.ID)
+// fetchAndStoreNodeID records the NodeID a node reports. Like every node
+// RPC it goes through the node's container when the control plane can't
+// reach the API, so remote nodes are identified too.
+func (m *Manager) fetchAndStoreNodeID(ctx context.Context, node Node) {
+	nodeID, err := m.queryNodeID(ctx, node)
 	if err != nil {
-		slog.Error("store node_id", "error", err, "node", node.Name)
+		slog.Debug("fetch node ID", "error", err, "node", node.Name)
 		return
 	}
-	slog.Info("discovered node ID", "node", node.Name, "node_id", result.Result.NodeID)
-	m.logEvent(ctx, "node.identified", node.Name, "Node ID: "+result.Result.NodeID, nil)
+	if nodeID == "" {
+		return
+	}
+	if err := m.storeNodeID(ctx, node, nodeID); err != nil {
+		slog.Error("store node_id", "error", err, "node", node.Name)
+	}
 }
 
-// reconcile syncs DB node statuses with actual Docker container states.
-func (m *Manager) reconcile(ctx context.Context) error {
-	slog.Info("running startup reconciliation")
-
-	// Build container state map per host.
-	m.clientsMu.RLock()
-	hostClients := make(map[int64]*docker.Client, len(m.clients))
-	for id, dc := range m.clients {
-		hostClients[id] = dc
+// queryNodeID asks a node's info API for its NodeID.
+func (m *Manager) queryNodeID(ctx context.Context, node Node) (string, error) {
+	var result struct {
+		NodeID string `json:"nodeID"`
 	}
-	m.clientsMu.RUnlock()
-
-	// hostID -> (containerName -> state)
-	containerStates := make(map[int64]map[string]string)
-	for hostID, dc := range hostClients {
-		containers, err := dc.ListManagedContainers(ctx)
-		if err != nil {
-			slog.Warn("reconcile: list containers", "host_id", hostID, "error", err)
-			continue
-		}
-		stateMap := make(map[string]string)
-		for _, c := range containers {
-			stateMap[c.Name] = c.State
-		}
-		containerStates[hostID] = stateMap
+	if err := m.callNodeRPC(ctx, node, "/ext/info", "info.getNodeID", nil, &result); err != nil {
+		return "", err
 	}
+	return result.NodeID, nil
+}
 
-	nodes, err := m.ListNodes(ctx)
+// storeNodeID saves a node's NodeID, logs a node.identified event and
+// checks it against the NodeIDs of the other nodes.
+func (m *Manager) storeNodeID(ctx context.Context, node Node, nodeID string) error {
+	_, err := m.pool.Exec(ctx, "UPDATE nodes SET node_id=$1, updated_at=now() WHERE id=$2", nodeID, node.ID)
 	if err != nil {
-		return fmt.Errorf("list nodes: %w", err)
+		return err
 	}
-
-	for _, node := range nodes {
-		if node.ContainerID == "" {
-			continue
-		}
-		containerName := "avax-" + node.Name
-		stateMap, hostKnown := containerStates[node.HostID]
-
-		var newStatus string
-		if !hostKnown {
-			// Host not connected — skip reconciliation for this node.
-			continue
-		}
-
-		state, found := stateMap[containerName]
-		if !found {
-			// Container gone — mark as stopped.
-			newStatus = "stopped"
-		} else {
-			switch state {
-			case "running":
-				newStatus = "running"
-			case "exited", "dead":
-				newStatus = "stopped"
-			case "created", "restarting":
-				newStatus = "creating"
-			default:
-				newStatus = "stopped"
-			}
-		}
-
-		if newStatus != node.Status {
-			slog.Info("reconcile", "node", node.Name, "old_status", node.Status, "new_status", newStatus)
-			_, err := m.pool.Exec(ctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2", newStatus, node.ID)
-			if err != nil {
-				slog.Error("reconcile update", "error", err, "node", node.Name)
-			}
-		}
-	}
-
+	slog.Info("discovered node ID", "node", node.Name, "node_id", nodeID)
+	m.logEvent(ctx, "node.identified", node.Name, "Node ID: "+nodeID, nil)
+	node.NodeID = nodeID
+	m.checkNewIdentity(ctx, node)
 	return nil
 }
 
 // StatusSummary holds summary data for the dashboard.
 type StatusSummary struct {
-	Version string         `json:"version"`
+	Version string           `json:"version"`
 	Counts  map[string]int64 `json:"counts"`
-	Nodes   []NodeSummary  `json:"nodes,omitempty"`
+	Nodes   []NodeSummary    `json:"nodes,omitempty"`
 }
 
 // L1Summary is a brief L1 representation for node cards.
@@ -782,6 +1407,7 @@ type L1Summary struct {
 	SubnetID string `json:"subnet_id"`
 	VM       string `json:"vm"`
 	Status   string `json:"status"`
+	RPCURL   string `json:"rpc_url,omitempty"`
 }
 
 // NodeSummary is a brief node representation for the dashboard.
@@ -794,9 +1420,22 @@ type NodeSummary struct {
 	NodeID      string      `json:"node_id,omitempty"`
 	StakingPort int         `json:"staking_port"`
 	Status      string      `json:"status"`
+	StakingEnd  *time.Time  `json:"staking_end,omitempty"`
+	Version     string      `json:"version,omitempty"`
+	NewDigest   string      `json:"new_digest,omitempty"` // image tag moved to this digest (last drift check)
+	Tags        []string    `json:"tags,omitempty"`
 	L1s         []L1Summary `json:"l1s"`
 }
 
+// DefaultImage returns the default AvalancheGo image for an Avalanche network,
+// falling back to the global default when no per-network image is configured.
+func (m *Manager) DefaultImage(network string) string {
+	if img := m.avagoImages[network]; img != "" {
+		return img
+	}
+	return m.avagoImage
+}
+
 // LocalHostID returns the database ID of the local host.
 func (m *Manager) LocalHostID() int64 {
 	return m.localHostID
@@ -805,7 +1444,7 @@ func (m *Manager) LocalHostID() int64 {
 // ListL1sForNode returns L1s validated by the given node.
 func (m *Manager) ListL1sForNode(ctx context.Context, nodeID int64) ([]L1Summary, error) {
 	rows, err := m.pool.Query(ctx, `
-		SELECT l.id, l.name, l.subnet_id, l.vm, l.status
+		SELECT l.id, l.name, l.subnet_id, l.vm, l.status, l.rpc_url
 		FROM l1_validators v
 		JOIN l1s l ON v.l1_id = l.id
 		WHERE v.node_id = $1
@@ -818,7 +1457,7 @@ func (m *Manager) ListL1sForNode(ctx context.Context, nodeID int64) ([]L1Summary
 	var l1s []L1Summary
 	for rows.Next() {
 		var s L1Summary
-		if err := rows.Scan(&s.ID, &s.Name, &s.SubnetID, &s.VM, &s.Status); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.SubnetID, &s.VM, &s.Status, &s.RPCURL); err != nil {
 			return nil, err
 		}
 		l1s = append(l1s, s)
@@ -843,17 +1482,31 @@ func looksLikeContainerID(s string) bool {
 }
 
 func (m *Manager) logEvent(ctx context.Context, eventType, target, message string, details map[string]any) {
+	m.logEventAt(ctx, eventSeverity(eventType), eventType, target, message, details)
+}
+
+// logEventAt is logEvent for event types whose severity depends on what
+// happened, e.g. which status a node moved to.
+func (m *Manager) logEventAt(ctx context.Context, severity, eventType, target, message string, details map[string]any) {
 	detailJSON := []byte("{}")
 	if details != nil {
 		if b, err := json.Marshal(details); err == nil {
 			detailJSON = b
 		}
 	}
-	_, err := m.pool.Exec(ctx, `
-		INSERT INTO events (event_type, target, message, details)
-		VALUES ($1, $2, $3, $4)`,
-		eventType, target, message, detailJSON)
-	if err != nil {
+	e := Event{EventType: eventType, Severity: severity, Target: target, Message: message, Details: details}
+	if err := m.insertEvent(ctx, &e, detailJSON); err != nil {
 		slog.Error("log event", "error", err, "type", eventType, "target", target)
+		return
 	}
+	m.publish(e)
+}
+
+// insertEvent stores e, filling in its ID and timestamp.
+func (m *Manager) insertEvent(ctx context.Context, e *Event, detailJSON []byte) error {
+	return m.pool.QueryRow(ctx, `
+		INSERT INTO events (event_type, severity, target, message, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		e.EventType, e.Severity, e.Target, e.Message, detailJSON).Scan(&e.ID, &e.CreatedAt)
 }
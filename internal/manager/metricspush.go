@@ -0,0 +1,169 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetricsPushConfig holds settings for pushing node metrics to a Prometheus
+// Pushgateway, for hosts that Prometheus can't scrape inbound.
+type MetricsPushConfig struct {
+	URL      string        // Pushgateway base URL (empty = disabled)
+	Job      string        // job grouping label, e.g. "avalauncher"
+	Interval time.Duration // push interval
+	Auth     string        // optional "user:password" basic auth
+}
+
+// StartMetricsPusher begins a background loop that scrapes /ext/metrics from
+// every running node and pushes the samples to the Pushgateway, grouped by
+// job and instance (node name). It is a no-op if cfg.URL is empty.
+func (m *Manager) StartMetricsPusher(cfg MetricsPushConfig) {
+	if cfg.URL == "" {
+		return
+	}
+	m.startPoller("metrics_push", cfg.Interval, func() (int, int) { return m.pushMetrics(cfg) })
+	slog.Info("metrics pusher started", "url", cfg.URL, "interval", cfg.Interval)
+}
+
+func (m *Manager) pushMetrics(cfg MetricsPushConfig) (pushed, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Interval)
+	defer cancel()
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		slog.Error("push metrics: list nodes", "error", err)
+		return 0, 1
+	}
+	hosts := m.HostLabelsMap(ctx)
+
+	for _, node := range nodes {
+		if !containerUp(node.Status) {
+			continue
+		}
+		labels := map[string]string{
+			"node":    node.Name,
+			"host":    hosts[node.HostID],
+			"network": node.Network,
+		}
+		if node.NodeID != "" {
+			labels["node_id"] = node.NodeID
+		}
+		pushed++
+		if err := m.pushNodeMetrics(ctx, cfg, node, labels); err != nil {
+			slog.Warn("push metrics failed", "error", err, "node", node.Name)
+			failed++
+		}
+	}
+	return pushed, failed
+}
+
+// pushNodeMetrics scrapes one node and replaces its metric group on the
+// Pushgateway.
+func (m *Manager) pushNodeMetrics(ctx context.Context, cfg MetricsPushConfig, node Node, labels map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", m.nodeBaseURL(ctx, node)+"/ext/metrics", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("scrape: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scrape: HTTP %d", resp.StatusCode)
+	}
+
+	var buf bytes.Buffer
+	if err := relabelMetrics(resp.Body, &buf, labels); err != nil {
+		return fmt.Errorf("relabel: %w", err)
+	}
+
+	pushURL := fmt.Sprintf("%s/metrics/job/%s/instance/%s",
+		strings.TrimRight(cfg.URL, "/"), url.PathEscape(cfg.Job), url.PathEscape(node.Name))
+	push, err := http.NewRequestWithContext(ctx, "PUT", pushURL, &buf)
+	if err != nil {
+		return err
+	}
+	push.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	if user, pass, ok := strings.Cut(cfg.Auth, ":"); ok {
+		push.SetBasicAuth(user, pass)
+	}
+	presp, err := http.DefaultClient.Do(push)
+	if err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	defer presp.Body.Close()
+	if presp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(presp.Body, 512))
+		return fmt.Errorf("push: HTTP %d: %s", presp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// relabelMetrics copies Prometheus text exposition from r to w, adding labels
+// to every sample and dropping sample timestamps (the Pushgateway rejects
+// them). Labels already present on a sample are left as-is.
+func relabelMetrics(r io.Reader, w io.Writer, labels map[string]string) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	bw := bufio.NewWriter(w)
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			fmt.Fprintln(bw, line)
+			continue
+		}
+
+		// Split "name{labels} value [timestamp]" into its parts.
+		var name, existing, rest string
+		if i := strings.IndexByte(line, '{'); i >= 0 {
+			j := strings.LastIndexByte(line, '}')
+			if j < i {
+				continue
+			}
+			name, existing, rest = line[:i], line[i+1:j], strings.TrimSpace(line[j+1:])
+		} else {
+			var ok bool
+			name, rest, ok = strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var pairs []string
+		if existing = strings.TrimSuffix(existing, ","); existing != "" {
+			pairs = append(pairs, existing)
+		}
+		for _, k := range keys {
+			if strings.HasPrefix(existing, k+"=") || strings.Contains(existing, ","+k+"=") {
+				continue
+			}
+			pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+		}
+		fmt.Fprintf(bw, "%s{%s} %s\n", name, strings.Join(pairs, ","), fields[0])
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
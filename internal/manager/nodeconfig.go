@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// SetNodeConfig replaces a node's AvalancheGo flag and chain config
+// overrides and recreates its container to apply them.
+func (m *Manager) SetNodeConfig(ctx context.Context, id int64, cfg docker.NodeConfig) (*Node, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Status == "creating" {
+		return nil, fmt.Errorf("node %q is still being provisioned", node.Name)
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET node_configs=$1, updated_at=now() WHERE id=$2", cfg, id); err != nil {
+		return nil, fmt.Errorf("update config: %w", err)
+	}
+	chains := make([]string, 0, len(cfg.ChainConfigs))
+	for chain := range cfg.ChainConfigs {
+		chains = append(chains, chain)
+	}
+	m.logEvent(ctx, "node.config_updated", node.Name, "AvalancheGo config updated",
+		map[string]any{"profile": cfg.Profile, "flags": cfg.Flags, "chains": chains})
+
+	// Like throttle changes, a stopped node picks up the new config the
+	// next time it is reconfigured.
+	if node.ContainerID != "" && node.Status != "stopped" {
+		m.requestReconfigure(id)
+	}
+	return m.GetNode(ctx, id)
+}
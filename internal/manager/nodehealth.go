@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// HealthCheckResult is one of AvalancheGo's named health checks.
+type HealthCheckResult struct {
+	Name               string          `json:"name"`
+	Healthy            bool            `json:"healthy"`
+	Error              string          `json:"error,omitempty"`
+	Message            json.RawMessage `json:"message,omitempty"`
+	ContiguousFailures int64           `json:"contiguous_failures,omitempty"`
+	TimeOfFirstFailure *time.Time      `json:"time_of_first_failure,omitempty"`
+	Timestamp          *time.Time      `json:"timestamp,omitempty"`
+}
+
+// NodeHealth is the latest health probe of a node. Checks is empty for the
+// tcp method, which only tests that the staking port accepts connections.
+type NodeHealth struct {
+	NodeID    int64               `json:"node_id"`
+	Method    string              `json:"method"`
+	Healthy   bool                `json:"healthy"`
+	Checks    []HealthCheckResult `json:"checks"`          // failing checks first
+	Error     string              `json:"error,omitempty"` // why the probe itself failed
+	CheckedAt time.Time           `json:"checked_at"`
+
+	unreachable bool // the HTTP probe couldn't connect
+}
+
+// parse fills h from a health.health JSON-RPC response.
+func (h *NodeHealth) parse(body []byte) error {
+	var resp struct {
+		Result *struct {
+			Healthy bool `json:"healthy"`
+			Checks  map[string]struct {
+				Message json.RawMessage `json:"message"`
+				Error   *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+				Timestamp          *time.Time `json:"timestamp"`
+				ContiguousFailures int64      `json:"contiguousFailures"`
+				TimeOfFirstFailure *time.Time `json:"timeOfFirstFailure"`
+			} `json:"checks"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("decode health response: %w", err)
+	}
+	if resp.Result == nil {
+		return errors.New("health response has no result")
+	}
+
+	h.Healthy = resp.Result.Healthy
+	h.Checks = make([]HealthCheckResult, 0, len(resp.Result.Checks))
+	for name, c := range resp.Result.Checks {
+		r := HealthCheckResult{
+			Name:               name,
+			Healthy:            c.Error == nil,
+			Message:            c.Message,
+			ContiguousFailures: c.ContiguousFailures,
+			TimeOfFirstFailure: c.TimeOfFirstFailure,
+			Timestamp:          c.Timestamp,
+		}
+		if c.Error != nil {
+			r.Error = c.Error.Message
+		}
+		h.Checks = append(h.Checks, r)
+	}
+	sort.Slice(h.Checks, func(i, j int) bool {
+		if h.Checks[i].Healthy != h.Checks[j].Healthy {
+			return !h.Checks[i].Healthy
+		}
+		return h.Checks[i].Name < h.Checks[j].Name
+	})
+	return nil
+}
+
+// recordHealth stores h as the node's latest health result.
+func (m *Manager) recordHealth(ctx context.Context, nodeID int64, h NodeHealth) {
+	if h.Checks == nil {
+		h.Checks = []HealthCheckResult{}
+	}
+	checks, _ := json.Marshal(h.Checks)
+	if _, err := m.pool.Exec(ctx, `
+		INSERT INTO node_health (node_id, method, healthy, checks, error, checked_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		ON CONFLICT (node_id) DO UPDATE SET method=EXCLUDED.method, healthy=EXCLUDED.healthy,
+			checks=EXCLUDED.checks, error=EXCLUDED.error, checked_at=EXCLUDED.checked_at`,
+		nodeID, h.Method, h.Healthy, checks, h.Error); err != nil {
+		slog.Debug("record node health", "error", err, "node_id", nodeID)
+	}
+}
+
+// NodeHealthReport returns a node's latest health result, probing it first
+// when refresh is set or it has never been checked.
+func (m *Manager) NodeHealthReport(ctx context.Context, id int64, refresh bool) (*NodeHealth, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if refresh {
+		m.checkNodeHealth(ctx, *node)
+	}
+
+	h := NodeHealth{NodeID: id}
+	var checks []byte
+	err = m.pool.QueryRow(ctx, `
+		SELECT method, healthy, checks, error, checked_at FROM node_health WHERE node_id=$1`, id).
+		Scan(&h.Method, &h.Healthy, &checks, &h.Error, &h.CheckedAt)
+	if errors.Is(err, pgx.ErrNoRows) && !refresh {
+		return m.NodeHealthReport(ctx, id, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	h.Checks = []HealthCheckResult{}
+	json.Unmarshal(checks, &h.Checks)
+	return &h, nil
+}
@@ -0,0 +1,190 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// nodeHistoryRetention is how long node state history is kept.
+	nodeHistoryRetention = 90 * 24 * time.Hour
+
+	// nodeAtEventWindow is how far back /at looks for a node's events.
+	nodeAtEventWindow = time.Hour
+	// nodeAtSampleMaxAge is how old a metrics or uptime sample may be and
+	// still be reported as the value at the requested time.
+	nodeAtSampleMaxAge = time.Hour
+)
+
+// NodeState is a node as it was recorded at some point: what ran, where,
+// and how its last health probe went.
+type NodeState struct {
+	HostID        int64      `json:"host_id"`
+	Status        string     `json:"status"`
+	Image         string     `json:"image"`
+	ImageDigest   string     `json:"image_digest,omitempty"`
+	Version       string     `json:"version,omitempty"`
+	ContainerID   string     `json:"container_id,omitempty"`
+	Healthy       *bool      `json:"healthy,omitempty"` // nil before the first probe
+	HealthError   string     `json:"health_error,omitempty"`
+	FailingChecks []string   `json:"failing_checks,omitempty"`
+	RecordedAt    time.Time  `json:"recorded_at"`     // when this state was first seen
+	Until         *time.Time `json:"until,omitempty"` // when it next changed; nil if still current
+}
+
+// NodeAt reconstructs a node at a past time for post-incident review.
+type NodeAt struct {
+	NodeID int64      `json:"node_id"`
+	Name   string     `json:"name"`
+	At     time.Time  `json:"at"`
+	State  *NodeState `json:"state"` // nil when nothing was recorded by then
+
+	// Nearest samples at or before At, if recent enough to describe it.
+	HostMetrics *HostMetrics  `json:"host_metrics,omitempty"`
+	Uptime      *UptimeSample `json:"uptime,omitempty"`
+
+	Events []Event `json:"events"` // the node's events in the hour up to At, newest first
+}
+
+// StartNodeHistoryPoller records each node's state whenever it changed
+// since the last record. Status changes seen by the health poller and the
+// Docker event watcher are recorded as they happen; the poller catches the
+// rest (images, versions, operator actions).
+func (m *Manager) StartNodeHistoryPoller(interval time.Duration) {
+	m.startPoller("node_history", interval, m.pollNodeHistory)
+}
+
+func (m *Manager) pollNodeHistory() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := m.recordNodeHistory(ctx); err != nil {
+		slog.Warn("record node history", "error", err)
+		return 1, 1
+	}
+	if _, err := m.pool.Exec(ctx, "DELETE FROM node_history WHERE recorded_at < $1", time.Now().Add(-nodeHistoryRetention)); err != nil {
+		slog.Warn("prune node history", "error", err)
+	}
+	if _, err := m.pool.Exec(ctx, "DELETE FROM l1_status_history WHERE recorded_at < $1", time.Now().Add(-nodeHistoryRetention)); err != nil {
+		slog.Warn("prune L1 status history", "error", err)
+	}
+	return 1, 0
+}
+
+// recordNodeHistory snapshots the given nodes (every node when none are
+// given), adding a row only for those whose state differs from their
+// latest record, then records the L1 verdicts they add up to.
+func (m *Manager) recordNodeHistory(ctx context.Context, ids ...int64) error {
+	if ids == nil {
+		ids = []int64{}
+	}
+	_, err := m.pool.Exec(ctx, `
+		WITH cur AS (
+			SELECT n.id AS node_id, n.host_id, n.status, n.image, n.image_digest, n.avago_version, n.container_id,
+			       h.healthy, COALESCE(h.error, '') AS health_error,
+			       COALESCE(ARRAY(SELECT c->>'name' FROM jsonb_array_elements(h.checks) c
+			                      WHERE (c->>'healthy')::boolean IS NOT TRUE ORDER BY 1), '{}') AS failing_checks
+			FROM nodes n LEFT JOIN node_health h ON h.node_id = n.id
+			WHERE cardinality($1::bigint[]) = 0 OR n.id = ANY($1)
+		)
+		INSERT INTO node_history (node_id, host_id, status, image, image_digest, avago_version, container_id,
+			healthy, health_error, failing_checks)
+		SELECT cur.* FROM cur
+		WHERE NOT EXISTS (
+			SELECT 1 FROM (
+				SELECT * FROM node_history nh WHERE nh.node_id = cur.node_id ORDER BY recorded_at DESC LIMIT 1
+			) last
+			WHERE (last.host_id, last.status, last.image, last.image_digest, last.avago_version, last.container_id,
+			       last.healthy, last.health_error, last.failing_checks)
+			      IS NOT DISTINCT FROM
+			      (cur.host_id, cur.status, cur.image, cur.image_digest, cur.avago_version, cur.container_id,
+			       cur.healthy, cur.health_error, cur.failing_checks))`, ids)
+	if err != nil {
+		return err
+	}
+	return m.recordL1History(ctx)
+}
+
+// NodeAt returns a node's recorded state at t, the host metrics and uptime
+// samples taken closest before it, and the node's events in the hour up to
+// it.
+func (m *Manager) NodeAt(ctx context.Context, id int64, t time.Time) (*NodeAt, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if t.After(time.Now()) {
+		return nil, fmt.Errorf("time is in the future")
+	}
+	out := &NodeAt{NodeID: id, Name: node.Name, At: t, Events: []Event{}}
+
+	var s NodeState
+	err = m.pool.QueryRow(ctx, `
+		SELECT host_id, status, image, image_digest, avago_version, container_id, healthy, health_error,
+		       failing_checks, recorded_at,
+		       (SELECT min(recorded_at) FROM node_history nx WHERE nx.node_id = nh.node_id AND nx.recorded_at > nh.recorded_at)
+		FROM node_history nh
+		WHERE node_id = $1 AND recorded_at <= $2
+		ORDER BY recorded_at DESC LIMIT 1`, id, t).
+		Scan(&s.HostID, &s.Status, &s.Image, &s.ImageDigest, &s.Version, &s.ContainerID, &s.Healthy, &s.HealthError,
+			&s.FailingChecks, &s.RecordedAt, &s.Until)
+	switch {
+	case err == nil:
+		out.State = &s
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("node history: %w", err)
+	}
+
+	hostID := node.HostID
+	if out.State != nil {
+		hostID = out.State.HostID
+	}
+	var hm HostMetrics
+	err = m.pool.QueryRow(ctx, `
+		SELECT disk_total_bytes, disk_free_bytes, load1, load5, load15, mem_total_bytes, mem_used_bytes, created_at
+		FROM host_metrics WHERE host_id = $1 AND created_at <= $2 AND created_at > $3
+		ORDER BY created_at DESC LIMIT 1`, hostID, t, t.Add(-nodeAtSampleMaxAge)).
+		Scan(&hm.DiskTotalBytes, &hm.DiskFreeBytes, &hm.Load1, &hm.Load5, &hm.Load15,
+			&hm.MemTotalBytes, &hm.MemUsedBytes, &hm.SampledAt)
+	if err == nil {
+		out.HostMetrics = &hm
+	}
+
+	var us UptimeSample
+	err = m.pool.QueryRow(ctx, `
+		SELECT observed_at, connected, uptime, rewarding_stake, weighted_average, validation_end
+		FROM node_uptime WHERE node_id = $1 AND observed_at <= $2 AND observed_at > $3
+		ORDER BY observed_at DESC LIMIT 1`, id, t, t.Add(-nodeAtSampleMaxAge)).
+		Scan(&us.ObservedAt, &us.Connected, &us.Uptime, &us.RewardingStake, &us.WeightedAverage, &us.ValidationEnd)
+	if err == nil {
+		out.Uptime = &us
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, event_type, severity, target, message, details, created_at
+		FROM events
+		WHERE target = $1 AND created_at <= $2 AND created_at > $3
+		ORDER BY created_at DESC LIMIT 50`, node.Name, t, t.Add(-nodeAtEventWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Event
+		var details []byte
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Severity, &e.Target, &e.Message, &details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			json.Unmarshal(details, &e.Details)
+		}
+		out.Events = append(out.Events, e)
+	}
+	return out, rows.Err()
+}
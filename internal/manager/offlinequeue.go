@@ -0,0 +1,202 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Node operations that can wait for an offline host.
+const (
+	OpStart = "start"
+	OpStop  = "stop"
+)
+
+// Operation states of the offline queue.
+const (
+	OpQueued    = "queued"    // waiting for the node's host to reconnect
+	OpCancelled = "cancelled" // withdrawn or superseded before it ran
+)
+
+// ErrHostOffline is returned by node operations whose host is not connected.
+var ErrHostOffline = errors.New("host not connected")
+
+// hostOffline reports whether a host has no Docker client or is marked
+// unreachable by the host poller.
+func (m *Manager) hostOffline(ctx context.Context, hostID int64) bool {
+	if m.clientFor(hostID) == nil {
+		return true
+	}
+	var status string
+	if err := m.pool.QueryRow(ctx, "SELECT status FROM hosts WHERE id=$1", hostID).Scan(&status); err != nil {
+		return false
+	}
+	return status == "unreachable"
+}
+
+// QueueNodeOp queues a start, stop or reconfigure of a node to run when its
+// host reconnects. A queued start supersedes a queued stop and vice versa;
+// queueing an operation already waiting returns the waiting one.
+func (m *Manager) QueueNodeOp(ctx context.Context, id int64, kind string) (*Operation, error) {
+	switch kind {
+	case OpStart, OpStop, OpReconfigure:
+	default:
+		return nil, fmt.Errorf("operation %q can't be queued (want start, stop or reconfigure)", kind)
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	return m.queueOp(ctx, node, kind)
+}
+
+func (m *Manager) queueOp(ctx context.Context, node *Node, kind string) (*Operation, error) {
+	var opID int64
+	err := m.pool.QueryRow(ctx,
+		"SELECT id FROM operations WHERE node_id=$1 AND kind=$2 AND state=$3 ORDER BY id LIMIT 1",
+		node.ID, kind, OpQueued).Scan(&opID)
+	if err == nil {
+		return m.GetOperation(ctx, opID)
+	}
+
+	if kind == OpStart || kind == OpStop {
+		if _, err := m.pool.Exec(ctx, `
+			UPDATE operations SET state=$1, error=$2, updated_at=now()
+			WHERE node_id=$3 AND state=$4 AND kind IN ($5, $6)`,
+			OpCancelled, "superseded by a queued "+kind, node.ID, OpQueued, OpStart, OpStop); err != nil {
+			return nil, fmt.Errorf("supersede queued operations: %w", err)
+		}
+	}
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO operations (kind, node_id, state)
+		VALUES ($1, $2, $3)
+		RETURNING id`, kind, node.ID, OpQueued).Scan(&opID)
+	if err != nil {
+		return nil, fmt.Errorf("queue operation: %w", err)
+	}
+	m.logEvent(ctx, "node.op_queued", node.Name, fmt.Sprintf("Queued %s until host %d reconnects", kind, node.HostID),
+		map[string]any{"op_id": opID, "kind": kind, "host_id": node.HostID})
+
+	// The host may have come back between the failed attempt and now.
+	if !m.hostOffline(ctx, node.HostID) {
+		go m.runQueuedOps(node.HostID)
+	}
+	return m.GetOperation(ctx, opID)
+}
+
+// CancelOperation withdraws a queued operation, or one waiting to retry.
+// A node whose provisioning or reconfiguration retry is cancelled is
+// marked failed.
+func (m *Manager) CancelOperation(ctx context.Context, id int64) (*Operation, error) {
+	op, err := m.GetOperation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := m.pool.Exec(ctx,
+		"UPDATE operations SET state=$1, error=$2, retry_at=NULL, updated_at=now() WHERE id=$3 AND state IN ($4, $5)",
+		OpCancelled, "cancelled", id, OpQueued, OpRetry)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("operation %d is %s, not queued or retrying", id, op.State)
+	}
+	if op.State == OpRetry {
+		m.pool.Exec(ctx, "UPDATE nodes SET status='failed', updated_at=now() WHERE id=$1", op.NodeID)
+		m.logEvent(ctx, "node.failed", op.NodeName, "Retry of "+op.Kind+" cancelled: "+op.Error,
+			map[string]any{"op_id": id})
+	}
+	m.logEvent(ctx, "node.op_cancelled", op.NodeName, "Cancelled queued "+op.Kind,
+		map[string]any{"op_id": id, "kind": op.Kind})
+	return m.GetOperation(ctx, id)
+}
+
+// runQueuedOps runs the operations queued for nodes on a host, oldest
+// first. Each is claimed by moving it out of the queued state, so
+// overlapping runs never execute one twice. A host in maintenance keeps its
+// queue until it is resumed.
+func (m *Manager) runQueuedOps(hostID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var status string
+	if err := m.pool.QueryRow(ctx, "SELECT status FROM hosts WHERE id=$1", hostID).Scan(&status); err != nil || status == HostMaintenance {
+		return
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT o.id, o.kind, o.node_id, n.name FROM operations o
+		JOIN nodes n ON n.id = o.node_id
+		WHERE o.state = $1 AND n.host_id = $2
+		ORDER BY o.id`, OpQueued, hostID)
+	if err != nil {
+		slog.Warn("queued operations", "host_id", hostID, "error", err)
+		return
+	}
+	var ops []Operation
+	for rows.Next() {
+		var o Operation
+		if err := rows.Scan(&o.ID, &o.Kind, &o.NodeID, &o.NodeName); err != nil {
+			rows.Close()
+			slog.Warn("queued operations", "host_id", hostID, "error", err)
+			return
+		}
+		ops = append(ops, o)
+	}
+	rows.Close()
+
+	for _, op := range ops {
+		tag, err := m.pool.Exec(ctx,
+			"UPDATE operations SET state=$1, updated_at=now() WHERE id=$2 AND state=$3",
+			OpRunning, op.ID, OpQueued)
+		if err != nil || tag.RowsAffected() == 0 {
+			continue
+		}
+		switch op.Kind {
+		case OpStart:
+			err = m.StartNode(ctx, op.NodeID)
+		case OpStop:
+			err = m.StopNode(ctx, op.NodeID)
+		case OpReconfigure:
+			// The reconfigure journals itself as its own operation.
+			m.requestReconfigure(op.NodeID)
+		}
+		if err != nil {
+			m.finishOp(ctx, op.ID, err.Error())
+			m.logEvent(ctx, "node.queued_op_failed", op.NodeName, fmt.Sprintf("Queued %s failed: %v", op.Kind, err),
+				map[string]any{"op_id": op.ID, "kind": op.Kind})
+			continue
+		}
+		m.finishOp(ctx, op.ID, "")
+		slog.Info("ran queued operation", "node", op.NodeName, "kind", op.Kind, "op_id", op.ID)
+	}
+}
+
+// resumeQueuedOps runs, at startup, operations queued for hosts that are
+// connected again.
+func (m *Manager) resumeQueuedOps(ctx context.Context) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT DISTINCT n.host_id FROM operations o
+		JOIN nodes n ON n.id = o.node_id
+		WHERE o.state = $1`, OpQueued)
+	if err != nil {
+		slog.Warn("resume queued operations", "error", err)
+		return
+	}
+	var hostIDs []int64
+	for rows.Next() {
+		var id int64
+		if rows.Scan(&id) == nil {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, hostID := range hostIDs {
+		if !m.hostOffline(ctx, hostID) {
+			go m.runQueuedOps(hostID)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/jackc/pgx/v5"
+)
+
+// Operation kinds.
+const (
+	OpProvision   = "provision"
+	OpReconfigure = "reconfigure"
+)
+
+// Operation states.
+const (
+	OpRunning = "running"
+	OpDone    = "done"
+	OpFailed  = "failed"
+	OpResumed = "resumed"  // interrupted by a crash and re-run on startup
+	OpRetry   = "retrying" // failed a step worth retrying; runs again at retry_at
+)
+
+// Retries of provisioning and reconfiguration. The delay after a failed
+// attempt doubles from opRetryBackoff up to opRetryMaxDelay.
+const (
+	opMaxAttempts   = 5
+	opRetryBackoff  = 30 * time.Second
+	opRetryMaxDelay = 10 * time.Minute
+	opRetryInterval = 15 * time.Second // how often due retries are picked up
+	opRetryLease    = time.Minute      // a picked-up retry isn't picked up again for this long
+	opFailTimeout   = 30 * time.Second // for recording a failure or retry, on a context of its own
+)
+
+// Operation is a journal entry for a multi-step node operation.
+type Operation struct {
+	ID        int64           `json:"id"`
+	Kind      string          `json:"kind"`
+	NodeID    int64           `json:"node_id"`
+	NodeName  string          `json:"node_name"`
+	Step      string          `json:"step"`
+	State     string          `json:"state"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Attempts  int             `json:"attempts"`
+	RetryAt   *time.Time      `json:"retry_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// beginOp journals the start of an operation and returns its ID. An
+// operation of the same kind waiting to retry on the node is taken over as
+// its next attempt. The journal is best-effort: on failure it logs and
+// returns 0, and later calls no-op.
+func (m *Manager) beginOp(ctx context.Context, kind string, nodeID int64, params any) int64 {
+	raw, _ := json.Marshal(params)
+	var id int64
+	err := m.pool.QueryRow(ctx, `
+		UPDATE operations SET state=$1, attempts=attempts+1, retry_at=NULL, step='', error='', params=$2, updated_at=now()
+		WHERE id = (SELECT id FROM operations WHERE kind=$3 AND node_id=$4 AND state=$5 ORDER BY id DESC LIMIT 1)
+		RETURNING id`, OpRunning, raw, kind, nodeID, OpRetry).Scan(&id)
+	if err == nil {
+		return id
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		slog.Error("journal operation retry", "error", err, "kind", kind, "node_id", nodeID)
+	}
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO operations (kind, node_id, params)
+		VALUES ($1, $2, $3)
+		RETURNING id`, kind, nodeID, raw).Scan(&id)
+	if err != nil {
+		slog.Error("journal operation", "error", err, "kind", kind, "node_id", nodeID)
+		return 0
+	}
+	return id
+}
+
+// opStep records that an operation completed step.
+func (m *Manager) opStep(ctx context.Context, opID int64, step string) {
+	if opID == 0 {
+		return
+	}
+	var kind, nodeName string
+	if err := m.pool.QueryRow(ctx, `
+		UPDATE operations SET step=$1, updated_at=now() WHERE id=$2
+		RETURNING kind, (SELECT name FROM nodes WHERE nodes.id = operations.node_id)`,
+		step, opID).Scan(&kind, &nodeName); err != nil {
+		slog.Error("journal operation step", "error", err, "op_id", opID, "step", step)
+		return
+	}
+	// Progress isn't worth an events row, but the dashboard wants it live.
+	m.publish(Event{
+		EventType: "operation.step",
+		Severity:  SeverityInfo,
+		Target:    nodeName,
+		Message:   fmt.Sprintf("%s: %s", kind, step),
+		Details:   map[string]any{"op_id": opID, "kind": kind, "step": step},
+		CreatedAt: time.Now(),
+	})
+}
+
+// finishOp marks an operation done, or failed with msg.
+func (m *Manager) finishOp(ctx context.Context, opID int64, msg string) {
+	if opID == 0 {
+		return
+	}
+	state := OpDone
+	if msg != "" {
+		state = OpFailed
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE operations SET state=$1, error=$2, updated_at=now() WHERE id=$3",
+		state, msg, opID); err != nil {
+		slog.Error("journal operation finish", "error", err, "op_id", opID)
+	}
+}
+
+// retryOp schedules a failed attempt of an operation to run again, after
+// a delay that doubles with each attempt, and logs a node.op_retry event.
+// It reports false once the operation has used its attempts (or isn't
+// journaled); the caller then fails it.
+func (m *Manager) retryOp(ctx context.Context, opID int64, nodeName, msg string) bool {
+	if opID == 0 {
+		return false
+	}
+	var kind string
+	var attempts int
+	if err := m.pool.QueryRow(ctx, "SELECT kind, attempts FROM operations WHERE id=$1", opID).Scan(&kind, &attempts); err != nil {
+		slog.Error("journal operation retry", "error", err, "op_id", opID)
+		return false
+	}
+	if attempts >= opMaxAttempts {
+		return false
+	}
+	delay := min(opRetryBackoff<<(attempts-1), opRetryMaxDelay)
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE operations SET state=$1, error=$2, retry_at=$3, updated_at=now() WHERE id=$4",
+		OpRetry, msg, time.Now().Add(delay), opID); err != nil {
+		slog.Error("journal operation retry", "error", err, "op_id", opID)
+		return false
+	}
+	m.logEvent(ctx, "node.op_retry", nodeName,
+		fmt.Sprintf("%s; retrying %s in %s (attempt %d of %d)", msg, kind, delay, attempts+1, opMaxAttempts),
+		map[string]any{"op_id": opID, "kind": kind, "attempt": attempts + 1, "error": msg})
+	return true
+}
+
+// StartRetryPoller begins a background loop that re-runs operations whose
+// retry is due.
+func (m *Manager) StartRetryPoller() {
+	m.startPoller("op_retries", opRetryInterval, m.pollOpRetries)
+	slog.Info("operation retry poller started", "max_attempts", opMaxAttempts)
+}
+
+// pollOpRetries starts the due retries. Each is leased by moving its
+// retry_at forward, so it isn't started twice while its attempt begins.
+// Retries of nodes whose host is offline wait for the next lease to expire
+// without using an attempt.
+func (m *Manager) pollOpRetries() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rows, err := m.pool.Query(ctx, `
+		UPDATE operations SET retry_at = now() + make_interval(secs => $1)
+		WHERE state = $2 AND retry_at <= now()
+		RETURNING id, kind, node_id, params`, opRetryLease.Seconds(), OpRetry)
+	if err != nil {
+		slog.Error("operation retries", "error", err)
+		return 0, 1
+	}
+	var ops []Operation
+	for rows.Next() {
+		var o Operation
+		if err := rows.Scan(&o.ID, &o.Kind, &o.NodeID, &o.Params); err != nil {
+			rows.Close()
+			slog.Error("operation retries", "error", err)
+			return 0, 1
+		}
+		ops = append(ops, o)
+	}
+	rows.Close()
+
+	for _, op := range ops {
+		checked++
+		node, err := m.GetNode(ctx, op.NodeID)
+		if err != nil {
+			failed++
+			continue
+		}
+		if m.hostOffline(ctx, node.HostID) {
+			continue
+		}
+		switch op.Kind {
+		case OpProvision:
+			var req CreateNodeRequest
+			if err := json.Unmarshal(op.Params, &req); err != nil {
+				m.finishOp(ctx, op.ID, "invalid journaled params")
+				failed++
+				continue
+			}
+			// Clear out what the failed attempt left behind.
+			dc := m.clientFor(node.HostID)
+			if err := dc.ContainerRemove(ctx, "avax-"+node.Name, false); err != nil && !client.IsErrNotFound(err) {
+				slog.Warn("operation retry: remove container", "error", err, "node", node.Name)
+				failed++
+				continue
+			}
+			m.pool.Exec(ctx, "UPDATE nodes SET container_id='', updated_at=now() WHERE id=$1", node.ID)
+			opID := m.beginOp(ctx, OpProvision, node.ID, req)
+			go m.provisionNode(opID, node.ID, node.HostID, req)
+		case OpReconfigure:
+			// The reconfigure takes the waiting operation over in beginOp.
+			m.requestReconfigure(node.ID)
+		default:
+			m.finishOp(ctx, op.ID, "operation can't be retried")
+		}
+	}
+	return checked, failed
+}
+
+// ListOperations returns recent operations, newest first, optionally
+// filtered by state.
+func (m *Manager) ListOperations(ctx context.Context, state string, limit int) ([]Operation, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := m.pool.Query(ctx, `
+		SELECT o.id, o.kind, o.node_id, n.name, o.step, o.state, o.params, o.error, o.attempts, o.retry_at, o.created_at, o.updated_at
+		FROM operations o
+		JOIN nodes n ON n.id = o.node_id
+		WHERE $1 = '' OR o.state = $1
+		ORDER BY o.id DESC LIMIT $2`, state, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops := []Operation{}
+	for rows.Next() {
+		var o Operation
+		if err := rows.Scan(&o.ID, &o.Kind, &o.NodeID, &o.NodeName, &o.Step, &o.State, &o.Params,
+			&o.Error, &o.Attempts, &o.RetryAt, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ops = append(ops, o)
+	}
+	return ops, rows.Err()
+}
+
+// ErrOperationNotFound is returned for an unknown operation ID.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// GetOperation returns one operation by ID.
+func (m *Manager) GetOperation(ctx context.Context, id int64) (*Operation, error) {
+	var o Operation
+	err := m.pool.QueryRow(ctx, `
+		SELECT o.id, o.kind, o.node_id, n.name, o.step, o.state, o.params, o.error, o.attempts, o.retry_at, o.created_at, o.updated_at
+		FROM operations o
+		JOIN nodes n ON n.id = o.node_id
+		WHERE o.id = $1`, id).Scan(&o.ID, &o.Kind, &o.NodeID, &o.NodeName, &o.Step, &o.State, &o.Params,
+		&o.Error, &o.Attempts, &o.RetryAt, &o.CreatedAt, &o.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOperationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// recoverOperations finds operations left running by a crash. A provision
+// whose container was created is resumed by starting it; anything else has
+// its half-built container rolled back and the operation re-run.
+func (m *Manager) recoverOperations(ctx context.Context) {
+	ops, err := m.ListOperations(ctx, OpRunning, 1000)
+	if err != nil {
+		slog.Warn("recover operations", "error", err)
+		return
+	}
+
+	for _, op := range ops {
+		// Rotations only touch the database and are safe to re-run.
+		if op.Kind == OpRotate {
+			m.resumeRotation(op)
+			continue
+		}
+		// Port changes re-run their own wait and reachability check.
+		if op.Kind == OpStakingPort {
+			m.resumeStakingPortChange(op)
+			continue
+		}
+		// Dequeued starts and stops go back in the queue.
+		if op.Kind == OpStart || op.Kind == OpStop {
+			m.pool.Exec(ctx, "UPDATE operations SET state=$1, updated_at=now() WHERE id=$2", OpQueued, op.ID)
+			continue
+		}
+		// Validator registrations may have staked already: never re-issue.
+		if op.Kind == OpRegisterValidator {
+			m.resumeRegistration(op)
+			continue
+		}
+
+		node, err := m.GetNode(ctx, op.NodeID)
+		if err != nil {
+			continue
+		}
+		dc := m.clientFor(node.HostID)
+		if dc == nil {
+			// Leave it journaled; it is retried on the next startup.
+			slog.Warn("recover operation: host not connected", "op_id", op.ID, "node", node.Name)
+			continue
+		}
+		containerName := "avax-" + node.Name
+
+		// A created container only needs starting.
+		if op.Kind == OpProvision && op.Step == "created" {
+			if info, err := dc.ContainerInspect(ctx, containerName); err == nil {
+				if err := dc.ContainerStart(ctx, info.ID); err == nil {
+					m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, status='bootstrapping', updated_at=now() WHERE id=$2",
+						info.ID, node.ID)
+					m.finishOp(ctx, op.ID, "")
+					m.logEvent(ctx, "node.recovered", node.Name, "Resumed interrupted provisioning",
+						map[string]any{"op_id": op.ID, "step": op.Step})
+					continue
+				}
+			}
+		}
+
+		// Roll back whatever was half-built, then re-run from the start.
+		if err := dc.ContainerRemove(ctx, containerName, false); err != nil && !client.IsErrNotFound(err) {
+			slog.Warn("recover operation: remove container", "error", err, "node", node.Name)
+			continue
+		}
+		m.pool.Exec(ctx, "UPDATE nodes SET container_id='', updated_at=now() WHERE id=$1", node.ID)
+		m.pool.Exec(ctx, "UPDATE operations SET state=$1, updated_at=now() WHERE id=$2", OpResumed, op.ID)
+		m.logEvent(ctx, "node.recovered", node.Name,
+			fmt.Sprintf("Rolled back interrupted %s at step %q and restarted it", op.Kind, op.Step),
+			map[string]any{"op_id": op.ID, "step": op.Step})
+
+		switch op.Kind {
+		case OpProvision:
+			var req CreateNodeRequest
+			if err := json.Unmarshal(op.Params, &req); err != nil {
+				m.finishOp(ctx, op.ID, "invalid journaled params")
+				continue
+			}
+			opID := m.beginOp(ctx, OpProvision, node.ID, req)
+			go m.provisionNode(opID, node.ID, node.HostID, req)
+		case OpReconfigure:
+			m.requestReconfigure(node.ID)
+		}
+	}
+}
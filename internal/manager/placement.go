@@ -0,0 +1,156 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// L1 affinity modes for Placement.
+const (
+	AffinitySpread       = "spread"        // prefer hosts without validators of the L1
+	AffinityStrictSpread = "strict-spread" // never share a host with them
+	AffinityPack         = "pack"          // prefer hosts already running them
+)
+
+// Placement steers automatic host selection when CreateNode is called
+// without a host_id.
+type Placement struct {
+	// Labels restricts candidates to hosts whose labels match, e.g.
+	// {"arch": "x86_64"}.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// L1ID is the L1 the node is meant to validate. With Affinity it
+	// spreads (or packs) its validators across hosts.
+	L1ID     int64  `json:"l1_id,omitempty"`
+	Affinity string `json:"affinity,omitempty"` // spread (default), strict-spread, or pack
+}
+
+// hostCandidate is a host's load as seen by the scheduler.
+type hostCandidate struct {
+	Host
+	nodes      int   // active nodes
+	memUsed    int64 // sum of node memory limits, bytes
+	cpuUsed    float64
+	l1Siblings int // validators of Placement.L1ID on this host
+}
+
+// pickHost chooses a host for a new node: online and connected, matching
+// the placement labels, with room for the requested limits and a free
+// staking port. Hosts are ranked by L1 affinity, then fewest nodes, then
+// most unreserved memory.
+func (m *Manager) pickHost(ctx context.Context, p Placement, stakingPort int, cpus float64, memory int64) (int64, error) {
+	switch p.Affinity {
+	case "":
+		p.Affinity = AffinitySpread
+	case AffinitySpread, AffinityStrictSpread, AffinityPack:
+	default:
+		return 0, fmt.Errorf("invalid placement affinity %q (want spread, strict-spread, or pack)", p.Affinity)
+	}
+
+	hosts, err := m.ListHosts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list hosts: %w", err)
+	}
+	load, err := m.hostLoad(ctx, p.L1ID)
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []hostCandidate
+	var rejected []string
+	for _, h := range hosts {
+		c := load[h.ID]
+		c.Host = h
+		reason := m.unplaceable(ctx, c, p, stakingPort, cpus, memory)
+		if reason != "" {
+			rejected = append(rejected, h.Name+": "+reason)
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("no host can run this node (%s)", strings.Join(rejected, "; "))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.l1Siblings != b.l1Siblings {
+			if p.Affinity == AffinityPack {
+				return a.l1Siblings > b.l1Siblings
+			}
+			return a.l1Siblings < b.l1Siblings
+		}
+		if a.nodes != b.nodes {
+			return a.nodes < b.nodes
+		}
+		return freeMemory(a) > freeMemory(b)
+	})
+	chosen := candidates[0]
+	slog.Info("placement", "host", chosen.Name, "nodes", chosen.nodes, "candidates", len(candidates))
+	return chosen.ID, nil
+}
+
+// unplaceable returns why a node can't go on a host, or "" if it can.
+func (m *Manager) unplaceable(ctx context.Context, c hostCandidate, p Placement, stakingPort int, cpus float64, memory int64) string {
+	if c.Status != "online" || m.clientFor(c.ID) == nil {
+		return "not connected"
+	}
+	for k, v := range p.Labels {
+		if got, ok := c.Labels[k]; !ok || fmt.Sprint(got) != v {
+			return fmt.Sprintf("label %s != %s", k, v)
+		}
+	}
+	if p.L1ID != 0 && p.Affinity == AffinityStrictSpread && c.l1Siblings > 0 {
+		return "already runs a validator of the L1"
+	}
+	if n, ok := c.Labels["cpus"].(float64); ok && n > 0 && cpus > 0 && c.cpuUsed+cpus > n {
+		return "not enough unreserved CPU"
+	}
+	if mb, ok := c.Labels["memory_mb"].(float64); ok && mb > 0 && memory > 0 && c.memUsed+memory > int64(mb)<<20 {
+		return "not enough unreserved memory"
+	}
+	if err := m.checkPortConflicts(ctx, c.ID, 0, []int{stakingPort}); err != nil {
+		return "staking port in use"
+	}
+	return ""
+}
+
+// hostLoad sums active nodes and their resource limits per host, and
+// counts validators of l1ID (0 = none) on each.
+func (m *Manager) hostLoad(ctx context.Context, l1ID int64) (map[int64]hostCandidate, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT n.host_id, COUNT(*), COALESCE(SUM(n.memory_limit), 0)::BIGINT, COALESCE(SUM(n.cpu_limit), 0)::DOUBLE PRECISION,
+		       COUNT(v.node_id)
+		FROM nodes n
+		LEFT JOIN l1_validators v ON v.node_id = n.id AND v.l1_id = $1
+		WHERE n.status NOT IN ('stopped','failed')
+		GROUP BY n.host_id`, l1ID)
+	if err != nil {
+		return nil, fmt.Errorf("host load: %w", err)
+	}
+	defer rows.Close()
+
+	load := map[int64]hostCandidate{}
+	for rows.Next() {
+		var hostID int64
+		var c hostCandidate
+		if err := rows.Scan(&hostID, &c.nodes, &c.memUsed, &c.cpuUsed, &c.l1Siblings); err != nil {
+			return nil, err
+		}
+		load[hostID] = c
+	}
+	return load, rows.Err()
+}
+
+// freeMemory is a host's memory not reserved by node limits, or 0 if the
+// host's memory is unknown.
+func freeMemory(c hostCandidate) int64 {
+	mb, ok := c.Labels["memory_mb"].(float64)
+	if !ok {
+		return 0
+	}
+	return int64(mb)<<20 - c.memUsed
+}
@@ -0,0 +1,147 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PollerStats reports a background poller's activity since startup.
+type PollerStats struct {
+	Name          string     `json:"name"`
+	Interval      string     `json:"interval"`
+	Paused        bool       `json:"paused"`
+	Runs          int64      `json:"runs"`
+	LastRun       *time.Time `json:"last_run,omitempty"`
+	LastDuration  float64    `json:"last_duration_seconds"`
+	Checked       int        `json:"checked"`  // items checked in the last run
+	Failures      int        `json:"failures"` // failed checks in the last run
+	TotalFailures int64      `json:"total_failures"`
+}
+
+// poller holds one background loop's stats and pause switch.
+type poller struct {
+	mu    sync.Mutex
+	stats PollerStats
+}
+
+// pollerJitter is the random fraction added to or taken off each poller
+// period, so loops started together drift apart instead of firing in sync.
+const pollerJitter = 0.1
+
+// startPoller runs fn every interval (±10%) until the manager stops,
+// skipping ticks while the poller is paused. The first run lands at a random
+// point within the first interval. fn reports how many items it checked and
+// how many of those failed. A poller without a positive interval never
+// runs.
+func (m *Manager) startPoller(name string, interval time.Duration, fn func() (checked, failed int)) {
+	if interval <= 0 {
+		slog.Warn("poller disabled: interval must be positive", "poller", name, "interval", interval)
+		return
+	}
+	p := &poller{stats: PollerStats{Name: name, Interval: interval.String()}}
+	m.pollersMu.Lock()
+	m.pollers[name] = p
+	m.pollersMu.Unlock()
+
+	m.pollerWg.Add(1)
+	go func() {
+		defer m.pollerWg.Done()
+		timer := time.NewTimer(rand.N(interval) + 1)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-m.stopPoller:
+				return
+			case <-timer.C:
+				timer.Reset(jitter(interval, pollerJitter))
+				p.mu.Lock()
+				paused := p.stats.Paused
+				p.mu.Unlock()
+				if paused {
+					continue
+				}
+
+				start := time.Now()
+				checked, failed := fn()
+
+				p.mu.Lock()
+				p.stats.Runs++
+				p.stats.LastRun = &start
+				p.stats.LastDuration = time.Since(start).Seconds()
+				p.stats.Checked = checked
+				p.stats.Failures = failed
+				p.stats.TotalFailures += int64(failed)
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// jitter returns d randomly stretched or shrunk by up to frac of itself.
+func jitter(d time.Duration, frac float64) time.Duration {
+	spread := time.Duration(float64(d) * frac)
+	if spread <= 0 {
+		return d
+	}
+	return d - spread + rand.N(2*spread+1)
+}
+
+// sleepOrStop waits for d and reports false if the manager stopped first.
+func (m *Manager) sleepOrStop(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-m.stopPoller:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// Pollers returns the stats of every running poller, sorted by name.
+func (m *Manager) Pollers() []PollerStats {
+	m.pollersMu.Lock()
+	defer m.pollersMu.Unlock()
+	out := make([]PollerStats, 0, len(m.pollers))
+	for _, p := range m.pollers {
+		p.mu.Lock()
+		out = append(out, p.stats)
+		p.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// SetPollerPaused pauses or resumes a poller, e.g. during control-plane
+// maintenance. A paused poller keeps its schedule but skips its work.
+func (m *Manager) SetPollerPaused(ctx context.Context, name string, paused bool) (*PollerStats, error) {
+	m.pollersMu.Lock()
+	p, ok := m.pollers[name]
+	m.pollersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("poller %q not found", name)
+	}
+
+	p.mu.Lock()
+	changed := p.stats.Paused != paused
+	p.stats.Paused = paused
+	st := p.stats
+	p.mu.Unlock()
+
+	if changed {
+		if paused {
+			m.logEvent(ctx, "poller.paused", name, "Poller paused", nil)
+			slog.Warn("poller paused", "poller", name)
+		} else {
+			m.logEvent(ctx, "poller.resumed", name, "Poller resumed", nil)
+			slog.Info("poller resumed", "poller", name)
+		}
+	}
+	return &st, nil
+}
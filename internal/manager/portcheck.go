@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeImage is the image used to run TCP probes from managed hosts.
+const probeImage = "busybox:latest"
+
+// PortCheckRequest selects where a staking-port check is run from.
+type PortCheckRequest struct {
+	FromHostIDs []int64 `json:"from_host_ids"` // empty = control plane + all other connected hosts
+}
+
+// PortProbe is the result of one reachability probe.
+type PortProbe struct {
+	From      string  `json:"from"`
+	HostID    int64   `json:"host_id,omitempty"`
+	Reachable bool    `json:"reachable"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// PortCheckResult is the verdict of a staking-port reachability test.
+type PortCheckResult struct {
+	NodeID    int64       `json:"node_id"`
+	Target    string      `json:"target"`
+	Reachable bool        `json:"reachable"`
+	Diagnosis string      `json:"diagnosis"`
+	Probes    []PortProbe `json:"probes"`
+	CheckedAt time.Time   `json:"checked_at"`
+}
+
+// CheckStakingPort verifies that a node's staking port is reachable on its
+// public IP, probing from the control plane and from other managed hosts.
+func (m *Manager) CheckStakingPort(ctx context.Context, id int64, req PortCheckRequest) (*PortCheckResult, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if !containerUp(node.Status) {
+		return nil, fmt.Errorf("node %q is not running", node.Name)
+	}
+
+	// The node reports the public IP it advertises to peers.
+	var ipRes struct {
+		IP string `json:"ip"`
+	}
+	if err := m.callNodeRPC(ctx, *node, "/ext/info", "info.getNodeIP", nil, &ipRes); err != nil {
+		return nil, fmt.Errorf("get node IP: %w", err)
+	}
+	host, _, err := net.SplitHostPort(ipRes.IP)
+	if err != nil {
+		host = ipRes.IP
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return nil, fmt.Errorf("node advertises non-public IP %q", ipRes.IP)
+	}
+	target := net.JoinHostPort(host, strconv.Itoa(node.StakingPort))
+
+	result := &PortCheckResult{NodeID: node.ID, Target: target, CheckedAt: time.Now().UTC()}
+
+	hostIDs := req.FromHostIDs
+	probeControlPlane := len(hostIDs) == 0
+	if len(hostIDs) == 0 {
+		hosts, err := m.ListHosts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hosts {
+			// The node's own host would test hairpin NAT, not reachability.
+			if h.ID != node.HostID && m.clientFor(h.ID) != nil {
+				hostIDs = append(hostIDs, h.ID)
+			}
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	add := func(p PortProbe) {
+		mu.Lock()
+		result.Probes = append(result.Probes, p)
+		mu.Unlock()
+	}
+
+	if probeControlPlane {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			add(probeTCP(target))
+		}()
+	}
+	for _, hid := range hostIDs {
+		wg.Add(1)
+		go func(hid int64) {
+			defer wg.Done()
+			add(m.probeTCPFromHost(probeCtx, hid, host, node.StakingPort))
+		}(hid)
+	}
+	wg.Wait()
+
+	reachable := 0
+	for _, p := range result.Probes {
+		if p.Reachable {
+			reachable++
+		}
+	}
+	result.Reachable = reachable > 0
+	switch {
+	case len(result.Probes) == 0:
+		result.Diagnosis = "no probes available — add another host or call from the control plane"
+	case reachable == len(result.Probes):
+		result.Diagnosis = "staking port reachable from all probes"
+	case reachable > 0:
+		result.Diagnosis = "staking port reachable from some probes only — check firewall source restrictions or hairpin NAT"
+	default:
+		result.Diagnosis = fmt.Sprintf("staking port unreachable — check NAT port forwarding and firewall rules for TCP %d", node.StakingPort)
+	}
+
+	severity := SeverityInfo
+	if reachable < len(result.Probes) {
+		severity = SeverityWarning
+	}
+	m.logEventAt(ctx, severity, "node.port_check", node.Name, result.Diagnosis,
+		map[string]any{"target": target, "reachable": result.Reachable, "probes": len(result.Probes)})
+	return result, nil
+}
+
+// probeTCP dials target directly from the control plane.
+func probeTCP(target string) PortProbe {
+	p := PortProbe{From: "control-plane"}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", target, 5*time.Second)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	conn.Close()
+	p.Reachable = true
+	p.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	return p
+}
+
+// probeTCPFromHost runs a short-lived probe container on a managed host
+// that attempts a TCP connection to ip:port.
+func (m *Manager) probeTCPFromHost(ctx context.Context, hostID int64, ip string, port int) PortProbe {
+	p := PortProbe{From: fmt.Sprintf("host %d", hostID), HostID: hostID}
+	if h, err := m.GetHost(ctx, hostID); err == nil {
+		p.From = h.Name
+	}
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		p.Error = "host not connected"
+		return p
+	}
+	code, out, err := dc.RunOnce(ctx, probeImage, []string{"nc", "-z", "-w", "5", ip, strconv.Itoa(port)}, nil)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if code != 0 {
+		p.Error = strings.TrimSpace(out)
+		if p.Error == "" {
+			p.Error = fmt.Sprintf("connection failed (exit %d)", code)
+		}
+		return p
+	}
+	p.Reachable = true
+	return p
+}
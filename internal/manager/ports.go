@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// checkPortConflicts returns an error if any of ports is taken on the host
+// by another node with a container, stopped ones included, or one being
+// created: a port handed out while its holder is stopped would fail the
+// holder's next start. excludeNodeID skips a node being reconfigured
+// (0 = none). Callers that go on to insert a node hold portsMu.
+func (m *Manager) checkPortConflicts(ctx context.Context, hostID, excludeNodeID int64, ports []int) error {
+	used, err := m.hostPortsInUse(ctx, hostID, excludeNodeID, false)
+	if err != nil {
+		return err
+	}
+	return portConflict(used, ports)
+}
+
+// checkStartPorts returns an error if a port the node binds is held by
+// another node whose container is up, so a start fails with the node's
+// name rather than Docker's bind error.
+func (m *Manager) checkStartPorts(ctx context.Context, node Node) error {
+	used, err := m.hostPortsInUse(ctx, node.HostID, node.ID, true)
+	if err != nil {
+		return err
+	}
+	ports := []int{node.StakingPort}
+	if bindsHTTPPort(node.NetworkMode, node.ExposeHTTP) {
+		ports = append(ports, node.HTTPPort)
+	}
+	return portConflict(used, ports)
+}
+
+func portConflict(used map[int]string, ports []int) error {
+	for _, p := range ports {
+		if name, ok := used[p]; ok {
+			return fmt.Errorf("port %d already in use on this host by node %q", p, name)
+		}
+	}
+	return nil
+}
+
+// hostPortsInUse maps each port held on the host to the node holding it:
+// nodes with a container or being created, or with up true only those
+// whose container is running. Every node binds its staking port;
+// host-network and expose_http nodes bind their HTTP port as well.
+func (m *Manager) hostPortsInUse(ctx context.Context, hostID, excludeNodeID int64, up bool) (map[int]string, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT name, staking_port, http_port, network_mode, expose_http FROM nodes
+		WHERE host_id=$1 AND id != $2
+		  AND (status = ANY($3) OR (NOT $4 AND (container_id <> '' OR status = 'creating')))`,
+		hostID, excludeNodeID, []string{"bootstrapping", "running", "unhealthy"}, up)
+	if err != nil {
+		return nil, fmt.Errorf("check port: %w", err)
+	}
+	defer rows.Close()
+
+	used := map[int]string{}
+	for rows.Next() {
+		var name, mode string
+		var stakingPort, httpPort int
+		var exposeHTTP bool
+		if err := rows.Scan(&name, &stakingPort, &httpPort, &mode, &exposeHTTP); err != nil {
+			return nil, fmt.Errorf("check port: %w", err)
+		}
+		used[stakingPort] = name
+		if bindsHTTPPort(mode, exposeHTTP) {
+			used[httpPort] = name
+		}
+	}
+	return used, rows.Err()
+}
+
+// bindsHTTPPort reports whether a node binds its HTTP port on the host.
+func bindsHTTPPort(networkMode string, exposeHTTP bool) bool {
+	return networkMode == "host" || exposeHTTP
+}
+
+// freeHTTPPort returns the lowest port from 9650 up that no node holds on
+// the host (see checkPortConflicts), skipping reserved (the new node's own
+// staking port). Callers hold portsMu until the node is inserted. Ports
+// held by processes outside avalauncher aren't known here; Docker reports
+// them when the container starts.
+func (m *Manager) freeHTTPPort(ctx context.Context, hostID int64, reserved ...int) (int, error) {
+	used, err := m.hostPortsInUse(ctx, hostID, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	for p := 9650; p <= 65535; p++ {
+		if _, ok := used[p]; !ok && !slices.Contains(reserved, p) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("no free HTTP port on host %d", hostID)
+}
+
+// checkHostNetworkSupported rejects host networking on Docker daemons that
+// don't run natively on Linux (e.g. Docker Desktop), where it has no effect.
+func (m *Manager) checkHostNetworkSupported(ctx context.Context, hostID int64) error {
+	h, err := m.GetHost(ctx, hostID)
+	if err != nil {
+		return fmt.Errorf("get host: %w", err)
+	}
+	if osName, _ := h.Labels["os"].(string); strings.Contains(osName, "Docker Desktop") {
+		return fmt.Errorf("host network mode requires a Linux Docker host (host %q runs %s)", h.Name, osName)
+	}
+	return nil
+}
+
+// hostAddress returns the address at which the manager can reach ports bound
+// directly on a host: the Docker bridge gateway for the local host, or the
+// SSH hostname for remote hosts.
+func (m *Manager) hostAddress(ctx context.Context, hostID int64) string {
+	if hostID == m.localHostID {
+		if m.localGateway != "" {
+			return m.localGateway
+		}
+		return "127.0.0.1"
+	}
+	var sshAddr, address string
+	if err := m.pool.QueryRow(ctx, "SELECT ssh_addr, address FROM hosts WHERE id=$1", hostID).Scan(&sshAddr, &address); err != nil {
+		return ""
+	}
+	if address != "" {
+		return address
+	}
+	return sshHostname(sshAddr)
+}
+
+// sshHostname extracts the hostname from an SSH address like user@host:22.
+func sshHostname(sshAddr string) string {
+	if i := strings.LastIndex(sshAddr, "@"); i >= 0 {
+		sshAddr = sshAddr[i+1:]
+	}
+	if i := strings.LastIndex(sshAddr, ":"); i >= 0 && !strings.Contains(sshAddr[i:], "]") {
+		sshAddr = sshAddr[:i]
+	}
+	return strings.Trim(sshAddr, "[]")
+}
@@ -0,0 +1,167 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/pchain"
+)
+
+// OpRegisterValidator journals a Primary Network validator registration.
+const OpRegisterValidator = "register_validator"
+
+// defaultDelegationFee is the percent of delegator rewards a new validator
+// keeps, the Primary Network minimum.
+const defaultDelegationFee = 2.0
+
+// RegisterValidatorRequest stakes a node as a Primary Network validator.
+type RegisterValidatorRequest struct {
+	Stake         uint64  `json:"stake"`          // nAVAX locked for the staking period
+	Duration      string  `json:"duration"`       // Go duration, e.g. "336h" (mainnet: 2 weeks to 1 year)
+	RewardAddress string  `json:"reward_address"` // P-Chain address receiving validation and delegation rewards
+	DelegationFee float64 `json:"delegation_fee"` // percent; default 2
+	PrivateKey    string  `json:"private_key"`    // pays the stake and fee; default PCHAIN_PRIVATE_KEY
+}
+
+// registration is a validated RegisterValidatorRequest.
+type registration struct {
+	node      Node
+	key       *pchain.PrivateKey
+	duration  time.Duration
+	validator pchain.PrimaryValidator
+}
+
+// RegisterValidator issues an AddPermissionlessValidatorTx staking the node
+// as a Primary Network validator, paid from the request's key or the
+// configured wallet and issued through the node's own P-Chain API. It runs
+// in the background as a journaled operation, which is returned; on commit
+// the node row records the tx ID and the staking period's end.
+func (m *Manager) RegisterValidator(ctx context.Context, id int64, req RegisterValidatorRequest) (*Operation, error) {
+	if req.Stake == 0 {
+		return nil, fmt.Errorf("stake is required (nAVAX)")
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q", req.Duration)
+	}
+	if _, _, err := pchain.ParseAddress(req.RewardAddress); err != nil {
+		return nil, fmt.Errorf("invalid reward_address: %w", err)
+	}
+	if req.DelegationFee == 0 {
+		req.DelegationFee = defaultDelegationFee
+	}
+	if req.DelegationFee < 0 || req.DelegationFee > 100 {
+		return nil, fmt.Errorf("delegation_fee must be a percentage")
+	}
+
+	r := &registration{key: m.defaultPChainKey(), duration: duration}
+	if req.PrivateKey != "" {
+		if r.key, err = pchain.ParseKey(req.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+	if r.key == nil {
+		return nil, fmt.Errorf("private_key is required (PCHAIN_PRIVATE_KEY is not set)")
+	}
+
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	if node.StakingEnd != nil && node.StakingEnd.After(time.Now()) {
+		return nil, fmt.Errorf("node %q is already validating until %s", node.Name, node.StakingEnd.UTC().Format(time.RFC3339))
+	}
+	if ready, reason := m.nodeReady(ctx, *node); !ready {
+		return nil, fmt.Errorf("node %q cannot issue transactions: %s", node.Name, reason)
+	}
+	signer, err := m.validatorSigner(ctx, *node)
+	if err != nil {
+		return nil, fmt.Errorf("node %q: %w", node.Name, err)
+	}
+	r.node = *node
+	r.validator = pchain.PrimaryValidator{
+		NodeID:            signer.NodeID,
+		Stake:             req.Stake,
+		PublicKey:         signer.PublicKey,
+		ProofOfPossession: signer.ProofOfPossession,
+		RewardAddress:     req.RewardAddress,
+		DelegationFee:     req.DelegationFee,
+	}
+
+	opID := m.beginOp(ctx, OpRegisterValidator, id, map[string]any{
+		"stake": req.Stake, "duration": duration.String(),
+		"reward_address": req.RewardAddress, "delegation_fee": req.DelegationFee,
+	})
+	if opID == 0 {
+		return nil, fmt.Errorf("could not journal validator registration")
+	}
+	go m.runRegistration(opID, r)
+	return m.GetOperation(ctx, opID)
+}
+
+// runRegistration issues the transaction and records the staking period.
+func (m *Manager) runRegistration(opID int64, r *registration) {
+	ctx, cancel := context.WithTimeout(context.Background(), deployTimeout)
+	defer cancel()
+
+	fail := func(err error) {
+		slog.Error("register validator", "error", err, "node", r.node.Name)
+		m.finishOp(ctx, opID, err.Error())
+		m.logEvent(ctx, "node.validator_failed", r.node.Name, fmt.Sprintf("Validator registration failed: %v", err),
+			map[string]any{"op_id": opID})
+	}
+
+	rpc := func(ctx context.Context, endpoint, method string, params, out any) error {
+		return m.callNodeRPC(ctx, r.node, endpoint, method, params, out)
+	}
+	w, err := pchain.NewWallet(ctx, r.key, rpc)
+	if err != nil {
+		fail(err)
+		return
+	}
+	r.validator.End = time.Now().Add(r.duration).Truncate(time.Second)
+	m.logEvent(ctx, "node.validator_registering", r.node.Name,
+		fmt.Sprintf("Staking %s AVAX until %s, paid by %s", formatAVAX(r.validator.Stake),
+			r.validator.End.UTC().Format(time.RFC3339), w.Address()),
+		map[string]any{"op_id": opID})
+
+	txID, err := w.AddPrimaryValidator(ctx, r.validator)
+	if err != nil {
+		fail(err)
+		return
+	}
+	m.opStep(ctx, opID, "committed")
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET validator_tx_id=$1, staking_end=$2, updated_at=now() WHERE id=$3",
+		txID.String(), r.validator.End, r.node.ID); err != nil {
+		slog.Error("register validator: record staking period", "error", err, "node", r.node.Name)
+	}
+	m.finishOp(ctx, opID, "")
+	m.logEvent(ctx, "node.validator_registered", r.node.Name,
+		fmt.Sprintf("Primary Network validator until %s (tx %s)", r.validator.End.UTC().Format(time.RFC3339), txID),
+		map[string]any{"op_id": opID, "tx_id": txID.String()})
+}
+
+// resumeRegistration fails a registration interrupted by a restart. It is
+// not retried: the transaction may have committed, and the stake with it.
+func (m *Manager) resumeRegistration(op Operation) {
+	msg := "interrupted by a restart; check platform.getCurrentValidators before retrying"
+	if op.Step == "committed" {
+		msg = "interrupted after the transaction committed; the staking period is picked up by the uptime poller"
+	}
+	m.finishOp(context.Background(), op.ID, msg)
+}
+
+// formatAVAX renders nAVAX as AVAX, e.g. 2000000000000 -> "2000".
+func formatAVAX(n uint64) string {
+	s := fmt.Sprintf("%d.%09d", n/1_000_000_000, n%1_000_000_000)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
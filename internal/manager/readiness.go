@@ -0,0 +1,146 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PendingValidator is a validator assignment queued until its node is ready.
+type PendingValidator struct {
+	NodeID    int64     `json:"node_id"`
+	NodeName  string    `json:"node_name"`
+	Weight    int64     `json:"weight"`
+	Reason    string    `json:"reason"` // why the node was last found not ready
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// nodeReady reports whether node can safely become a validator: it must be
+// running, pass its health check and have bootstrapped the P-Chain. When it
+// isn't ready the second value says why.
+func (m *Manager) nodeReady(ctx context.Context, node Node) (bool, string) {
+	if node.Status != "running" || node.ContainerID == "" {
+		return false, fmt.Sprintf("status is %s", node.Status)
+	}
+	if !m.checkNodeHealth(ctx, node) {
+		return false, "health check failing"
+	}
+	booted, err := m.pChainBootstrapped(ctx, node)
+	if err != nil {
+		return false, fmt.Sprintf("bootstrap status unavailable: %v", err)
+	}
+	if !booted {
+		return false, "P-Chain still bootstrapping"
+	}
+	return true, ""
+}
+
+// pChainBootstrapped asks a node (info.isBootstrapped) whether it has
+// bootstrapped the P-Chain.
+func (m *Manager) pChainBootstrapped(ctx context.Context, node Node) (bool, error) {
+	var res struct {
+		IsBootstrapped bool `json:"isBootstrapped"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/info", "info.isBootstrapped", map[string]string{"chain": "P"}, &res); err != nil {
+		return false, err
+	}
+	return res.IsBootstrapped, nil
+}
+
+// queueValidator records a validator assignment to apply once the node is
+// ready. The health poller picks it up.
+func (m *Manager) queueValidator(ctx context.Context, l1ID int64, l1Name string, node Node, weight int64, reason string) (*L1Validator, error) {
+	_, err := m.pool.Exec(ctx, `
+		INSERT INTO pending_validators (l1_id, node_id, weight, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (l1_id, node_id) DO UPDATE SET weight=EXCLUDED.weight, reason=EXCLUDED.reason`,
+		l1ID, node.ID, weight, reason)
+	if err != nil {
+		return nil, fmt.Errorf("queue validator: %w", err)
+	}
+
+	m.logEvent(ctx, "l1.validator.queued", l1Name,
+		fmt.Sprintf("Validator queued: node %s not ready (%s)", node.Name, reason),
+		map[string]any{"node": node.Name, "weight": weight})
+	return &L1Validator{NodeID: node.ID, NodeName: node.Name, Weight: weight, Pending: true}, nil
+}
+
+// pendingValidators returns the queued validators for an L1.
+func (m *Manager) pendingValidators(ctx context.Context, l1ID int64) ([]PendingValidator, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT p.node_id, n.name, p.weight, p.reason, p.created_at
+		FROM pending_validators p
+		JOIN nodes n ON p.node_id = n.id
+		WHERE p.l1_id = $1
+		ORDER BY p.created_at`, l1ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := []PendingValidator{}
+	for rows.Next() {
+		var p PendingValidator
+		if err := rows.Scan(&p.NodeID, &p.NodeName, &p.Weight, &p.Reason, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// applyPendingValidators promotes queued validators whose nodes have become
+// ready. It runs after each health poll.
+func (m *Manager) applyPendingValidators(ctx context.Context) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT p.l1_id, l.name, l.subnet_id, p.node_id, p.weight, p.reason
+		FROM pending_validators p
+		JOIN l1s l ON p.l1_id = l.id
+		ORDER BY p.created_at`)
+	if err != nil {
+		slog.Error("pending validators", "error", err)
+		return
+	}
+	type pending struct {
+		l1ID           int64
+		l1Name, subnet string
+		nodeID, weight int64
+		reason         string
+	}
+	var queue []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.l1ID, &p.l1Name, &p.subnet, &p.nodeID, &p.weight, &p.reason); err != nil {
+			rows.Close()
+			slog.Error("pending validators", "error", err)
+			return
+		}
+		queue = append(queue, p)
+	}
+	rows.Close()
+
+	for _, p := range queue {
+		node, err := m.GetNode(ctx, p.nodeID)
+		if err != nil {
+			continue
+		}
+		ready, reason := m.nodeReady(ctx, *node)
+		if !ready {
+			if reason != p.reason {
+				m.pool.Exec(ctx, "UPDATE pending_validators SET reason=$1 WHERE l1_id=$2 AND node_id=$3",
+					reason, p.l1ID, p.nodeID)
+			}
+			continue
+		}
+
+		// Claim the row first so a concurrent removal wins.
+		tag, err := m.pool.Exec(ctx, "DELETE FROM pending_validators WHERE l1_id=$1 AND node_id=$2", p.l1ID, p.nodeID)
+		if err != nil || tag.RowsAffected() == 0 {
+			continue
+		}
+		if _, err := m.insertValidator(ctx, p.l1ID, p.l1Name, p.subnet, node.ID, node.Name, p.weight); err != nil {
+			slog.Error("apply pending validator", "error", err, "l1", p.l1Name, "node", node.Name)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// Startup timeouts. Each remote host gets its own, so a hung SSH host can't
+// hold up startup for the others.
+const (
+	hostConnectTimeout     = 15 * time.Second // SSH connect and ping of one host
+	reconcileHostTimeout   = time.Minute      // reconciliation of one host
+	startupRecoveryTimeout = time.Minute      // secret resealing and recovery of interrupted work
+)
+
+// ReconcileResult reports how reconciliation went on one host.
+type ReconcileResult struct {
+	HostID    int64     `json:"host_id"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration_seconds"`
+	Nodes     int       `json:"nodes"`     // nodes with a container on the host
+	Updated   int       `json:"updated"`   // node statuses changed
+	Unmanaged int       `json:"unmanaged"` // unmanaged AvalancheGo containers found
+	TimedOut  bool      `json:"timed_out,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// reconcileReport keeps the results of the last reconciliation.
+type reconcileReport struct {
+	mu      sync.Mutex
+	results []ReconcileResult
+}
+
+// ReconcileResults returns the per-host results of the last startup
+// reconciliation, ordered by host name.
+func (m *Manager) ReconcileResults() []ReconcileResult {
+	m.reconciled.mu.Lock()
+	defer m.reconciled.mu.Unlock()
+	return append([]ReconcileResult{}, m.reconciled.results...)
+}
+
+// reconcile syncs DB node statuses with actual Docker container states. Hosts
+// are reconciled concurrently, each under its own timeout; a host that
+// doesn't finish in time is reported as timed out and left to the health
+// poller.
+func (m *Manager) reconcile() error {
+	slog.Info("running startup reconciliation")
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileHostTimeout)
+	defer cancel()
+
+	m.clientsMu.RLock()
+	hostClients := make(map[int64]*docker.Client, len(m.clients))
+	for id, dc := range m.clients {
+		hostClients[id] = dc
+	}
+	m.clientsMu.RUnlock()
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	byHost := make(map[int64][]Node)
+	for _, node := range nodes {
+		if node.ContainerID != "" {
+			byHost[node.HostID] = append(byHost[node.HostID], node)
+		}
+	}
+
+	hostNames := make(map[int64]string)
+	rows, err := m.pool.Query(ctx, "SELECT id, name FROM hosts")
+	if err != nil {
+		return fmt.Errorf("list hosts: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err == nil {
+			hostNames[id] = name
+		}
+	}
+	rows.Close()
+
+	var results []ReconcileResult
+	started := time.Now()
+	done := make(chan ReconcileResult, len(hostClients))
+	pending := make(map[int64]bool, len(hostClients))
+	for hostID, dc := range hostClients {
+		pending[hostID] = true
+		go func() {
+			hctx, cancel := context.WithTimeout(context.Background(), reconcileHostTimeout)
+			defer cancel()
+			done <- m.reconcileHost(hctx, hostID, hostNames[hostID], dc, byHost[hostID])
+		}()
+	}
+	for hostID, name := range hostNames {
+		if hostClients[hostID] == nil {
+			results = append(results, ReconcileResult{HostID: hostID, Host: name, StartedAt: started,
+				Nodes: len(byHost[hostID]), Error: "not connected"})
+		}
+	}
+
+	// A Docker call that ignores its context would still block; stop
+	// waiting shortly after the timeout and report the host instead.
+	deadline := time.NewTimer(reconcileHostTimeout + 5*time.Second)
+	defer deadline.Stop()
+wait:
+	for len(pending) > 0 {
+		select {
+		case r := <-done:
+			delete(pending, r.HostID)
+			results = append(results, r)
+		case <-deadline.C:
+			break wait
+		}
+	}
+	for hostID := range pending {
+		r := ReconcileResult{HostID: hostID, Host: hostNames[hostID], StartedAt: started,
+			Duration: time.Since(started).Seconds(), Nodes: len(byHost[hostID]), TimedOut: true,
+			Error: fmt.Sprintf("timed out after %s", reconcileHostTimeout)}
+		m.logEvent(ctx, "host.reconcile_failed", r.Host, "Startup reconciliation "+r.Error, nil)
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Host < results[j].Host })
+	m.reconciled.mu.Lock()
+	m.reconciled.results = results
+	m.reconciled.mu.Unlock()
+	return nil
+}
+
+// reconcileHost syncs the statuses of one host's nodes with its containers
+// and reports unmanaged AvalancheGo containers found there.
+func (m *Manager) reconcileHost(ctx context.Context, hostID int64, hostName string, dc *docker.Client, nodes []Node) (r ReconcileResult) {
+	r = ReconcileResult{HostID: hostID, Host: hostName, StartedAt: time.Now(), Nodes: len(nodes)}
+	defer func() { r.Duration = time.Since(r.StartedAt).Seconds() }()
+
+	containers, err := dc.ListManagedContainers(ctx)
+	if err != nil {
+		slog.Warn("reconcile: list containers", "host", hostName, "error", err)
+		r.TimedOut = errors.Is(err, context.DeadlineExceeded)
+		r.Error = err.Error()
+		// The event outlives the host's timeout.
+		m.logEvent(context.WithoutCancel(ctx), "host.reconcile_failed", hostName, "Startup reconciliation failed: "+err.Error(), nil)
+		return r
+	}
+	stateMap := make(map[string]string)
+	for _, c := range containers {
+		stateMap[c.Name] = c.State
+	}
+
+	for _, node := range nodes {
+		state, found := stateMap["avax-"+node.Name]
+		if !found {
+			// Adopted containers carry no managed-by label; look them up by ID.
+			if info, err := dc.ContainerInspect(ctx, node.ContainerID); err == nil && info.State != nil {
+				state, found = info.State.Status, true
+			}
+		}
+		if ctx.Err() != nil {
+			// Out of time: a failed lookup says nothing about the container.
+			r.TimedOut = true
+			r.Error = fmt.Sprintf("timed out after %s", reconcileHostTimeout)
+			m.logEvent(context.WithoutCancel(ctx), "host.reconcile_failed", hostName, "Startup reconciliation "+r.Error, nil)
+			return r
+		}
+		var newStatus string
+		if !found {
+			// Container gone — mark as stopped.
+			newStatus = "stopped"
+		} else {
+			switch state {
+			case "running":
+				// Keep the finer status; a node found up from stopped
+				// bootstraps before the health poller calls it running.
+				newStatus = node.Status
+				if !containerUp(node.Status) {
+					newStatus = "bootstrapping"
+				}
+			case "exited", "dead":
+				newStatus = "stopped"
+			case "created", "restarting":
+				newStatus = "creating"
+			default:
+				newStatus = "stopped"
+			}
+		}
+
+		if newStatus != node.Status {
+			slog.Info("reconcile", "node", node.Name, "old_status", node.Status, "new_status", newStatus)
+			_, err := m.pool.Exec(ctx, "UPDATE nodes SET status=$1, updated_at=now() WHERE id=$2", newStatus, node.ID)
+			if err != nil {
+				slog.Error("reconcile update", "error", err, "node", node.Name)
+				continue
+			}
+			r.Updated++
+		}
+	}
+
+	r.Unmanaged = m.reportUnmanaged(ctx, hostID, hostName)
+	return r
+}
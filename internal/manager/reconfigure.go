@@ -0,0 +1,31 @@
+package manager
+
+// requestReconfigure schedules a container recreate for a node. Requests are
+// serialized per node: while one runs, further requests coalesce into a
+// single rerun, which picks up every change made in the meantime since
+// reconfigureNode reads the node's configuration fresh.
+func (m *Manager) requestReconfigure(nodeID int64) {
+	m.reconfigsMu.Lock()
+	if _, running := m.reconfigs[nodeID]; running {
+		m.reconfigs[nodeID] = true
+		m.reconfigsMu.Unlock()
+		return
+	}
+	m.reconfigs[nodeID] = false
+	m.reconfigsMu.Unlock()
+
+	go func() {
+		for {
+			m.reconfigureNode(nodeID)
+
+			m.reconfigsMu.Lock()
+			if !m.reconfigs[nodeID] {
+				delete(m.reconfigs, nodeID)
+				m.reconfigsMu.Unlock()
+				return
+			}
+			m.reconfigs[nodeID] = false
+			m.reconfigsMu.Unlock()
+		}
+	}()
+}
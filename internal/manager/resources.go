@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/go-units"
+)
+
+// minMemoryLimit is the smallest memory limit Docker accepts.
+const minMemoryLimit = 6 << 20
+
+// parseMemoryLimit turns a limit like "8g" or "512m" into bytes. Empty
+// means unlimited.
+func parseMemoryLimit(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := units.RAMInBytes(s)
+	if err != nil || n < minMemoryLimit {
+		return 0, fmt.Errorf("invalid memory_limit %q (want a size of at least 6m, e.g. \"8g\")", s)
+	}
+	return n, nil
+}
+
+// checkResourceLimits rejects limits the host can't satisfy, using the CPU
+// and memory totals recorded when the host was added.
+func (m *Manager) checkResourceLimits(ctx context.Context, hostID int64, cpus float64, memory int64) error {
+	if cpus < 0 || (cpus > 0 && cpus < 0.01) {
+		return fmt.Errorf("cpu_limit must be at least 0.01 cores")
+	}
+	if cpus == 0 && memory == 0 {
+		return nil
+	}
+	h, err := m.GetHost(ctx, hostID)
+	if err != nil {
+		return fmt.Errorf("get host: %w", err)
+	}
+	if n, ok := h.Labels["cpus"].(float64); ok && n > 0 && cpus > n {
+		return fmt.Errorf("cpu_limit %.2f exceeds the %d CPUs of host %q", cpus, int(n), h.Name)
+	}
+	if mb, ok := h.Labels["memory_mb"].(float64); ok && mb > 0 && memory > int64(mb)<<20 {
+		return fmt.Errorf("memory_limit exceeds the %d MiB of host %q", int64(mb), h.Name)
+	}
+	return nil
+}
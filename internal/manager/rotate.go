@@ -0,0 +1,228 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// OpRotate journals a validator rotation. Its node_id is the incoming node.
+const OpRotate = "rotate"
+
+// RotateValidatorRequest holds parameters for replacing one validator with
+// another on an L1.
+type RotateValidatorRequest struct {
+	FromNodeID int64  `json:"from_node_id"`
+	ToNodeID   int64  `json:"to_node_id"`
+	Weight     int64  `json:"weight"`  // default: the outgoing validator's weight
+	Timeout    string `json:"timeout"` // how long to wait for the new node, e.g. "2h" (default 1h)
+}
+
+// rotation is the journaled state of a rotation, enough to resume it.
+type rotation struct {
+	L1ID     int64     `json:"l1_id"`
+	FromNode int64     `json:"from_node_id"`
+	ToNode   int64     `json:"to_node_id"`
+	Weight   int64     `json:"weight"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// rotationPollInterval is how often a rotation checks on the new node.
+const rotationPollInterval = 10 * time.Second
+
+// RotateValidator replaces validator FromNodeID with ToNodeID: the new node is
+// added (queued until it is ready), the manager waits for it to be
+// reconfigured, healthy and bootstrapped on the L1's chain, and only then
+// removes the old one. It runs in the background; progress is journaled as
+// an operation, which is returned.
+func (m *Manager) RotateValidator(ctx context.Context, l1ID int64, req RotateValidatorRequest) (*Operation, error) {
+	if req.FromNodeID == 0 || req.ToNodeID == 0 {
+		return nil, fmt.Errorf("from_node_id and to_node_id are required")
+	}
+	if req.FromNodeID == req.ToNodeID {
+		return nil, fmt.Errorf("from_node_id and to_node_id must differ")
+	}
+	timeout := time.Hour
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", req.Timeout)
+		}
+		timeout = d
+	}
+
+	l1, err := m.GetL1(ctx, l1ID)
+	if err != nil {
+		return nil, ErrL1NotFound
+	}
+	var from *L1Validator
+	for i, v := range l1.Validators {
+		if v.NodeID == req.ToNodeID {
+			return nil, fmt.Errorf("node %q is already a validator for L1 %q", v.NodeName, l1.Name)
+		}
+		if v.NodeID == req.FromNodeID {
+			from = &l1.Validators[i]
+		}
+	}
+	if from == nil {
+		return nil, fmt.Errorf("node %d is not a validator for L1 %q", req.FromNodeID, l1.Name)
+	}
+	to, err := m.GetNode(ctx, req.ToNodeID)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+
+	var busy bool
+	if err := m.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM operations WHERE kind=$1 AND state=$2 AND params->>'l1_id' = $3)`,
+		OpRotate, OpRunning, fmt.Sprint(l1ID)).Scan(&busy); err != nil {
+		return nil, fmt.Errorf("check rotations: %w", err)
+	}
+	if busy {
+		return nil, fmt.Errorf("L1 %q already has a rotation in progress", l1.Name)
+	}
+
+	r := rotation{
+		L1ID:     l1ID,
+		FromNode: req.FromNodeID,
+		ToNode:   req.ToNodeID,
+		Weight:   req.Weight,
+		Deadline: time.Now().Add(timeout),
+	}
+	if r.Weight <= 0 {
+		r.Weight = from.Weight
+	}
+	opID := m.beginOp(ctx, OpRotate, to.ID, r)
+	if opID == 0 {
+		return nil, fmt.Errorf("could not journal rotation")
+	}
+	m.logEvent(ctx, "l1.validator.rotating", l1.Name,
+		fmt.Sprintf("Rotating validator %s → %s", from.NodeName, to.Name),
+		map[string]any{"op_id": opID, "from": from.NodeName, "to": to.Name})
+
+	go m.runRotation(opID, r)
+	return m.GetOperation(ctx, opID)
+}
+
+// runRotation drives a rotation to completion. Every step is idempotent so a
+// rotation interrupted by a restart is simply run again.
+func (m *Manager) runRotation(opID int64, r rotation) {
+	ctx, cancel := context.WithDeadline(context.Background(), r.Deadline)
+	defer cancel()
+
+	var l1Name string
+	m.pool.QueryRow(ctx, "SELECT name FROM l1s WHERE id=$1", r.L1ID).Scan(&l1Name)
+	fail := func(msg string) {
+		// Use a fresh context: the deadline may be what failed us.
+		fctx, fcancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer fcancel()
+		m.finishOp(fctx, opID, msg)
+		m.logEvent(fctx, "l1.validator.rotation_failed", l1Name, "Rotation failed: "+msg+" (old validator kept)",
+			map[string]any{"op_id": opID})
+	}
+
+	// 1. Add the new node, or queue it until it is ready.
+	if !m.isValidator(ctx, r.L1ID, r.ToNode) {
+		var pending bool
+		m.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pending_validators WHERE l1_id=$1 AND node_id=$2)",
+			r.L1ID, r.ToNode).Scan(&pending)
+		if !pending {
+			if _, err := m.AddValidator(ctx, r.L1ID, AddValidatorRequest{
+				NodeID: r.ToNode, Weight: r.Weight, WhenReady: true,
+			}); err != nil {
+				fail(err.Error())
+				return
+			}
+		}
+	}
+	m.opStep(ctx, opID, "added")
+
+	// 2. Wait for it to be assigned, reconfigured and serving the L1.
+	ticker := time.NewTicker(rotationPollInterval)
+	defer ticker.Stop()
+	reason := "waiting"
+	for {
+		var ready bool
+		ready, reason = m.rotationTargetReady(ctx, r)
+		if ready {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			fail("timed out waiting for new validator: " + reason)
+			return
+		case <-ticker.C:
+		}
+	}
+	m.opStep(ctx, opID, "healthy")
+
+	// 3. Retire the old node.
+	if m.isValidator(ctx, r.L1ID, r.FromNode) {
+		if err := m.RemoveValidator(ctx, r.L1ID, r.FromNode); err != nil {
+			fail("remove old validator: " + err.Error())
+			return
+		}
+	}
+	m.opStep(ctx, opID, "removed")
+	m.finishOp(ctx, opID, "")
+	m.logEvent(ctx, "l1.validator.rotated", l1Name, "Validator rotation complete",
+		map[string]any{"op_id": opID, "from_node_id": r.FromNode, "to_node_id": r.ToNode})
+}
+
+// rotationTargetReady reports whether the incoming node is an active
+// validator, done reconfiguring, ready, and bootstrapped on the L1's chain.
+func (m *Manager) rotationTargetReady(ctx context.Context, r rotation) (bool, string) {
+	if !m.isValidator(ctx, r.L1ID, r.ToNode) {
+		return false, "validator still queued"
+	}
+	m.reconfigsMu.Lock()
+	_, reconfiguring := m.reconfigs[r.ToNode]
+	m.reconfigsMu.Unlock()
+	if reconfiguring {
+		return false, "node reconfiguring"
+	}
+	node, err := m.GetNode(ctx, r.ToNode)
+	if err != nil {
+		return false, "node not found"
+	}
+	if ready, reason := m.nodeReady(ctx, *node); !ready {
+		return false, reason
+	}
+
+	var blockchainID string
+	m.pool.QueryRow(ctx, "SELECT blockchain_id FROM l1s WHERE id=$1", r.L1ID).Scan(&blockchainID)
+	if blockchainID == "" {
+		return true, ""
+	}
+	var res struct {
+		IsBootstrapped bool `json:"isBootstrapped"`
+	}
+	if err := m.callNodeRPC(ctx, *node, "/ext/info", "info.isBootstrapped", map[string]string{"chain": blockchainID}, &res); err != nil {
+		return false, fmt.Sprintf("L1 chain status unavailable: %v", err)
+	}
+	if !res.IsBootstrapped {
+		return false, "L1 chain still bootstrapping"
+	}
+	return true, ""
+}
+
+// isValidator reports whether node is an active validator of the L1.
+func (m *Manager) isValidator(ctx context.Context, l1ID, nodeID int64) bool {
+	var exists bool
+	m.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM l1_validators WHERE l1_id=$1 AND node_id=$2)",
+		l1ID, nodeID).Scan(&exists)
+	return exists
+}
+
+// resumeRotation restarts a journaled rotation after a restart.
+func (m *Manager) resumeRotation(op Operation) {
+	var r rotation
+	if err := json.Unmarshal(op.Params, &r); err != nil {
+		m.finishOp(context.Background(), op.ID, "invalid journaled params")
+		return
+	}
+	slog.Info("resuming validator rotation", "op_id", op.ID, "step", op.Step)
+	go m.runRotation(op.ID, r)
+}
@@ -0,0 +1,103 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// nodeBaseURL returns the base URL of a node's AvalancheGo HTTP API. Bridge
+// nodes are addressed by container name on the shared Docker network, which
+// only exists on the local host; host-network nodes and exposed bridge nodes
+// on remote hosts by their host's address.
+func (m *Manager) nodeBaseURL(ctx context.Context, node Node) string {
+	if node.NetworkMode == "host" {
+		return fmt.Sprintf("http://%s:%d", m.hostAddress(ctx, node.HostID), node.HTTPPort)
+	}
+	if node.ExposeHTTP && node.HostID != m.localHostID {
+		return fmt.Sprintf("http://%s:%d", m.hostAddress(ctx, node.HostID), node.HTTPPort)
+	}
+	return fmt.Sprintf("http://avax-%s:9650", node.Name)
+}
+
+// rpcError is the error object of a JSON-RPC 2.0 response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// callNodeRPC issues a JSON-RPC 2.0 request against a node endpoint
+// (e.g. "/ext/info") and decodes the result into out. Nodes whose API the
+// control plane can't address, or can't connect to, are called from inside
+// their container instead (see execNodeHTTP).
+func (m *Manager) callNodeRPC(ctx context.Context, node Node, endpoint, method string, params any, out any) error {
+	payload := map[string]any{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		payload["params"] = params
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if !m.apiReachable(node) {
+		return m.execNodeRPC(ctx, node, endpoint, method, body, out)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", m.nodeBaseURL(ctx, node)+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		m.recordLatency(ctx, node.ID, method, time.Since(start), false)
+		if ctx.Err() == nil && node.ContainerID != "" {
+			return m.execNodeRPC(ctx, node, endpoint, method, body, out)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	m.recordLatency(ctx, node.ID, method, time.Since(start), true)
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: read response: %w", method, err)
+	}
+	if err := decodeRPC(method, raw, out); err != nil {
+		return fmt.Errorf("%w (HTTP %d)", err, resp.StatusCode)
+	}
+	return nil
+}
+
+// execNodeRPC sends a JSON-RPC request from inside the node's container.
+func (m *Manager) execNodeRPC(ctx context.Context, node Node, endpoint, method string, body []byte, out any) error {
+	raw, err := m.execNodeHTTP(ctx, node, endpoint, string(body))
+	if err != nil {
+		return fmt.Errorf("%s via exec: %w", method, err)
+	}
+	return decodeRPC(method, []byte(raw), out)
+}
+
+// decodeRPC decodes a JSON-RPC 2.0 response body into out.
+func decodeRPC(method string, raw []byte, out any) error {
+	var result struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("%s: %s", method, result.Error.Message)
+	}
+	if out == nil || len(result.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Result, out)
+}
@@ -0,0 +1,256 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/dns"
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// RPCDNSConfig enables health-based DNS for public L1 RPC hostnames.
+type RPCDNSConfig struct {
+	Provider     dns.Provider
+	TTL          int    // record TTL in seconds
+	LocalAddress string // public IP or name of the local host (empty = local nodes aren't published)
+}
+
+// RPCDNSState is the last DNS sync of an L1's RPC hostname.
+type RPCDNSState struct {
+	Hostname string     `json:"hostname"`
+	Records  []string   `json:"records"` // addresses currently published
+	SyncedAt *time.Time `json:"synced_at,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// rpcDNS holds the DNS provider and per-L1 sync state.
+type rpcDNS struct {
+	cfg RPCDNSConfig // set before Start
+
+	mu        sync.Mutex
+	state     map[int64]*RPCDNSState // l1ID -> last sync
+	noHealthy map[int64]bool         // l1IDs whose last sync found no healthy node
+}
+
+// rpcDNSRefresh is how often published records are re-sent even when
+// unchanged, repairing edits made at the provider.
+const rpcDNSRefresh = time.Hour
+
+// SetRPCDNS enables health-based DNS: after every health poll, each exposed
+// L1's RPC hostname is pointed at the hosts of its healthy backing nodes.
+// Call it before Start.
+func (m *Manager) SetRPCDNS(cfg RPCDNSConfig) {
+	m.rpcDNS.cfg = cfg
+	m.rpcDNS.state = map[int64]*RPCDNSState{}
+	m.rpcDNS.noHealthy = map[int64]bool{}
+	slog.Info("RPC DNS failover enabled", "provider", cfg.Provider.Name(), "ttl", cfg.TTL)
+}
+
+// rpcDNSState returns an L1's last DNS sync, or nil when DNS is disabled or
+// the L1 hasn't been synced.
+func (m *Manager) rpcDNSState(l1ID int64) *RPCDNSState {
+	m.rpcDNS.mu.Lock()
+	defer m.rpcDNS.mu.Unlock()
+	if s, ok := m.rpcDNS.state[l1ID]; ok {
+		c := *s
+		return &c
+	}
+	return nil
+}
+
+// rpcDNSTarget is an L1 whose RPC hostname is, or was, published.
+type rpcDNSTarget struct {
+	id        int64
+	name      string
+	exposed   bool     // rpc_url is set
+	published []string // l1s.rpc_dns_records
+}
+
+// syncRPCDNS brings every exposed L1's DNS records in line with the health of
+// its RPC nodes, and removes the records of withdrawn endpoints. If no
+// backing node is healthy the records are left as they are: a degraded
+// endpoint beats an unresolvable one.
+func (m *Manager) syncRPCDNS() {
+	cfg := m.rpcDNS.cfg
+	if cfg.Provider == nil || m.traefikDomain == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT id, name, rpc_url != '', rpc_dns_records FROM l1s
+		WHERE rpc_url != '' OR cardinality(rpc_dns_records) > 0
+		ORDER BY id`)
+	if err != nil {
+		slog.Error("rpc dns: list L1s", "error", err)
+		return
+	}
+	var targets []rpcDNSTarget
+	for rows.Next() {
+		var t rpcDNSTarget
+		if err := rows.Scan(&t.id, &t.name, &t.exposed, &t.published); err != nil {
+			rows.Close()
+			slog.Error("rpc dns: scan L1", "error", err)
+			return
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+
+	for _, t := range targets {
+		m.syncL1DNS(ctx, cfg, t)
+	}
+}
+
+// syncL1DNS publishes one L1's healthy RPC node addresses.
+func (m *Manager) syncL1DNS(ctx context.Context, cfg RPCDNSConfig, t rpcDNSTarget) {
+	hostname := docker.L1RPCHost(t.name, m.traefikDomain)
+	want := []string{}
+	if t.exposed {
+		addrs, total, err := m.healthyRPCAddrs(ctx, t.id, cfg.LocalAddress)
+		if err != nil {
+			slog.Error("rpc dns: resolve backends", "l1", t.name, "error", err)
+			return
+		}
+		m.rpcDNS.mu.Lock()
+		wasDown := m.rpcDNS.noHealthy[t.id]
+		m.rpcDNS.noHealthy[t.id] = len(addrs) == 0
+		m.rpcDNS.mu.Unlock()
+		if len(addrs) == 0 {
+			if !wasDown {
+				m.logEvent(ctx, "l1.rpc_dns_no_healthy", t.name,
+					fmt.Sprintf("No healthy RPC node among %d; DNS for %s left unchanged", total, hostname), nil)
+			}
+			return
+		}
+		want = addrs
+	}
+
+	m.rpcDNS.mu.Lock()
+	prev := m.rpcDNS.state[t.id]
+	m.rpcDNS.mu.Unlock()
+	if slices.Equal(want, t.published) && prev != nil && prev.Error == "" &&
+		prev.SyncedAt != nil && time.Since(*prev.SyncedAt) < rpcDNSRefresh {
+		return
+	}
+
+	state := &RPCDNSState{Hostname: hostname, Records: t.published}
+	if err := cfg.Provider.SetRecords(ctx, hostname, want, cfg.TTL); err != nil {
+		state.Error = err.Error()
+		if prev == nil || prev.Error != state.Error {
+			m.logEvent(ctx, "l1.rpc_dns_failed", t.name,
+				fmt.Sprintf("Updating %s at %s failed: %v", hostname, cfg.Provider.Name(), err), nil)
+		}
+	} else {
+		now := time.Now()
+		state.Records, state.SyncedAt = want, &now
+		if _, err := m.pool.Exec(ctx, "UPDATE l1s SET rpc_dns_records=$1 WHERE id=$2", want, t.id); err != nil {
+			slog.Error("rpc dns: store records", "l1", t.name, "error", err)
+		}
+		if !slices.Equal(want, t.published) {
+			added, removed := stringDiff(t.published, want), stringDiff(want, t.published)
+			m.logEvent(ctx, "l1.rpc_dns_updated", t.name,
+				fmt.Sprintf("%s now points at %d address(es)", hostname, len(want)),
+				map[string]any{"hostname": hostname, "records": want, "added": added, "removed": removed, "provider": cfg.Provider.Name()})
+		}
+	}
+
+	m.rpcDNS.mu.Lock()
+	if t.exposed {
+		m.rpcDNS.state[t.id] = state
+	} else if state.Error == "" {
+		delete(m.rpcDNS.state, t.id)
+		delete(m.rpcDNS.noHealthy, t.id)
+	}
+	m.rpcDNS.mu.Unlock()
+}
+
+// healthyRPCAddrs returns the sorted public IPs of the hosts running an L1's
+// healthy RPC nodes, and how many RPC nodes the L1 has. A node counts as
+// healthy while it is running on an online host (drained ones don't count).
+func (m *Manager) healthyRPCAddrs(ctx context.Context, l1ID int64, localAddr string) ([]string, int, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT n.name, n.host_id, n.status, h.status
+		FROM l1_rpc_nodes r
+		JOIN nodes n ON n.id = r.node_id
+		JOIN hosts h ON h.id = n.host_id
+		WHERE r.l1_id = $1`, l1ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	type backend struct {
+		name, status, hostStatus string
+		hostID                   int64
+	}
+	var backends []backend
+	for rows.Next() {
+		var b backend
+		if err := rows.Scan(&b.name, &b.hostID, &b.status, &b.hostStatus); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		backends = append(backends, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var ips []string
+	for _, b := range backends {
+		if b.status != "running" || b.hostStatus != "online" {
+			continue
+		}
+		addr := localAddr
+		if b.hostID != m.localHostID {
+			addr = m.hostAddress(ctx, b.hostID)
+		}
+		if addr == "" {
+			slog.Warn("rpc dns: no public address for node's host", "node", b.name)
+			continue
+		}
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, ip.String())
+			continue
+		}
+		resolved, err := net.DefaultResolver.LookupIPAddr(ctx, addr)
+		if err != nil {
+			slog.Warn("rpc dns: resolve host address", "node", b.name, "address", addr, "error", err)
+			continue
+		}
+		for _, ip := range resolved {
+			ips = append(ips, ip.IP.String())
+		}
+	}
+	slices.Sort(ips)
+	return slices.Compact(ips), len(backends), nil
+}
+
+// withdrawRPCDNS deletes an L1's published records, e.g. when it is deleted.
+func (m *Manager) withdrawRPCDNS(ctx context.Context, name string, published []string) {
+	cfg := m.rpcDNS.cfg
+	if cfg.Provider == nil || m.traefikDomain == "" || len(published) == 0 {
+		return
+	}
+	hostname := docker.L1RPCHost(name, m.traefikDomain)
+	if err := cfg.Provider.SetRecords(ctx, hostname, nil, cfg.TTL); err != nil {
+		slog.Warn("rpc dns: withdraw records", "l1", name, "hostname", hostname, "error", err)
+	}
+}
+
+// stringDiff returns the strings of b not in a.
+func stringDiff(a, b []string) []string {
+	out := []string{}
+	for _, s := range b {
+		if !slices.Contains(a, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,437 @@
+package manager
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/primal-host/avalauncher/internal/docker"
+	"github.com/primal-host/avalauncher/internal/storage"
+)
+
+// Snapshot states.
+const (
+	SnapshotCreating = "creating"
+	SnapshotDone     = "done"
+	SnapshotFailed   = "failed"
+)
+
+// snapshotTimeout bounds taking or restoring one snapshot; mainnet
+// databases run to hundreds of gigabytes.
+const snapshotTimeout = 12 * time.Hour
+
+// Snapshot is a backup of a node: an archive of its database volume or an
+// export of its staking keys. It outlives the node, so a replacement can be
+// restored from it.
+type Snapshot struct {
+	ID         int64      `json:"id"`
+	Kind       string     `json:"kind"`    // db or staking_keys
+	NodeID     *int64     `json:"node_id"` // nil once the node is deleted
+	NodeName   string     `json:"node_name"`
+	Network    string     `json:"network"`
+	Image      string     `json:"image"`
+	Version    string     `json:"version,omitempty"`
+	Store      string     `json:"store"`
+	Key        string     `json:"key"`
+	SizeBytes  int64      `json:"size_bytes"` // compressed
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// RestoreRequest is the body of a node restore.
+type RestoreRequest struct {
+	SnapshotID int64 `json:"snapshot_id"`
+}
+
+// snapshots holds the backup store and the nodes with a snapshot or
+// restore in progress.
+type snapshots struct {
+	cfg BackupConfig // set before Start; zero Store = backups disabled
+
+	mu   sync.Mutex
+	busy map[int64]bool
+}
+
+// SetBackups sets where node snapshots and staking key exports are written,
+// and how many of each are kept. Call it before Start, which resumes
+// interrupted snapshots.
+func (m *Manager) SetBackups(cfg BackupConfig) {
+	m.snapshots.cfg = cfg
+	m.snapshots.busy = map[int64]bool{}
+	slog.Info("node backups enabled", "store", cfg.Store.Name(), "keep_last", cfg.KeepLast)
+}
+
+// backupStore returns the configured store, or an error when there is none.
+func (m *Manager) backupStore() (storage.Store, error) {
+	if m.snapshots.cfg.Store == nil {
+		return nil, fmt.Errorf("backups are not configured (set BACKUP_TARGET)")
+	}
+	return m.snapshots.cfg.Store, nil
+}
+
+// claimSnapshotNode marks a node busy with a snapshot or restore and
+// returns the store; release with releaseSnapshotNode.
+func (m *Manager) claimSnapshotNode(node *Node) (storage.Store, error) {
+	store, err := m.backupStore()
+	if err != nil {
+		return nil, err
+	}
+	m.snapshots.mu.Lock()
+	defer m.snapshots.mu.Unlock()
+	if m.snapshots.busy[node.ID] {
+		return nil, fmt.Errorf("node %q already has a snapshot or restore in progress", node.Name)
+	}
+	m.snapshots.busy[node.ID] = true
+	return store, nil
+}
+
+func (m *Manager) releaseSnapshotNode(id int64) {
+	m.snapshots.mu.Lock()
+	delete(m.snapshots.busy, id)
+	m.snapshots.mu.Unlock()
+}
+
+const snapshotColumns = `id, kind, node_id, node_name, network, image, avago_version, store, key, size_bytes, state, error,
+	created_at, finished_at`
+
+func scanSnapshot(row pgx.Row) (*Snapshot, error) {
+	var s Snapshot
+	err := row.Scan(&s.ID, &s.Kind, &s.NodeID, &s.NodeName, &s.Network, &s.Image, &s.Version, &s.Store, &s.Key,
+		&s.SizeBytes, &s.State, &s.Error, &s.CreatedAt, &s.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ErrSnapshotNotFound is returned for an unknown snapshot or backup ID.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// GetSnapshot returns one snapshot.
+func (m *Manager) GetSnapshot(ctx context.Context, id int64) (*Snapshot, error) {
+	s, err := scanSnapshot(m.pool.QueryRow(ctx, "SELECT "+snapshotColumns+" FROM snapshots WHERE id=$1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSnapshotNotFound
+	}
+	return s, err
+}
+
+// ListSnapshots returns database snapshots newest first, optionally of one
+// node.
+func (m *Manager) ListSnapshots(ctx context.Context, nodeID int64) ([]Snapshot, error) {
+	return m.ListBackups(ctx, nodeID, BackupDB)
+}
+
+// ListBackups returns backups newest first, optionally only of one node
+// and one kind.
+func (m *Manager) ListBackups(ctx context.Context, nodeID int64, kind string) ([]Snapshot, error) {
+	rows, err := m.pool.Query(ctx, "SELECT "+snapshotColumns+` FROM snapshots
+		WHERE ($1 = 0 OR node_id = $1) AND ($2 = '' OR kind = $2) ORDER BY created_at DESC`, nodeID, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Snapshot{}
+	for rows.Next() {
+		s, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// SnapshotNode archives a node's database volume to the backup store in
+// the background. A running node is stopped for the copy, so the archive
+// is consistent, and started again afterwards.
+func (m *Manager) SnapshotNode(ctx context.Context, id int64) (*Snapshot, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if node.ContainerID == "" {
+		return nil, fmt.Errorf("node %q has no container", node.Name)
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", node.HostID)
+	}
+	store, err := m.claimSnapshotNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapID int64
+	err = m.pool.QueryRow(ctx, `
+		INSERT INTO snapshots (kind, node_id, node_name, network, image, avago_version, store, state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		BackupDB, node.ID, node.Name, node.Network, node.Image, node.Version, store.Name(), SnapshotCreating).Scan(&snapID)
+	if err != nil {
+		m.releaseSnapshotNode(node.ID)
+		return nil, fmt.Errorf("insert snapshot: %w", err)
+	}
+	key := fmt.Sprintf("snapshots/%s/%s-%d.tar.gz", node.Name, time.Now().UTC().Format("20060102T150405Z"), snapID)
+	m.pool.Exec(ctx, "UPDATE snapshots SET key=$1 WHERE id=$2", key, snapID)
+
+	go func() {
+		defer m.releaseSnapshotNode(node.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+		size, err := m.writeSnapshot(ctx, dc, node, store, key)
+
+		// The outcome is recorded on a fresh context: ctx may be what ran out.
+		rctx, rcancel := context.WithTimeout(context.Background(), time.Minute)
+		defer rcancel()
+		if err != nil {
+			store.Delete(rctx, key)
+			m.pool.Exec(rctx, "UPDATE snapshots SET state=$1, error=$2, finished_at=now() WHERE id=$3",
+				SnapshotFailed, err.Error(), snapID)
+			m.logEvent(rctx, "node.snapshot_failed", node.Name, "Snapshot failed: "+err.Error(),
+				map[string]any{"snapshot_id": snapID})
+			return
+		}
+		m.pool.Exec(rctx, "UPDATE snapshots SET state=$1, size_bytes=$2, finished_at=now() WHERE id=$3",
+			SnapshotDone, size, snapID)
+		m.logEvent(rctx, "node.snapshot_created", node.Name,
+			fmt.Sprintf("Snapshot %d written to %s (%d bytes)", snapID, store.Name(), size),
+			map[string]any{"snapshot_id": snapID, "key": key, "size_bytes": size})
+		m.pruneBackups(rctx, store, node.Name, BackupDB)
+	}()
+	return m.GetSnapshot(ctx, snapID)
+}
+
+// writeSnapshot streams the node's db directory, gzipped, into the store.
+func (m *Manager) writeSnapshot(ctx context.Context, dc *docker.Client, node *Node, store storage.Store, key string) (int64, error) {
+	resume, err := m.pauseNode(ctx, dc, node)
+	if err != nil {
+		return 0, err
+	}
+	defer resume()
+
+	rc, err := dc.ArchiveDir(ctx, node.ContainerID, docker.DBDir)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		_, err := io.Copy(gz, rc)
+		if err == nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	size, err := store.Put(ctx, key, pr)
+	pr.CloseWithError(err)
+	return size, err
+}
+
+// RestoreNode replaces a node's database with a snapshot in the background.
+// The node is stopped, its db volume emptied and refilled from the archive,
+// and started again if it was running.
+func (m *Manager) RestoreNode(ctx context.Context, id int64, req RestoreRequest) (*Snapshot, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := m.GetSnapshot(ctx, req.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Kind != BackupDB {
+		return nil, fmt.Errorf("backup %d is not a database snapshot", snap.ID)
+	}
+	if snap.State != SnapshotDone {
+		return nil, fmt.Errorf("snapshot %d is %s", snap.ID, snap.State)
+	}
+	if snap.Network != node.Network {
+		return nil, fmt.Errorf("snapshot %d is of a %s node, %q runs on %s", snap.ID, snap.Network, node.Name, node.Network)
+	}
+	if node.ContainerID == "" {
+		return nil, fmt.Errorf("node %q has no container", node.Name)
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", node.HostID)
+	}
+	store, err := m.claimSnapshotNode(node)
+	if err != nil {
+		return nil, err
+	}
+	if store.Name() != snap.Store {
+		m.releaseSnapshotNode(node.ID)
+		return nil, fmt.Errorf("snapshot %d is in %s, not the configured %s", snap.ID, snap.Store, store.Name())
+	}
+
+	go func() {
+		defer m.releaseSnapshotNode(node.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		defer cancel()
+		details := map[string]any{"snapshot_id": snap.ID, "from_node": snap.NodeName}
+		err := m.readSnapshot(ctx, dc, node, store, snap.Key)
+
+		rctx, rcancel := context.WithTimeout(context.Background(), time.Minute)
+		defer rcancel()
+		if err != nil {
+			m.logEvent(rctx, "node.restore_failed", node.Name, "Restore failed: "+err.Error(), details)
+			return
+		}
+		m.logEvent(rctx, "node.restored", node.Name,
+			fmt.Sprintf("Database restored from snapshot %d of %s", snap.ID, snap.NodeName), details)
+	}()
+	return snap, nil
+}
+
+// readSnapshot empties the node's db volume and unpacks the archive into it.
+// The archive is read through and checked first, so one that is corrupt or
+// unreadable fails the restore before the node's database is touched.
+func (m *Manager) readSnapshot(ctx context.Context, dc *docker.Client, node *Node, store storage.Store, key string) error {
+	if err := verifySnapshot(ctx, store, key); err != nil {
+		return err
+	}
+
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", key, err)
+	}
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", key, err)
+	}
+
+	resume, err := m.pauseNode(ctx, dc, node)
+	if err != nil {
+		return err
+	}
+	defer resume()
+
+	p := &docker.AvagoParams{Name: node.Name}
+	if err := dc.EmptyVolume(ctx, probeImage, p.VolumeDB()); err != nil {
+		return err
+	}
+	// Entries are named db/..., so they land in the db volume.
+	return dc.ExtractArchive(ctx, node.ContainerID, docker.DataDir, gz)
+}
+
+// verifySnapshot reads a database snapshot end to end: its gzip checksum,
+// its tar structure, and that every entry lies under db/.
+func verifySnapshot(ctx context.Context, store storage.Store, key string) error {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", key, err)
+	}
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", key, err)
+	}
+	tr := tar.NewReader(gz)
+	entries := 0
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", key, err)
+		}
+		name := path.Clean(h.Name)
+		if name != "db" && !strings.HasPrefix(name, "db/") {
+			return fmt.Errorf("verify %s: entry %q is outside db/", key, h.Name)
+		}
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return fmt.Errorf("verify %s: %w", key, err)
+		}
+		entries++
+	}
+	// Reading to the end checks the gzip trailer.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return fmt.Errorf("verify %s: %w", key, err)
+	}
+	if entries == 0 {
+		return fmt.Errorf("verify %s: archive is empty", key)
+	}
+	return nil
+}
+
+// pauseNode stops a node's container if it is running. The returned
+// function starts it again, so the node ends up as it was found.
+func (m *Manager) pauseNode(ctx context.Context, dc *docker.Client, node *Node) (func(), error) {
+	info, err := dc.ContainerInspect(ctx, node.ContainerID)
+	if err != nil {
+		return nil, fmt.Errorf("inspect container: %w", err)
+	}
+	if info.State == nil || !info.State.Running {
+		return func() {}, nil
+	}
+
+	done := m.expectContainerEvents(node.ContainerID)
+	if err := dc.ContainerStop(ctx, node.ContainerID, 30); err != nil {
+		done()
+		return nil, fmt.Errorf("stop container: %w", err)
+	}
+	m.pool.Exec(ctx, "UPDATE nodes SET status='stopped', updated_at=now() WHERE id=$1", node.ID)
+	return func() {
+		defer done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := dc.ContainerStart(ctx, node.ContainerID); err != nil {
+			m.logEvent(ctx, "node.start_failed", node.Name, "Could not restart after snapshot: "+err.Error(), nil)
+			return
+		}
+		m.pool.Exec(ctx, "UPDATE nodes SET status='bootstrapping', updated_at=now() WHERE id=$1", node.ID)
+	}, nil
+}
+
+// DeleteSnapshot removes a backup of any kind and its archive.
+func (m *Manager) DeleteSnapshot(ctx context.Context, id int64) error {
+	snap, err := m.GetSnapshot(ctx, id)
+	if err != nil {
+		return err
+	}
+	if snap.State == SnapshotCreating {
+		return fmt.Errorf("snapshot %d is still being created", id)
+	}
+	store, _ := m.backupStore()
+	if err := m.removeBackup(ctx, store, snap); err != nil {
+		return err
+	}
+	m.logEvent(ctx, "node.snapshot_deleted", snap.NodeName, fmt.Sprintf("Backup %d deleted", id),
+		map[string]any{"snapshot_id": id, "kind": snap.Kind, "key": snap.Key})
+	return nil
+}
+
+// recoverSnapshots fails snapshots left in progress by a restart. Their node
+// may have been left stopped.
+func (m *Manager) recoverSnapshots(ctx context.Context) {
+	rows, err := m.pool.Query(ctx, `
+		UPDATE snapshots SET state=$1, error='interrupted by a control plane restart', finished_at=now()
+		WHERE state=$2 RETURNING id, node_name`, SnapshotFailed, SnapshotCreating)
+	if err != nil {
+		slog.Warn("recover snapshots", "error", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if rows.Scan(&id, &name) == nil {
+			slog.Warn("snapshot interrupted; check the node is running", "snapshot_id", id, "node", name)
+		}
+	}
+}
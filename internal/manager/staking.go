@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// blsOrder is the order r of the BLS12-381 scalar field. A BLS secret key
+// is a big-endian scalar in [1, r).
+var blsOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// stakingKeys is a node's identity: the TLS staking certificate and key
+// (which determine its NodeID) and its BLS signer key.
+type stakingKeys struct {
+	Cert   string // PEM certificate
+	Key    string // PEM PKCS#8 private key
+	Signer string // hex-encoded 32-byte BLS secret key
+}
+
+// generateStakingKeys creates a self-signed staking certificate in the same
+// shape AvalancheGo generates on first boot, plus a BLS signer key.
+func generateStakingKeys() (*stakingKeys, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return nil, fmt.Errorf("generate staking key: %w", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(0),
+		NotBefore:             time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Now().AddDate(100, 0, 0),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageDataEncipherment,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("create staking cert: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal staking key: %w", err)
+	}
+
+	// Rejection-sample a non-zero scalar below the BLS group order.
+	var signer []byte
+	for {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("generate signer key: %w", err)
+		}
+		if n := new(big.Int).SetBytes(b); n.Sign() > 0 && n.Cmp(blsOrder) < 0 {
+			signer = b
+			break
+		}
+	}
+
+	return &stakingKeys{
+		Cert:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Key:    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		Signer: hex.EncodeToString(signer),
+	}, nil
+}
+
+// nodeSecretAAD binds a sealed value to the node row and column it is
+// stored in.
+func nodeSecretAAD(nodeID int64, column string) string {
+	return fmt.Sprintf("nodes/%d/%s", nodeID, column)
+}
+
+// reserveNodeID allocates the ID of a node about to be inserted, so its
+// staking keys can be sealed to the row before the INSERT.
+func (m *Manager) reserveNodeID(ctx context.Context) (int64, error) {
+	var id int64
+	err := m.pool.QueryRow(ctx, "SELECT nextval(pg_get_serial_sequence('nodes', 'id'))").Scan(&id)
+	return id, err
+}
+
+// sealKeys encrypts the private parts of k for storage in node nodeID.
+func (m *Manager) sealKeys(nodeID int64, k *stakingKeys) error {
+	var err error
+	if k.Key, err = m.secrets.Seal(k.Key, nodeSecretAAD(nodeID, "staking_key")); err != nil {
+		return err
+	}
+	k.Signer, err = m.secrets.Seal(k.Signer, nodeSecretAAD(nodeID, "staking_signer"))
+	return err
+}
+
+// installStakingKeys copies a node's stored staking files into its container
+// before it starts, so a recreated container keeps the same NodeID. Nodes
+// created before keys were managed have none stored and are left alone.
+func (m *Manager) installStakingKeys(ctx context.Context, dc *docker.Client, nodeID int64, containerID string) error {
+	var k stakingKeys
+	if err := m.pool.QueryRow(ctx,
+		"SELECT staking_cert, staking_key, staking_signer FROM nodes WHERE id=$1", nodeID).
+		Scan(&k.Cert, &k.Key, &k.Signer); err != nil {
+		return fmt.Errorf("load staking keys: %w", err)
+	}
+	var err error
+	if k.Key, err = m.secrets.Open(k.Key, nodeSecretAAD(nodeID, "staking_key")); err != nil {
+		return fmt.Errorf("decrypt staking key: %w", err)
+	}
+	if k.Signer, err = m.secrets.Open(k.Signer, nodeSecretAAD(nodeID, "staking_signer")); err != nil {
+		return fmt.Errorf("decrypt signer key: %w", err)
+	}
+	if k.Cert == "" || k.Key == "" {
+		return nil
+	}
+	files := map[string][]byte{
+		"staker.crt": []byte(k.Cert),
+		"staker.key": []byte(k.Key),
+	}
+	if k.Signer != "" {
+		signer, err := hex.DecodeString(k.Signer)
+		if err != nil {
+			return fmt.Errorf("decode signer key: %w", err)
+		}
+		files["signer.key"] = signer
+	}
+	return dc.CopyFiles(ctx, containerID, docker.StakingDir, files, 0o600)
+}
+
+// resealSecrets rewrites every stored secret under the current master key:
+// plaintext rows are encrypted and rows sealed with the previous key are
+// re-encrypted. It runs at startup, so rotating the key is a matter of
+// setting SECRETS_KEY_PREVIOUS to the old key and SECRETS_KEY to the new one.
+func (m *Manager) resealSecrets(ctx context.Context) error {
+	if !m.secrets.Enabled() {
+		return nil
+	}
+	rows, err := m.pool.Query(ctx, "SELECT id, staking_key, staking_signer FROM nodes")
+	if err != nil {
+		return err
+	}
+	type row struct {
+		id          int64
+		key, signer string
+	}
+	var stale []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.key, &r.signer); err != nil {
+			rows.Close()
+			return err
+		}
+		if m.secrets.NeedsReseal(r.key) || m.secrets.NeedsReseal(r.signer) {
+			stale = append(stale, r)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range stale {
+		key, err := m.reseal(r.key, nodeSecretAAD(r.id, "staking_key"))
+		if err != nil {
+			return fmt.Errorf("node %d staking_key: %w", r.id, err)
+		}
+		signer, err := m.reseal(r.signer, nodeSecretAAD(r.id, "staking_signer"))
+		if err != nil {
+			return fmt.Errorf("node %d staking_signer: %w", r.id, err)
+		}
+		if _, err := m.pool.Exec(ctx,
+			"UPDATE nodes SET staking_key=$1, staking_signer=$2 WHERE id=$3", key, signer, r.id); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		slog.Info("secrets re-encrypted", "nodes", len(stale))
+	}
+	return m.resealManagedSecrets(ctx)
+}
+
+// reseal decrypts s (if sealed) and seals it under the current key, bound
+// to aad.
+func (m *Manager) reseal(s, aad string) (string, error) {
+	plain, err := m.secrets.Open(s, aad)
+	if err != nil {
+		return "", err
+	}
+	return m.secrets.Seal(plain, aad)
+}
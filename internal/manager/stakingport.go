@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OpStakingPort journals a staking port change.
+const OpStakingPort = "staking_port"
+
+// stakingPortTimeout bounds how long a port change waits for the recreated
+// node to come back healthy.
+const stakingPortTimeout = 10 * time.Minute
+
+// StakingPortRequest moves a node to a new staking port.
+type StakingPortRequest struct {
+	StakingPort int  `json:"staking_port"`
+	SkipVerify  bool `json:"skip_verify"` // don't run a reachability check afterwards
+}
+
+// stakingPortChange is the journaled state of a port change.
+type stakingPortChange struct {
+	From   int  `json:"from"`
+	To     int  `json:"to"`
+	Verify bool `json:"verify"`
+}
+
+// ChangeStakingPort moves a node to a new staking port without touching its
+// identity: the row is updated and the container recreated through the
+// usual reconfigure path, which reinstalls the same staking keys and keeps
+// the volumes. A running node is then waited on until healthy and its new
+// port checked for reachability. Progress is journaled as an operation,
+// which is returned; a stopped node just picks up the port on its next start.
+func (m *Manager) ChangeStakingPort(ctx context.Context, id int64, req StakingPortRequest) (*Operation, error) {
+	if req.StakingPort < 1 || req.StakingPort > 65535 {
+		return nil, fmt.Errorf("invalid staking_port %d", req.StakingPort)
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Status == "creating" {
+		return nil, fmt.Errorf("node %q is still being provisioned", node.Name)
+	}
+	if node.StakingPort == req.StakingPort {
+		return nil, fmt.Errorf("node %q already uses staking port %d", node.Name, req.StakingPort)
+	}
+	if bindsHTTPPort(node.NetworkMode, node.ExposeHTTP) && node.HTTPPort == req.StakingPort {
+		return nil, fmt.Errorf("http_port and staking_port must differ")
+	}
+	if err := m.checkPortConflicts(ctx, node.HostID, id, []int{req.StakingPort}); err != nil {
+		return nil, err
+	}
+
+	recreate := node.ContainerID != "" && node.Status != "stopped"
+	change := stakingPortChange{From: node.StakingPort, To: req.StakingPort, Verify: recreate && !req.SkipVerify}
+	opID := m.beginOp(ctx, OpStakingPort, id, change)
+	if opID == 0 {
+		return nil, fmt.Errorf("could not journal staking port change")
+	}
+
+	// Mark the node creating in the same write so waiters can't see the old
+	// container as already done.
+	status := node.Status
+	if recreate {
+		status = "creating"
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET staking_port=$1, status=$2, updated_at=now() WHERE id=$3",
+		req.StakingPort, status, id); err != nil {
+		m.finishOp(ctx, opID, err.Error())
+		return nil, fmt.Errorf("update staking port: %w", err)
+	}
+	m.opStep(ctx, opID, "updated")
+	m.logEvent(ctx, "node.staking_port_changed", node.Name,
+		fmt.Sprintf("Staking port %d → %d", change.From, change.To),
+		map[string]any{"op_id": opID, "from": change.From, "to": change.To})
+
+	if !recreate {
+		m.finishOp(ctx, opID, "")
+		return m.GetOperation(ctx, opID)
+	}
+	m.requestReconfigure(id)
+	go m.finishStakingPortChange(opID, id, change)
+	return m.GetOperation(ctx, opID)
+}
+
+// finishStakingPortChange waits for the recreated node to come back healthy
+// and, if asked, verifies that the new port is reachable.
+func (m *Manager) finishStakingPortChange(opID, id int64, change stakingPortChange) {
+	ctx, cancel := context.WithTimeout(context.Background(), stakingPortTimeout)
+	defer cancel()
+
+	node, err := m.WaitNode(ctx, id, WaitHealthy)
+	if err != nil {
+		// Use a fresh context: the timeout may be what failed us.
+		fctx, fcancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer fcancel()
+		msg := fmt.Sprintf("node did not come back healthy on port %d: %v", change.To, err)
+		m.finishOp(fctx, opID, msg)
+		if node != nil {
+			m.logEvent(fctx, "node.staking_port_failed", node.Name, msg, map[string]any{"op_id": opID})
+		}
+		return
+	}
+	m.opStep(ctx, opID, "healthy")
+
+	if !change.Verify {
+		m.finishOp(ctx, opID, "")
+		return
+	}
+	result, err := m.CheckStakingPort(ctx, id, PortCheckRequest{})
+	if err != nil {
+		m.finishOp(ctx, opID, "reachability check: "+err.Error())
+		return
+	}
+	if !result.Reachable {
+		m.finishOp(ctx, opID, result.Diagnosis)
+		m.logEvent(ctx, "node.staking_port_failed", node.Name,
+			fmt.Sprintf("Port %d not reachable: %s", change.To, result.Diagnosis),
+			map[string]any{"op_id": opID, "target": result.Target})
+		return
+	}
+	m.opStep(ctx, opID, "verified")
+	m.finishOp(ctx, opID, "")
+}
+
+// resumeStakingPortChange re-runs the wait and check of a port change that
+// was interrupted by a restart. The row already holds the new port; the
+// container is recreated again in case the crash beat the reconfigure.
+func (m *Manager) resumeStakingPortChange(op Operation) {
+	var change stakingPortChange
+	if err := json.Unmarshal(op.Params, &change); err != nil {
+		m.finishOp(context.Background(), op.ID, "invalid journaled params")
+		return
+	}
+	if op.Step == "" {
+		// Crashed before the row was written: nothing changed.
+		m.finishOp(context.Background(), op.ID, "interrupted before the port was changed")
+		return
+	}
+	if op.Step == "updated" {
+		m.requestReconfigure(op.NodeID)
+	}
+	go m.finishStakingPortChange(op.ID, op.NodeID, change)
+}
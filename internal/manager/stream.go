@@ -0,0 +1,50 @@
+package manager
+
+import "log/slog"
+
+// subscriberBuffer is how many events a slow stream client may fall behind
+// before it starts missing them.
+const subscriberBuffer = 64
+
+// Subscribe returns a channel that receives every event as it is logged,
+// plus operation progress. Call the returned func to unsubscribe. The
+// channel is closed when the manager shuts down.
+func (m *Manager) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	m.subsMu.Lock()
+	m.subs[ch] = struct{}{}
+	m.subsMu.Unlock()
+
+	return ch, func() {
+		m.subsMu.Lock()
+		if _, ok := m.subs[ch]; ok {
+			delete(m.subs, ch)
+			close(ch)
+		}
+		m.subsMu.Unlock()
+	}
+}
+
+// publish fans e out to subscribers without blocking; a subscriber whose
+// buffer is full drops the event.
+func (m *Manager) publish(e Event) {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+	for ch := range m.subs {
+		select {
+		case ch <- e:
+		default:
+			slog.Debug("event stream subscriber lagging, dropped event", "type", e.EventType)
+		}
+	}
+}
+
+// closeSubscribers ends all streams so the HTTP server can shut down.
+func (m *Manager) closeSubscribers() {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+	for ch := range m.subs {
+		delete(m.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,252 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/jackc/pgx/v5"
+)
+
+// StartStuckNodePoller begins a background loop that repairs nodes left in
+// creating, or failed, for longer than timeout. Nodes with a running,
+// queued or retrying operation are left to it.
+func (m *Manager) StartStuckNodePoller(timeout time.Duration) {
+	interval := min(timeout/2, 5*time.Minute)
+	m.startPoller("stuck_nodes", interval, func() (int, int) { return m.repairStuckNodes(timeout) })
+	slog.Info("stuck node poller started", "timeout", timeout)
+}
+
+func (m *Manager) repairStuckNodes(timeout time.Duration) (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT `+nodeColumns+` FROM nodes n
+		WHERE n.status IN ('creating', 'failed')
+		  AND n.updated_at < now() - make_interval(secs => $1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM operations o WHERE o.node_id = n.id AND o.state IN ($2, $3, $4))
+		  AND NOT EXISTS (
+			SELECT 1 FROM events e WHERE e.event_type = 'node.stuck' AND e.target = n.name AND e.created_at > n.updated_at)
+		ORDER BY n.id`, timeout.Seconds(), OpRunning, OpQueued, OpRetry)
+	if err != nil {
+		slog.Error("stuck nodes", "error", err)
+		return 0, 1
+	}
+	var nodes []Node
+	for rows.Next() {
+		n, err := scanNode(rows)
+		if err != nil {
+			rows.Close()
+			slog.Error("stuck nodes", "error", err)
+			return 0, 1
+		}
+		nodes = append(nodes, *n)
+	}
+	rows.Close()
+
+	for _, node := range nodes {
+		if m.hostOffline(ctx, node.HostID) {
+			continue // the host poller reports it; repair once it's back
+		}
+		checked++
+		if err := m.repairStuckNode(ctx, node, timeout); err != nil {
+			slog.Warn("repair stuck node", "error", err, "node", node.Name)
+			failed++
+		}
+	}
+	return checked, failed
+}
+
+// repairStuckNode settles one stuck node from its container's actual
+// state. A running container is taken as the node's. One left created or
+// stopped is started only if the journal shows its staking keys were
+// installed (the "created" step); otherwise it would boot with a new
+// identity, so it is removed and the operation run again, as is one that
+// never made its container. Anything else is reported once as node.stuck,
+// pointing at POST /api/nodes/:id/retry.
+func (m *Manager) repairStuckNode(ctx context.Context, node Node, timeout time.Duration) error {
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
+	}
+	ref := node.ContainerID
+	if ref == "" {
+		ref = "avax-" + node.Name
+	}
+	info, err := dc.ContainerInspect(ctx, ref)
+	if err != nil && !client.IsErrNotFound(err) {
+		return err
+	}
+	found := err == nil && info.State != nil
+	stuckFor := fmt.Sprintf("%s for over %s", node.Status, timeout)
+
+	switch {
+	case found && info.State.Running:
+		m.setRepaired(ctx, node, info.ID, "Container is running; was "+stuckFor)
+		return nil
+	case node.Status == "failed":
+		return m.reportStuck(ctx, node, stuckFor)
+	}
+
+	op, err := m.lastContainerOp(ctx, node.ID)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		m.markStuckFailed(ctx, node, fmt.Sprintf("Stuck %s with no journaled operation", stuckFor))
+		return nil
+	}
+	if found {
+		if op.Step == "created" || op.Step == "started" {
+			if dc = m.clientFor(node.HostID); dc == nil {
+				return fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
+			}
+			if err := dc.ContainerStart(ctx, info.ID); err != nil {
+				m.markStuckFailed(ctx, node, fmt.Sprintf("Stuck %s; starting its %s container failed: %v", stuckFor, info.State.Status, err))
+				return nil
+			}
+			m.setRepaired(ctx, node, info.ID, fmt.Sprintf("Started the %s container; was %s", info.State.Status, stuckFor))
+			return nil
+		}
+		// Left behind before its staking keys were installed.
+		if dc = m.clientFor(node.HostID); dc == nil {
+			return fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
+		}
+		if err := dc.ContainerRemove(ctx, info.ID, false); err != nil && !client.IsErrNotFound(err) {
+			return fmt.Errorf("remove container without staking keys: %w", err)
+		}
+		m.pool.Exec(ctx, "UPDATE nodes SET container_id='', updated_at=now() WHERE id=$1", node.ID)
+	}
+
+	if op.Kind == OpReconfigure {
+		m.logEvent(ctx, "node.repaired", node.Name, "Resumed reconfiguring; was "+stuckFor, map[string]any{"op_id": op.ID})
+		m.requestReconfigure(node.ID)
+		return nil
+	}
+	var req CreateNodeRequest
+	if err := json.Unmarshal(op.Params, &req); err != nil {
+		m.markStuckFailed(ctx, node, fmt.Sprintf("Stuck %s with no usable container; journaled provision is unreadable", stuckFor))
+		return nil
+	}
+	m.logEvent(ctx, "node.repaired", node.Name, "Resumed provisioning; was "+stuckFor, map[string]any{"op_id": op.ID})
+	opID := m.beginOp(ctx, OpProvision, node.ID, req)
+	go m.provisionNode(opID, node.ID, node.HostID, req)
+	return nil
+}
+
+// setRepaired points a node at its container and lets the health poller
+// take it from bootstrapping.
+func (m *Manager) setRepaired(ctx context.Context, node Node, containerID, msg string) {
+	m.pool.Exec(ctx, "UPDATE nodes SET container_id=$1, status='bootstrapping', updated_at=now() WHERE id=$2",
+		containerID, node.ID)
+	m.logEvent(ctx, "node.repaired", node.Name, msg, nil)
+	m.recordNodeHistory(ctx, node.ID)
+}
+
+func (m *Manager) markStuckFailed(ctx context.Context, node Node, reason string) {
+	m.pool.Exec(ctx, "UPDATE nodes SET status='failed', updated_at=now() WHERE id=$1", node.ID)
+	m.logEvent(ctx, "node.failed", node.Name, reason+"; retry with POST /api/nodes/"+fmt.Sprint(node.ID)+"/retry", nil)
+	m.recordNodeHistory(ctx, node.ID)
+}
+
+// reportStuck logs, once per failure, that a node has stayed failed, with
+// the error of its last operation.
+func (m *Manager) reportStuck(ctx context.Context, node Node, stuckFor string) error {
+	var lastErr string
+	err := m.pool.QueryRow(ctx,
+		"SELECT error FROM operations WHERE node_id=$1 AND state=$2 ORDER BY id DESC LIMIT 1",
+		node.ID, OpFailed).Scan(&lastErr)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	msg := "Node " + stuckFor
+	if lastErr != "" {
+		msg += ": " + lastErr
+	}
+	m.logEvent(ctx, "node.stuck", node.Name, msg+"; retry with POST /api/nodes/"+fmt.Sprint(node.ID)+"/retry",
+		map[string]any{"error": lastErr})
+	return nil
+}
+
+// lastContainerOp returns the node's latest provision or reconfigure
+// operation, the ones that create its container, or nil.
+func (m *Manager) lastContainerOp(ctx context.Context, nodeID int64) (*Operation, error) {
+	var op Operation
+	err := m.pool.QueryRow(ctx, `
+		SELECT id, kind, step, state, params FROM operations
+		WHERE node_id=$1 AND kind IN ($2, $3) ORDER BY id DESC LIMIT 1`,
+		nodeID, OpProvision, OpReconfigure).Scan(&op.ID, &op.Kind, &op.Step, &op.State, &op.Params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// RetryNode runs a failed or stuck node's setup again. A retry waiting in
+// the journal is made due now. A node whose provisioning never finished is
+// provisioned again from its journaled request; any other is recreated
+// from its row, as by a reconfigure.
+func (m *Manager) RetryNode(ctx context.Context, id int64) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if node.Status != "failed" && node.Status != "creating" {
+		return nil, fmt.Errorf("node %q is %s; only failed or creating nodes can be retried", node.Name, node.Status)
+	}
+	var opID int64
+	var opState string
+	err = m.pool.QueryRow(ctx,
+		"SELECT id, state FROM operations WHERE node_id=$1 AND state IN ($2, $3) ORDER BY id DESC LIMIT 1",
+		id, OpRunning, OpRetry).Scan(&opID, &opState)
+	switch {
+	case err == nil && opState == OpRunning:
+		return nil, fmt.Errorf("node %q has operation %d running", node.Name, opID)
+	case err == nil:
+		if _, err := m.pool.Exec(ctx, "UPDATE operations SET retry_at=now(), updated_at=now() WHERE id=$1", opID); err != nil {
+			return nil, err
+		}
+		m.logEvent(ctx, "node.retry", node.Name, "Retry requested; running the waiting retry now", map[string]any{"op_id": opID})
+		return node, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+	if m.hostOffline(ctx, node.HostID) {
+		return nil, ErrHostOffline
+	}
+
+	op, err := m.lastContainerOp(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if op != nil && op.Kind == OpProvision && op.State != OpDone {
+		var req CreateNodeRequest
+		if err := json.Unmarshal(op.Params, &req); err != nil {
+			return nil, fmt.Errorf("journaled provision of node %q is unreadable", node.Name)
+		}
+		dc := m.clientFor(node.HostID)
+		if dc == nil {
+			return nil, fmt.Errorf("host %d: %w", node.HostID, ErrHostOffline)
+		}
+		if err := dc.ContainerRemove(ctx, "avax-"+node.Name, false); err != nil && !client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("remove leftover container: %w", err)
+		}
+		m.pool.Exec(ctx, "UPDATE nodes SET container_id='', status='creating', updated_at=now() WHERE id=$1", id)
+		m.logEvent(ctx, "node.retry", node.Name, "Retrying provisioning", nil)
+		opID := m.beginOp(ctx, OpProvision, id, req)
+		go m.provisionNode(opID, id, node.HostID, req)
+	} else {
+		m.logEvent(ctx, "node.retry", node.Name, "Retrying by recreating the container", nil)
+		m.requestReconfigure(id)
+	}
+	return m.GetNode(ctx, id)
+}
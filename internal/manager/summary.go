@@ -0,0 +1,161 @@
+package manager
+
+import (
+	"context"
+	"time"
+)
+
+// FleetSummary is a compact rollup of fleet state for CLIs and scrapers.
+type FleetSummary struct {
+	Version string            `json:"version"`
+	Nodes   map[string]int    `json:"nodes"`
+	Hosts   []HostNodeCounts  `json:"hosts"`
+	L1s     []L1StatusSummary `json:"l1s"`
+	Pending []PendingOp       `json:"pending"`
+	Alerts  []Alert           `json:"alerts"`
+}
+
+// HostNodeCounts holds node counts by status for a single host.
+type HostNodeCounts struct {
+	ID     int64          `json:"id"`
+	Name   string         `json:"name"`
+	Status string         `json:"status"`
+	Nodes  map[string]int `json:"nodes"`
+}
+
+// L1StatusSummary is an L1 verdict derived from validator node statuses
+// in the database, without probing the nodes.
+type L1StatusSummary struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	SubnetID  string    `json:"-"`
+	Verdict   string    `json:"verdict"`
+	Healthy   int       `json:"healthy"`
+	Total     int       `json:"total"`
+	UpdatedAt time.Time `json:"-"`
+	Owner     string    `json:"-"`
+	Contact   string    `json:"-"`
+	URL       string    `json:"-"`
+}
+
+// PendingOp is an in-flight node operation.
+type PendingOp struct {
+	NodeID int64     `json:"node_id"`
+	Name   string    `json:"name"`
+	Status string    `json:"status"`
+	Since  time.Time `json:"since"`
+}
+
+// Summary returns the fleet-wide rollup.
+func (m *Manager) Summary(ctx context.Context) (*FleetSummary, error) {
+	s := &FleetSummary{
+		Nodes:   map[string]int{},
+		Hosts:   []HostNodeCounts{},
+		Pending: []PendingOp{},
+	}
+
+	rows, err := m.pool.Query(ctx, `
+		SELECT h.id, h.name, h.status, COALESCE(n.status, ''), COUNT(n.id)::int
+		FROM hosts h
+		LEFT JOIN nodes n ON n.host_id = h.id
+		GROUP BY h.id, h.name, h.status, n.status
+		ORDER BY h.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name, hostStatus, nodeStatus string
+		var count int
+		if err := rows.Scan(&id, &name, &hostStatus, &nodeStatus, &count); err != nil {
+			return nil, err
+		}
+		if len(s.Hosts) == 0 || s.Hosts[len(s.Hosts)-1].ID != id {
+			s.Hosts = append(s.Hosts, HostNodeCounts{ID: id, Name: name, Status: hostStatus, Nodes: map[string]int{}})
+		}
+		if nodeStatus != "" {
+			s.Hosts[len(s.Hosts)-1].Nodes[nodeStatus] = count
+			s.Nodes[nodeStatus] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	prows, err := m.pool.Query(ctx, `
+		SELECT id, name, status, updated_at FROM nodes
+		WHERE status = 'creating'
+		ORDER BY updated_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer prows.Close()
+	for prows.Next() {
+		var p PendingOp
+		if err := prows.Scan(&p.NodeID, &p.Name, &p.Status, &p.Since); err != nil {
+			return nil, err
+		}
+		s.Pending = append(s.Pending, p)
+	}
+	if err := prows.Err(); err != nil {
+		return nil, err
+	}
+	prows.Close()
+
+	if s.L1s, err = m.L1StatusSummaries(ctx); err != nil {
+		return nil, err
+	}
+	if s.Alerts, err = m.FiringAlerts(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// L1StatusSummaries returns a DB-derived verdict for every L1.
+func (m *Manager) L1StatusSummaries(ctx context.Context) ([]L1StatusSummary, error) {
+	return m.queryL1Statuses(ctx, "")
+}
+
+// L1Status returns the DB-derived verdict of one L1.
+func (m *Manager) L1Status(ctx context.Context, id int64) (*L1StatusSummary, error) {
+	l1s, err := m.queryL1Statuses(ctx, "WHERE l.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(l1s) == 0 {
+		return nil, ErrL1NotFound
+	}
+	return &l1s[0], nil
+}
+
+// queryL1Statuses counts the running validators of the L1s matching where.
+func (m *Manager) queryL1Statuses(ctx context.Context, where string, args ...any) ([]L1StatusSummary, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT l.id, l.name, l.status, l.subnet_id, l.updated_at, l.owner, l.contact, l.url,
+		       COUNT(v.id)::int,
+		       COUNT(v.id) FILTER (WHERE n.status = 'running')::int
+		FROM l1s l
+		LEFT JOIN l1_validators v ON v.l1_id = l.id
+		LEFT JOIN nodes n ON n.id = v.node_id
+		`+where+`
+		GROUP BY l.id
+		ORDER BY l.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	l1s := []L1StatusSummary{}
+	for rows.Next() {
+		var l L1StatusSummary
+		if err := rows.Scan(&l.ID, &l.Name, &l.Status, &l.SubnetID, &l.UpdatedAt, &l.Owner, &l.Contact, &l.URL, &l.Total, &l.Healthy); err != nil {
+			return nil, err
+		}
+		l.Verdict = l1Verdict(l.Healthy, l.Total)
+		l1s = append(l1s, l)
+	}
+	return l1s, rows.Err()
+}
@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxNodeTags caps the tags on one node.
+const maxNodeTags = 32
+
+// tagRe matches a node tag: lowercase letters, digits, and . _ : - inside.
+var tagRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9._:-]{0,62}[a-z0-9])?$`)
+
+// normalizeTags lowercases, de-duplicates and sorts tags, rejecting
+// invalid ones.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !tagRe.MatchString(t) {
+			return nil, fmt.Errorf("invalid tag %q (want lowercase letters, digits, and . _ : - inside, up to 64 chars)", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	if len(out) > maxNodeTags {
+		return nil, fmt.Errorf("at most %d tags per node", maxNodeTags)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// SetNodeTags replaces a node's tags. Tags only group and filter nodes; the
+// container is left alone.
+func (m *Manager) SetNodeTags(ctx context.Context, id int64, tags []string) (*Node, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if _, err := m.pool.Exec(ctx, "UPDATE nodes SET tags=$1, updated_at=now() WHERE id=$2", tags, id); err != nil {
+		return nil, fmt.Errorf("update tags: %w", err)
+	}
+	m.logEvent(ctx, "node.tags_updated", node.Name, "Tags: "+strings.Join(tags, ", "),
+		map[string]any{"tags": tags, "previous": node.Tags})
+	return m.GetNode(ctx, id)
+}
@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// SetNodeThrottle replaces a node's disk and bandwidth limits and recreates
+// its container to apply them. Pass a zero Throttle to lift all limits once
+// the node has finished bootstrapping.
+func (m *Manager) SetNodeThrottle(ctx context.Context, id int64, t docker.Throttle) (*Node, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Status == "creating" {
+		return nil, fmt.Errorf("node %q is still being provisioned", node.Name)
+	}
+
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET throttle=$1, updated_at=now() WHERE id=$2", t, id); err != nil {
+		return nil, fmt.Errorf("update throttle: %w", err)
+	}
+	m.logEvent(ctx, "node.throttle_updated", node.Name, "Throttle updated",
+		map[string]any{"throttle": t})
+
+	// Only running containers are recreated; a stopped node picks up the
+	// new limits the next time it is reconfigured.
+	if node.ContainerID != "" && node.Status != "stopped" {
+		m.requestReconfigure(id)
+	}
+	return m.GetNode(ctx, id)
+}
@@ -0,0 +1,227 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// parseTTL turns a TTL like "24h" into an expiry time. Empty means none.
+func parseTTL(ttl string) (*time.Time, error) {
+	if ttl == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid ttl %q (want a duration like \"24h\")", ttl)
+	}
+	t := time.Now().Add(d)
+	return &t, nil
+}
+
+// SetNodeTTL sets a node to expire ttl from now, or clears its expiry when
+// ttl is empty.
+func (m *Manager) SetNodeTTL(ctx context.Context, id int64, ttl string) (*Node, error) {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+	expiresAt, err := parseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt != nil && node.Network == "mainnet" {
+		return nil, fmt.Errorf("ttl is not allowed on mainnet nodes")
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE nodes SET expires_at=$1, expiry_warned=false, updated_at=now() WHERE id=$2", expiresAt, id); err != nil {
+		return nil, fmt.Errorf("update ttl: %w", err)
+	}
+	m.logEvent(ctx, "node.ttl_updated", node.Name, expiryMessage(expiresAt), nil)
+	return m.GetNode(ctx, id)
+}
+
+// SetL1TTL sets an L1 to expire ttl from now, or clears its expiry when ttl
+// is empty.
+func (m *Manager) SetL1TTL(ctx context.Context, id int64, ttl string) (*L1Detail, error) {
+	var name string
+	if err := m.pool.QueryRow(ctx, "SELECT name FROM l1s WHERE id=$1", id).Scan(&name); err != nil {
+		return nil, ErrL1NotFound
+	}
+	expiresAt, err := parseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.pool.Exec(ctx,
+		"UPDATE l1s SET expires_at=$1, expiry_warned=false, updated_at=now() WHERE id=$2", expiresAt, id); err != nil {
+		return nil, fmt.Errorf("update ttl: %w", err)
+	}
+	m.logEvent(ctx, "l1.ttl_updated", name, expiryMessage(expiresAt), nil)
+	return m.GetL1(ctx, id)
+}
+
+func expiryMessage(t *time.Time) string {
+	if t == nil {
+		return "Expiry cleared"
+	}
+	return "Expires at " + t.UTC().Format(time.RFC3339)
+}
+
+// StartJanitor begins a background loop that tears down expired nodes and
+// L1s, logging a warning event warnBefore their expiry.
+func (m *Manager) StartJanitor(interval, warnBefore time.Duration) {
+	m.startPoller("janitor", interval, func() (int, int) { return m.runJanitor(warnBefore) })
+	slog.Info("janitor started", "interval", interval, "warn_before", warnBefore)
+}
+
+func (m *Manager) runJanitor(warnBefore time.Duration) (expired, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	m.warnExpiring(ctx, warnBefore)
+	m.pruneAudit(ctx)
+	m.pruneUptime(ctx)
+
+	// L1s first, so expiring nodes are no longer their validators.
+	rows, err := m.pool.Query(ctx, "SELECT id, name FROM l1s WHERE expires_at <= now() ORDER BY id")
+	if err != nil {
+		slog.Error("janitor: list expired L1s", "error", err)
+		return 0, 1
+	}
+	type row struct {
+		id   int64
+		name string
+	}
+	var l1s []row
+	for rows.Next() {
+		var e row
+		if err := rows.Scan(&e.id, &e.name); err == nil {
+			l1s = append(l1s, e)
+		}
+	}
+	rows.Close()
+	for _, l := range l1s {
+		expired++
+		if err := m.expireL1(ctx, l.id); err != nil {
+			slog.Error("janitor: tear down L1", "error", err, "l1", l.name)
+			m.logEvent(ctx, "l1.expire_failed", l.name, "Teardown failed: "+err.Error(), nil)
+			failed++
+		}
+	}
+
+	rows, err = m.pool.Query(ctx, "SELECT id, name FROM nodes WHERE expires_at <= now() ORDER BY id")
+	if err != nil {
+		slog.Error("janitor: list expired nodes", "error", err)
+		return expired, failed + 1
+	}
+	var nodes []row
+	for rows.Next() {
+		var e row
+		if err := rows.Scan(&e.id, &e.name); err == nil {
+			nodes = append(nodes, e)
+		}
+	}
+	rows.Close()
+	for _, n := range nodes {
+		expired++
+		if err := m.expireNode(ctx, n.id); err != nil {
+			slog.Error("janitor: tear down node", "error", err, "node", n.name)
+			m.logEvent(ctx, "node.expire_failed", n.name, "Teardown failed: "+err.Error(), nil)
+			failed++
+		}
+	}
+	return expired, failed
+}
+
+// warnExpiring logs one warning event per node or L1 about to expire.
+func (m *Manager) warnExpiring(ctx context.Context, warnBefore time.Duration) {
+	for _, t := range []struct{ table, prefix string }{{"l1s", "l1"}, {"nodes", "node"}} {
+		// Table names are hardcoded constants, not user input.
+		rows, err := m.pool.Query(ctx, `
+			UPDATE `+t.table+` SET expiry_warned=true
+			WHERE NOT expiry_warned AND expires_at > now() AND expires_at <= $1
+			RETURNING name, expires_at`, time.Now().Add(warnBefore))
+		if err != nil {
+			slog.Error("janitor: expiry warnings", "error", err, "table", t.table)
+			continue
+		}
+		type warning struct {
+			name string
+			at   time.Time
+		}
+		var warnings []warning
+		for rows.Next() {
+			var w warning
+			if err := rows.Scan(&w.name, &w.at); err == nil {
+				warnings = append(warnings, w)
+			}
+		}
+		rows.Close()
+		for _, w := range warnings {
+			m.logEvent(ctx, t.prefix+".expiring", w.name,
+				fmt.Sprintf("Expires in %s and will be torn down", time.Until(w.at).Round(time.Minute)),
+				map[string]any{"expires_at": w.at})
+		}
+	}
+}
+
+// expireL1 removes an expired L1's validators, reconfigures their nodes to
+// stop tracking it, and deletes it.
+func (m *Manager) expireL1(ctx context.Context, id int64) error {
+	l1, err := m.GetL1(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := m.pool.Exec(ctx, "DELETE FROM l1_validators WHERE l1_id=$1", id); err != nil {
+		return fmt.Errorf("remove validators: %w", err)
+	}
+	for _, v := range l1.Validators {
+		m.recordWeight(ctx, id, v.NodeID, 0, "l1_expired")
+	}
+	if err := m.DeleteL1(ctx, id); err != nil {
+		return err
+	}
+	m.logEvent(ctx, "l1.expired", l1.Name, fmt.Sprintf("TTL expired; removed %d validator(s) and deleted", len(l1.Validators)), nil)
+	if l1.SubnetID != "" {
+		for _, v := range l1.Validators {
+			m.requestReconfigure(v.NodeID)
+		}
+	}
+	return nil
+}
+
+// expireNode drops an expired node's validator assignments and deletes it
+// with its volumes.
+func (m *Manager) expireNode(ctx context.Context, id int64) error {
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+	rows, err := m.pool.Query(ctx, "DELETE FROM l1_validators WHERE node_id=$1 RETURNING l1_id", id)
+	if err != nil {
+		return fmt.Errorf("remove validator assignments: %w", err)
+	}
+	var l1IDs []int64
+	for rows.Next() {
+		var l1ID int64
+		if err := rows.Scan(&l1ID); err != nil {
+			rows.Close()
+			return fmt.Errorf("remove validator assignments: %w", err)
+		}
+		l1IDs = append(l1IDs, l1ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("remove validator assignments: %w", err)
+	}
+	for _, l1ID := range l1IDs {
+		m.recordWeight(ctx, l1ID, id, 0, "node_expired")
+	}
+	if err := m.DeleteNode(ctx, id, DeleteNodeOptions{RemoveVolumes: true, Force: true}); err != nil {
+		return err
+	}
+	m.logEvent(ctx, "node.expired", node.Name,
+		fmt.Sprintf("TTL expired; removed %d validator assignment(s) and deleted", len(l1IDs)), nil)
+	return nil
+}
@@ -0,0 +1,229 @@
+package manager
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/primal-host/avalauncher/internal/docker"
+)
+
+// UnmanagedContainers lists AvalancheGo containers on a host that were
+// started outside avalauncher and haven't been adopted.
+func (m *Manager) UnmanagedContainers(ctx context.Context, hostID int64) ([]docker.UnmanagedContainer, error) {
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", hostID)
+	}
+	containers, err := dc.ListUnmanagedAvago(ctx)
+	if err != nil {
+		return nil, err
+	}
+	adopted, err := m.adoptedContainers(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+	out := []docker.UnmanagedContainer{}
+	for _, c := range containers {
+		if !adopted[c.ID] {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// adoptedContainers returns the container IDs of a host's nodes.
+func (m *Manager) adoptedContainers(ctx context.Context, hostID int64) (map[string]bool, error) {
+	rows, err := m.pool.Query(ctx, "SELECT container_id FROM nodes WHERE host_id=$1 AND container_id != ''", hostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// AdoptContainer registers an unmanaged AvalancheGo container as a node.
+// The container keeps running as-is; its network, ports and staking keys
+// are read from it so a later reconfigure recreates it with the same
+// NodeID (but fresh, managed volumes). name defaults to the container name.
+func (m *Manager) AdoptContainer(ctx context.Context, hostID int64, containerID, name string) (*Node, error) {
+	dc := m.clientFor(hostID)
+	if dc == nil {
+		return nil, fmt.Errorf("host %d not connected", hostID)
+	}
+	info, err := dc.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("container %q not found", containerID)
+	}
+	if info.Config.Labels[docker.LabelManagedBy] == docker.ManagedByValue {
+		return nil, fmt.Errorf("container %q is already managed", strings.TrimPrefix(info.Name, "/"))
+	}
+	if !docker.LooksLikeAvago(info.Config.Image, strings.Join(append(append([]string{}, info.Config.Entrypoint...), info.Config.Cmd...), " ")) {
+		return nil, fmt.Errorf("container %q does not look like avalanchego", strings.TrimPrefix(info.Name, "/"))
+	}
+	adopted, err := m.adoptedContainers(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if adopted[info.ID] {
+		return nil, fmt.Errorf("container %q is already adopted", strings.TrimPrefix(info.Name, "/"))
+	}
+
+	if name == "" {
+		name = strings.TrimPrefix(info.Name, "/")
+	}
+	var exists bool
+	if err := m.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM nodes WHERE name=$1)", name).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check name: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("node %q already exists", name)
+	}
+
+	// Read settings from AVAGO_* env and --flags, falling back to the
+	// AvalancheGo defaults.
+	setting := func(flag string) string {
+		prefix := "--" + flag + "="
+		for _, arg := range append(append([]string{}, info.Config.Entrypoint...), info.Config.Cmd...) {
+			if v, ok := strings.CutPrefix(arg, prefix); ok {
+				return v
+			}
+		}
+		env := "AVAGO_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_")) + "="
+		for _, e := range info.Config.Env {
+			if v, ok := strings.CutPrefix(e, env); ok {
+				return v
+			}
+		}
+		return ""
+	}
+	network := setting("network-id")
+	if network == "" {
+		network = "mainnet"
+	}
+	httpPort, _ := strconv.Atoi(setting("http-port"))
+	if httpPort == 0 {
+		httpPort = 9650
+	}
+	stakingPort, _ := strconv.Atoi(setting("staking-port"))
+	if stakingPort == 0 {
+		stakingPort = 9651
+	}
+	networkMode := ""
+	exposeHTTP := false
+	hostPorts := []int{stakingPort}
+	if info.HostConfig != nil && info.HostConfig.NetworkMode.IsHost() {
+		networkMode = "host"
+		hostPorts = append(hostPorts, httpPort)
+	} else {
+		// Bridge: the published host ports are what peers and clients
+		// connect to. A published HTTP port is kept as expose_http.
+		published, publishedHTTP := 0, 0
+		if info.HostConfig != nil {
+			for port, bindings := range info.HostConfig.PortBindings {
+				if len(bindings) == 0 {
+					continue
+				}
+				switch port.Int() {
+				case stakingPort:
+					published, _ = strconv.Atoi(bindings[0].HostPort)
+				case httpPort:
+					publishedHTTP, _ = strconv.Atoi(bindings[0].HostPort)
+				}
+			}
+		}
+		if published == 0 {
+			return nil, fmt.Errorf("container %q does not publish its staking port %d", strings.TrimPrefix(info.Name, "/"), stakingPort)
+		}
+		stakingPort = published
+		hostPorts = []int{stakingPort}
+		httpPort = 9650
+		if publishedHTTP != 0 {
+			httpPort, exposeHTTP = publishedHTTP, true
+			hostPorts = append(hostPorts, httpPort)
+		}
+	}
+	m.portsMu.Lock()
+	defer m.portsMu.Unlock()
+	if err := m.checkPortConflicts(ctx, hostID, 0, hostPorts); err != nil {
+		return nil, err
+	}
+
+	// Keep the staking identity so the NodeID survives recreation.
+	// AvalancheGo's default staking dir is the one avalauncher uses.
+	keys := &stakingKeys{}
+	files, err := dc.ReadFiles(ctx, info.ID, docker.StakingDir, []string{"staker.crt", "staker.key", "signer.key"})
+	if err != nil {
+		slog.Warn("adopt: read staking keys", "container", info.Name, "error", err)
+	}
+	if files["staker.crt"] != nil && files["staker.key"] != nil {
+		keys.Cert, keys.Key = string(files["staker.crt"]), string(files["staker.key"])
+		if signer := files["signer.key"]; signer != nil {
+			keys.Signer = hex.EncodeToString(signer)
+		}
+	}
+	id, err := m.reserveNodeID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("allocate node id: %w", err)
+	}
+	if err := m.sealKeys(id, keys); err != nil {
+		return nil, fmt.Errorf("encrypt staking keys: %w", err)
+	}
+
+	status := "stopped"
+	if info.State != nil && info.State.Running {
+		status = "bootstrapping"
+	}
+	node, err := scanNode(m.pool.QueryRow(ctx, `
+		INSERT INTO nodes (id, name, host_id, image, network, container_id, http_port, staking_port, expose_http, network_mode,
+		                   staking_cert, staking_key, staking_signer, status)
+		OVERRIDING SYSTEM VALUE
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING `+nodeColumns,
+		id, name, hostID, info.Config.Image, network, info.ID, httpPort, stakingPort, exposeHTTP, networkMode,
+		keys.Cert, keys.Key, keys.Signer, status,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("insert node: %w", err)
+	}
+
+	// Bridge nodes are addressed as avax-<name> on the avax network.
+	if networkMode == "" {
+		if err := dc.SetNetworkAliases(ctx, m.avaxDockerNet, info.ID, []string{"avax-" + name}); err != nil {
+			slog.Warn("adopt: attach to avax network", "node", name, "error", err)
+		}
+	}
+
+	m.logEvent(ctx, "node.adopted", name, "Adopted container "+strings.TrimPrefix(info.Name, "/"),
+		map[string]any{"container_id": info.ID, "staking_keys": keys.Cert != ""})
+	return node, nil
+}
+
+// reportUnmanaged logs an event when a host runs unmanaged AvalancheGo
+// containers, found during startup reconciliation, and returns how many.
+func (m *Manager) reportUnmanaged(ctx context.Context, hostID int64, hostName string) int {
+	containers, err := m.UnmanagedContainers(ctx, hostID)
+	if err != nil || len(containers) == 0 {
+		return 0
+	}
+	names := make([]string, len(containers))
+	for i, c := range containers {
+		names[i] = c.Name
+	}
+	slog.Info("unmanaged avalanchego containers", "host", hostName, "containers", names)
+	m.logEvent(ctx, "host.unmanaged", hostName,
+		fmt.Sprintf("%d unmanaged avalanchego container(s)", len(containers)),
+		map[string]any{"containers": names})
+	return len(containers)
+}
@@ -0,0 +1,758 @@
+package manager
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Upgrade states.
+const (
+	UpgradeScheduled = "scheduled" // waiting for start_at
+	UpgradeCanary    = "canary"    // upgrading the canary node
+	UpgradeSoaking   = "soaking"   // watching the canary
+	UpgradeAwaiting  = "awaiting"  // canary passed; waiting for proceed
+	UpgradeRolling   = "rolling"   // upgrading the remaining nodes one at a time
+	UpgradePaused    = "paused"    // a node failed; proceed retries it
+	UpgradeDone      = "done"
+	UpgradeFailed    = "failed" // the canary failed and was rolled back
+	UpgradeCancelled = "cancelled"
+)
+
+// Per-node upgrade states.
+const (
+	UpgradeNodePending    = "pending"
+	UpgradeNodeUpgrading  = "upgrading"
+	UpgradeNodeDone       = "done"
+	UpgradeNodeFailed     = "failed"
+	UpgradeNodeRolledBack = "rolled_back"
+)
+
+const (
+	defaultSoak        = 30 * time.Minute
+	defaultNodeTimeout = 30 * time.Minute
+
+	// soakCheckInterval is how often the canary is checked while soaking.
+	soakCheckInterval = 30 * time.Second
+	// soakMaxUnhealthy consecutive failed checks fail the canary.
+	soakMaxUnhealthy = 3
+	// canaryMaxLag is how many P-Chain blocks the canary may trail the
+	// highest healthy peer on its network at the end of the soak.
+	canaryMaxLag = 10
+	// scheduleCheckInterval is how often a scheduled upgrade checks for
+	// cancellation while it waits for its window.
+	scheduleCheckInterval = time.Minute
+)
+
+// UpgradeRequest starts a rolling image upgrade.
+type UpgradeRequest struct {
+	Image        string  `json:"image"`
+	NodeIDs      []int64 `json:"node_ids"`       // nodes to upgrade (empty = every node on network)
+	Network      string  `json:"network"`        // with empty node_ids
+	CanaryNodeID int64   `json:"canary_node_id"` // upgraded first and soaked (0 = no canary phase)
+	Soak         string  `json:"soak"`           // canary soak period (default 30m)
+	AutoProceed  bool    `json:"auto_proceed"`   // continue after a clean soak without confirmation
+	NodeTimeout  string  `json:"node_timeout"`   // per-node wait for healthy + bootstrapped (default 30m)
+
+	// StartAt opens the activation window (empty or past = now). Deadline
+	// is when every node should run the image, e.g. a network upgrade's
+	// activation time.
+	StartAt  *time.Time `json:"start_at,omitempty"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// UpgradeNode is one node's progress within an upgrade.
+type UpgradeNode struct {
+	NodeID     int64      `json:"node_id"`
+	Name       string     `json:"name"`
+	HostID     int64      `json:"host_id"`
+	Host       string     `json:"host"`
+	FromImage  string     `json:"from_image"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// UpgradeProgress summarizes an upgrade's nodes.
+type UpgradeProgress struct {
+	Total     int    `json:"total"`
+	Done      int    `json:"done"`
+	Failed    int    `json:"failed"`  // failed or rolled back
+	Pending   int    `json:"pending"` // including the node upgrading now
+	Hosts     int    `json:"hosts"`
+	HostsDone int    `json:"hosts_done"`
+	Host      string `json:"host,omitempty"` // host being upgraded now
+
+	// EstimatedFinish extrapolates from the nodes upgraded so far. AtRisk
+	// is set when it, or the present, is past the deadline with nodes left.
+	EstimatedFinish *time.Time `json:"estimated_finish,omitempty"`
+	AtRisk          bool       `json:"at_risk,omitempty"`
+}
+
+// Upgrade is a rolling image upgrade across a set of nodes.
+type Upgrade struct {
+	ID           int64           `json:"id"`
+	Image        string          `json:"image"`
+	State        string          `json:"state"`
+	CanaryNodeID int64           `json:"canary_node_id,omitempty"`
+	Soak         string          `json:"soak,omitempty"`
+	SoakUntil    *time.Time      `json:"soak_until,omitempty"`
+	AutoProceed  bool            `json:"auto_proceed"`
+	NodeTimeout  string          `json:"node_timeout"`
+	StartAt      *time.Time      `json:"start_at,omitempty"`
+	Deadline     *time.Time      `json:"deadline,omitempty"`
+	Nodes        []UpgradeNode   `json:"nodes"`
+	Progress     UpgradeProgress `json:"progress"`
+	Error        string          `json:"error,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+const upgradeColumns = `id, image, state, canary_node_id, soak, soak_until, auto_proceed, node_timeout, start_at, deadline, nodes, error, created_at, updated_at`
+
+func scanUpgrade(row rowScanner) (*Upgrade, error) {
+	var u Upgrade
+	var nodes []byte
+	if err := row.Scan(&u.ID, &u.Image, &u.State, &u.CanaryNodeID, &u.Soak, &u.SoakUntil, &u.AutoProceed,
+		&u.NodeTimeout, &u.StartAt, &u.Deadline, &nodes, &u.Error, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	u.Nodes = []UpgradeNode{}
+	json.Unmarshal(nodes, &u.Nodes)
+	u.Progress = upgradeProgress(&u, time.Now())
+	return &u, nil
+}
+
+// upgradeProgress counts an upgrade's nodes and estimates when it will
+// finish at the average pace of the nodes upgraded so far.
+func upgradeProgress(u *Upgrade, now time.Time) UpgradeProgress {
+	p := UpgradeProgress{Total: len(u.Nodes)}
+	hostLeft := map[int64]bool{}
+	var took time.Duration
+	var timed int
+	for _, n := range u.Nodes {
+		if _, ok := hostLeft[n.HostID]; !ok {
+			hostLeft[n.HostID] = false
+		}
+		switch n.State {
+		case UpgradeNodeDone:
+			p.Done++
+			if n.StartedAt != nil && n.FinishedAt != nil {
+				took += n.FinishedAt.Sub(*n.StartedAt)
+				timed++
+			}
+		case UpgradeNodeFailed, UpgradeNodeRolledBack:
+			p.Failed++
+		default:
+			p.Pending++
+			hostLeft[n.HostID] = true
+			if n.State == UpgradeNodeUpgrading {
+				p.Host = n.Host
+			}
+		}
+	}
+	p.Hosts = len(hostLeft)
+	for _, left := range hostLeft {
+		if !left {
+			p.HostsDone++
+		}
+	}
+
+	switch u.State {
+	case UpgradeDone, UpgradeFailed, UpgradeCancelled:
+		return p
+	}
+	if p.Pending > 0 && timed > 0 {
+		from := now
+		if u.StartAt != nil && u.StartAt.After(now) {
+			from = *u.StartAt
+		}
+		finish := from.Add(took / time.Duration(timed) * time.Duration(p.Pending)).Truncate(time.Second)
+		p.EstimatedFinish = &finish
+	}
+	if u.Deadline != nil && p.Pending+p.Failed > 0 {
+		p.AtRisk = now.After(*u.Deadline) || (p.EstimatedFinish != nil && p.EstimatedFinish.After(*u.Deadline))
+	}
+	return p
+}
+
+// StartUpgrade validates req, records the upgrade and starts rolling it out
+// in the background, or at req.StartAt. Nodes are upgraded host by host.
+// With a canary the canary node goes first and is soaked; the rest follow
+// once it passes and the upgrade is confirmed (or at once with
+// auto_proceed). Only one upgrade may be active at a time.
+func (m *Manager) StartUpgrade(ctx context.Context, req UpgradeRequest) (*Upgrade, error) {
+	if req.Image == "" {
+		return nil, fmt.Errorf("image is required")
+	}
+	if err := m.checkImageAllowed(req.Image); err != nil {
+		return nil, err
+	}
+	soak, err := parseUpgradeDuration("soak", req.Soak, defaultSoak)
+	if err != nil {
+		return nil, err
+	}
+	nodeTimeout, err := parseUpgradeDuration("node_timeout", req.NodeTimeout, defaultNodeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if req.StartAt != nil && !req.StartAt.After(now) {
+		req.StartAt = nil
+	}
+	if req.Deadline != nil {
+		if !req.Deadline.After(now) {
+			return nil, fmt.Errorf("deadline is in the past")
+		}
+		if req.StartAt != nil && !req.Deadline.After(*req.StartAt) {
+			return nil, fmt.Errorf("deadline must be after start_at")
+		}
+	}
+
+	var active bool
+	if err := m.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM upgrades WHERE state NOT IN ($1, $2, $3))",
+		UpgradeDone, UpgradeFailed, UpgradeCancelled).Scan(&active); err != nil {
+		return nil, fmt.Errorf("check upgrades: %w", err)
+	}
+	if active {
+		return nil, fmt.Errorf("another upgrade is in progress")
+	}
+
+	nodeIDs := req.NodeIDs
+	if len(nodeIDs) == 0 {
+		if req.Network == "" {
+			return nil, fmt.Errorf("node_ids or network is required")
+		}
+		rows, err := m.pool.Query(ctx, "SELECT id FROM nodes WHERE network=$1 ORDER BY host_id, id", req.Network)
+		if err != nil {
+			return nil, fmt.Errorf("list nodes: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if rows.Scan(&id) == nil {
+				nodeIDs = append(nodeIDs, id)
+			}
+		}
+		rows.Close()
+		if len(nodeIDs) == 0 {
+			return nil, fmt.Errorf("no nodes on network %q", req.Network)
+		}
+	}
+
+	// The canary goes first; everything else keeps the requested order.
+	var nodes []UpgradeNode
+	seen := map[int64]bool{}
+	add := func(id int64) error {
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		node, err := m.GetNode(ctx, id)
+		if err != nil {
+			return fmt.Errorf("node %d not found", id)
+		}
+		if node.Status == "creating" {
+			return fmt.Errorf("node %q is still being provisioned", node.Name)
+		}
+		state := UpgradeNodePending
+		if node.Image == req.Image {
+			state = UpgradeNodeDone
+		}
+		var host string
+		m.pool.QueryRow(ctx, "SELECT name FROM hosts WHERE id=$1", node.HostID).Scan(&host)
+		nodes = append(nodes, UpgradeNode{NodeID: id, Name: node.Name, HostID: node.HostID, Host: host,
+			FromImage: node.Image, State: state})
+		return nil
+	}
+	if req.CanaryNodeID != 0 {
+		if err := add(req.CanaryNodeID); err != nil {
+			return nil, err
+		}
+		if nodes[0].State == UpgradeNodeDone {
+			return nil, fmt.Errorf("canary node %q already runs %s", nodes[0].Name, req.Image)
+		}
+	}
+	for _, id := range nodeIDs {
+		if err := add(id); err != nil {
+			return nil, err
+		}
+	}
+	// Roll host by host: the rest are grouped by host, hosts in the order
+	// they first appear, so each host's nodes are upgraded together.
+	rest := nodes
+	if req.CanaryNodeID != 0 {
+		rest = nodes[1:]
+	}
+	hostOrder := map[int64]int{}
+	for _, n := range rest {
+		if _, ok := hostOrder[n.HostID]; !ok {
+			hostOrder[n.HostID] = len(hostOrder)
+		}
+	}
+	slices.SortStableFunc(rest, func(a, b UpgradeNode) int { return cmp.Compare(hostOrder[a.HostID], hostOrder[b.HostID]) })
+
+	state := UpgradeRolling
+	soakStr := ""
+	if req.CanaryNodeID != 0 {
+		state = UpgradeCanary
+		soakStr = soak.String()
+	}
+	if req.StartAt != nil {
+		state = UpgradeScheduled
+	}
+	nodesJSON, _ := json.Marshal(nodes)
+	u, err := scanUpgrade(m.pool.QueryRow(ctx, `
+		INSERT INTO upgrades (image, state, canary_node_id, soak, auto_proceed, node_timeout, start_at, deadline, nodes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+upgradeColumns,
+		req.Image, state, req.CanaryNodeID, soakStr, req.AutoProceed, nodeTimeout.String(), req.StartAt, req.Deadline, nodesJSON))
+	if err != nil {
+		return nil, fmt.Errorf("insert upgrade: %w", err)
+	}
+
+	if req.StartAt != nil {
+		m.logEvent(ctx, "upgrade.scheduled", req.Image,
+			fmt.Sprintf("Upgrade to %s scheduled for %d node(s) at %s%s", req.Image, len(nodes),
+				req.StartAt.UTC().Format(time.RFC3339), deadlineSuffix(u)),
+			map[string]any{"upgrade_id": u.ID})
+	} else {
+		m.logUpgradeStarted(ctx, u)
+	}
+
+	go m.runUpgrade(u.ID)
+	return u, nil
+}
+
+// logUpgradeStarted logs the start of an upgrade's rollout.
+func (m *Manager) logUpgradeStarted(ctx context.Context, u *Upgrade) {
+	msg := fmt.Sprintf("Upgrade to %s started for %d node(s)", u.Image, len(u.Nodes))
+	if u.CanaryNodeID != 0 {
+		msg += fmt.Sprintf(", canary %s soaking %s", u.Nodes[0].Name, u.Soak)
+	}
+	m.logEvent(ctx, "upgrade.started", u.Image, msg+deadlineSuffix(u), map[string]any{"upgrade_id": u.ID})
+}
+
+func deadlineSuffix(u *Upgrade) string {
+	if u.Deadline == nil {
+		return ""
+	}
+	return ", deadline " + u.Deadline.UTC().Format(time.RFC3339)
+}
+
+func parseUpgradeDuration(name, s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return d, nil
+}
+
+// ListUpgrades returns upgrades, newest first.
+func (m *Manager) ListUpgrades(ctx context.Context) ([]Upgrade, error) {
+	rows, err := m.pool.Query(ctx, "SELECT "+upgradeColumns+" FROM upgrades ORDER BY id DESC LIMIT 50")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Upgrade{}
+	for rows.Next() {
+		u, err := scanUpgrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *u)
+	}
+	return out, rows.Err()
+}
+
+// GetUpgrade returns one upgrade.
+func (m *Manager) GetUpgrade(ctx context.Context, id int64) (*Upgrade, error) {
+	return scanUpgrade(m.pool.QueryRow(ctx, "SELECT "+upgradeColumns+" FROM upgrades WHERE id=$1", id))
+}
+
+// ProceedUpgrade confirms an upgrade waiting after its canary soak, or
+// resumes a paused one by retrying the node that failed.
+func (m *Manager) ProceedUpgrade(ctx context.Context, id int64) (*Upgrade, error) {
+	u, err := m.GetUpgrade(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade not found")
+	}
+	switch u.State {
+	case UpgradeAwaiting:
+	case UpgradePaused:
+		for i := range u.Nodes {
+			if u.Nodes[i].State == UpgradeNodeFailed {
+				u.Nodes[i].State = UpgradeNodePending
+				u.Nodes[i].Error = ""
+			}
+		}
+	default:
+		return nil, fmt.Errorf("upgrade is %s, not awaiting confirmation or paused", u.State)
+	}
+	u.State = UpgradeRolling
+	u.Error = ""
+	if err := m.saveUpgrade(ctx, u); err != nil {
+		return nil, err
+	}
+	m.logEvent(ctx, "upgrade.proceeding", u.Image, "Upgrade rollout confirmed", map[string]any{"upgrade_id": id})
+	go m.runUpgrade(id)
+	return m.GetUpgrade(ctx, id)
+}
+
+// CancelUpgrade stops an upgrade after the node currently being upgraded.
+// Nodes already upgraded keep the new image.
+func (m *Manager) CancelUpgrade(ctx context.Context, id int64) (*Upgrade, error) {
+	tag, err := m.pool.Exec(ctx,
+		"UPDATE upgrades SET state=$1, updated_at=now() WHERE id=$2 AND state NOT IN ($3, $4, $5)",
+		UpgradeCancelled, id, UpgradeDone, UpgradeFailed, UpgradeCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("upgrade not found or already finished")
+	}
+	u, err := m.GetUpgrade(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.logEvent(ctx, "upgrade.cancelled", u.Image, "Upgrade cancelled", map[string]any{"upgrade_id": id})
+	return u, nil
+}
+
+// saveUpgrade writes an upgrade's progress, unless it was cancelled in the
+// meantime.
+func (m *Manager) saveUpgrade(ctx context.Context, u *Upgrade) error {
+	nodes, _ := json.Marshal(u.Nodes)
+	_, err := m.pool.Exec(ctx, `
+		UPDATE upgrades SET state=$1, soak_until=$2, nodes=$3, error=$4, updated_at=now()
+		WHERE id=$5 AND state != $6`,
+		u.State, u.SoakUntil, nodes, u.Error, u.ID, UpgradeCancelled)
+	if err != nil {
+		return fmt.Errorf("save upgrade: %w", err)
+	}
+	return nil
+}
+
+// upgradeCancelled reports whether the upgrade was cancelled.
+func (m *Manager) upgradeCancelled(ctx context.Context, id int64) bool {
+	var state string
+	if err := m.pool.QueryRow(ctx, "SELECT state FROM upgrades WHERE id=$1", id).Scan(&state); err != nil {
+		return true
+	}
+	return state == UpgradeCancelled
+}
+
+// runUpgrade drives an upgrade from its current state until it finishes,
+// pauses or needs confirmation. Runners are serialized, and each reloads the
+// upgrade, so a proceed racing a runner that is just exiting is safe.
+func (m *Manager) runUpgrade(id int64) {
+	m.upgradeMu.Lock()
+	defer m.upgradeMu.Unlock()
+
+	ctx := context.Background()
+	u, err := m.GetUpgrade(ctx, id)
+	if err != nil {
+		slog.Error("upgrade: load", "error", err, "upgrade_id", id)
+		return
+	}
+	nodeTimeout, _ := time.ParseDuration(u.NodeTimeout)
+	if nodeTimeout <= 0 {
+		nodeTimeout = defaultNodeTimeout
+	}
+
+	if u.State == UpgradeScheduled && !m.awaitUpgradeWindow(ctx, u) {
+		return
+	}
+
+	if u.State == UpgradeCanary || u.State == UpgradeSoaking {
+		if !m.runCanary(ctx, u, nodeTimeout) {
+			return
+		}
+		if !u.AutoProceed {
+			u.State = UpgradeAwaiting
+			m.saveUpgrade(ctx, u)
+			m.logEvent(ctx, "upgrade.awaiting", u.Image,
+				"Canary passed its soak; confirm to upgrade the remaining nodes",
+				map[string]any{"upgrade_id": u.ID})
+			return
+		}
+		u.State = UpgradeRolling
+		m.saveUpgrade(ctx, u)
+	}
+	if u.State != UpgradeRolling {
+		return
+	}
+
+	deadlineLogged := false
+	for i := range u.Nodes {
+		n := &u.Nodes[i]
+		if n.State == UpgradeNodeDone || n.State == UpgradeNodeRolledBack {
+			continue
+		}
+		if m.upgradeCancelled(ctx, u.ID) {
+			return
+		}
+		// Past the deadline the rollout carries on: a node on the old image
+		// only gets further behind.
+		if u.Deadline != nil && !deadlineLogged && time.Now().After(*u.Deadline) {
+			deadlineLogged = true
+			left := upgradeProgress(u, time.Now()).Pending
+			m.logEvent(ctx, "upgrade.deadline_missed", u.Image,
+				fmt.Sprintf("Deadline %s passed with %d node(s) left to upgrade", u.Deadline.UTC().Format(time.RFC3339), left),
+				map[string]any{"upgrade_id": u.ID, "pending": left})
+		}
+		if err := m.upgradeOne(ctx, u, n, nodeTimeout); err != nil {
+			u.State = UpgradePaused
+			u.Error = fmt.Sprintf("node %s: %v", n.Name, err)
+			m.saveUpgrade(ctx, u)
+			m.logEvent(ctx, "upgrade.paused", u.Image, "Upgrade paused: "+u.Error,
+				map[string]any{"upgrade_id": u.ID, "node": n.Name})
+			return
+		}
+		lastOnHost := i == len(u.Nodes)-1 || u.Nodes[i+1].HostID != n.HostID
+		if lastOnHost && !(u.CanaryNodeID != 0 && i == 0) {
+			m.logEvent(ctx, "upgrade.host_done", n.Host, fmt.Sprintf("Nodes on %s upgraded to %s", n.Host, u.Image),
+				map[string]any{"upgrade_id": u.ID, "host_id": n.HostID})
+		}
+	}
+
+	u.State = UpgradeDone
+	m.saveUpgrade(ctx, u)
+	m.logEvent(ctx, "upgrade.done", u.Image, fmt.Sprintf("Upgrade to %s finished", u.Image),
+		map[string]any{"upgrade_id": u.ID})
+}
+
+// awaitUpgradeWindow waits for a scheduled upgrade's start_at, pre-pulling
+// the image on the nodes' hosts so the window isn't spent downloading. It
+// reports whether the rollout should begin.
+func (m *Manager) awaitUpgradeWindow(ctx context.Context, u *Upgrade) bool {
+	pulled := map[int64]bool{}
+	for _, n := range u.Nodes {
+		if pulled[n.HostID] || n.State != UpgradeNodePending {
+			continue
+		}
+		pulled[n.HostID] = true
+		if dc := m.clientFor(n.HostID); dc != nil {
+			if err := dc.EnsureImage(ctx, u.Image); err != nil {
+				slog.Warn("upgrade: pre-pull", "error", err, "upgrade_id", u.ID, "host", n.Host)
+			}
+		}
+	}
+
+	for u.StartAt != nil && time.Now().Before(*u.StartAt) {
+		if !m.sleepOrStop(min(scheduleCheckInterval, time.Until(*u.StartAt))) || m.upgradeCancelled(ctx, u.ID) {
+			return false
+		}
+	}
+	if m.upgradeCancelled(ctx, u.ID) {
+		return false
+	}
+	u.State = UpgradeRolling
+	if u.CanaryNodeID != 0 {
+		u.State = UpgradeCanary
+	}
+	if err := m.saveUpgrade(ctx, u); err != nil {
+		slog.Error("upgrade: start", "error", err, "upgrade_id", u.ID)
+		return false
+	}
+	m.logUpgradeStarted(ctx, u)
+	return true
+}
+
+// runCanary upgrades and soaks the canary node. On failure the canary is
+// rolled back to its previous image and the upgrade fails; it reports
+// whether the rollout may continue.
+func (m *Manager) runCanary(ctx context.Context, u *Upgrade, nodeTimeout time.Duration) bool {
+	c := &u.Nodes[0]
+	fail := func(reason string) bool {
+		u.State = UpgradeFailed
+		u.Error = "canary " + c.Name + ": " + reason
+		if err := m.rollbackNode(ctx, c.NodeID, c.FromImage); err != nil {
+			u.Error += fmt.Sprintf(" (rollback failed: %v)", err)
+		} else {
+			c.State = UpgradeNodeRolledBack
+		}
+		m.saveUpgrade(ctx, u)
+		m.logEvent(ctx, "upgrade.canary_failed", u.Image, "Upgrade stopped: "+u.Error,
+			map[string]any{"upgrade_id": u.ID, "node": c.Name})
+		return false
+	}
+
+	if u.State == UpgradeCanary {
+		if err := m.upgradeOne(ctx, u, c, nodeTimeout); err != nil {
+			return fail(err.Error())
+		}
+		soak, _ := time.ParseDuration(u.Soak)
+		until := time.Now().Add(soak)
+		u.State = UpgradeSoaking
+		u.SoakUntil = &until
+		m.saveUpgrade(ctx, u)
+		m.logEvent(ctx, "upgrade.soaking", u.Image,
+			fmt.Sprintf("Canary %s upgraded; soaking until %s", c.Name, until.UTC().Format(time.RFC3339)),
+			map[string]any{"upgrade_id": u.ID, "node": c.Name})
+	}
+
+	// A restart resumes the soak where it left off.
+	unhealthy := 0
+	for u.SoakUntil != nil && time.Now().Before(*u.SoakUntil) {
+		if !m.sleepOrStop(min(soakCheckInterval, time.Until(*u.SoakUntil))) || m.upgradeCancelled(ctx, u.ID) {
+			return false
+		}
+		node, err := m.GetNode(ctx, c.NodeID)
+		if err != nil {
+			return fail("node disappeared")
+		}
+		cctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		healthy := node.Status == "running" && m.checkNodeHealth(cctx, *node)
+		cancel()
+		if healthy {
+			unhealthy = 0
+			continue
+		}
+		if unhealthy++; unhealthy >= soakMaxUnhealthy {
+			return fail(fmt.Sprintf("unhealthy for %d consecutive checks during soak", unhealthy))
+		}
+	}
+
+	if lag, err := m.canaryLag(ctx, c.NodeID); err != nil {
+		return fail("height check: " + err.Error())
+	} else if lag > canaryMaxLag {
+		return fail(fmt.Sprintf("P-Chain height %d blocks behind peers", lag))
+	}
+	return true
+}
+
+// canaryLag returns how many P-Chain blocks the node trails the highest
+// running node on the same network. With no peers to compare against it
+// returns 0.
+func (m *Manager) canaryLag(ctx context.Context, nodeID int64) (uint64, error) {
+	node, err := m.GetNode(ctx, nodeID)
+	if err != nil {
+		return 0, err
+	}
+	cctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	own, err := m.chainHeight(cctx, *node, &L1{})
+	if err != nil {
+		return 0, err
+	}
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var best uint64
+	for _, peer := range nodes {
+		if peer.ID == nodeID || peer.Network != node.Network || peer.Status != "running" {
+			continue
+		}
+		if h, err := m.chainHeight(cctx, peer, &L1{}); err == nil && h > best {
+			best = h
+		}
+	}
+	if best <= own {
+		return 0, nil
+	}
+	return best - own, nil
+}
+
+// upgradeOne moves one node to the upgrade's image and waits for it to be
+// healthy and bootstrapped. Stopped nodes only get the new image recorded.
+func (m *Manager) upgradeOne(ctx context.Context, u *Upgrade, n *UpgradeNode, timeout time.Duration) error {
+	started := time.Now()
+	n.State = UpgradeNodeUpgrading
+	n.StartedAt, n.FinishedAt = &started, nil
+	m.saveUpgrade(ctx, u)
+
+	err := m.setNodeImage(ctx, n.NodeID, u.Image, timeout)
+	now := time.Now()
+	n.FinishedAt = &now
+	if err != nil {
+		n.State = UpgradeNodeFailed
+		n.Error = err.Error()
+		m.saveUpgrade(ctx, u)
+		return err
+	}
+	n.State = UpgradeNodeDone
+	m.saveUpgrade(ctx, u)
+	m.logEvent(ctx, "node.upgraded", n.Name, fmt.Sprintf("Upgraded %s → %s", n.FromImage, u.Image),
+		map[string]any{"upgrade_id": u.ID})
+	return nil
+}
+
+// setNodeImage pulls image on the node's host, records it and recreates the
+// container, then waits until the node is bootstrapped.
+func (m *Manager) setNodeImage(ctx context.Context, nodeID int64, image string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	node, err := m.GetNode(ctx, nodeID)
+	if err != nil {
+		return ErrNodeNotFound
+	}
+	dc := m.clientFor(node.HostID)
+	if dc == nil {
+		return fmt.Errorf("host not connected")
+	}
+	if err := dc.EnsureImage(ctx, image); err != nil {
+		return fmt.Errorf("pull image: %w", err)
+	}
+
+	recreate := node.ContainerID != "" && node.Status != "stopped"
+	status := node.Status
+	if recreate {
+		status = "creating"
+	}
+	if _, err := m.pool.Exec(ctx, "UPDATE nodes SET image=$1, status=$2, updated_at=now() WHERE id=$3",
+		image, status, nodeID); err != nil {
+		return fmt.Errorf("update image: %w", err)
+	}
+	if !recreate {
+		return nil
+	}
+	m.requestReconfigure(nodeID)
+	if _, err := m.WaitNode(ctx, nodeID, WaitBootstrapped); err != nil {
+		if errors.Is(err, ErrWaitTimeout) {
+			return fmt.Errorf("not bootstrapped within %s", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// rollbackNode puts a node back on its previous image.
+func (m *Manager) rollbackNode(ctx context.Context, nodeID int64, image string) error {
+	if err := m.setNodeImage(ctx, nodeID, image, defaultNodeTimeout); err != nil {
+		return err
+	}
+	var name string
+	m.pool.QueryRow(ctx, "SELECT name FROM nodes WHERE id=$1", nodeID).Scan(&name)
+	m.logEvent(ctx, "node.rolled_back", name, "Rolled back to "+image, nil)
+	return nil
+}
+
+// recoverUpgrades restarts the runner of an upgrade interrupted by a
+// restart. A node caught mid-upgrade is simply upgraded again; a scheduled
+// upgrade goes back to waiting for its window.
+func (m *Manager) recoverUpgrades(ctx context.Context) {
+	var id int64
+	err := m.pool.QueryRow(ctx,
+		"SELECT id FROM upgrades WHERE state IN ($1, $2, $3, $4) ORDER BY id DESC LIMIT 1",
+		UpgradeScheduled, UpgradeCanary, UpgradeSoaking, UpgradeRolling).Scan(&id)
+	if err != nil {
+		return
+	}
+	slog.Info("resuming upgrade", "upgrade_id", id)
+	go m.runUpgrade(id)
+}
@@ -0,0 +1,207 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Uptime tracking of primary network validators.
+const (
+	// uptimeRequirement is the uptime percentage a validator needs over its
+	// staking period to be rewarded.
+	uptimeRequirement = 80.0
+	uptimeRetention   = 400 * 24 * time.Hour // longest staking period plus slack
+)
+
+// UptimeSample is one observation of a validator node's uptime.
+type UptimeSample struct {
+	ObservedAt time.Time `json:"observed_at"`
+	Connected  bool      `json:"connected"`
+	// Uptime is the node's own view of its uptime in the current validation
+	// period, from platform.getCurrentValidators.
+	Uptime float64 `json:"uptime"`
+	// RewardingStake and WeightedAverage are how the rest of the network
+	// sees the node, from info.uptime; nil when that call failed.
+	RewardingStake  *float64   `json:"rewarding_stake_percentage,omitempty"`
+	WeightedAverage *float64   `json:"weighted_average_percentage,omitempty"`
+	ValidationEnd   *time.Time `json:"validation_end,omitempty"`
+}
+
+// NodeUptime is a node's uptime history over a window.
+type NodeUptime struct {
+	NodeID      int64          `json:"node_id"`
+	Name        string         `json:"name"`
+	Window      string         `json:"window"`
+	Requirement float64        `json:"requirement"`
+	Latest      *UptimeSample  `json:"latest,omitempty"`
+	Min         *float64       `json:"min,omitempty"`     // lowest observed uptime in the window
+	Average     *float64       `json:"average,omitempty"` // mean observed uptime in the window
+	MeetsReward *bool          `json:"meets_reward,omitempty"`
+	Samples     []UptimeSample `json:"samples"`
+}
+
+// StartUptimePoller samples the uptime of validator nodes every interval.
+func (m *Manager) StartUptimePoller(interval time.Duration) {
+	m.startPoller("uptime", interval, m.pollUptime)
+}
+
+// pollUptime records an uptime sample for every running node that is a
+// current primary network validator. Other nodes are skipped.
+func (m *Manager) pollUptime() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return 0, 1
+	}
+	for _, n := range nodes {
+		if n.NodeID == "" || (n.Status != "running" && n.Status != "unhealthy") {
+			continue
+		}
+		checked++
+		if err := m.sampleUptime(ctx, n); err != nil {
+			failed++
+			slog.Debug("uptime sample", "error", err, "node", n.Name)
+		}
+	}
+	return checked, failed
+}
+
+// sampleUptime queries a node for its validator entry and network-observed
+// uptime and stores the result.
+func (m *Manager) sampleUptime(ctx context.Context, node Node) error {
+	var vals struct {
+		Validators []struct {
+			NodeID    string      `json:"nodeID"`
+			EndTime   json.Number `json:"endTime"`
+			Uptime    json.Number `json:"uptime"`
+			Connected bool        `json:"connected"`
+		} `json:"validators"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/bc/P", "platform.getCurrentValidators",
+		map[string]any{"nodeIDs": []string{node.NodeID}}, &vals); err != nil {
+		return err
+	}
+	if len(vals.Validators) == 0 {
+		return nil // not a validator
+	}
+	v := vals.Validators[0]
+	uptime, err := v.Uptime.Float64()
+	if err != nil {
+		return fmt.Errorf("invalid uptime %q", v.Uptime)
+	}
+	var end *time.Time
+	if secs, err := v.EndTime.Int64(); err == nil && secs > 0 {
+		t := time.Unix(secs, 0).UTC()
+		end = &t
+	}
+
+	var rewarding, weighted *float64
+	var seen struct {
+		RewardingStake  json.Number `json:"rewardingStakePercentage"`
+		WeightedAverage json.Number `json:"weightedAveragePercentage"`
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/info", "info.uptime", map[string]any{}, &seen); err == nil {
+		if f, err := seen.RewardingStake.Float64(); err == nil {
+			rewarding = &f
+		}
+		if f, err := seen.WeightedAverage.Float64(); err == nil {
+			weighted = &f
+		}
+	}
+
+	if end != nil && (node.StakingEnd == nil || !node.StakingEnd.Equal(*end)) {
+		m.pool.Exec(ctx, "UPDATE nodes SET staking_end=$1, updated_at=now() WHERE id=$2", end, node.ID)
+	}
+
+	var prev float64
+	hasPrev := m.pool.QueryRow(ctx,
+		"SELECT uptime FROM node_uptime WHERE node_id=$1 ORDER BY observed_at DESC LIMIT 1", node.ID).
+		Scan(&prev) == nil
+
+	if _, err := m.pool.Exec(ctx, `
+		INSERT INTO node_uptime (node_id, connected, uptime, rewarding_stake, weighted_average, validation_end)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		node.ID, v.Connected, uptime, rewarding, weighted, end); err != nil {
+		return fmt.Errorf("store uptime: %w", err)
+	}
+
+	if uptime < uptimeRequirement && (!hasPrev || prev >= uptimeRequirement) {
+		m.logEvent(ctx, "node.uptime_low", node.Name,
+			fmt.Sprintf("Validator uptime %.2f%% is below the %.0f%% reward requirement", uptime, uptimeRequirement),
+			map[string]any{"uptime": uptime, "node_id": node.NodeID})
+	} else if uptime >= uptimeRequirement && hasPrev && prev < uptimeRequirement {
+		m.logEvent(ctx, "node.uptime_recovered", node.Name,
+			fmt.Sprintf("Validator uptime back to %.2f%%", uptime),
+			map[string]any{"uptime": uptime, "node_id": node.NodeID})
+	}
+	return nil
+}
+
+// pruneUptime removes uptime samples older than the retention period.
+func (m *Manager) pruneUptime(ctx context.Context) {
+	_, err := m.pool.Exec(ctx, "DELETE FROM node_uptime WHERE observed_at < $1", time.Now().Add(-uptimeRetention))
+	if err != nil {
+		slog.Warn("prune uptime samples", "error", err)
+	}
+}
+
+// NodeUptimeHistory returns a node's uptime samples over the given window,
+// oldest first, with the latest value judged against the reward requirement.
+func (m *Manager) NodeUptimeHistory(ctx context.Context, id int64, window time.Duration) (*NodeUptime, error) {
+	if window <= 0 {
+		window = 14 * 24 * time.Hour
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		return nil, ErrNodeNotFound
+	}
+
+	report := &NodeUptime{
+		NodeID:      id,
+		Name:        node.Name,
+		Window:      window.String(),
+		Requirement: uptimeRequirement,
+		Samples:     []UptimeSample{},
+	}
+	rows, err := m.pool.Query(ctx, `
+		SELECT observed_at, connected, uptime, rewarding_stake, weighted_average, validation_end
+		FROM node_uptime
+		WHERE node_id = $1 AND observed_at >= $2
+		ORDER BY observed_at`, id, time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sum float64
+	for rows.Next() {
+		var s UptimeSample
+		if err := rows.Scan(&s.ObservedAt, &s.Connected, &s.Uptime, &s.RewardingStake, &s.WeightedAverage, &s.ValidationEnd); err != nil {
+			return nil, err
+		}
+		if report.Min == nil || s.Uptime < *report.Min {
+			v := s.Uptime
+			report.Min = &v
+		}
+		sum += s.Uptime
+		report.Samples = append(report.Samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if n := len(report.Samples); n > 0 {
+		latest := report.Samples[n-1]
+		avg := sum / float64(n)
+		meets := latest.Uptime >= uptimeRequirement
+		report.Latest = &latest
+		report.Average = &avg
+		report.MeetsReward = &meets
+	}
+	return report, nil
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// VersionSkew reports the nodes of one Avalanche network running more than
+// one AvalancheGo version.
+type VersionSkew struct {
+	Network  string              `json:"network"`
+	Versions map[string][]string `json:"versions"` // version -> node names
+	Warning  string              `json:"warning"`
+}
+
+// StartVersionPoller periodically refreshes every running node's
+// AvalancheGo version. Newly started nodes are also asked by the health
+// poller as soon as they are healthy.
+func (m *Manager) StartVersionPoller(interval time.Duration) {
+	m.startPoller("versions", interval, m.pollVersions)
+}
+
+func (m *Manager) pollVersions() (checked, failed int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	nodes, err := m.ListNodes(ctx)
+	if err != nil {
+		return 0, 1
+	}
+	for _, n := range nodes {
+		if n.Status != "running" {
+			continue
+		}
+		checked++
+		if !m.fetchAndStoreVersion(ctx, n) {
+			failed++
+		}
+	}
+	return checked, failed
+}
+
+// fetchAndStoreVersion records the version a node reports via
+// info.getNodeVersion, logging node.version_changed when it differs from
+// the last one seen.
+func (m *Manager) fetchAndStoreVersion(ctx context.Context, node Node) bool {
+	var result struct {
+		Version string `json:"version"` // e.g. "avalanchego/1.13.0"
+	}
+	if err := m.callNodeRPC(ctx, node, "/ext/info", "info.getNodeVersion", nil, &result); err != nil {
+		slog.Debug("fetch node version", "error", err, "node", node.Name)
+		return false
+	}
+	if result.Version == "" || result.Version == node.Version {
+		return true
+	}
+	if _, err := m.pool.Exec(ctx, "UPDATE nodes SET avago_version=$1, updated_at=now() WHERE id=$2", result.Version, node.ID); err != nil {
+		slog.Error("store node version", "error", err, "node", node.Name)
+		return false
+	}
+	if node.Version != "" {
+		m.logEvent(ctx, "node.version_changed", node.Name, fmt.Sprintf("%s → %s", node.Version, result.Version),
+			map[string]any{"from": node.Version, "to": result.Version})
+	}
+	return true
+}
+
+// VersionSkews groups running nodes by Avalanche network and reports each
+// network whose nodes run different AvalancheGo versions, e.g. a rollout
+// left half done before a mandatory network upgrade. Networks are compared
+// separately since Fuji usually moves first.
+func VersionSkews(nodes []Node) []VersionSkew {
+	byNetwork := map[string]map[string][]string{}
+	for _, n := range nodes {
+		if n.Version == "" || !containerUp(n.Status) {
+			continue
+		}
+		if byNetwork[n.Network] == nil {
+			byNetwork[n.Network] = map[string][]string{}
+		}
+		byNetwork[n.Network][n.Version] = append(byNetwork[n.Network][n.Version], n.Name)
+	}
+	skews := []VersionSkew{}
+	for network, versions := range byNetwork {
+		if len(versions) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(versions))
+		for v := range versions {
+			names = append(names, v)
+		}
+		slices.Sort(names)
+		skews = append(skews, VersionSkew{
+			Network:  network,
+			Versions: versions,
+			Warning:  fmt.Sprintf("%s nodes run %d AvalancheGo versions: %s", network, len(versions), strings.Join(names, ", ")),
+		})
+	}
+	slices.SortFunc(skews, func(a, b VersionSkew) int { return strings.Compare(a.Network, b.Network) })
+	return skews
+}
@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Conditions accepted by WaitNode and WaitL1.
+const (
+	WaitRunning      = "running"      // node status is running (P-Chain bootstrapped)
+	WaitHealthy      = "healthy"      // node: running and passing its health check; L1: verdict healthy
+	WaitBootstrapped = "bootstrapped" // node: healthy and P-Chain bootstrapped
+	WaitStopped      = "stopped"      // node status is stopped
+	WaitDeployed     = "deployed"     // L1 has a subnet_id and blockchain_id
+)
+
+// waitPollInterval is how often wait conditions are re-checked.
+const waitPollInterval = 2 * time.Second
+
+// ErrWaitTimeout is returned when a wait's context expires first.
+var ErrWaitTimeout = errors.New("timed out")
+
+// WaitError reports that a wait can no longer succeed, e.g. the node failed.
+type WaitError struct{ Reason string }
+
+func (e *WaitError) Error() string { return e.Reason }
+
+// WaitNode blocks until the node meets cond or ctx is done, and returns the
+// node as last seen. A failed node ends a wait for running, healthy or
+// bootstrapped early with a *WaitError.
+func (m *Manager) WaitNode(ctx context.Context, id int64, cond string) (*Node, error) {
+	switch cond {
+	case WaitRunning, WaitHealthy, WaitBootstrapped, WaitStopped:
+	default:
+		return nil, fmt.Errorf("invalid condition %q (want running, healthy, bootstrapped, or stopped)", cond)
+	}
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		node, err := m.GetNode(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ErrWaitTimeout
+			}
+			return nil, ErrNodeNotFound
+		}
+		if cond != WaitStopped && node.Status == "failed" {
+			return node, &WaitError{Reason: fmt.Sprintf("node %q failed", node.Name)}
+		}
+
+		var met bool
+		switch cond {
+		case WaitRunning:
+			met = node.Status == "running"
+		case WaitStopped:
+			met = node.Status == "stopped"
+		case WaitHealthy:
+			met = node.Status == "running" && m.checkNodeHealth(ctx, *node)
+		case WaitBootstrapped:
+			met, _ = m.nodeReady(ctx, *node)
+		}
+		if met {
+			return node, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return node, ErrWaitTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitL1 blocks until the L1 meets cond (deployed or healthy) or ctx is
+// done, and returns the L1 as last seen. A failed deployment ends the wait
+// early with a *WaitError.
+func (m *Manager) WaitL1(ctx context.Context, id int64, cond string) (*L1Detail, error) {
+	switch cond {
+	case WaitDeployed, WaitHealthy:
+	default:
+		return nil, fmt.Errorf("invalid condition %q (want deployed or healthy)", cond)
+	}
+
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		l1, err := m.GetL1(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ErrWaitTimeout
+			}
+			return nil, ErrL1NotFound
+		}
+		if l1.Status == "failed" {
+			return l1, &WaitError{Reason: fmt.Sprintf("L1 %q failed", l1.Name)}
+		}
+
+		var met bool
+		switch cond {
+		case WaitDeployed:
+			met = l1.SubnetID != "" && l1.BlockchainID != ""
+		case WaitHealthy:
+			if h, err := m.L1Health(ctx, id); err == nil {
+				met = h.Verdict == L1Healthy
+			}
+		}
+		if met {
+			return l1, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return l1, ErrWaitTimeout
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WeightChange is one entry of an L1's append-only validator weight history.
+// A removed validator is recorded with weight 0.
+type WeightChange struct {
+	ID          int64     `json:"id"`
+	NodeID      int64     `json:"node_id"`
+	NodeName    string    `json:"node_name"`
+	AvagoNodeID string    `json:"avago_node_id,omitempty"` // NodeID at the time, if known
+	Weight      int64     `json:"weight"`
+	Reason      string    `json:"reason"` // added, removed, node_deleted, node_expired, l1_expired, backfill
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// ValidatorWeight is one validator's share of an L1's stake.
+type ValidatorWeight struct {
+	NodeID      int64     `json:"node_id"`
+	NodeName    string    `json:"node_name"`
+	AvagoNodeID string    `json:"avago_node_id,omitempty"`
+	Weight      int64     `json:"weight"`
+	Share       float64   `json:"share"` // fraction of the total weight
+	Since       time.Time `json:"since"` // when this weight was recorded
+}
+
+// WeightDistribution is an L1's validator set at a point in time.
+type WeightDistribution struct {
+	L1ID        int64             `json:"l1_id"`
+	L1Name      string            `json:"l1_name"`
+	At          time.Time         `json:"at"`
+	TotalWeight int64             `json:"total_weight"`
+	Validators  []ValidatorWeight `json:"validators"`
+}
+
+// WeightHistoryFilter narrows WeightHistory. Zero fields match everything.
+type WeightHistoryFilter struct {
+	NodeID int64 // 0 = every validator
+	Since  time.Time
+	Until  time.Time
+}
+
+// recordWeight appends a validator's weight on an L1 to the history. The
+// history is informational, so a failed write is logged rather than failing
+// the change it describes. Rows keep the L1's name and outlive it, so the
+// history of an expired or deleted L1 stays readable.
+func (m *Manager) recordWeight(ctx context.Context, l1ID, nodeID, weight int64, reason string) {
+	if _, err := m.pool.Exec(ctx, `
+		INSERT INTO l1_validator_weights (l1_id, l1_name, node_id, node_name, avago_node_id, weight, reason)
+		SELECT $1, COALESCE((SELECT name FROM l1s WHERE id = $1), ''), n.id, n.name, n.node_id, $3, $4
+		FROM nodes n WHERE n.id = $2`,
+		l1ID, nodeID, weight, reason); err != nil {
+		slog.Warn("record validator weight failed", "l1", l1ID, "node", nodeID, "error", err)
+	}
+}
+
+// weightHistoryL1 returns the name of an L1 that exists or has recorded
+// weights, falling back to the history for L1s that have since been deleted.
+func (m *Manager) weightHistoryL1(ctx context.Context, l1ID int64) (string, error) {
+	var name string
+	err := m.pool.QueryRow(ctx, `
+		SELECT name FROM (
+			SELECT name, 0 AS pref FROM l1s WHERE id = $1
+			UNION ALL
+			(SELECT l1_name, 1 FROM l1_validator_weights WHERE l1_id = $1 ORDER BY id DESC LIMIT 1)
+		) n ORDER BY pref LIMIT 1`, l1ID).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrL1NotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("look up L1: %w", err)
+	}
+	return name, nil
+}
+
+// WeightDistribution returns each validator's weight on an L1 as of at
+// (now when zero): the latest recorded weight per node, removed ones left out.
+func (m *Manager) WeightDistribution(ctx context.Context, l1ID int64, at time.Time) (*WeightDistribution, error) {
+	name, err := m.weightHistoryL1(ctx, l1ID)
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	rows, err := m.pool.Query(ctx, `
+		SELECT node_id, node_name, avago_node_id, weight, recorded_at FROM (
+			SELECT DISTINCT ON (node_id) node_id, node_name, avago_node_id, weight, recorded_at
+			FROM l1_validator_weights
+			WHERE l1_id = $1 AND recorded_at <= $2
+			ORDER BY node_id, recorded_at DESC, id DESC
+		) latest
+		WHERE weight > 0
+		ORDER BY weight DESC, node_name`,
+		l1ID, at)
+	if err != nil {
+		return nil, fmt.Errorf("query weights: %w", err)
+	}
+	defer rows.Close()
+
+	d := &WeightDistribution{L1ID: l1ID, L1Name: name, At: at, Validators: []ValidatorWeight{}}
+	for rows.Next() {
+		var v ValidatorWeight
+		if err := rows.Scan(&v.NodeID, &v.NodeName, &v.AvagoNodeID, &v.Weight, &v.Since); err != nil {
+			return nil, fmt.Errorf("scan weight: %w", err)
+		}
+		d.TotalWeight += v.Weight
+		d.Validators = append(d.Validators, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range d.Validators {
+		d.Validators[i].Share = float64(d.Validators[i].Weight) / float64(d.TotalWeight)
+	}
+	return d, nil
+}
+
+// WeightHistory lists an L1's recorded weight changes, oldest first.
+func (m *Manager) WeightHistory(ctx context.Context, l1ID int64, f WeightHistoryFilter) ([]WeightChange, error) {
+	if _, err := m.weightHistoryL1(ctx, l1ID); err != nil {
+		return nil, err
+	}
+	query := `SELECT id, node_id, node_name, avago_node_id, weight, reason, recorded_at FROM l1_validator_weights WHERE l1_id = $1`
+	args := []any{l1ID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.NodeID != 0 {
+		query += " AND node_id = " + arg(f.NodeID)
+	}
+	if !f.Since.IsZero() {
+		query += " AND recorded_at >= " + arg(f.Since)
+	}
+	if !f.Until.IsZero() {
+		query += " AND recorded_at < " + arg(f.Until)
+	}
+	query += " ORDER BY recorded_at, id"
+
+	rows, err := m.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query weight history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []WeightChange{}
+	for rows.Next() {
+		var w WeightChange
+		if err := rows.Scan(&w.ID, &w.NodeID, &w.NodeName, &w.AvagoNodeID, &w.Weight, &w.Reason, &w.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan weight change: %w", err)
+		}
+		changes = append(changes, w)
+	}
+	return changes, rows.Err()
+}
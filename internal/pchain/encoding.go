@@ -0,0 +1,269 @@
+package pchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ID is a 32-byte Avalanche identifier (tx, asset, subnet, chain, VM).
+type ID [32]byte
+
+// String returns the CB58 form used by the APIs.
+func (id ID) String() string { return encodeCB58(id[:]) }
+
+// ParseID decodes a CB58 ID.
+func ParseID(s string) (ID, error) {
+	var id ID
+	b, err := decodeCB58(s)
+	if err != nil {
+		return id, err
+	}
+	if len(b) != len(id) {
+		return id, errors.New("invalid ID length")
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeCB58 is base58 with a 4-byte SHA-256 checksum appended.
+func encodeCB58(b []byte) string {
+	sum := sha256.Sum256(b)
+	b = append(append([]byte{}, b...), sum[28:]...)
+
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	var out []byte
+	for n.Sign() > 0 {
+		m := new(big.Int)
+		n.DivMod(n, radix, m)
+		out = append(out, base58Alphabet[m.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func decodeCB58(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		i := strings.IndexRune(base58Alphabet, c)
+		if i < 0 {
+			return nil, errors.New("invalid base58 character")
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	b := n.Bytes()
+	for _, c := range s {
+		if c != rune(base58Alphabet[0]) {
+			break
+		}
+		b = append([]byte{0}, b...)
+	}
+	if len(b) < 4 {
+		return nil, errors.New("cb58 too short")
+	}
+	payload, check := b[:len(b)-4], b[len(b)-4:]
+	sum := sha256.Sum256(payload)
+	if !bytes.Equal(sum[28:], check) {
+		return nil, errors.New("bad cb58 checksum")
+	}
+	return payload, nil
+}
+
+// encodeHex is the API's "hex" encoding: 0x-prefixed with a 4-byte
+// SHA-256 checksum.
+func encodeHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "0x" + hex.EncodeToString(append(append([]byte{}, b...), sum[28:]...))
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, errors.New("hex payload too short")
+	}
+	payload, check := b[:len(b)-4], b[len(b)-4:]
+	sum := sha256.Sum256(payload)
+	if !bytes.Equal(sum[28:], check) {
+		return nil, errors.New("bad hex checksum")
+	}
+	return payload, nil
+}
+
+// hrp returns the bech32 human-readable part for a network ID.
+func hrp(networkID uint32) string {
+	switch networkID {
+	case 1:
+		return "avax"
+	case 5:
+		return "fuji"
+	case 12345:
+		return "local"
+	default:
+		return "custom"
+	}
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// formatAddress renders a short ID as a P-Chain address, e.g. "P-fuji1...".
+func formatAddress(networkID uint32, id [20]byte) string {
+	h := hrp(networkID)
+	data := convertBits(id[:])
+	values := append(hrpExpand(h), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ 1
+
+	var sb strings.Builder
+	sb.WriteString("P-" + h + "1")
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return sb.String()
+}
+
+// ParseAddress decodes a P-Chain address such as "P-fuji1..." (the "P-"
+// prefix is optional), returning its human-readable part and short ID.
+func ParseAddress(s string) (string, [20]byte, error) {
+	var id [20]byte
+	s = strings.ToLower(strings.TrimPrefix(s, "P-"))
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || len(s)-sep-1 < 6 {
+		return "", id, fmt.Errorf("invalid address %q", s)
+	}
+	h := s[:sep]
+	var values []byte
+	for i := sep + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return "", id, fmt.Errorf("invalid address %q: bad character %q", s, s[i])
+		}
+		values = append(values, byte(d))
+	}
+	if bech32Polymod(append(hrpExpand(h), values...)) != 1 {
+		return "", id, fmt.Errorf("invalid address %q: bad checksum", s)
+	}
+
+	// Regroup the 5-bit data (minus the checksum) into bytes.
+	var out []byte
+	acc, bits := 0, 0
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | int(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	if len(out) != len(id) || bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", id, fmt.Errorf("invalid address %q: want 20 bytes", s)
+	}
+	copy(id[:], out)
+	return h, id, nil
+}
+
+func convertBits(b []byte) []byte {
+	var out []byte
+	acc, bits := 0, 0
+	for _, v := range b {
+		acc = acc<<8 | int(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out = append(out, byte(acc>>bits&31))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(5-bits)&31))
+	}
+	return out
+}
+
+func hrpExpand(h string) []byte {
+	out := make([]byte, 0, len(h)*2+1)
+	for i := 0; i < len(h); i++ {
+		out = append(out, h[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(h); i++ {
+		out = append(out, h[i]&31)
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// packer writes Avalanche's linear codec: big-endian integers, u32-length
+// slices, u16-length strings.
+type packer struct{ b []byte }
+
+func (p *packer) u16(v uint16)   { p.b = binary.BigEndian.AppendUint16(p.b, v) }
+func (p *packer) u32(v uint32)   { p.b = binary.BigEndian.AppendUint32(p.b, v) }
+func (p *packer) u64(v uint64)   { p.b = binary.BigEndian.AppendUint64(p.b, v) }
+func (p *packer) fixed(b []byte) { p.b = append(p.b, b...) }
+func (p *packer) bytes(b []byte) { p.u32(uint32(len(b))); p.fixed(b) }
+func (p *packer) str(s string)   { p.u16(uint16(len(s))); p.fixed([]byte(s)) }
+
+// unpacker reads the linear codec, remembering the first error.
+type unpacker struct {
+	b   []byte
+	err error
+}
+
+func (u *unpacker) take(n int) []byte {
+	if u.err != nil {
+		return make([]byte, n)
+	}
+	if len(u.b) < n {
+		u.err = errors.New("unexpected end of data")
+		return make([]byte, n)
+	}
+	v := u.b[:n]
+	u.b = u.b[n:]
+	return v
+}
+
+func (u *unpacker) u16() uint16 { return binary.BigEndian.Uint16(u.take(2)) }
+func (u *unpacker) u32() uint32 { return binary.BigEndian.Uint32(u.take(4)) }
+func (u *unpacker) u64() uint64 { return binary.BigEndian.Uint64(u.take(8)) }
+func (u *unpacker) id() ID {
+	var id ID
+	copy(id[:], u.take(32))
+	return id
+}
@@ -0,0 +1,127 @@
+package pchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// L1Validator is an initial validator of a subnet being converted to an L1.
+type L1Validator struct {
+	NodeID            [20]byte
+	Weight            uint64
+	Balance           uint64 // nAVAX prepaid for the validator's continuous fee
+	PublicKey         [48]byte
+	ProofOfPossession [96]byte
+}
+
+// ParseNodeID decodes a "NodeID-<cb58>" string.
+func ParseNodeID(s string) ([20]byte, error) {
+	var id [20]byte
+	rest, ok := strings.CutPrefix(s, "NodeID-")
+	if !ok {
+		return id, fmt.Errorf("invalid node ID %q", s)
+	}
+	b, err := decodeCB58(rest)
+	if err != nil {
+		return id, fmt.Errorf("invalid node ID %q: %w", s, err)
+	}
+	if len(b) != len(id) {
+		return id, fmt.Errorf("invalid node ID %q: want 20 bytes", s)
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// ParseProofOfPossession decodes the BLS public key and proof of possession
+// reported by info.getNodeID (0x-prefixed hex, no checksum).
+func ParseProofOfPossession(publicKey, pop string) (pub [48]byte, sig [96]byte, err error) {
+	pb, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil || len(pb) != len(pub) {
+		return pub, sig, errors.New("invalid BLS public key")
+	}
+	sb, err := hex.DecodeString(strings.TrimPrefix(pop, "0x"))
+	if err != nil || len(sb) != len(sig) {
+		return pub, sig, errors.New("invalid BLS proof of possession")
+	}
+	copy(pub[:], pb)
+	copy(sig[:], sb)
+	return pub, sig, nil
+}
+
+// ValidationID is the ID the P-Chain assigns to the initial validator at
+// index in a subnet's conversion: SHA-256(subnetID || index).
+func ValidationID(subnetID ID, index int) ID {
+	b := binary.BigEndian.AppendUint32(append([]byte{}, subnetID[:]...), uint32(index))
+	return sha256.Sum256(b)
+}
+
+// ConvertSubnetToL1 issues a ConvertSubnetToL1Tx for a subnet the wallet
+// owns, making chainID/address its validator manager, and waits for it to
+// commit. The validators' remaining balance and deactivation owner is the
+// wallet. It returns the tx ID and each validator's validation ID, in the
+// order given.
+func (w *Wallet) ConvertSubnetToL1(ctx context.Context, subnetID, chainID ID, address []byte, validators []L1Validator) (ID, []ID, error) {
+	if len(validators) == 0 {
+		return ID{}, nil, errors.New("at least one validator is required")
+	}
+	// The P-Chain requires validators sorted by node ID.
+	order := make([]int, len(validators))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return bytes.Compare(validators[order[a]].NodeID[:], validators[order[b]].NodeID[:]) < 0
+	})
+
+	var burn uint64
+	for _, v := range validators {
+		burn += v.Balance
+	}
+	n := uint64(len(validators))
+	txID, err := w.issue(ctx, txSpec{
+		typeID:     typeConvertSubnetToL1Tx,
+		burn:       burn,
+		subnetAuth: true,
+		// Each validator is written to state and its proof of possession
+		// verified.
+		extra: complexity{dbRead: n + 2, dbWrite: 4*n + 2, compute: 1100 * n},
+		body: func(p *packer) {
+			p.fixed(subnetID[:])
+			p.fixed(chainID[:])
+			p.bytes(address)
+			p.u32(uint32(len(validators)))
+			for _, i := range order {
+				v := validators[i]
+				p.bytes(v.NodeID[:])
+				p.u64(v.Weight)
+				p.u64(v.Balance)
+				p.fixed(v.PublicKey[:])
+				p.fixed(v.ProofOfPossession[:])
+				for range 2 { // remaining balance owner, deactivation owner
+					p.u32(1)
+					p.u32(1)
+					p.fixed(w.addr[:])
+				}
+			}
+			p.u32(typeInput)
+			p.u32(1) // subnet auth sig indices: the wallet is the only owner
+			p.u32(0)
+		},
+	})
+	if err != nil {
+		return txID, nil, err
+	}
+
+	ids := make([]ID, len(validators))
+	for pos, i := range order {
+		ids[i] = ValidationID(subnetID, pos)
+	}
+	return txID, ids, nil
+}
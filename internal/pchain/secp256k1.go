@@ -0,0 +1,74 @@
+package pchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // Avalanche addresses are RIPEMD-160 hashes
+)
+
+// PrivateKey is a secp256k1 key controlling P-Chain funds. The curve
+// arithmetic is decred's constant-time implementation, the one AvalancheGo
+// itself uses.
+type PrivateKey struct {
+	key *secp256k1.PrivateKey
+}
+
+// ParseKey accepts a key as "PrivateKey-<cb58>" (the Avalanche wallet
+// format) or as 32 hex-encoded bytes.
+func ParseKey(s string) (*PrivateKey, error) {
+	s = strings.TrimSpace(s)
+	var raw []byte
+	var err error
+	if rest, ok := strings.CutPrefix(s, "PrivateKey-"); ok {
+		raw, err = decodeCB58(rest)
+	} else {
+		raw, err = hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, errors.New("parse private key: want 32 bytes")
+	}
+	var d secp256k1.ModNScalar
+	if overflow := d.SetByteSlice(raw); overflow || d.IsZero() {
+		return nil, errors.New("parse private key: out of range")
+	}
+	return &PrivateKey{key: secp256k1.NewPrivateKey(&d)}, nil
+}
+
+// compressedPub is the 33-byte SEC1 compressed public key.
+func (k *PrivateKey) compressedPub() []byte {
+	return k.key.PubKey().SerializeCompressed()
+}
+
+// ShortID is the 20-byte address the key controls: RIPEMD-160(SHA-256(pub)).
+func (k *PrivateKey) ShortID() [20]byte {
+	sh := sha256.Sum256(k.compressedPub())
+	r := ripemd160.New()
+	r.Write(sh[:])
+	var id [20]byte
+	copy(id[:], r.Sum(nil))
+	return id
+}
+
+// sign returns a deterministic (RFC 6979) recoverable signature over a
+// 32-byte hash in Avalanche's [r || s || v] layout, with s in the lower half
+// of the order as the P-Chain requires.
+func (k *PrivateKey) sign(hash []byte) ([65]byte, error) {
+	var sig [65]byte
+	if len(hash) != 32 {
+		return sig, fmt.Errorf("sign: want a 32-byte hash, got %d bytes", len(hash))
+	}
+	// SignCompact yields [27 + 4 (compressed) + v || r || s].
+	compact := ecdsa.SignCompact(k.key, hash, true)
+	copy(sig[:64], compact[1:])
+	sig[64] = compact[0] - 27 - 4
+	return sig, nil
+}
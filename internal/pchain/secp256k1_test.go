@@ -0,0 +1,115 @@
+package pchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// ewoqKey is the well-known funded key of local Avalanche networks.
+const (
+	ewoqKey     = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+	ewoqKeyHex  = "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"
+	ewoqShortID = "3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+	ewoqAddress = "P-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"
+)
+
+func TestParseKey(t *testing.T) {
+	cb58, err := ParseKey(ewoqKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexKey, err := ParseKey("0x" + ewoqKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := cb58.ShortID()
+	if got := hex.EncodeToString(id[:]); got != ewoqShortID {
+		t.Errorf("short ID = %s, want %s", got, ewoqShortID)
+	}
+	if hexKey.ShortID() != id {
+		t.Error("hex and CB58 forms of the key differ")
+	}
+	if got := formatAddress(12345, id); got != ewoqAddress {
+		t.Errorf("address = %s, want %s", got, ewoqAddress)
+	}
+	h, parsed, err := ParseAddress(ewoqAddress)
+	if err != nil || h != "local" || parsed != id {
+		t.Errorf("ParseAddress = %q, %x, %v", h, parsed, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"00",
+		"0000000000000000000000000000000000000000000000000000000000000000", // zero
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", // the group order
+		"PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNM",     // bad checksum
+	} {
+		if _, err := ParseKey(bad); err == nil {
+			t.Errorf("ParseKey(%q) accepted", bad)
+		}
+	}
+}
+
+// RFC 6979 deterministic signatures over SHA-256, from the secp256k1 test
+// vectors used by Trezor and python-ecdsa.
+func TestSignVectors(t *testing.T) {
+	for _, tc := range []struct {
+		key, msg, r, s string
+	}{
+		{
+			key: "0000000000000000000000000000000000000000000000000000000000000001",
+			msg: "Satoshi Nakamoto",
+			r:   "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8",
+			s:   "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5",
+		},
+		{
+			key: "0000000000000000000000000000000000000000000000000000000000000001",
+			msg: "All those moments will be lost in time, like tears in rain. Time to die...",
+			r:   "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b",
+			s:   "547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21",
+		},
+	} {
+		key, err := ParseKey(tc.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256([]byte(tc.msg))
+		sig, err := key.sign(hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(sig[:32]); got != tc.r {
+			t.Errorf("%q: r = %s, want %s", tc.msg, got, tc.r)
+		}
+		if got := hex.EncodeToString(sig[32:64]); got != tc.s {
+			t.Errorf("%q: s = %s, want %s", tc.msg, got, tc.s)
+		}
+		assertRecovers(t, key, hash[:], sig)
+	}
+}
+
+// assertRecovers checks that a [r || s || v] signature is low-S and
+// recovers to the key's public key.
+func assertRecovers(t *testing.T, key *PrivateKey, hash []byte, sig [65]byte) {
+	t.Helper()
+	if sig[64] > 1 {
+		t.Fatalf("recovery id %d, want 0 or 1", sig[64])
+	}
+	// The P-Chain rejects s above half the group order.
+	halfN, _ := hex.DecodeString("7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a0")
+	if bytes.Compare(sig[32:64], halfN) > 0 {
+		t.Errorf("s = %x is not in the lower half", sig[32:64])
+	}
+	compact := append([]byte{27 + 4 + sig[64]}, sig[:64]...)
+	pub, compressed, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if !compressed || !bytes.Equal(pub.SerializeCompressed(), key.compressedPub()) {
+		t.Error("signature recovers to a different public key")
+	}
+}
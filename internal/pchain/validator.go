@@ -0,0 +1,78 @@
+package pchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// delegationDenominator is the P-Chain's unit for delegation shares:
+// 1,000,000 is 100%.
+const delegationDenominator = 1_000_000
+
+// PrimaryValidator describes a Primary Network validator registration.
+type PrimaryValidator struct {
+	NodeID            [20]byte
+	End               time.Time
+	Stake             uint64 // nAVAX, locked until End and then returned to the wallet
+	PublicKey         [48]byte
+	ProofOfPossession [96]byte
+	RewardAddress     string  // P-Chain address receiving validation and delegation rewards
+	DelegationFee     float64 // percent of delegator rewards kept, e.g. 2
+}
+
+// AddPrimaryValidator issues an AddPermissionlessValidatorTx staking v.Stake
+// from the wallet on the Primary Network and waits for it to commit. The
+// validation starts when the transaction is accepted.
+func (w *Wallet) AddPrimaryValidator(ctx context.Context, v PrimaryValidator) (ID, error) {
+	h, reward, err := ParseAddress(v.RewardAddress)
+	if err != nil {
+		return ID{}, err
+	}
+	if h != hrp(w.networkID) {
+		return ID{}, fmt.Errorf("reward address %s is not on this network (%s)", v.RewardAddress, hrp(w.networkID))
+	}
+	if v.Stake == 0 {
+		return ID{}, errors.New("stake is required")
+	}
+	if v.DelegationFee < 0 || v.DelegationFee > 100 {
+		return ID{}, fmt.Errorf("invalid delegation fee %v%%", v.DelegationFee)
+	}
+	shares := uint32(v.DelegationFee / 100 * delegationDenominator)
+
+	return w.issue(ctx, txSpec{
+		typeID:    typeAddPermissionlessValidatorTx,
+		staticFee: "addPrimaryNetworkValidatorFee",
+		burn:      v.Stake,
+		// The staker, its weight and public key diffs are written to state,
+		// and the proof of possession verified.
+		extra: complexity{dbRead: 1, dbWrite: 4, compute: 1100},
+		body: func(p *packer) {
+			p.fixed(v.NodeID[:])
+			p.u64(uint64(time.Now().Unix())) // ignored since Durango
+			p.u64(uint64(v.End.Unix()))
+			p.u64(v.Stake)            // weight
+			p.fixed(make([]byte, 32)) // Primary Network subnet ID
+			p.u32(typeProofOfPossession)
+			p.fixed(v.PublicKey[:])
+			p.fixed(v.ProofOfPossession[:])
+			p.u32(1) // stake outputs
+			p.fixed(w.avaxID[:])
+			p.u32(typeTransferOutput)
+			p.u64(v.Stake)
+			p.u64(0) // locktime
+			p.u32(1) // threshold
+			p.u32(1)
+			p.fixed(w.addr[:])
+			for range 2 { // validation and delegation rewards owners
+				p.u32(typeOutputOwners)
+				p.u64(0)
+				p.u32(1)
+				p.u32(1)
+				p.fixed(reward[:])
+			}
+			p.u32(shares)
+		},
+	})
+}
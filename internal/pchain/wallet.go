@@ -0,0 +1,415 @@
+package pchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// P-Chain codec type IDs.
+const (
+	typeTransferInput  = 5
+	typeTransferOutput = 7
+	typeCredential     = 9
+	typeInput          = 10
+	typeOutputOwners   = 11
+	typeCreateChainTx  = 15
+	typeCreateSubnetTx = 16
+
+	typeAddPermissionlessValidatorTx = 25
+	typeProofOfPossession            = 28
+
+	typeConvertSubnetToL1Tx = 35
+)
+
+// RPC calls a JSON-RPC method on an AvalancheGo node, e.g. endpoint
+// "/ext/bc/P", and decodes the result into out.
+type RPC func(ctx context.Context, endpoint, method string, params, out any) error
+
+// Wallet builds, signs and issues P-Chain transactions paid for by a single
+// secp256k1 key. It spends only unlocked, single-signature AVAX outputs.
+type Wallet struct {
+	key       *PrivateKey
+	rpc       RPC
+	networkID uint32
+	avaxID    ID
+	addr      [20]byte
+}
+
+// NewWallet looks up the network and AVAX asset through rpc.
+func NewWallet(ctx context.Context, key *PrivateKey, rpc RPC) (*Wallet, error) {
+	w := &Wallet{key: key, rpc: rpc, addr: key.ShortID()}
+
+	var net struct {
+		NetworkID flexUint64 `json:"networkID"`
+	}
+	if err := rpc(ctx, "/ext/info", "info.getNetworkID", nil, &net); err != nil {
+		return nil, fmt.Errorf("get network id: %w", err)
+	}
+	w.networkID = uint32(net.NetworkID)
+
+	var asset struct {
+		AssetID string `json:"assetID"`
+	}
+	if err := rpc(ctx, "/ext/bc/P", "platform.getStakingAssetID", map[string]any{}, &asset); err != nil {
+		return nil, fmt.Errorf("get AVAX asset id: %w", err)
+	}
+	id, err := ParseID(asset.AssetID)
+	if err != nil {
+		return nil, fmt.Errorf("parse AVAX asset id: %w", err)
+	}
+	w.avaxID = id
+	return w, nil
+}
+
+// Address is the wallet's P-Chain address, e.g. "P-fuji1...".
+func (w *Wallet) Address() string {
+	return formatAddress(w.networkID, w.addr)
+}
+
+// CreateSubnet issues a CreateSubnetTx owned by the wallet (threshold 1) and
+// waits for it to commit. The returned tx ID is the subnet ID.
+func (w *Wallet) CreateSubnet(ctx context.Context) (ID, error) {
+	return w.issue(ctx, txSpec{
+		typeID:    typeCreateSubnetTx,
+		staticFee: "createSubnetTxFee",
+		body: func(p *packer) {
+			p.u32(typeOutputOwners)
+			p.u64(0) // locktime
+			p.u32(1) // threshold
+			p.u32(1)
+			p.fixed(w.addr[:])
+		},
+	})
+}
+
+// CreateChain issues a CreateChainTx on a subnet the wallet owns and waits
+// for it to commit. The returned tx ID is the blockchain ID.
+func (w *Wallet) CreateChain(ctx context.Context, subnetID ID, name string, vmID ID, genesis []byte) (ID, error) {
+	return w.issue(ctx, txSpec{
+		typeID:     typeCreateChainTx,
+		staticFee:  "createBlockchainTxFee",
+		subnetAuth: true,
+		body: func(p *packer) {
+			p.fixed(subnetID[:])
+			p.str(name)
+			p.fixed(vmID[:])
+			p.u32(0) // fx IDs
+			p.bytes(genesis)
+			p.u32(typeInput)
+			p.u32(1) // subnet auth sig indices: the wallet is the only owner
+			p.u32(0)
+		},
+	})
+}
+
+// txSpec describes the type-specific part of a transaction.
+type txSpec struct {
+	typeID     uint32
+	body       func(p *packer) // fields after the BaseTx
+	subnetAuth bool            // adds a credential authorizing the subnet owner
+	staticFee  string          // info.getTxFee field for pre-Etna networks
+	extra      complexity      // beyond inputs, outputs and size
+	burn       uint64          // nAVAX consumed on top of the fee, e.g. validator balances or stake
+}
+
+// complexity is the P-Chain's gas dimensions for a transaction.
+type complexity struct {
+	bandwidth, dbRead, dbWrite, compute uint64
+}
+
+// utxo is a spendable AVAX output.
+type utxo struct {
+	txID     ID
+	index    uint32
+	amount   uint64
+	sigIndex uint32
+}
+
+// issue funds, signs and issues a transaction, then waits for it to commit.
+func (w *Wallet) issue(ctx context.Context, spec txSpec) (ID, error) {
+	utxos, err := w.spendable(ctx)
+	if err != nil {
+		return ID{}, err
+	}
+
+	// The fee depends on the size, which depends on how many inputs pay
+	// the fee: grow the estimate until the inputs cover it.
+	var fee uint64
+	for range 5 {
+		ins, total, err := selectUTXOs(utxos, fee+spec.burn)
+		if err != nil {
+			return ID{}, fmt.Errorf("%w (wallet %s)", err, w.Address())
+		}
+		unsigned := w.unsignedTx(spec, ins, total-fee-spec.burn)
+		signed, err := w.sign(unsigned, len(ins), spec.subnetAuth)
+		if err != nil {
+			return ID{}, err
+		}
+		outs := 0
+		if total > fee+spec.burn {
+			outs = 1
+		}
+		need, err := w.fee(ctx, spec, len(signed), len(ins), outs)
+		if err != nil {
+			return ID{}, err
+		}
+		if need <= fee {
+			return w.submit(ctx, signed)
+		}
+		fee = need
+	}
+	return ID{}, errors.New("could not settle on a transaction fee")
+}
+
+// spendable returns the wallet's unlocked single-signature AVAX outputs.
+func (w *Wallet) spendable(ctx context.Context) ([]utxo, error) {
+	const limit = 1024
+	var out []utxo
+	params := map[string]any{"addresses": []string{w.Address()}, "limit": limit, "encoding": "hex"}
+	now := uint64(time.Now().Unix())
+	for {
+		var res struct {
+			NumFetched flexUint64 `json:"numFetched"`
+			UTXOs      []string   `json:"utxos"`
+			EndIndex   struct {
+				Address string `json:"address"`
+				UTXO    string `json:"utxo"`
+			} `json:"endIndex"`
+		}
+		if err := w.rpc(ctx, "/ext/bc/P", "platform.getUTXOs", params, &res); err != nil {
+			return nil, fmt.Errorf("get utxos: %w", err)
+		}
+		for _, s := range res.UTXOs {
+			b, err := decodeHex(s)
+			if err != nil {
+				return nil, fmt.Errorf("decode utxo: %w", err)
+			}
+			if u, ok := w.parseUTXO(b, now); ok {
+				out = append(out, u)
+			}
+		}
+		if int(res.NumFetched) < limit {
+			break
+		}
+		params["startIndex"] = res.EndIndex
+	}
+	return out, nil
+}
+
+// parseUTXO decodes a UTXO and reports whether the wallet can spend it alone.
+func (w *Wallet) parseUTXO(b []byte, now uint64) (utxo, bool) {
+	u := unpacker{b: b}
+	u.u16() // codec version
+	var x utxo
+	x.txID = u.id()
+	x.index = u.u32()
+	asset := u.id()
+	if u.u32() != typeTransferOutput || asset != w.avaxID {
+		return x, false
+	}
+	x.amount = u.u64()
+	locktime := u.u64()
+	threshold := u.u32()
+	n := u.u32()
+	if u.err != nil || locktime > now || threshold != 1 || n > 128 {
+		return x, false
+	}
+	found := false
+	for i := uint32(0); i < n; i++ {
+		var a [20]byte
+		copy(a[:], u.take(20))
+		if a == w.addr && !found {
+			x.sigIndex, found = i, true
+		}
+	}
+	return x, found && u.err == nil
+}
+
+// selectUTXOs picks outputs, largest first, until they cover amount.
+func selectUTXOs(utxos []utxo, amount uint64) ([]utxo, uint64, error) {
+	sorted := append([]utxo{}, utxos...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].amount > sorted[j].amount })
+	var picked []utxo
+	var total uint64
+	for _, u := range sorted {
+		if total >= amount && len(picked) > 0 {
+			break
+		}
+		picked = append(picked, u)
+		total += u.amount
+	}
+	if total < amount || len(picked) == 0 {
+		return nil, 0, fmt.Errorf("insufficient funds: have %d nAVAX, need %d", total, amount)
+	}
+	// Inputs must be sorted by UTXO ID.
+	sort.Slice(picked, func(i, j int) bool {
+		if c := bytes.Compare(picked[i].txID[:], picked[j].txID[:]); c != 0 {
+			return c < 0
+		}
+		return picked[i].index < picked[j].index
+	})
+	return picked, total, nil
+}
+
+// unsignedTx serializes a transaction whose BaseTx spends ins and returns
+// change to the wallet.
+func (w *Wallet) unsignedTx(spec txSpec, ins []utxo, change uint64) []byte {
+	p := &packer{}
+	p.u16(0) // codec version
+	p.u32(spec.typeID)
+	p.u32(w.networkID)
+	p.fixed(make([]byte, 32)) // P-Chain blockchain ID
+	if change > 0 {
+		p.u32(1)
+		p.fixed(w.avaxID[:])
+		p.u32(typeTransferOutput)
+		p.u64(change)
+		p.u64(0) // locktime
+		p.u32(1) // threshold
+		p.u32(1)
+		p.fixed(w.addr[:])
+	} else {
+		p.u32(0)
+	}
+	p.u32(uint32(len(ins)))
+	for _, in := range ins {
+		p.fixed(in.txID[:])
+		p.u32(in.index)
+		p.fixed(w.avaxID[:])
+		p.u32(typeTransferInput)
+		p.u64(in.amount)
+		p.u32(1)
+		p.u32(in.sigIndex)
+	}
+	p.bytes(nil) // memo
+	spec.body(p)
+	return p.b
+}
+
+// sign appends one credential per input (and one for subnet auth).
+func (w *Wallet) sign(unsigned []byte, numIns int, subnetAuth bool) ([]byte, error) {
+	hash := sha256.Sum256(unsigned)
+	sig, err := w.key.sign(hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	creds := numIns
+	if subnetAuth {
+		creds++
+	}
+	p := &packer{b: append([]byte{}, unsigned...)}
+	p.u32(uint32(creds))
+	for range creds {
+		p.u32(typeCredential)
+		p.u32(1)
+		p.fixed(sig[:])
+	}
+	return p.b, nil
+}
+
+// fee returns the fee for a transaction. On Etna networks it is the gas
+// (an upper bound on the transaction's complexity, weighted by the fee
+// config) times twice the current gas price, so a price rise between
+// building and issuing doesn't get it rejected; the surplus is burned.
+// Older networks charge the static fee named in spec.
+func (w *Wallet) fee(ctx context.Context, spec txSpec, size, numIns, numOuts int) (uint64, error) {
+	var cfg struct {
+		Weights []flexUint64 `json:"weights"`
+	}
+	var state struct {
+		Price flexUint64 `json:"price"`
+	}
+	if err := w.rpc(ctx, "/ext/bc/P", "platform.getFeeConfig", map[string]any{}, &cfg); err != nil || len(cfg.Weights) != 4 {
+		return w.staticFee(ctx, spec.staticFee)
+	}
+	if err := w.rpc(ctx, "/ext/bc/P", "platform.getFeeState", map[string]any{}, &state); err != nil {
+		return 0, fmt.Errorf("get fee state: %w", err)
+	}
+
+	sigs := uint64(numIns)
+	if spec.subnetAuth {
+		sigs++
+	}
+	c := complexity{
+		bandwidth: uint64(size),
+		dbRead:    uint64(numIns) + 4 + spec.extra.dbRead,
+		dbWrite:   uint64(numIns+numOuts) + 4 + spec.extra.dbWrite,
+		compute:   sigs*200 + 1000 + spec.extra.compute,
+	}
+	gas := c.bandwidth*uint64(cfg.Weights[0]) + c.dbRead*uint64(cfg.Weights[1]) +
+		c.dbWrite*uint64(cfg.Weights[2]) + c.compute*uint64(cfg.Weights[3])
+	return gas * uint64(state.Price) * 2, nil
+}
+
+func (w *Wallet) staticFee(ctx context.Context, field string) (uint64, error) {
+	if field == "" {
+		return 0, errors.New("network has no dynamic fees (pre-Etna)")
+	}
+	var fees map[string]flexUint64
+	if err := w.rpc(ctx, "/ext/info", "info.getTxFee", nil, &fees); err != nil {
+		return 0, fmt.Errorf("get tx fee: %w", err)
+	}
+	fee, ok := fees[field]
+	if !ok {
+		return 0, fmt.Errorf("node reports no %s", field)
+	}
+	return uint64(fee), nil
+}
+
+// submit issues a signed transaction and waits for it to commit.
+func (w *Wallet) submit(ctx context.Context, signed []byte) (ID, error) {
+	txID := ID(sha256.Sum256(signed))
+	var res struct {
+		TxID string `json:"txID"`
+	}
+	if err := w.rpc(ctx, "/ext/bc/P", "platform.issueTx",
+		map[string]any{"tx": encodeHex(signed), "encoding": "hex"}, &res); err != nil {
+		return txID, fmt.Errorf("issue tx: %w", err)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		var st struct {
+			Status string `json:"status"`
+			Reason string `json:"reason"`
+		}
+		if err := w.rpc(ctx, "/ext/bc/P", "platform.getTxStatus", map[string]any{"txID": txID.String()}, &st); err != nil {
+			return txID, fmt.Errorf("tx status: %w", err)
+		}
+		switch st.Status {
+		case "Committed":
+			return txID, nil
+		case "Aborted", "Dropped":
+			return txID, fmt.Errorf("tx %s %s: %s", txID, strings.ToLower(st.Status), st.Reason)
+		}
+		select {
+		case <-ctx.Done():
+			return txID, fmt.Errorf("tx %s not committed: %w", txID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// flexUint64 decodes a uint64 sent either as a JSON number or a string.
+type flexUint64 uint64
+
+func (f *flexUint64) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", b)
+	}
+	*f = flexUint64(v)
+	return nil
+}
+
+var _ json.Unmarshaler = (*flexUint64)(nil)
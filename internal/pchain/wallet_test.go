@@ -0,0 +1,317 @@
+package pchain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fixed IDs so the expected encodings below stay readable.
+var (
+	testAsset  = repeatID(0x33) // AVAX asset ID
+	testUTXOTx = repeatID(0x22)
+	testSubnet = repeatID(0x44)
+	testChain  = repeatID(0x55)
+)
+
+func repeatID(b byte) ID {
+	var id ID
+	for i := range id {
+		id[i] = b
+	}
+	return id
+}
+
+// fakeNode answers the P-Chain calls a wallet makes: one UTXO holding
+// balance nAVAX, static fees unless gas weights are set, and every issued
+// tx committed.
+type fakeNode struct {
+	balance uint64
+	weights []uint64 // Etna gas weights; the gas price is 1
+	issued  []byte
+	polled  string // tx ID passed to platform.getTxStatus
+}
+
+func (f *fakeNode) rpc(_ context.Context, _, method string, params, out any) error {
+	var res any
+	switch method {
+	case "info.getNetworkID":
+		res = map[string]any{"networkID": "12345"}
+	case "platform.getStakingAssetID":
+		res = map[string]any{"assetID": testAsset.String()}
+	case "platform.getUTXOs":
+		res = map[string]any{"numFetched": "1", "utxos": []string{encodeHex(f.utxo())}}
+	case "platform.getFeeConfig":
+		if f.weights == nil {
+			return fmt.Errorf("method not found") // pre-Etna: static fees
+		}
+		res = map[string]any{"weights": f.weights}
+	case "platform.getFeeState":
+		res = map[string]any{"price": "1"}
+	case "info.getTxFee":
+		res = map[string]any{"createSubnetTxFee": "1000000", "createBlockchainTxFee": "2000000", "addPrimaryNetworkValidatorFee": "0"}
+	case "platform.issueTx":
+		b, err := decodeHex(params.(map[string]any)["tx"].(string))
+		if err != nil {
+			return err
+		}
+		f.issued = b
+		res = map[string]any{"txID": ""}
+	case "platform.getTxStatus":
+		f.polled = params.(map[string]any)["txID"].(string)
+		res = map[string]any{"status": "Committed"}
+	default:
+		return fmt.Errorf("unexpected call %s", method)
+	}
+	b, _ := json.Marshal(res)
+	return json.Unmarshal(b, out)
+}
+
+// utxo is a single-owner AVAX output of the ewoq key.
+func (f *fakeNode) utxo() []byte {
+	return mustHex(
+		"0000", // codec version
+		hex.EncodeToString(testUTXOTx[:]),
+		"00000000", // output index
+		hex.EncodeToString(testAsset[:]),
+		"00000007", // secp256k1 transfer output
+		fmt.Sprintf("%016x", f.balance),
+		"0000000000000000", // locktime
+		"00000001",         // threshold
+		"00000001", ewoqShortID,
+	)
+}
+
+func mustHex(parts ...string) []byte {
+	b, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func newTestWallet(t *testing.T, balance uint64) (*Wallet, *fakeNode) {
+	t.Helper()
+	key, err := ParseKey(ewoqKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &fakeNode{balance: balance}
+	w, err := NewWallet(context.Background(), key, node.rpc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, node
+}
+
+// baseTx is the expected BaseTx of a wallet transaction spending the
+// fake UTXO and returning change.
+func baseTx(typeID string, balance, change uint64) string {
+	return strings.Join([]string{
+		"0000",     // codec version
+		typeID,     // tx type
+		"00003039", // network ID 12345
+		strings.Repeat("00", 32),
+		// outputs: change to the wallet
+		"00000001",
+		hex.EncodeToString(testAsset[:]),
+		"00000007",
+		fmt.Sprintf("%016x", change),
+		"0000000000000000",
+		"00000001",
+		"00000001", ewoqShortID,
+		// inputs: the fake UTXO, signed by address index 0
+		"00000001",
+		hex.EncodeToString(testUTXOTx[:]),
+		"00000000",
+		hex.EncodeToString(testAsset[:]),
+		"00000005", // secp256k1 transfer input
+		fmt.Sprintf("%016x", balance),
+		"00000001", "00000000",
+		"00000000", // memo
+	}, "")
+}
+
+// checkIssued compares the issued tx's unsigned bytes with want, verifies
+// each credential's signature, and checks the returned tx ID.
+func checkIssued(t *testing.T, node *fakeNode, key *PrivateKey, txID ID, want []byte, creds int) {
+	t.Helper()
+	signed := node.issued
+	if !bytes.HasPrefix(signed, want) {
+		t.Fatalf("unsigned tx mismatch\n got %x\nwant %x", signed[:min(len(signed), len(want))], want)
+	}
+	rest := signed[len(want):]
+	if len(rest) != 4+creds*(4+4+65) {
+		t.Fatalf("credentials are %d bytes, want %d credentials", len(rest), creds)
+	}
+	u := unpacker{b: rest}
+	if n := u.u32(); n != uint32(creds) {
+		t.Fatalf("%d credentials, want %d", n, creds)
+	}
+	hash := sha256.Sum256(want)
+	for range creds {
+		if typ, sigs := u.u32(), u.u32(); typ != typeCredential || sigs != 1 {
+			t.Fatalf("credential type %d with %d signatures", typ, sigs)
+		}
+		var sig [65]byte
+		copy(sig[:], u.take(65))
+		assertRecovers(t, key, hash[:], sig)
+	}
+
+	if wantID := ID(sha256.Sum256(signed)); txID != wantID {
+		t.Errorf("tx ID = %s, want %s", txID, wantID)
+	}
+	if node.polled != txID.String() {
+		t.Errorf("polled status of %s, want %s", node.polled, txID)
+	}
+}
+
+func TestCreateSubnetTx(t *testing.T) {
+	w, node := newTestWallet(t, 5_000_000)
+	if got := w.Address(); got != ewoqAddress {
+		t.Errorf("wallet address = %s, want %s", got, ewoqAddress)
+	}
+	txID, err := w.CreateSubnet(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHex(
+		baseTx("00000010", 5_000_000, 4_000_000),
+		"0000000b",         // owners
+		"0000000000000000", // locktime
+		"00000001",         // threshold
+		"00000001", ewoqShortID,
+	)
+	checkIssued(t, node, w.key, txID, want, 1)
+}
+
+func TestCreateChainTx(t *testing.T) {
+	w, node := newTestWallet(t, 5_000_000)
+	genesis := []byte(`{"config":{}}`)
+	txID, err := w.CreateChain(context.Background(), testSubnet, "demo", testChain, genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustHex(
+		baseTx("0000000f", 5_000_000, 3_000_000),
+		hex.EncodeToString(testSubnet[:]),
+		"0004", hex.EncodeToString([]byte("demo")),
+		hex.EncodeToString(testChain[:]), // VM ID
+		"00000000",                       // fx IDs
+		fmt.Sprintf("%08x", len(genesis)), hex.EncodeToString(genesis),
+		"0000000a", "00000001", "00000000", // subnet auth: signature index 0
+	)
+	checkIssued(t, node, w.key, txID, want, 2)
+}
+
+func TestConvertSubnetToL1Tx(t *testing.T) {
+	w, node := newTestWallet(t, 5_000_000)
+	// Given out of node ID order; the tx sorts them.
+	vals := []L1Validator{
+		{NodeID: [20]byte{0x02}, Weight: 20, Balance: 100},
+		{NodeID: [20]byte{0x01}, Weight: 10, Balance: 200},
+	}
+	for i := range vals {
+		for j := range vals[i].PublicKey {
+			vals[i].PublicKey[j] = byte(0xa0 + i)
+		}
+		for j := range vals[i].ProofOfPossession {
+			vals[i].ProofOfPossession[j] = byte(0xb0 + i)
+		}
+	}
+	manager := []byte{0xfe, 0xed}
+	// Charge compute only: 2 signatures * 200 + 1000 + 2 validators * 1100
+	// gas, doubled against price rises.
+	node.weights = []uint64{0, 0, 0, 1}
+	const fee = 2 * 3600
+	txID, validationIDs, err := w.ConvertSubnetToL1(context.Background(), testSubnet, testChain, manager, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := func(v L1Validator) string {
+		return strings.Join([]string{
+			"00000014", hex.EncodeToString(v.NodeID[:]),
+			fmt.Sprintf("%016x", v.Weight),
+			fmt.Sprintf("%016x", v.Balance),
+			hex.EncodeToString(v.PublicKey[:]),
+			hex.EncodeToString(v.ProofOfPossession[:]),
+			"00000001", "00000001", ewoqShortID, // remaining balance owner
+			"00000001", "00000001", ewoqShortID, // deactivation owner
+		}, "")
+	}
+	want := mustHex(
+		baseTx("00000023", 5_000_000, 5_000_000-300-fee),
+		hex.EncodeToString(testSubnet[:]),
+		hex.EncodeToString(testChain[:]),
+		"00000002", "feed", // manager address
+		"00000002", validator(vals[1]), validator(vals[0]),
+		"0000000a", "00000001", "00000000",
+	)
+	checkIssued(t, node, w.key, txID, want, 2)
+
+	// Validation IDs follow the sorted order but are returned as given.
+	if validationIDs[1] != ValidationID(testSubnet, 0) || validationIDs[0] != ValidationID(testSubnet, 1) {
+		t.Errorf("validation IDs out of order: %v", validationIDs)
+	}
+	want0 := sha256.Sum256(append(append([]byte{}, testSubnet[:]...), 0, 0, 0, 1))
+	if ValidationID(testSubnet, 1) != want0 {
+		t.Error("ValidationID is not SHA-256(subnetID || index)")
+	}
+}
+
+func TestAddPrimaryValidatorTx(t *testing.T) {
+	w, node := newTestWallet(t, 3_000_000_000_000)
+	end := time.Unix(1_800_000_000, 0)
+	v := PrimaryValidator{
+		NodeID:        [20]byte{0x07},
+		End:           end,
+		Stake:         2_000_000_000_000,
+		RewardAddress: ewoqAddress,
+		DelegationFee: 2,
+	}
+	txID, err := w.AddPrimaryValidator(context.Background(), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owners := strings.Join([]string{"0000000b", "0000000000000000", "00000001", "00000001", ewoqShortID}, "")
+	head := mustHex(
+		baseTx("00000019", 3_000_000_000_000, 1_000_000_000_000),
+		hex.EncodeToString(v.NodeID[:]),
+	)
+	tail := mustHex(
+		fmt.Sprintf("%016x", end.Unix()),
+		fmt.Sprintf("%016x", v.Stake), // weight
+		strings.Repeat("00", 32),      // Primary Network
+		"0000001c", strings.Repeat("00", 48), strings.Repeat("00", 96),
+		// stake outputs
+		"00000001", hex.EncodeToString(testAsset[:]), "00000007",
+		fmt.Sprintf("%016x", v.Stake), "0000000000000000", "00000001", "00000001", ewoqShortID,
+		owners, owners, // validation and delegation rewards
+		"00004e20", // 2% of 1,000,000
+	)
+	// The start time (ignored since Durango) is the wall clock; compare
+	// around it.
+	signed := node.issued
+	if len(signed) < len(head)+8+len(tail) {
+		t.Fatalf("tx too short: %x", signed)
+	}
+	start := int64(binaryUint64(signed[len(head):]))
+	if d := time.Since(time.Unix(start, 0)); d < 0 || d > time.Minute {
+		t.Errorf("start time %d is not now", start)
+	}
+	want := append(append(append([]byte{}, head...), signed[len(head):len(head)+8]...), tail...)
+	checkIssued(t, node, w.key, txID, want, 1)
+}
+
+func binaryUint64(b []byte) uint64 {
+	u := unpacker{b: b}
+	return u.u64()
+}
@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// prefix marks an encrypted value: enc:v2:<key id>:<base64 nonce+ciphertext>.
+// Values are bound to the row and column they are stored in through the
+// GCM additional data.
+const prefix = "enc:v2:"
+
+// scrypt cost parameters, the recommended interactive settings: about 64 MiB
+// and a few hundred milliseconds per key, paid once at startup.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	// SaltSize is the length of the installation's key derivation salt.
+	SaltSize = 16
+)
+
+// Box encrypts secrets for storage in Postgres with AES-256-GCM. It seals
+// with the current master key and opens values sealed with either the
+// current or the previous key, which allows key rotation.
+type Box struct {
+	current  *key
+	previous *key
+}
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// New builds a Box from master key strings of any length, stretched with
+// scrypt under salt (stored once per installation, see SaltSize). An empty
+// current key disables encryption: Seal then returns values unchanged.
+func New(current, previous string, salt []byte) (*Box, error) {
+	if len(salt) < SaltSize {
+		return nil, fmt.Errorf("key derivation salt must be at least %d bytes", SaltSize)
+	}
+	b := &Box{}
+	for _, c := range []struct {
+		master string
+		dst    **key
+	}{{current, &b.current}, {previous, &b.previous}} {
+		if c.master == "" {
+			continue
+		}
+		derived, err := scrypt.Key([]byte(c.master), salt, scryptN, scryptR, scryptP, 32)
+		if err != nil {
+			return nil, err
+		}
+		if *c.dst, err = newKey(derived); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func newKey(derived []byte) (*key, error) {
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// The key ID is a hash of the derived key, never the key itself.
+	id := sha256.Sum256(derived)
+	return &key{id: hex.EncodeToString(id[:4]), aead: aead}, nil
+}
+
+// NewSalt returns a random key derivation salt.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// Enabled reports whether a master key is configured.
+func (b *Box) Enabled() bool {
+	return b != nil && b.current != nil
+}
+
+// IsSealed reports whether s is an encrypted value.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// Seal encrypts plain with the current key, bound to aad: the same aad must
+// be passed to Open, so a value copied to another row or column no longer
+// decrypts. Empty values stay empty.
+func (b *Box) Seal(plain, aad string) (string, error) {
+	if !b.Enabled() || plain == "" {
+		return plain, nil
+	}
+	nonce := make([]byte, b.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.current.aead.Seal(nonce, nonce, []byte(plain), []byte(aad))
+	return prefix + b.current.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed for aad. Unsealed values are returned
+// unchanged so rows written before encryption was enabled keep working.
+func (b *Box) Open(s, aad string) (string, error) {
+	rest, ok := strings.CutPrefix(s, prefix)
+	if !ok {
+		return s, nil
+	}
+	var candidates []*key
+	if b != nil {
+		candidates = []*key{b.current, b.previous}
+	}
+	id, payload, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("malformed sealed value")
+	}
+	var k *key
+	for _, c := range candidates {
+		if c != nil && c.id == id {
+			k = c
+			break
+		}
+	}
+	if k == nil {
+		return "", fmt.Errorf("value sealed with unknown key %s", id)
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("decode sealed value: %w", err)
+	}
+	n := k.aead.NonceSize()
+	if len(raw) < n {
+		return "", errors.New("sealed value too short")
+	}
+	plain, err := k.aead.Open(nil, raw[:n], raw[n:], []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+// NeedsReseal reports whether s should be rewritten under the current key:
+// it is plaintext or sealed with a different key.
+func (b *Box) NeedsReseal(s string) bool {
+	if !b.Enabled() || s == "" {
+		return false
+	}
+	return !strings.HasPrefix(s, prefix+b.current.id+":")
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testSalt = bytes.Repeat([]byte{0x5a}, SaltSize)
+
+func TestSealBindsAAD(t *testing.T) {
+	b, err := New("correct horse", "", testSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := b.Seal("staker key", "nodes/1/staking_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, prefix) || b.NeedsReseal(sealed) {
+		t.Fatalf("sealed = %q", sealed)
+	}
+	if got, err := b.Open(sealed, "nodes/1/staking_key"); err != nil || got != "staker key" {
+		t.Fatalf("Open = %q, %v", got, err)
+	}
+	for _, aad := range []string{"nodes/2/staking_key", "nodes/1/staking_signer", ""} {
+		if _, err := b.Open(sealed, aad); err == nil {
+			t.Errorf("opened with aad %q", aad)
+		}
+	}
+
+	// Another salt derives another key.
+	other, _ := New("correct horse", "", bytes.Repeat([]byte{0xa5}, SaltSize))
+	if _, err := other.Open(sealed, "nodes/1/staking_key"); err == nil {
+		t.Error("opened under a different salt")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old, _ := New("old", "", testSalt)
+	sealed, _ := old.Seal("v", "secrets/a/value")
+
+	b, err := New("new", "old", testSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.NeedsReseal(sealed) {
+		t.Error("value under the previous key does not need a reseal")
+	}
+	if got, err := b.Open(sealed, "secrets/a/value"); err != nil || got != "v" {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+}
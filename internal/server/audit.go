@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/primal-host/avalauncher/internal/manager"
+)
+
+// maxAuditBody is how much of a request body is read for the audit summary.
+const maxAuditBody = 64 << 10
+
+// sensitiveField reports whether a payload field may carry a secret.
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	if name == "value" || name == "env" {
+		return true
+	}
+	for _, s := range []string{"key", "secret", "password", "token", "auth"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces sensitive values in a decoded JSON payload and shortens
+// long strings.
+func redact(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if sensitiveField(k) {
+				t[k] = "[redacted]"
+			} else {
+				t[k] = redact(val)
+			}
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = redact(t[i])
+		}
+		return t
+	case string:
+		if len(t) > 200 {
+			return t[:200] + "…"
+		}
+	}
+	return v
+}
+
+// actor names the caller of an authenticated request: the noknok handle
+// for forward-auth users, "admin-key" for bearer token callers.
+func (s *Server) actor(c echo.Context) string {
+	if c.Request().Header.Get("X-User-Role") == "admin" {
+		if handle := c.Request().Header.Get("X-User-Handle"); handle != "" {
+			return handle
+		}
+		return "noknok"
+	}
+	return "admin-key"
+}
+
+// auditWriter keeps the start of error responses for the audit row.
+type auditWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.body.Len() < 1024 {
+		w.body.Write(b[:min(len(b), 1024-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// audit records every mutating call to the authenticated API in api_audit:
+// who made it, what it asked for (secrets redacted) and how it ended.
+func (s *Server) audit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return next(c)
+		}
+
+		var payload map[string]any
+		if req.Body != nil && req.ContentLength != 0 {
+			body, _ := io.ReadAll(io.LimitReader(req.Body, maxAuditBody+1))
+			req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+			if len(body) > maxAuditBody {
+				payload = map[string]any{"truncated_bytes": len(body)}
+			} else if err := json.Unmarshal(body, &payload); err != nil && len(body) > 0 {
+				payload = map[string]any{"bytes": len(body)}
+			}
+			if payload != nil {
+				payload = redact(payload).(map[string]any)
+			}
+		}
+		if len(c.QueryParams()) > 0 {
+			if payload == nil {
+				payload = map[string]any{}
+			}
+			payload["query"] = c.QueryString()
+		}
+
+		w := &auditWriter{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = w
+		start := time.Now()
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		entry := manager.AuditEntry{
+			Actor:      s.actor(c),
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Payload:    payload,
+			Status:     c.Response().Status,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if entry.Status >= 400 {
+			var resp struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(w.body.Bytes(), &resp) == nil && resp.Error != "" {
+				entry.Error = resp.Error
+			} else {
+				entry.Error = strings.TrimSpace(w.body.String())
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), 5*time.Second)
+		defer cancel()
+		s.mgr.RecordAudit(ctx, entry)
+		return nil
+	}
+}
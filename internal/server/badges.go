@@ -0,0 +1,113 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/primal-host/avalauncher/internal/manager"
+)
+
+// Badge colors (shields.io palette).
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+	badgeGrey   = "#9f9f9f"
+	badgeBlue   = "#007ec6"
+)
+
+// badgeID parses a badge path parameter like "12.svg" into 12.
+func badgeID(c echo.Context) (int64, error) {
+	return strconv.ParseInt(strings.TrimSuffix(c.Param("id"), ".svg"), 10, 64)
+}
+
+func (s *Server) handleL1Badge(c echo.Context) error {
+	id, err := badgeID(c)
+	if err != nil {
+		return s.renderBadge(c, "l1", "invalid", badgeGrey)
+	}
+	l1, err := s.mgr.L1Status(c.Request().Context(), id)
+	if errors.Is(err, manager.ErrL1NotFound) {
+		return s.renderBadge(c, "l1", "not found", badgeGrey)
+	}
+	if err != nil {
+		return s.renderBadge(c, "l1", "unavailable", badgeGrey)
+	}
+
+	if l1.SubnetID == "" {
+		return s.renderBadge(c, l1.Name, "pending", badgeGrey)
+	}
+	switch l1.Verdict {
+	case manager.L1Healthy:
+		return s.renderBadge(c, l1.Name, "healthy", badgeGreen)
+	case manager.L1Degraded:
+		return s.renderBadge(c, l1.Name, fmt.Sprintf("degraded %d/%d", l1.Healthy, l1.Total), badgeYellow)
+	case manager.L1Down:
+		return s.renderBadge(c, l1.Name, "down", badgeRed)
+	default:
+		return s.renderBadge(c, l1.Name, "no validators", badgeGrey)
+	}
+}
+
+func (s *Server) handleNodeBadge(c echo.Context) error {
+	id, err := badgeID(c)
+	if err != nil {
+		return s.renderBadge(c, "node", "invalid", badgeGrey)
+	}
+	node, err := s.mgr.GetNode(c.Request().Context(), id)
+	if errors.Is(err, manager.ErrNodeNotFound) {
+		return s.renderBadge(c, "node", "not found", badgeGrey)
+	}
+	if err != nil {
+		return s.renderBadge(c, "node", "unavailable", badgeGrey)
+	}
+	return s.renderBadge(c, node.Name, node.Status, nodeStatusColor(node.Status))
+}
+
+// nodeStatusColor maps a node status to a badge color.
+func nodeStatusColor(status string) string {
+	switch status {
+	case "running":
+		return badgeGreen
+	case "creating", "bootstrapping":
+		return badgeBlue
+	case "unhealthy":
+		return badgeYellow
+	case "failed":
+		return badgeRed
+	default:
+		return badgeGrey
+	}
+}
+
+// renderBadge writes a flat shields-style SVG badge. Badges are never cached
+// so embedded images reflect live status.
+func (s *Server) renderBadge(c echo.Context, label, message, color string) error {
+	// Approximate Verdana 11px glyph width; good enough for short labels.
+	labelW := 6*len(label) + 10
+	msgW := 6*len(message) + 10
+	total := labelW + msgW
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+
+		`<title>%s: %s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%d" y="14">%s</text><text x="%d" y="14">%s</text></g></svg>`,
+		total, label, message,
+		label, message,
+		total,
+		labelW, labelW, msgW, color, total,
+		labelW/2, label, labelW+msgW/2, message)
+
+	c.Response().Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	return c.Blob(http.StatusOK, "image/svg+xml", []byte(svg))
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handleMetrics serves poller and remote host link statistics in the
+// Prometheus text format.
+func (s *Server) handleMetrics(c echo.Context) error {
+	pollers := s.mgr.Pollers()
+
+	var b strings.Builder
+	metric := func(name, typ, help string, value func(i int) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for i, p := range pollers {
+			fmt.Fprintf(&b, "%s{poller=%q} %g\n", name, p.Name, value(i))
+		}
+	}
+	metric("avalauncher_poller_runs_total", "counter", "Completed poller runs.",
+		func(i int) float64 { return float64(pollers[i].Runs) })
+	metric("avalauncher_poller_last_run_timestamp_seconds", "gauge", "Start of the last run (0 = never).",
+		func(i int) float64 {
+			if pollers[i].LastRun == nil {
+				return 0
+			}
+			return float64(pollers[i].LastRun.Unix())
+		})
+	metric("avalauncher_poller_last_duration_seconds", "gauge", "Duration of the last run.",
+		func(i int) float64 { return pollers[i].LastDuration })
+	metric("avalauncher_poller_checked", "gauge", "Items checked in the last run.",
+		func(i int) float64 { return float64(pollers[i].Checked) })
+	metric("avalauncher_poller_failures", "gauge", "Failed checks in the last run.",
+		func(i int) float64 { return float64(pollers[i].Failures) })
+	metric("avalauncher_poller_failures_total", "counter", "Failed checks since startup.",
+		func(i int) float64 { return float64(pollers[i].TotalFailures) })
+	metric("avalauncher_poller_paused", "gauge", "1 if the poller is paused.",
+		func(i int) float64 {
+			if pollers[i].Paused {
+				return 1
+			}
+			return 0
+		})
+
+	conns, err := s.mgr.HostConnections(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	hostMetric := func(name, typ, help string, value func(i int) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for i, h := range conns {
+			fmt.Fprintf(&b, "%s{host=%q} %g\n", name, h.Name, value(i))
+		}
+	}
+	hostMetric("avalauncher_host_docker_requests_total", "counter", "Docker API requests to the host over SSH.",
+		func(i int) float64 { return float64(conns[i].Requests) })
+	hostMetric("avalauncher_host_docker_errors_total", "counter", "SSH/Docker connection failures (transport errors and failed connects).",
+		func(i int) float64 { return float64(conns[i].Errors) })
+	hostMetric("avalauncher_host_reconnects_total", "counter", "Reconnects after the host became unreachable.",
+		func(i int) float64 { return float64(conns[i].Reconnects) })
+	hostMetric("avalauncher_host_last_error_timestamp_seconds", "gauge", "Time of the last connection failure (0 = never).",
+		func(i int) float64 {
+			if conns[i].LastErrorAt == nil {
+				return 0
+			}
+			return float64(conns[i].LastErrorAt.Unix())
+		})
+	hostMetric("avalauncher_host_connected", "gauge", "1 if the manager holds a Docker client for the host.",
+		func(i int) float64 {
+			if conns[i].Connected {
+				return 1
+			}
+			return 0
+		})
+
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
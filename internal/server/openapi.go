@@ -0,0 +1,455 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/primal-host/avalauncher/internal/config"
+	"github.com/primal-host/avalauncher/internal/docker"
+	"github.com/primal-host/avalauncher/internal/logging"
+	"github.com/primal-host/avalauncher/internal/manager"
+)
+
+// apiParam is a documented query parameter.
+type apiParam struct {
+	name string
+	typ  string // string, integer, boolean
+	desc string
+}
+
+// apiOp documents one route. body and resp are zero values of the Go types
+// the handler binds and returns; their JSON schemas are derived by reflection.
+type apiOp struct {
+	summary string
+	public  bool
+	query   []apiParam
+	body    any
+	status  int    // success status, default 200
+	also    int    // second success status (e.g. 202 when queued)
+	resp    any    // nil with an empty mime means no documented body
+	mime    string // non-JSON response type
+}
+
+// Inline response shapes the handlers build as maps.
+type (
+	statusResponse struct {
+		Status string `json:"status"`
+	}
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+	healthResponse struct {
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	}
+	dependencyConflict struct {
+		Error        string                   `json:"error"`
+		Dependencies manager.NodeDependencies `json:"dependencies"`
+	}
+	identityList struct {
+		Policy     string                 `json:"policy"`
+		Identities []manager.NodeIdentity `json:"identities"`
+	}
+)
+
+var waitParams = []apiParam{
+	{"for", "string", "Condition to wait for"},
+	{"timeout", "string", "Go duration, default 300s, max 30m"},
+}
+
+// apiDocs describes every route registered in routes(), keyed by
+// "METHOD path". Routes missing here still appear in the document.
+var apiDocs = map[string]apiOp{
+	"GET /health":                      {summary: "Health check", public: true, resp: healthResponse{}},
+	"GET /":                            {summary: "Dashboard", public: true, mime: "text/html"},
+	"GET /static/*":                    {summary: "Dashboard assets (cacheable when ?v= matches the build's asset version)", public: true, mime: "application/octet-stream"},
+	"GET /api/status":                  {summary: "Card counts and node summaries (full details when authenticated)", public: true, resp: map[string]any{}},
+	"GET /api/ui-config":               {summary: "Dashboard settings: poll interval, event stream use, enabled sections", public: true, resp: UIConfig{}},
+	"GET /api/openapi.json":            {summary: "This OpenAPI document", public: true, resp: map[string]any{}},
+	"GET /api/badges/l1/:id":           {summary: "L1 health status badge (id may end in .svg); rate limited per client IP", public: true, mime: "image/svg+xml"},
+	"GET /api/badges/node/:id":         {summary: "Node status badge (id may end in .svg); rate limited per client IP", public: true, mime: "image/svg+xml"},
+	"GET /metrics":                     {summary: "Poller and host link statistics in Prometheus text format", mime: "text/plain"},
+	"GET /api/audit":                   {summary: "API call audit, newest first", resp: []manager.AuditEntry{}, query: []apiParam{{"actor", "string", ""}, {"method", "string", ""}, {"path", "string", "Path prefix"}, {"failed", "boolean", "Only calls answered with status >= 400"}, {"since", "string", "RFC 3339"}, {"until", "string", "RFC 3339"}, {"limit", "integer", "Default 100, max 1000"}}},
+	"GET /api/summary":                 {summary: "Compact fleet rollup", resp: manager.FleetSummary{}},
+	"POST /api/nodes":                  {summary: "Create and start a node", body: manager.CreateNodeRequest{}, status: http.StatusCreated, resp: manager.Node{}},
+	"GET /api/nodes":                   {summary: "List nodes by ID; X-Total-Count holds the number matching", resp: []manager.Node{}, query: []apiParam{{"status", "string", ""}, {"host_id", "integer", ""}, {"network", "string", ""}, {"tag", "string", "Only nodes with this tag; repeat to require several"}, {"limit", "integer", "Default all"}, {"offset", "integer", ""}}},
+	"GET /api/node-profiles":           {summary: "Node profiles selectable as config.profile", resp: []docker.NodeProfile{}},
+	"GET /api/nodes/identities":        {summary: "NodeIDs of all nodes with their holders, duplicates first", resp: identityList{}, query: []apiParam{{"duplicates", "boolean", "Only NodeIDs held by more than one node"}}},
+	"GET /api/nodes/:id":               {summary: "Get node details", resp: manager.Node{}},
+	"POST /api/nodes/:id/start":        {summary: "Start a stopped node (409 when its NodeID is already running and DUPLICATE_NODE_ID=reject)", resp: statusResponse{}, query: []apiParam{{"queue", "boolean", "If the host is offline, queue the start and return the queued operation (202)"}}},
+	"POST /api/nodes/:id/stop":         {summary: "Stop a running node", resp: statusResponse{}, query: []apiParam{{"queue", "boolean", "If the host is offline, queue the stop and return the queued operation (202)"}}},
+	"DELETE /api/nodes/:id":            {summary: "Remove a node; 409 with the dependency report unless acknowledged", resp: statusResponse{}, query: []apiParam{{"remove_volumes", "boolean", ""}, {"ack", "string", "Comma-separated dependency kinds"}, {"force", "boolean", "Ignore non-blocking dependencies"}, {"validators", "string", "block, cascade or reassign"}, {"reassign_to", "integer", "Node id for validators=reassign"}}},
+	"GET /api/nodes/:id/dependencies":  {summary: "Dry run of a node delete", resp: manager.NodeDependencies{}, query: []apiParam{{"remove_volumes", "boolean", ""}}},
+	"GET /api/nodes/:id/logs":          {summary: "Container logs", mime: "text/plain", query: []apiParam{{"tail", "string", "Lines, default 50"}, {"follow", "boolean", "Stream new lines"}}},
+	"POST /api/nodes/:id/repull":       {summary: "Pull the node's image tag again and recreate the node if it moved to a new digest", status: http.StatusAccepted, resp: manager.Node{}},
+	"POST /api/nodes/:id/retry":        {summary: "Run a failed or stuck node's provisioning (or container recreation) again", status: http.StatusAccepted, resp: manager.Node{}},
+	"POST /api/nodes/:id/identify":     {summary: "Query the node's info API for its NodeID now and store it", resp: manager.Node{}},
+	"POST /api/nodes/:id/exec":         {summary: "Run a command in the node container (allowlisted unless EXEC_ALLOW_ANY); streams output, exit code in the X-Exit-Code trailer; 403 for disallowed commands", mime: "text/plain", body: manager.ExecRequest{}},
+	"GET /api/nodes/:id/inspect":       {summary: "Raw docker inspect JSON, secrets redacted", resp: map[string]any{}},
+	"GET /api/nodes/:id/latency":       {summary: "RPC latency p50/p95", resp: manager.NodeLatency{}, query: []apiParam{{"window", "string", "Go duration, default 1h"}, {"bucket", "string", "Go duration, default 5m"}}},
+	"GET /api/nodes/:id/health":        {summary: "Latest health probe with every AvalancheGo check, failing first", resp: manager.NodeHealth{}, query: []apiParam{{"refresh", "boolean", "Probe now"}}},
+	"GET /api/nodes/:id/at":            {summary: "The node's recorded status, image and health at a past time, with nearby metrics and events", resp: manager.NodeAt{}, query: []apiParam{{"time", "string", "RFC 3339 timestamp (required)"}}},
+	"GET /api/nodes/:id/events":        {summary: "The node's event timeline, newest first; X-Total-Count holds the number matching", resp: []manager.Event{}, query: []apiParam{{"limit", "integer", "Default 50, max 1000"}, {"offset", "integer", ""}, {"severity", "string", "Only events at or above info, warning, error or critical"}, {"type", "string", "Exact type, or a prefix ending in * (node.*)"}, {"since", "string", "RFC 3339"}, {"until", "string", "RFC 3339"}}},
+	"GET /api/nodes/:id/uptime":        {summary: "Validator uptime history against the 80% reward requirement", resp: manager.NodeUptime{}, query: []apiParam{{"window", "string", "Go duration, default 336h"}}},
+	"GET /api/nodes/:id/usage":         {summary: "Volume sizes in bytes", resp: manager.NodeDiskUsage{}, query: []apiParam{{"refresh", "boolean", "Measure now"}}},
+	"POST /api/nodes/:id/check-port":   {summary: "Staking-port reachability test", body: manager.PortCheckRequest{}, resp: manager.PortCheckResult{}},
+	"POST /api/nodes/:id/validator":    {summary: "Stake the node as a Primary Network validator; returns the register_validator operation", body: manager.RegisterValidatorRequest{}, status: http.StatusAccepted, resp: manager.Operation{}},
+	"POST /api/nodes/:id/staking-port": {summary: "Move a node to a new staking port, keeping its identity; returns the staking_port operation", body: manager.StakingPortRequest{}, status: http.StatusAccepted, resp: manager.Operation{}},
+	"PUT /api/nodes/:id/aliases": {summary: "Replace a node's DNS aliases", body: struct {
+		DNSAliases []string `json:"dns_aliases"`
+	}{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/tags": {summary: "Replace a node's tags", body: struct {
+		Tags []string `json:"tags"`
+	}{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/throttle": {summary: "Replace a node's disk/bandwidth throttle", body: docker.Throttle{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/restart-policy": {summary: "Set a node's Docker restart policy and auto-heal", body: struct {
+		RestartPolicy string `json:"restart_policy"`
+		AutoHeal      bool   `json:"auto_heal"`
+	}{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/env": {summary: "Replace a node's extra env vars", body: struct {
+		Env map[string]string `json:"env"`
+	}{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/config": {summary: "Replace a node's AvalancheGo flags and chain configs", body: docker.NodeConfig{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/health-check": {summary: "Set the health probe method", body: struct {
+		HealthCheck string `json:"health_check"`
+	}{}, resp: manager.Node{}},
+	"PUT /api/nodes/:id/ttl": {summary: "Set node expiry", body: struct {
+		TTL string `json:"ttl"`
+	}{}, resp: manager.Node{}},
+	"GET /api/nodes/:id/wait":            {summary: "Block until running, healthy, bootstrapped or stopped", resp: manager.Node{}, query: waitParams},
+	"POST /api/nodes/:id/snapshot":       {summary: "Archive the node's database volume to the snapshot target, stopping it for the copy", status: http.StatusAccepted, resp: manager.Snapshot{}},
+	"POST /api/nodes/:id/restore":        {summary: "Replace the node's database with a snapshot of a node on the same network", body: manager.RestoreRequest{}, status: http.StatusAccepted, resp: manager.Snapshot{}},
+	"GET /api/snapshots":                 {summary: "Node database snapshots, newest first", resp: []manager.Snapshot{}, query: []apiParam{{"node_id", "integer", "Only this node's snapshots"}}},
+	"GET /api/snapshots/:id":             {summary: "One backup", resp: manager.Snapshot{}},
+	"DELETE /api/snapshots/:id":          {summary: "Delete a backup and its object", resp: statusResponse{}},
+	"POST /api/nodes/:id/staking/export": {summary: "Export the node's staking keys (sealed as stored) to the backup target", status: http.StatusCreated, resp: manager.Snapshot{}},
+	"GET /api/backups":                   {summary: "Backups, newest first: db snapshots and staking key exports", resp: []manager.Snapshot{}, query: []apiParam{{"node_id", "integer", "Only this node's backups"}, {"kind", "string", "db or staking_keys"}}},
+	"GET /api/events":                    {summary: "Audit event log, newest first; X-Total-Count holds the number matching", resp: []manager.Event{}, query: []apiParam{{"limit", "integer", "Default 50, max 1000"}, {"offset", "integer", ""}, {"severity", "string", "Only events at or above info, warning, error or critical"}, {"event_type", "string", "Exact type, or a prefix ending in * (node.*)"}, {"type", "string", "Alias of event_type"}, {"target", "string", "Node, host or L1 name"}, {"since", "string", "RFC 3339"}, {"until", "string", "RFC 3339"}}},
+	"POST /api/events":                   {summary: "Post an operator event (stored as custom.<type>)", body: manager.CustomEventRequest{}, status: http.StatusCreated, resp: manager.Event{}},
+	"GET /api/events/stream":             {summary: "Server-Sent Events of logged events and operation progress", mime: "text/event-stream", query: []apiParam{{"severity", "string", "Only events at or above info, warning, error or critical"}}},
+	"GET /api/operations":                {summary: "Operation journal, newest first", resp: []manager.Operation{}, query: []apiParam{{"state", "string", ""}, {"limit", "integer", "Default 50"}}},
+	"GET /api/operations/:id":            {summary: "One operation", resp: manager.Operation{}},
+	"DELETE /api/operations/:id":         {summary: "Cancel a queued operation, or one waiting to retry (409 otherwise)", resp: manager.Operation{}},
+	"GET /api/log-level":                 {summary: "Current log level", resp: logging.Status{}},
+	"PUT /api/log-level": {summary: "Change log level", body: struct {
+		Level    string `json:"level"`
+		Duration string `json:"duration"`
+	}{}, resp: logging.Status{}},
+	"GET /api/admin/pollers":               {summary: "Poller stats", resp: []manager.PollerStats{}},
+	"POST /api/admin/pollers/:name/pause":  {summary: "Pause a poller", resp: manager.PollerStats{}},
+	"POST /api/admin/pollers/:name/resume": {summary: "Resume a poller", resp: manager.PollerStats{}},
+	"GET /api/admin/reconcile":             {summary: "Per-host results of startup reconciliation", resp: []manager.ReconcileResult{}},
+	"GET /api/secrets":                     {summary: "Managed secret names", resp: []manager.Secret{}},
+	"PUT /api/secrets/:name": {summary: "Create or replace a managed secret", body: struct {
+		Value string `json:"value"`
+	}{}, resp: manager.Secret{}},
+	"DELETE /api/secrets/:name":   {summary: "Delete a managed secret", resp: statusResponse{}},
+	"GET /api/hosts":              {summary: "List all hosts", resp: []manager.Host{}},
+	"POST /api/hosts/import":      {summary: "Add the hosts of an Ansible inventory or SSH config, several at a time", body: manager.ImportHostsRequest{}, resp: manager.HostImportReport{}},
+	"POST /api/hosts":             {summary: "Add a remote host", body: manager.AddHostRequest{}, status: http.StatusCreated, resp: manager.Host{}},
+	"GET /api/hosts/connections":  {summary: "SSH/Docker link stats per remote host", resp: []manager.HostConnection{}},
+	"GET /api/hosts/:id/overview": {summary: "Host info, usage, nodes, events and alerts", resp: manager.HostOverview{}},
+	"PUT /api/hosts/:id/cost": {summary: "Set a host's monthly cost", body: struct {
+		CostPerMonth float64 `json:"cost_per_month"`
+	}{}, resp: manager.Host{}},
+	"PUT /api/hosts/:id/address": {summary: "Set the address used to reach node HTTP APIs", body: struct {
+		Address string `json:"address"`
+	}{}, resp: manager.Host{}},
+	"POST /api/hosts/:id/drain":    {summary: "Put a host in maintenance", body: manager.DrainRequest{}, resp: manager.DrainResult{}},
+	"POST /api/hosts/:id/resume":   {summary: "Take a host out of maintenance", resp: manager.Host{}},
+	"GET /api/hosts/:id/proxy":     {summary: "The host's Traefik edge proxy, if deployed", resp: manager.EdgeProxyStatus{}},
+	"PUT /api/hosts/:id/proxy":     {summary: "Deploy or redeploy a Traefik edge proxy that gets certificates via ACME DNS-01", resp: manager.EdgeProxyStatus{}},
+	"DELETE /api/hosts/:id/proxy":  {summary: "Remove the host's edge proxy, keeping its certificates", resp: statusResponse{}},
+	"GET /api/hosts/:id/unmanaged": {summary: "AvalancheGo containers started outside avalauncher", resp: []docker.UnmanagedContainer{}},
+	"POST /api/hosts/:id/unmanaged/:container/adopt": {summary: "Register an unmanaged container as a node", body: struct {
+		Name string `json:"name"`
+	}{}, status: http.StatusCreated, resp: manager.Node{}},
+	"GET /api/images":                {summary: "Images on each online host (or host_id), newest first, flagged avalanchego/default", resp: []manager.HostImages{}, query: []apiParam{{"host_id", "integer", ""}, {"avalanchego", "boolean", "Only AvalancheGo images"}}},
+	"POST /api/images/pull":          {summary: "Pre-pull an image on the given (or every online) host, 4 at a time", body: manager.PullImageRequest{}, resp: manager.HostImageReport{}},
+	"GET /api/images/drift":          {summary: "Running nodes' image digests compared with what their tags resolve to now", resp: []manager.ImageDrift{}, query: []apiParam{{"drifted", "boolean", "Only nodes whose tag has moved"}}},
+	"POST /api/images/prune":         {summary: "Remove AvalancheGo images no container uses, keeping default images and pending upgrade targets", body: manager.PruneImagesRequest{}, resp: manager.HostImageReport{}},
+	"POST /api/upgrades":             {summary: "Start a rolling image upgrade, optionally with a canary phase and a start_at/deadline window", body: manager.UpgradeRequest{}, status: http.StatusAccepted, resp: manager.Upgrade{}},
+	"GET /api/upgrades":              {summary: "Upgrades, newest first, with progress", resp: []manager.Upgrade{}},
+	"GET /api/upgrades/:id":          {summary: "One upgrade with per-node progress", resp: manager.Upgrade{}},
+	"POST /api/upgrades/:id/proceed": {summary: "Confirm after the canary soak, or retry a paused upgrade", status: http.StatusAccepted, resp: manager.Upgrade{}},
+	"POST /api/upgrades/:id/cancel":  {summary: "Stop an upgrade after the current node", resp: manager.Upgrade{}},
+	"GET /api/costs":                 {summary: "Monthly cost attribution", resp: manager.CostReport{}},
+	"DELETE /api/hosts/:id":          {summary: "Remove a host with no nodes", resp: statusResponse{}},
+	"POST /api/l1s":                  {summary: "Create an L1", body: manager.CreateL1Request{}, status: http.StatusCreated, resp: manager.L1{}},
+	"GET /api/l1s":                   {summary: "List L1s with validator counts; X-Total-Count holds the number matching", resp: []manager.L1WithCount{}, query: []apiParam{{"status", "string", ""}, {"vm", "string", ""}, {"limit", "integer", "Default all"}, {"offset", "integer", ""}}},
+	"GET /api/l1s/:id":               {summary: "Get an L1 with validators", resp: manager.L1Detail{}},
+	"GET /api/l1s/:id/health":        {summary: "Aggregated L1 health verdict", resp: manager.L1Health{}},
+	"GET /api/l1s/:id/overview":      {summary: "L1 with validator health, endpoints and events", resp: manager.L1Overview{}},
+	"PATCH /api/l1s/:id":             {summary: "Update L1 ownership metadata and whether it is in the public uptime feed", body: manager.UpdateL1Request{}, resp: manager.L1Detail{}},
+	"DELETE /api/l1s/:id":            {summary: "Delete an L1 with no validators", resp: statusResponse{}},
+	"PUT /api/l1s/:id/rpc":           {summary: "Publish the L1's chain RPC at <name>-rpc.<domain> via the given validator nodes (empty list withdraws it)", body: manager.ExposeL1RPCRequest{}, resp: manager.L1Detail{}},
+	"PUT /api/l1s/:id/ttl": {summary: "Set L1 expiry", body: struct {
+		TTL string `json:"ttl"`
+	}{}, resp: manager.L1Detail{}},
+	"POST /api/l1s/:id/genesis":              {summary: "Build and store a subnet-evm genesis; returns it", body: manager.GenesisRequest{}, resp: map[string]any{}},
+	"POST /api/l1s/:id/deploy":               {summary: "Create the subnet and chain on the P-Chain", body: manager.DeployL1Request{}, status: http.StatusAccepted, resp: manager.L1Detail{}},
+	"POST /api/l1s/:id/convert":              {summary: "Convert the subnet to an L1", body: manager.ConvertL1Request{}, status: http.StatusAccepted, resp: manager.L1Detail{}},
+	"POST /api/l1s/:id/logs/bundle":          {summary: "Download recent logs of all the L1's validators as one .tar.gz (manifest.json plus a directory per node)", body: manager.LogBundleRequest{}, mime: "application/gzip"},
+	"GET /api/l1s/:id/wait":                  {summary: "Block until deployed or healthy", resp: manager.L1Detail{}, query: waitParams},
+	"POST /api/l1s/:id/validators":           {summary: "Add a validator; 202 when queued", body: manager.AddValidatorRequest{}, status: http.StatusCreated, also: http.StatusAccepted, resp: manager.L1Validator{}},
+	"DELETE /api/l1s/:id/validators/:nodeId": {summary: "Remove a validator", resp: statusResponse{}},
+	"GET /api/l1s/:id/weights":               {summary: "Validator weights and stake shares as of a point in time", resp: manager.WeightDistribution{}, query: []apiParam{{"at", "string", "RFC 3339, default now"}}},
+	"GET /api/l1s/:id/weights/history":       {summary: "Append-only validator weight changes, oldest first (removals have weight 0)", resp: []manager.WeightChange{}, query: []apiParam{{"node", "integer", "Node id"}, {"since", "string", "RFC 3339"}, {"until", "string", "RFC 3339"}}},
+	"POST /api/l1s/:id/validators/rotate":    {summary: "Replace a validator; returns the rotate operation", body: manager.RotateValidatorRequest{}, status: http.StatusAccepted, resp: manager.Operation{}},
+	"GET /api/public/l1s/:id/uptime.json":    {summary: "Status and rolling uptime of a public L1 for external monitors; rate limited per client IP (429 when exceeded)", public: true, resp: manager.L1Uptime{}},
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+)
+
+func (s *Server) handleOpenAPI(c echo.Context) error {
+	openAPIOnce.Do(func() {
+		openAPIDoc, _ = json.Marshal(buildOpenAPI(s.echo.Routes()))
+	})
+	return c.JSONBlob(http.StatusOK, openAPIDoc)
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// buildOpenAPI assembles an OpenAPI 3 document for the registered routes.
+func buildOpenAPI(routes []*echo.Route) map[string]any {
+	sg := &schemaGen{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+	sg.schemas["Error"] = sg.schema(reflect.TypeOf(errorResponse{}))
+	errResp := map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}}},
+	}
+
+	paths := map[string]map[string]any{}
+	for _, r := range routes {
+		switch r.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			continue
+		}
+		doc, ok := apiDocs[r.Method+" "+r.Path]
+		if !ok {
+			doc = apiOp{summary: r.Method + " " + r.Path}
+		}
+
+		op := map[string]any{
+			"operationId": operationID(r.Name),
+			"summary":     doc.summary,
+		}
+		if tag := routeTag(r.Path); tag != "" {
+			op["tags"] = []string{tag}
+		}
+		if doc.public {
+			op["security"] = []any{}
+		}
+
+		var params []any
+		for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+			typ := "string"
+			if m[1] == "id" && !strings.HasPrefix(r.Path, "/api/badges/") {
+				typ = "integer"
+			}
+			params = append(params, map[string]any{"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": typ}})
+		}
+		for _, q := range doc.query {
+			p := map[string]any{"name": q.name, "in": "query", "schema": map[string]any{"type": q.typ}}
+			if q.desc != "" {
+				p["description"] = q.desc
+			}
+			params = append(params, p)
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+
+		if doc.body != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": sg.schema(reflect.TypeOf(doc.body))}},
+			}
+		}
+
+		ok200 := map[string]any{"description": "OK"}
+		switch {
+		case doc.resp != nil:
+			ok200["content"] = map[string]any{"application/json": map[string]any{"schema": sg.schema(reflect.TypeOf(doc.resp))}}
+		case doc.mime != "":
+			ok200["content"] = map[string]any{doc.mime: map[string]any{"schema": map[string]any{"type": "string"}}}
+		}
+		status := doc.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		responses := map[string]any{itoa(status): ok200, "default": errResp}
+		if doc.also != 0 {
+			responses[itoa(doc.also)] = ok200
+		}
+		if r.Method == http.MethodDelete && r.Path == "/api/nodes/:id" {
+			responses["409"] = map[string]any{
+				"description": "Unacknowledged dependencies",
+				"content":     map[string]any{"application/json": map[string]any{"schema": sg.schema(reflect.TypeOf(dependencyConflict{}))}},
+			}
+		}
+		op["responses"] = responses
+
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(r.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Avalauncher API",
+			"version": config.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": sg.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "description": "ADMIN_KEY"},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+	}
+}
+
+// operationID turns an Echo handler name like
+// "...server.(*Server).handleCreateNode-fm" into "createNode".
+func operationID(handler string) string {
+	name := strings.TrimSuffix(handler[strings.LastIndex(handler, ".")+1:], "-fm")
+	name = strings.TrimPrefix(name, "handle")
+	if name == "" {
+		return handler
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// routeTag groups a path by its first segment under /api.
+func routeTag(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return ""
+	}
+	tag, _, _ := strings.Cut(rest, "/")
+	return strings.TrimSuffix(tag, ".json")
+}
+
+func itoa(n int) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+// schemaGen derives JSON schemas from Go types, registering named structs
+// under components/schemas.
+type schemaGen struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = t.Name()
+			if _, taken := g.schemas[name]; taken {
+				pkg := t.PkgPath()
+				name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+			}
+			g.names[t] = name
+			g.schemas[name] = map[string]any{} // placeholder for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// object builds an inline object schema from a struct's JSON fields,
+// flattening embedded structs as encoding/json does.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if required != nil {
+		out["required"] = required
+	}
+	return out
+}
+
+func (g *schemaGen) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/primal-host/avalauncher/internal/manager"
+	"golang.org/x/time/rate"
+)
+
+// DefaultPublicRateLimit is how many requests per minute each client IP may
+// make to the token-less /api/public and badge endpoints.
+const DefaultPublicRateLimit = 30
+
+// SetPublicRateLimit sets the per-client request budget of the /api/public
+// and badge endpoints, in requests per minute. Call it before Start.
+func (s *Server) SetPublicRateLimit(perMinute int) {
+	s.publicLimiter = middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(perMinute) / 60),
+		Burst:     min(perMinute, 5),
+		ExpiresIn: 10 * time.Minute,
+	})
+}
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or addresses.
+func ParseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", v)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			v += "/" + strconv.Itoa(bits)
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", v)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// SetTrustedProxies sets how client IPs are found for rate limiting. With
+// no proxies the TCP peer address is used and X-Forwarded-For is ignored,
+// since any client can set it; otherwise X-Forwarded-For is walked back
+// through the given proxies only. Call it before Start.
+func (s *Server) SetTrustedProxies(proxies []*net.IPNet) {
+	if len(proxies) == 0 {
+		s.echo.IPExtractor = echo.ExtractIPDirect()
+		return
+	}
+	opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, n := range proxies {
+		opts = append(opts, echo.TrustIPRange(n))
+	}
+	s.echo.IPExtractor = echo.ExtractIPFromXFFHeader(opts...)
+}
+
+// publicRateLimit throttles unauthenticated endpoints per client IP, so
+// external monitors can't turn them into a load on the database.
+func (s *Server) publicRateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if ok, _ := s.publicLimiter.Allow(c.RealIP()); !ok {
+			c.Response().Header().Set("Retry-After", "60")
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+		}
+		return next(c)
+	}
+}
+
+func (s *Server) handlePublicL1Uptime(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	u, err := s.mgr.L1Uptime(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrL1NotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "uptime unavailable"})
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=30")
+	c.Response().Header().Set("Access-Control-Allow-Origin", "*")
+	return c.JSON(http.StatusOK, u)
+}
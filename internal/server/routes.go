@@ -1,40 +1,138 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/primal-host/avalauncher/internal/config"
+	"github.com/primal-host/avalauncher/internal/docker"
+	"github.com/primal-host/avalauncher/internal/logging"
 	"github.com/primal-host/avalauncher/internal/manager"
 )
 
 func (s *Server) routes() {
 	s.echo.GET("/health", s.handleHealth)
 	s.echo.GET("/", s.handleDashboard)
+	s.echo.GET("/static/*", s.handleStatic)
 	s.echo.GET("/api/status", s.handleStatus)
+	s.echo.GET("/api/openapi.json", s.handleOpenAPI)
+	s.echo.GET("/api/ui-config", s.handleUIConfig)
+	s.echo.GET("/api/badges/l1/:id", s.handleL1Badge, s.publicRateLimit)
+	s.echo.GET("/api/badges/node/:id", s.handleNodeBadge, s.publicRateLimit)
+	s.echo.GET("/api/public/l1s/:id/uptime.json", s.handlePublicL1Uptime, s.publicRateLimit)
+	s.echo.GET("/metrics", s.handleMetrics, s.requireBearer)
 
 	// Authenticated API group.
-	api := s.echo.Group("/api", s.requireBearer)
+	api := s.echo.Group("/api", s.requireBearer, s.audit)
+	api.GET("/audit", s.handleListAudit)
+	api.GET("/summary", s.handleSummary)
 	api.POST("/nodes", s.handleCreateNode)
 	api.GET("/nodes", s.handleListNodes)
+	api.GET("/nodes/identities", s.handleNodeIdentities)
+	api.GET("/node-profiles", s.handleNodeProfiles)
 	api.GET("/nodes/:id", s.handleGetNode)
 	api.POST("/nodes/:id/start", s.handleStartNode)
 	api.POST("/nodes/:id/stop", s.handleStopNode)
 	api.DELETE("/nodes/:id", s.handleDeleteNode)
+	api.GET("/nodes/:id/dependencies", s.handleNodeDependencies)
 	api.GET("/nodes/:id/logs", s.handleNodeLogs)
+	api.POST("/nodes/:id/exec", s.handleNodeExec)
+	api.POST("/nodes/:id/repull", s.handleRepullNode)
+	api.POST("/nodes/:id/identify", s.handleIdentifyNode)
+	api.POST("/nodes/:id/retry", s.handleRetryNode)
+	api.GET("/nodes/:id/inspect", s.handleNodeInspect)
+	api.GET("/nodes/:id/latency", s.handleNodeLatency)
+	api.GET("/nodes/:id/health", s.handleNodeHealth)
+	api.GET("/nodes/:id/uptime", s.handleNodeUptime)
+	api.GET("/nodes/:id/at", s.handleNodeAt)
+	api.GET("/nodes/:id/events", s.handleNodeEvents)
+	api.GET("/nodes/:id/usage", s.handleNodeUsage)
+	api.POST("/nodes/:id/check-port", s.handleCheckPort)
+	api.POST("/nodes/:id/staking-port", s.handleChangeStakingPort)
+	api.POST("/nodes/:id/validator", s.handleRegisterValidator)
+	api.PUT("/nodes/:id/aliases", s.handleSetNodeAliases)
+	api.PUT("/nodes/:id/tags", s.handleSetNodeTags)
+	api.PUT("/nodes/:id/throttle", s.handleSetNodeThrottle)
+	api.PUT("/nodes/:id/restart-policy", s.handleSetNodeRestartPolicy)
+	api.PUT("/nodes/:id/env", s.handleSetNodeEnv)
+	api.PUT("/nodes/:id/config", s.handleSetNodeConfig)
+	api.PUT("/nodes/:id/health-check", s.handleSetNodeHealthCheck)
+	api.PUT("/nodes/:id/ttl", s.handleSetNodeTTL)
+	api.GET("/nodes/:id/wait", s.handleWaitNode)
+	api.POST("/nodes/:id/snapshot", s.handleSnapshotNode)
+	api.POST("/nodes/:id/restore", s.handleRestoreNode)
+	api.GET("/snapshots", s.handleListSnapshots)
+	api.GET("/snapshots/:id", s.handleGetSnapshot)
+	api.DELETE("/snapshots/:id", s.handleDeleteSnapshot)
+	api.POST("/nodes/:id/staking/export", s.handleExportStakingKeys)
+	api.GET("/backups", s.handleListBackups)
 	api.GET("/events", s.handleListEvents)
+	api.POST("/events", s.handleCreateEvent)
+	api.GET("/events/stream", s.handleEventStream)
+	api.GET("/operations", s.handleListOperations)
+	api.GET("/log-level", s.handleGetLogLevel)
+	api.PUT("/log-level", s.handleSetLogLevel)
+	api.GET("/operations/:id", s.handleGetOperation)
+	api.DELETE("/operations/:id", s.handleCancelOperation)
+	api.GET("/admin/pollers", s.handleListPollers)
+	api.POST("/admin/pollers/:name/pause", s.handlePausePoller)
+	api.POST("/admin/pollers/:name/resume", s.handleResumePoller)
+	api.GET("/admin/reconcile", s.handleReconcileResults)
+	api.GET("/secrets", s.handleListSecrets)
+	api.PUT("/secrets/:name", s.handleSetSecret)
+	api.DELETE("/secrets/:name", s.handleDeleteSecret)
 	api.GET("/hosts", s.handleListHosts)
+	api.GET("/hosts/connections", s.handleHostConnections)
 	api.POST("/hosts", s.handleAddHost)
+	api.POST("/hosts/import", s.handleImportHosts)
+	api.GET("/hosts/:id/overview", s.handleHostOverview)
+	api.PUT("/hosts/:id/cost", s.handleSetHostCost)
+	api.PUT("/hosts/:id/address", s.handleSetHostAddress)
+	api.POST("/hosts/:id/drain", s.handleDrainHost)
+	api.GET("/hosts/:id/unmanaged", s.handleUnmanagedContainers)
+	api.POST("/hosts/:id/unmanaged/:container/adopt", s.handleAdoptContainer)
+	api.POST("/hosts/:id/resume", s.handleResumeHost)
+	api.GET("/hosts/:id/proxy", s.handleGetEdgeProxy)
+	api.PUT("/hosts/:id/proxy", s.handleDeployEdgeProxy)
+	api.DELETE("/hosts/:id/proxy", s.handleRemoveEdgeProxy)
+	api.GET("/costs", s.handleCosts)
+	api.GET("/images", s.handleListImages)
+	api.POST("/images/pull", s.handlePullImage)
+	api.POST("/images/prune", s.handlePruneImages)
+	api.GET("/images/drift", s.handleImageDrift)
+	api.POST("/upgrades", s.handleStartUpgrade)
+	api.GET("/upgrades", s.handleListUpgrades)
+	api.GET("/upgrades/:id", s.handleGetUpgrade)
+	api.POST("/upgrades/:id/proceed", s.handleProceedUpgrade)
+	api.POST("/upgrades/:id/cancel", s.handleCancelUpgrade)
 	api.DELETE("/hosts/:id", s.handleRemoveHost)
 	api.POST("/l1s", s.handleCreateL1)
 	api.GET("/l1s", s.handleListL1s)
 	api.GET("/l1s/:id", s.handleGetL1)
+	api.GET("/l1s/:id/health", s.handleL1Health)
+	api.GET("/l1s/:id/overview", s.handleL1Overview)
+	api.PATCH("/l1s/:id", s.handleUpdateL1)
 	api.DELETE("/l1s/:id", s.handleDeleteL1)
+	api.PUT("/l1s/:id/ttl", s.handleSetL1TTL)
+	api.PUT("/l1s/:id/rpc", s.handleExposeL1RPC)
+	api.POST("/l1s/:id/genesis", s.handleSetL1Genesis)
+	api.POST("/l1s/:id/deploy", s.handleDeployL1)
+	api.POST("/l1s/:id/convert", s.handleConvertL1)
+	api.GET("/l1s/:id/wait", s.handleWaitL1)
+	api.POST("/l1s/:id/logs/bundle", s.handleL1LogBundle)
 	api.POST("/l1s/:id/validators", s.handleAddValidator)
 	api.DELETE("/l1s/:id/validators/:nodeId", s.handleRemoveValidator)
+	api.POST("/l1s/:id/validators/rotate", s.handleRotateValidator)
+	api.GET("/l1s/:id/weights", s.handleL1Weights)
+	api.GET("/l1s/:id/weights/history", s.handleL1WeightHistory)
 }
 
 // requireBearer is Echo middleware that checks the Authorization header.
@@ -54,11 +152,6 @@ func (s *Server) handleHealth(c echo.Context) error {
 	})
 }
 
-func (s *Server) handleDashboard(c echo.Context) error {
-	html := strings.ReplaceAll(dashboardHTML, "{{VERSION}}", config.Version)
-	return c.HTML(http.StatusOK, html)
-}
-
 func (s *Server) handleStatus(c echo.Context) error {
 	authenticated := s.checkBearer(c)
 	ctx := c.Request().Context()
@@ -111,16 +204,24 @@ func (s *Server) handleStatus(c echo.Context) error {
 					NodeID:      n.NodeID,
 					StakingPort: n.StakingPort,
 					Status:      n.Status,
+					StakingEnd:  n.StakingEnd,
+					Version:     n.Version,
+					NewDigest:   s.mgr.DriftedDigest(n.ID),
+					Tags:        n.Tags,
 					L1s:         l1s,
 				})
 			}
 			resp["nodes"] = summaries
+			if skews := manager.VersionSkews(nodes); len(skews) > 0 {
+				resp["version_skew"] = skews
+			}
 		}
 
 		hosts, err := s.mgr.ListHosts(ctx)
 		if err == nil {
 			resp["hosts_list"] = hosts
 		}
+		resp["disk_usage"] = s.mgr.HostDiskUsages()
 
 		l1sList, err := s.mgr.ListL1sForDashboard(ctx)
 		if err == nil {
@@ -131,6 +232,15 @@ func (s *Server) handleStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+func (s *Server) handleSummary(c echo.Context) error {
+	summary, err := s.mgr.Summary(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	summary.Version = config.Version
+	return c.JSON(http.StatusOK, summary)
+}
+
 func (s *Server) handleCreateNode(c echo.Context) error {
 	var req manager.CreateNodeRequest
 	if err := c.Bind(&req); err != nil {
@@ -144,16 +254,45 @@ func (s *Server) handleCreateNode(c echo.Context) error {
 }
 
 func (s *Server) handleListNodes(c echo.Context) error {
-	nodes, err := s.mgr.ListNodes(c.Request().Context())
+	f := manager.NodeFilter{
+		Status:  c.QueryParam("status"),
+		Network: c.QueryParam("network"),
+		Tags:    c.QueryParams()["tag"],
+	}
+	if v := c.QueryParam("host_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid host_id"})
+		}
+		f.HostID = id
+	}
+	var err error
+	if f.Limit, f.Offset, err = pageParams(c); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	nodes, total, err := s.mgr.QueryNodes(c.Request().Context(), f)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	if nodes == nil {
-		nodes = []manager.Node{}
-	}
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
 	return c.JSON(http.StatusOK, nodes)
 }
 
+// pageParams reads the ?limit= and ?offset= of a list endpoint; 0 means
+// unset.
+func pageParams(c echo.Context) (limit, offset int, err error) {
+	for name, dst := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if v := c.QueryParam(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return 0, 0, fmt.Errorf("invalid %s", name)
+			}
+			*dst = n
+		}
+	}
+	return limit, offset, nil
+}
+
 func (s *Server) handleGetNode(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -172,172 +311,1616 @@ func (s *Server) handleStartNode(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
 	if err := s.mgr.StartNode(c.Request().Context(), id); err != nil {
+		var dupErr *manager.DuplicateIdentityError
+		if errors.As(err, &dupErr) {
+			return c.JSON(http.StatusConflict, map[string]any{"error": err.Error(), "identity": dupErr.Identity})
+		}
+		if errors.Is(err, manager.ErrHostOffline) && c.QueryParam("queue") == "true" {
+			return s.queueNodeOp(c, id, manager.OpStart)
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "started"})
 }
 
+func (s *Server) handleNodeProfiles(c echo.Context) error {
+	return c.JSON(http.StatusOK, docker.NodeProfiles())
+}
+
+func (s *Server) handleNodeIdentities(c echo.Context) error {
+	ids, err := s.mgr.NodeIdentities(c.Request().Context(), c.QueryParam("duplicates") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"policy": s.mgr.IdentityPolicy(), "identities": ids})
+}
+
 func (s *Server) handleStopNode(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
 	if err := s.mgr.StopNode(c.Request().Context(), id); err != nil {
+		if errors.Is(err, manager.ErrHostOffline) && c.QueryParam("queue") == "true" {
+			return s.queueNodeOp(c, id, manager.OpStop)
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "stopped"})
 }
 
+// queueNodeOp queues an operation whose host is offline and returns it (202).
+func (s *Server) queueNodeOp(c echo.Context, id int64, kind string) error {
+	op, err := s.mgr.QueueNodeOp(c.Request().Context(), id, kind)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, op)
+}
+
 func (s *Server) handleDeleteNode(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	removeVolumes := c.QueryParam("remove_volumes") == "true"
-	if err := s.mgr.DeleteNode(c.Request().Context(), id, removeVolumes); err != nil {
+	opts := manager.DeleteNodeOptions{
+		RemoveVolumes: c.QueryParam("remove_volumes") == "true",
+		Force:         c.QueryParam("force") == "true",
+		Validators:    c.QueryParam("validators"),
+	}
+	if v := c.QueryParam("reassign_to"); v != "" {
+		if opts.ReassignTo, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid reassign_to"})
+		}
+	}
+	if ack := c.QueryParam("ack"); ack != "" {
+		opts.Ack = strings.Split(ack, ",")
+	}
+	if err := s.mgr.DeleteNode(c.Request().Context(), id, opts); err != nil {
+		var depErr *manager.DependencyError
+		if errors.As(err, &depErr) {
+			return c.JSON(http.StatusConflict, map[string]any{"error": err.Error(), "dependencies": depErr.Report})
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
 }
 
+func (s *Server) handleNodeDependencies(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	deps, err := s.mgr.NodeDependencies(c.Request().Context(), id, c.QueryParam("remove_volumes") == "true")
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, deps)
+}
+
 func (s *Server) handleNodeLogs(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
 	tail := c.QueryParam("tail")
-	reader, err := s.mgr.NodeLogs(c.Request().Context(), id, tail)
+	follow := c.QueryParam("follow") == "true"
+	reader, err := s.mgr.NodeLogs(c.Request().Context(), id, tail, follow)
+	if errors.Is(err, manager.ErrLogStreamLimit) {
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	defer reader.Close()
 
 	c.Response().Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !follow {
+		c.Response().WriteHeader(http.StatusOK)
+		io.Copy(c.Response().Writer, reader)
+		return nil
+	}
+
+	// Stream chunked, flushing each read so lines show up as they're logged.
+	// The reader ends when the client disconnects (request ctx is cancelled).
+	c.Response().Header().Set("X-Accel-Buffering", "no")
 	c.Response().WriteHeader(http.StatusOK)
-	io.Copy(c.Response().Writer, reader)
+	c.Response().Flush()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if _, werr := c.Response().Write(buf[:n]); werr != nil {
+				return nil
+			}
+			c.Response().Flush()
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// flushWriter streams exec output, sending the response header on the first
+// write so errors raised before the command starts can still be JSON.
+type flushWriter struct {
+	res     *echo.Response
+	started bool
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.res.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.res.Header().Set("X-Accel-Buffering", "no")
+		w.res.Header().Set("Trailer", "X-Exit-Code")
+		w.res.WriteHeader(http.StatusOK)
+	}
+	n, err := w.res.Write(p)
+	w.res.Flush()
+	return n, err
+}
+
+func (s *Server) handleNodeExec(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.ExecRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	// Output streams as the command produces it; the exit code follows in
+	// the X-Exit-Code trailer (or header, when the command printed nothing).
+	w := &flushWriter{res: c.Response()}
+	code, err := s.mgr.ExecNode(c.Request().Context(), id, req, w)
+	if !w.started {
+		if errors.Is(err, manager.ErrExecNotAllowed) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		c.Response().Header().Set("X-Exit-Code", strconv.Itoa(code))
+		return c.NoContent(http.StatusOK)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "\nexec: %v\n", err)
+	}
+	c.Response().Header().Set("X-Exit-Code", strconv.Itoa(code))
 	return nil
 }
 
-func (s *Server) handleListEvents(c echo.Context) error {
-	limit := 50
-	if l := c.QueryParam("limit"); l != "" {
-		if n, err := strconv.Atoi(l); err == nil && n > 0 {
-			limit = n
+func (s *Server) handleNodeInspect(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	info, err := s.mgr.NodeInspect(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+func (s *Server) handleNodeLatency(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var window, bucket time.Duration
+	if w := c.QueryParam("window"); w != "" {
+		if window, err = time.ParseDuration(w); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid window"})
 		}
 	}
-	events, err := s.mgr.ListEvents(c.Request().Context(), limit)
+	if b := c.QueryParam("bucket"); b != "" {
+		if bucket, err = time.ParseDuration(b); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid bucket"})
+		}
+	}
+	report, err := s.mgr.NodeLatency(c.Request().Context(), id, window, bucket)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleNodeHealth(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	if events == nil {
-		events = []manager.Event{}
+	health, err := s.mgr.NodeHealthReport(c.Request().Context(), id, c.QueryParam("refresh") == "true")
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, events)
+	return c.JSON(http.StatusOK, health)
 }
 
-func (s *Server) handleListHosts(c echo.Context) error {
-	hosts, err := s.mgr.ListHosts(c.Request().Context())
+func (s *Server) handleNodeUptime(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var window time.Duration
+	if w := c.QueryParam("window"); w != "" {
+		if window, err = time.ParseDuration(w); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid window"})
+		}
+	}
+	report, err := s.mgr.NodeUptimeHistory(c.Request().Context(), id, window)
 	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, hosts)
+	return c.JSON(http.StatusOK, report)
 }
 
-func (s *Server) handleAddHost(c echo.Context) error {
-	var req manager.AddHostRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+func (s *Server) handleNodeAt(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	host, err := s.mgr.AddHost(c.Request().Context(), req)
+	t, err := time.Parse(time.RFC3339, c.QueryParam("time"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid time (want RFC 3339)"})
+	}
+	at, err := s.mgr.NodeAt(c.Request().Context(), id, t)
 	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusCreated, host)
+	return c.JSON(http.StatusOK, at)
 }
 
-func (s *Server) handleRemoveHost(c echo.Context) error {
+func (s *Server) handleSnapshotNode(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	if err := s.mgr.RemoveHost(c.Request().Context(), id); err != nil {
+	snap, err := s.mgr.SnapshotNode(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+	return c.JSON(http.StatusAccepted, snap)
 }
 
-func (s *Server) handleCreateL1(c echo.Context) error {
-	var req manager.CreateL1Request
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+func (s *Server) handleRestoreNode(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	l1, err := s.mgr.CreateL1(c.Request().Context(), req)
+	var req manager.RestoreRequest
+	if err := c.Bind(&req); err != nil || req.SnapshotID == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "snapshot_id is required"})
+	}
+	snap, err := s.mgr.RestoreNode(c.Request().Context(), id, req)
 	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) || errors.Is(err, manager.ErrSnapshotNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusCreated, l1)
+	return c.JSON(http.StatusAccepted, snap)
 }
 
-func (s *Server) handleListL1s(c echo.Context) error {
-	l1s, err := s.mgr.ListL1s(c.Request().Context())
+func (s *Server) handleListSnapshots(c echo.Context) error {
+	var nodeID int64
+	if v := c.QueryParam("node_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid node_id"})
+		}
+		nodeID = id
+	}
+	snaps, err := s.mgr.ListSnapshots(c.Request().Context(), nodeID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, l1s)
+	return c.JSON(http.StatusOK, snaps)
 }
 
-func (s *Server) handleGetL1(c echo.Context) error {
+func (s *Server) handleExportStakingKeys(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	l1, err := s.mgr.GetL1(c.Request().Context(), id)
+	backup, err := s.mgr.ExportStakingKeys(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "L1 not found"})
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, l1)
+	return c.JSON(http.StatusCreated, backup)
 }
 
-func (s *Server) handleDeleteL1(c echo.Context) error {
+func (s *Server) handleListBackups(c echo.Context) error {
+	var nodeID int64
+	if v := c.QueryParam("node_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid node_id"})
+		}
+		nodeID = id
+	}
+	kind := c.QueryParam("kind")
+	if kind != "" && kind != manager.BackupDB && kind != manager.BackupStakingKeys {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid kind (want db or staking_keys)"})
+	}
+	backups, err := s.mgr.ListBackups(c.Request().Context(), nodeID, kind)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, backups)
+}
+
+func (s *Server) handleGetSnapshot(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	if err := s.mgr.DeleteL1(c.Request().Context(), id); err != nil {
+	snap, err := s.mgr.GetSnapshot(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrSnapshotNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, snap)
+}
+
+func (s *Server) handleDeleteSnapshot(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := s.mgr.DeleteSnapshot(c.Request().Context(), id); err != nil {
+		if errors.Is(err, manager.ErrSnapshotNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
 }
 
-func (s *Server) handleAddValidator(c echo.Context) error {
-	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+func (s *Server) handleNodeUsage(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	var req manager.AddValidatorRequest
+	refresh := c.QueryParam("refresh") == "true"
+	usage, err := s.mgr.NodeUsage(c.Request().Context(), id, refresh)
+	var unsupported *docker.UnsupportedError
+	if errors.As(err, &unsupported) {
+		return c.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
+func (s *Server) handleChangeStakingPort(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.StakingPortRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 	}
-	val, err := s.mgr.AddValidator(c.Request().Context(), l1ID, req)
+	op, err := s.mgr.ChangeStakingPort(c.Request().Context(), id, req)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusCreated, val)
+	return c.JSON(http.StatusAccepted, op)
 }
 
-func (s *Server) handleRemoveValidator(c echo.Context) error {
-	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+func (s *Server) handleRegisterValidator(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
 	}
-	nodeID, err := strconv.ParseInt(c.Param("nodeId"), 10, 64)
+	var req manager.RegisterValidatorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	op, err := s.mgr.RegisterValidator(c.Request().Context(), id, req)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid node id"})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	if err := s.mgr.RemoveValidator(c.Request().Context(), l1ID, nodeID); err != nil {
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (s *Server) handleCheckPort(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.PortCheckRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		}
+	}
+	result, err := s.mgr.CheckStakingPort(c.Request().Context(), id, req)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+	return c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handleSetNodeAliases(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		DNSAliases []string `json:"dns_aliases"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeAliases(c.Request().Context(), id, req.DNSAliases)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeTags(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeTags(c.Request().Context(), id, req.Tags)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeThrottle(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req docker.Throttle
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeThrottle(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeRestartPolicy(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		RestartPolicy string `json:"restart_policy"`
+		AutoHeal      bool   `json:"auto_heal"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeRestartPolicy(c.Request().Context(), id, req.RestartPolicy, req.AutoHeal)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeEnv(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		Env map[string]string `json:"env"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeEnv(c.Request().Context(), id, req.Env)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeConfig(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req docker.NodeConfig
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeConfig(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeHealthCheck(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		HealthCheck string `json:"health_check"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeHealthCheck(c.Request().Context(), id, req.HealthCheck)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleSetNodeTTL(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		TTL string `json:"ttl"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	node, err := s.mgr.SetNodeTTL(c.Request().Context(), id, req.TTL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleWaitNode(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	timeout, err := waitTimeout(c.QueryParam("timeout"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+	defer cancel()
+
+	cond := c.QueryParam("for")
+	if cond == "" {
+		cond = manager.WaitHealthy
+	}
+	node, err := s.mgr.WaitNode(ctx, id, cond)
+	return waitResponse(c, node, err)
+}
+
+func (s *Server) handleListAudit(c echo.Context) error {
+	f := manager.AuditFilter{
+		Actor:  c.QueryParam("actor"),
+		Method: strings.ToUpper(c.QueryParam("method")),
+		Path:   c.QueryParam("path"),
+		Failed: c.QueryParam("failed") == "true",
+	}
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		f.Limit = n
+	}
+	for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + name + " (want RFC 3339)"})
+			}
+			*dst = t
+		}
+	}
+	entries, err := s.mgr.ListAudit(c.Request().Context(), f)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+func (s *Server) handleCreateEvent(c echo.Context) error {
+	var req manager.CustomEventRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	event, err := s.mgr.RecordCustomEvent(c.Request().Context(), req, s.actor(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, event)
+}
+
+func (s *Server) handleListEvents(c echo.Context) error {
+	f, err := eventFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	events, total, err := s.mgr.ListEvents(c.Request().Context(), f)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+	return c.JSON(http.StatusOK, events)
+}
+
+func (s *Server) handleNodeEvents(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	f, err := eventFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	events, total, err := s.mgr.NodeEvents(c.Request().Context(), id, f)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+	return c.JSON(http.StatusOK, events)
+}
+
+// eventFilter reads the event list query parameters shared by
+// /api/events and /api/nodes/:id/events.
+func eventFilter(c echo.Context) (manager.EventFilter, error) {
+	f := manager.EventFilter{
+		MinSeverity: c.QueryParam("severity"),
+		EventType:   c.QueryParam("event_type"),
+		Target:      c.QueryParam("target"),
+	}
+	if f.EventType == "" {
+		f.EventType = c.QueryParam("type")
+	}
+	if f.MinSeverity != "" {
+		if err := manager.CheckSeverity(f.MinSeverity); err != nil {
+			return f, err
+		}
+	}
+	var err error
+	if f.Limit, f.Offset, err = pageParams(c); err != nil {
+		return f, err
+	}
+	for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s (want RFC 3339)", name)
+			}
+			*dst = t
+		}
+	}
+	return f, nil
+}
+
+// handleEventStream pushes events to the client as Server-Sent Events until
+// it disconnects. A comment line every 15s keeps proxies from timing out.
+// ?severity= limits the stream to events at or above a level.
+func (s *Server) handleEventStream(c echo.Context) error {
+	severity := c.QueryParam("severity")
+	if severity != "" {
+		if err := manager.CheckSeverity(severity); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+	events, unsubscribe := s.mgr.Subscribe()
+	defer unsubscribe()
+
+	w := c.Response()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	w.Flush()
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !manager.SeverityAtLeast(e.Severity, severity) {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.EventType, data)
+		}
+		w.Flush()
+	}
+}
+
+func (s *Server) handleListOperations(c echo.Context) error {
+	limit := 50
+	if l := c.QueryParam("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	ops, err := s.mgr.ListOperations(c.Request().Context(), c.QueryParam("state"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, ops)
+}
+
+func (s *Server) handleGetLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, logging.Current())
+}
+
+func (s *Server) handleSetLogLevel(c echo.Context) error {
+	var req struct {
+		Level    string `json:"level"`
+		Duration string `json:"duration"` // e.g. "15m"; empty = until restart
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if req.Level == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "level is required"})
+	}
+	var d time.Duration
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid duration"})
+		}
+	}
+	st, err := logging.SetLevel(req.Level, d)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, st)
+}
+
+func (s *Server) handleListPollers(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.mgr.Pollers())
+}
+
+func (s *Server) handleReconcileResults(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.mgr.ReconcileResults())
+}
+
+func (s *Server) handlePausePoller(c echo.Context) error {
+	st, err := s.mgr.SetPollerPaused(c.Request().Context(), c.Param("name"), true)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, st)
+}
+
+func (s *Server) handleResumePoller(c echo.Context) error {
+	st, err := s.mgr.SetPollerPaused(c.Request().Context(), c.Param("name"), false)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, st)
+}
+
+func (s *Server) handleGetOperation(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	op, err := s.mgr.GetOperation(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "operation not found"})
+	}
+	return c.JSON(http.StatusOK, op)
+}
+
+func (s *Server) handleCancelOperation(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	op, err := s.mgr.CancelOperation(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrOperationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, op)
+}
+
+func (s *Server) handleListSecrets(c echo.Context) error {
+	secrets, err := s.mgr.ListSecrets(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, secrets)
+}
+
+func (s *Server) handleSetSecret(c echo.Context) error {
+	var req struct {
+		Value string `json:"value"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	secret, err := s.mgr.SetSecret(c.Request().Context(), c.Param("name"), req.Value)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, secret)
+}
+
+func (s *Server) handleDeleteSecret(c echo.Context) error {
+	if err := s.mgr.DeleteSecret(c.Request().Context(), c.Param("name")); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (s *Server) handleListHosts(c echo.Context) error {
+	hosts, err := s.mgr.ListHosts(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, hosts)
+}
+
+func (s *Server) handleAddHost(c echo.Context) error {
+	var req manager.AddHostRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	host, err := s.mgr.AddHost(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, host)
+}
+
+func (s *Server) handleImportHosts(c echo.Context) error {
+	var req manager.ImportHostsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	report, err := s.mgr.ImportHosts(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleListImages(c echo.Context) error {
+	var hostID int64
+	if v := c.QueryParam("host_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid host_id"})
+		}
+		hostID = id
+	}
+	images, err := s.mgr.ListHostImages(c.Request().Context(), hostID, c.QueryParam("avalanchego") == "true")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, images)
+}
+
+func (s *Server) handlePullImage(c echo.Context) error {
+	var req manager.PullImageRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	report, err := s.mgr.PrePullImage(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handlePruneImages(c echo.Context) error {
+	var req manager.PruneImagesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	report, err := s.mgr.PruneImages(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleImageDrift(c echo.Context) error {
+	report, err := s.mgr.CheckImageDrift(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if c.QueryParam("drifted") == "true" {
+		drifted := []manager.ImageDrift{}
+		for _, d := range report {
+			if d.Drifted {
+				drifted = append(drifted, d)
+			}
+		}
+		report = drifted
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleRepullNode(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	node, err := s.mgr.RepullNode(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, node)
+}
+
+func (s *Server) handleRetryNode(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	node, err := s.mgr.RetryNode(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, node)
+}
+
+func (s *Server) handleIdentifyNode(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	node, err := s.mgr.IdentifyNode(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrNodeNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, node)
+}
+
+func (s *Server) handleHostOverview(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	overview, err := s.mgr.HostOverview(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "host not found"})
+	}
+	return c.JSON(http.StatusOK, overview)
+}
+
+func (s *Server) handleSetHostCost(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		CostPerMonth float64 `json:"cost_per_month"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	host, err := s.mgr.SetHostCost(c.Request().Context(), id, req.CostPerMonth)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, host)
+}
+
+func (s *Server) handleSetHostAddress(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	host, err := s.mgr.SetHostAddress(c.Request().Context(), id, req.Address)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, host)
+}
+
+func (s *Server) handleGetEdgeProxy(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	status, err := s.mgr.EdgeProxy(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrHostNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleDeployEdgeProxy(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	status, err := s.mgr.DeployEdgeProxy(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, manager.ErrHostNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+func (s *Server) handleRemoveEdgeProxy(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := s.mgr.RemoveEdgeProxy(c.Request().Context(), id); err != nil {
+		if errors.Is(err, manager.ErrHostNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+}
+
+func (s *Server) handleDrainHost(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.DrainRequest
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		}
+	}
+	res, err := s.mgr.DrainHost(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func (s *Server) handleUnmanagedContainers(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	containers, err := s.mgr.UnmanagedContainers(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, containers)
+}
+
+func (s *Server) handleAdoptContainer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		}
+	}
+	node, err := s.mgr.AdoptContainer(c.Request().Context(), id, c.Param("container"), req.Name)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, node)
+}
+
+func (s *Server) handleResumeHost(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	host, err := s.mgr.ResumeHost(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, host)
+}
+
+func (s *Server) handleHostConnections(c echo.Context) error {
+	conns, err := s.mgr.HostConnections(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, conns)
+}
+
+func (s *Server) handleStartUpgrade(c echo.Context) error {
+	var req manager.UpgradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	u, err := s.mgr.StartUpgrade(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, u)
+}
+
+func (s *Server) handleListUpgrades(c echo.Context) error {
+	upgrades, err := s.mgr.ListUpgrades(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, upgrades)
+}
+
+func (s *Server) handleGetUpgrade(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	u, err := s.mgr.GetUpgrade(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "upgrade not found"})
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+func (s *Server) handleProceedUpgrade(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	u, err := s.mgr.ProceedUpgrade(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, u)
+}
+
+func (s *Server) handleCancelUpgrade(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	u, err := s.mgr.CancelUpgrade(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+func (s *Server) handleL1LogBundle(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.LogBundleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	bundle, err := s.mgr.CollectL1Logs(c.Request().Context(), id, req)
+	if err != nil {
+		if errors.Is(err, manager.ErrL1NotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("Content-Type", "application/gzip")
+	c.Response().Header().Set("Content-Disposition", `attachment; filename="`+bundle.Filename()+`"`)
+	c.Response().WriteHeader(http.StatusOK)
+	return bundle.Write(c.Response().Writer)
+}
+
+func (s *Server) handleCosts(c echo.Context) error {
+	report, err := s.mgr.Costs(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) handleRemoveHost(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := s.mgr.RemoveHost(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+}
+
+func (s *Server) handleCreateL1(c echo.Context) error {
+	var req manager.CreateL1Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.CreateL1(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, l1)
+}
+
+func (s *Server) handleListL1s(c echo.Context) error {
+	f := manager.L1Filter{Status: c.QueryParam("status"), VM: c.QueryParam("vm")}
+	var err error
+	if f.Limit, f.Offset, err = pageParams(c); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	l1s, total, err := s.mgr.ListL1s(c.Request().Context(), f)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+	return c.JSON(http.StatusOK, l1s)
+}
+
+func (s *Server) handleGetL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	l1, err := s.mgr.GetL1(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "L1 not found"})
+	}
+	return c.JSON(http.StatusOK, l1)
+}
+
+func (s *Server) handleL1Health(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	health, err := s.mgr.L1Health(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "L1 not found"})
+	}
+	return c.JSON(http.StatusOK, health)
+}
+
+func (s *Server) handleL1Overview(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	overview, err := s.mgr.L1Overview(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "L1 not found"})
+	}
+	return c.JSON(http.StatusOK, overview)
+}
+
+func (s *Server) handleUpdateL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.UpdateL1Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.UpdateL1(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, l1)
+}
+
+func (s *Server) handleDeleteL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := s.mgr.DeleteL1(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (s *Server) handleSetL1TTL(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req struct {
+		TTL string `json:"ttl"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.SetL1TTL(c.Request().Context(), id, req.TTL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, l1)
+}
+
+func (s *Server) handleExposeL1RPC(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.ExposeL1RPCRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.ExposeL1RPC(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, l1)
+}
+
+func (s *Server) handleSetL1Genesis(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.GenesisRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	genesis, err := s.mgr.SetL1Genesis(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSONBlob(http.StatusOK, genesis)
+}
+
+func (s *Server) handleDeployL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.DeployL1Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.DeployL1(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, l1)
+}
+
+func (s *Server) handleConvertL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.ConvertL1Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	l1, err := s.mgr.ConvertL1(c.Request().Context(), id, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, l1)
+}
+
+func (s *Server) handleWaitL1(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	timeout, err := waitTimeout(c.QueryParam("timeout"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+	defer cancel()
+
+	cond := c.QueryParam("for")
+	if cond == "" {
+		cond = manager.WaitDeployed
+	}
+	l1, err := s.mgr.WaitL1(ctx, id, cond)
+	return waitResponse(c, l1, err)
+}
+
+// maxWaitTimeout caps how long a wait request may hold a connection.
+const maxWaitTimeout = 30 * time.Minute
+
+// waitTimeout parses a wait endpoint's timeout (default 300s).
+func waitTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 300 * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout")
+	}
+	if d > maxWaitTimeout {
+		d = maxWaitTimeout
+	}
+	return d, nil
+}
+
+// waitResponse maps a wait result to a status CI can branch on: 200 when the
+// condition was met, 408 on timeout, 409 when it can no longer be met. The
+// last seen state is included where there is one.
+func waitResponse(c echo.Context, state any, err error) error {
+	var werr *manager.WaitError
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, state)
+	case errors.Is(err, manager.ErrWaitTimeout):
+		return c.JSON(http.StatusRequestTimeout, map[string]any{"error": "timed out", "state": state})
+	case errors.As(err, &werr):
+		return c.JSON(http.StatusConflict, map[string]any{"error": werr.Error(), "state": state})
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+}
+
+func (s *Server) handleAddValidator(c echo.Context) error {
+	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.AddValidatorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	val, err := s.mgr.AddValidator(c.Request().Context(), l1ID, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if val.Pending {
+		return c.JSON(http.StatusAccepted, val)
+	}
+	return c.JSON(http.StatusCreated, val)
+}
+
+func (s *Server) handleRemoveValidator(c echo.Context) error {
+	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	nodeID, err := strconv.ParseInt(c.Param("nodeId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid node id"})
+	}
+	if err := s.mgr.RemoveValidator(c.Request().Context(), l1ID, nodeID); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+}
+
+func (s *Server) handleRotateValidator(c echo.Context) error {
+	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req manager.RotateValidatorRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	op, err := s.mgr.RotateValidator(c.Request().Context(), l1ID, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, op)
+}
+
+func (s *Server) handleL1Weights(c echo.Context) error {
+	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var at time.Time
+	if v := c.QueryParam("at"); v != "" {
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid at (want RFC 3339)"})
+		}
+	}
+	d, err := s.mgr.WeightDistribution(c.Request().Context(), l1ID, at)
+	if err != nil {
+		if errors.Is(err, manager.ErrL1NotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+func (s *Server) handleL1WeightHistory(c echo.Context) error {
+	l1ID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var f manager.WeightHistoryFilter
+	if v := c.QueryParam("node"); v != "" {
+		if f.NodeID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid node"})
+		}
+	}
+	for name, dst := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + name + " (want RFC 3339)"})
+			}
+			*dst = t
+		}
+	}
+	changes, err := s.mgr.WeightHistory(c.Request().Context(), l1ID, f)
+	if err != nil {
+		if errors.Is(err, manager.ErrL1NotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, changes)
 }
 
 func (s *Server) checkBearer(c echo.Context) bool {
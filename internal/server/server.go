@@ -13,12 +13,14 @@ import (
 
 // Server holds the Echo instance and dependencies.
 type Server struct {
-	echo           *echo.Echo
-	db             *database.DB
-	mgr            *manager.Manager
-	adminKey       string
-	addr           string
-	traefikDomain  string // e.g. "avax.primal.host" (empty = no RPC URLs)
+	echo          *echo.Echo
+	db            *database.DB
+	mgr           *manager.Manager
+	adminKey      string
+	addr          string
+	traefikDomain string                             // e.g. "avax.primal.host" (empty = no RPC URLs)
+	ui            UIConfig                           // dashboard behaviour, served at /api/ui-config
+	publicLimiter *middleware.RateLimiterMemoryStore // per-IP budget of /api/public and badges
 }
 
 // New creates a configured Echo server.
@@ -34,6 +36,9 @@ func New(db *database.DB, mgr *manager.Manager, addr, adminKey, traefikDomain st
 	s.echo.HideBanner = true
 	s.echo.HidePort = true
 	s.echo.Use(middleware.Recover())
+	s.SetUIConfig(DefaultUIConfig())
+	s.SetPublicRateLimit(DefaultPublicRateLimit)
+	s.SetTrustedProxies(nil)
 	s.routes()
 	return s
 }
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// uiSections are the dashboard sections that can be switched off.
+var uiSections = []string{"cards", "actions", "nodes"}
+
+// UIConfig tunes the dashboard per deployment. It is served publicly at
+// /api/ui-config and read by the page on load.
+type UIConfig struct {
+	PollInterval time.Duration `json:"-"`
+	PollMillis   int64         `json:"poll_interval_ms"` // refresh period while not streaming (or always, without SSE)
+	EventStream  bool          `json:"event_stream"`     // refresh on /api/events/stream pushes
+	Sections     []string      `json:"sections"`         // enabled sections, in uiSections
+	Brand        Branding      `json:"brand"`
+}
+
+// Branding white-labels the dashboard. It is rendered into the page and
+// also served with the rest of the UIConfig.
+type Branding struct {
+	ProductName string `json:"product_name"`
+	LogoURL     string `json:"logo_url,omitempty"`     // shown left of the product name
+	AccentColor string `json:"accent_color,omitempty"` // hex color of primary buttons; default blue
+}
+
+// accentColor matches the accepted accent colors: #rgb or #rrggbb.
+var accentColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// DefaultUIConfig is the dashboard's built-in behaviour.
+func DefaultUIConfig() UIConfig {
+	return UIConfig{
+		PollInterval: 10 * time.Second,
+		EventStream:  true,
+		Sections:     uiSections,
+		Brand:        Branding{ProductName: "Avalauncher"},
+	}
+}
+
+// ParseBranding validates branding from its environment values. An empty
+// product name keeps the default.
+func ParseBranding(productName, logoURL, accent string) (Branding, error) {
+	b := DefaultUIConfig().Brand
+	if productName = strings.TrimSpace(productName); productName != "" {
+		b.ProductName = productName
+	}
+	if logoURL != "" {
+		u, err := url.Parse(logoURL)
+		if err != nil || !(u.Scheme == "https" || u.Scheme == "http" || (u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/"))) {
+			return b, fmt.Errorf("invalid logo URL %q (want http(s):// or an absolute path)", logoURL)
+		}
+		b.LogoURL = logoURL
+	}
+	if accent != "" {
+		if !accentColor.MatchString(accent) {
+			return b, fmt.Errorf("invalid accent color %q (want #rgb or #rrggbb)", accent)
+		}
+		b.AccentColor = accent
+	}
+	return b, nil
+}
+
+// ParseUIConfig builds a UIConfig from its environment values: a poll
+// interval ("10s"), whether to use the event stream, and a comma-separated
+// list of sections.
+func ParseUIConfig(poll string, eventStream bool, sections string) (UIConfig, error) {
+	cfg := DefaultUIConfig()
+	cfg.EventStream = eventStream
+	d, err := time.ParseDuration(poll)
+	if err != nil || d < time.Second {
+		return cfg, fmt.Errorf("invalid poll interval %q (want a duration of at least 1s)", poll)
+	}
+	cfg.PollInterval = d
+
+	cfg.Sections = []string{}
+	for _, s := range strings.Split(sections, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !slices.Contains(uiSections, s) {
+			return cfg, fmt.Errorf("unknown dashboard section %q (want %s)", s, strings.Join(uiSections, ", "))
+		}
+		cfg.Sections = append(cfg.Sections, s)
+	}
+	return cfg, nil
+}
+
+// SetUIConfig replaces the dashboard configuration.
+func (s *Server) SetUIConfig(cfg UIConfig) {
+	cfg.PollMillis = cfg.PollInterval.Milliseconds()
+	s.ui = cfg
+}
+
+func (s *Server) handleUIConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.ui)
+}
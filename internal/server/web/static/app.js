@@ -0,0 +1,465 @@
+let adminKey = sessionStorage.getItem('adminKey') || '';
+let authenticated = false;
+let hostsList = [];
+let nodesList = [];
+let traefikDomain = '';
+let groupBy = localStorage.getItem('groupBy') || 'host';
+
+function headers() {
+  const h = {'Content-Type': 'application/json'};
+  if (adminKey) h['Authorization'] = 'Bearer ' + adminKey;
+  return h;
+}
+
+function updateAuthBadge(isAuth, userHandle) {
+  const b = document.getElementById('auth-badge');
+  if (isAuth) {
+    b.textContent = userHandle || 'authenticated';
+    b.className = 'auth-status ok';
+    b.style.cursor = 'default';
+    b.onclick = null;
+  } else {
+    b.textContent = 'click for key';
+    b.className = 'auth-status no';
+    b.style.cursor = 'pointer';
+    b.onclick = showKeyModal;
+  }
+}
+
+function showKeyModal() {
+  document.getElementById('key-modal').classList.add('active');
+  document.getElementById('admin-key').focus();
+}
+function hideKeyModal() { document.getElementById('key-modal').classList.remove('active'); }
+function saveKey() {
+  adminKey = document.getElementById('admin-key').value.trim();
+  sessionStorage.setItem('adminKey', adminKey);
+  hideKeyModal();
+  refresh();
+}
+
+function showHostModal() {
+  if (!authenticated) { showKeyModal(); return; }
+  document.getElementById('host-error').style.display = 'none';
+  document.getElementById('host-modal').classList.add('active');
+  document.getElementById('host-name').focus();
+}
+function hideHostModal() { document.getElementById('host-modal').classList.remove('active'); }
+
+async function addHost() {
+  const name = document.getElementById('host-name').value.trim();
+  const ssh = document.getElementById('host-ssh').value.trim();
+  if (!name || !ssh) { showError('host-error', 'Name and SSH address are required'); return; }
+  try {
+    const r = await fetch('/api/hosts', {method: 'POST', headers: headers(), body: JSON.stringify({name, ssh_addr: ssh})});
+    const d = await r.json();
+    if (!r.ok) { showError('host-error', d.error || 'Failed'); return; }
+    hideHostModal();
+    document.getElementById('host-name').value = '';
+    document.getElementById('host-ssh').value = '';
+    refresh();
+  } catch(e) { showError('host-error', e.message); }
+}
+
+async function removeHost(id, name) {
+  if (!confirm('Remove host ' + name + '?')) return;
+  try {
+    const r = await fetch('/api/hosts/' + id, {method: 'DELETE', headers: headers()});
+    if (!r.ok) {
+      const d = await r.json();
+      alert(d.error || 'Failed to remove host');
+    }
+    refresh();
+  } catch(e) { console.error(e); }
+}
+
+function populateHostSelect() {
+  const sel = document.getElementById('node-host');
+  sel.innerHTML = '<option value="0">Automatic</option>';
+  for (const h of hostsList) {
+    const opt = document.createElement('option');
+    opt.value = h.id;
+    const label = h.labels && h.labels.hostname ? h.labels.hostname : h.name;
+    opt.textContent = label + (h.ssh_addr ? ' (' + h.ssh_addr + ')' : ' (local)');
+    sel.appendChild(opt);
+  }
+}
+
+function showCreateModal() {
+  if (!authenticated) { showKeyModal(); return; }
+  document.getElementById('create-error').style.display = 'none';
+  populateHostSelect();
+  document.getElementById('create-modal').classList.add('active');
+  document.getElementById('node-name').focus();
+}
+function hideCreateModal() { document.getElementById('create-modal').classList.remove('active'); }
+
+async function createNode() {
+  const name = document.getElementById('node-name').value.trim();
+  const port = parseInt(document.getElementById('node-port').value) || 9651;
+  const network = document.getElementById('node-network').value;
+  const image = document.getElementById('node-image').value.trim();
+  const profile = document.getElementById('node-profile').value;
+  const hostId = parseInt(document.getElementById('node-host').value) || 0;
+  if (!name) { showError('create-error', 'Name is required'); return; }
+  try {
+    const body = {name, staking_port: port, network: network, host_id: hostId};
+    if (image) body.image = image;
+    if (profile) body.config = {profile};
+    const r = await fetch('/api/nodes', {method: 'POST', headers: headers(), body: JSON.stringify(body)});
+    const d = await r.json();
+    if (!r.ok) { showError('create-error', d.error || 'Failed'); return; }
+    hideCreateModal();
+    document.getElementById('node-name').value = '';
+    refresh();
+  } catch(e) { showError('create-error', e.message); }
+}
+
+function showError(id, msg) {
+  const el = document.getElementById(id);
+  el.textContent = msg;
+  el.style.display = 'block';
+}
+
+function showL1Modal() {
+  if (!authenticated) { showKeyModal(); return; }
+  document.getElementById('l1-error').style.display = 'none';
+  document.getElementById('l1-modal').classList.add('active');
+  document.getElementById('l1-name').focus();
+}
+function hideL1Modal() { document.getElementById('l1-modal').classList.remove('active'); }
+
+async function createL1() {
+  const name = document.getElementById('l1-name').value.trim();
+  const vm = document.getElementById('l1-vm').value;
+  const subnetId = document.getElementById('l1-subnet').value.trim();
+  const blockchainId = document.getElementById('l1-blockchain').value.trim();
+  if (!name) { showError('l1-error', 'Name is required'); return; }
+  try {
+    const body = {name, vm};
+    if (subnetId) body.subnet_id = subnetId;
+    if (blockchainId) body.blockchain_id = blockchainId;
+    const r = await fetch('/api/l1s', {method: 'POST', headers: headers(), body: JSON.stringify(body)});
+    const d = await r.json();
+    if (!r.ok) { showError('l1-error', d.error || 'Failed'); return; }
+    hideL1Modal();
+    document.getElementById('l1-name').value = '';
+    document.getElementById('l1-subnet').value = '';
+    document.getElementById('l1-blockchain').value = '';
+    refresh();
+  } catch(e) { showError('l1-error', e.message); }
+}
+
+async function deleteL1(id, name) {
+  if (!confirm('Delete L1 ' + name + '?')) return;
+  try {
+    const r = await fetch('/api/l1s/' + id, {method: 'DELETE', headers: headers()});
+    if (!r.ok) {
+      const d = await r.json();
+      alert(d.error || 'Failed to delete L1');
+    }
+    refresh();
+  } catch(e) { console.error(e); }
+}
+
+function showValidatorModal(l1Id) {
+  if (!authenticated) { showKeyModal(); return; }
+  document.getElementById('validator-error').style.display = 'none';
+  document.getElementById('validator-l1-id').value = l1Id;
+  const sel = document.getElementById('validator-node');
+  sel.innerHTML = '';
+  for (const n of nodesList) {
+    const opt = document.createElement('option');
+    opt.value = n.id;
+    opt.textContent = n.name;
+    sel.appendChild(opt);
+  }
+  document.getElementById('validator-modal').classList.add('active');
+}
+function hideValidatorModal() { document.getElementById('validator-modal').classList.remove('active'); }
+
+async function addValidator() {
+  const l1Id = document.getElementById('validator-l1-id').value;
+  const nodeId = parseInt(document.getElementById('validator-node').value) || 0;
+  const weight = parseInt(document.getElementById('validator-weight').value) || 100;
+  try {
+    const r = await fetch('/api/l1s/' + l1Id + '/validators', {method: 'POST', headers: headers(), body: JSON.stringify({node_id: nodeId, weight: weight})});
+    const d = await r.json();
+    if (!r.ok) { showError('validator-error', d.error || 'Failed'); return; }
+    hideValidatorModal();
+    refresh();
+  } catch(e) { showError('validator-error', e.message); }
+}
+
+async function removeValidator(l1Id, nodeId, nodeName) {
+  if (!confirm('Remove validator ' + nodeName + '?')) return;
+  try {
+    const r = await fetch('/api/l1s/' + l1Id + '/validators/' + nodeId, {method: 'DELETE', headers: headers()});
+    if (!r.ok) {
+      const d = await r.json();
+      alert(d.error || 'Failed to remove validator');
+    }
+    refresh();
+  } catch(e) { console.error(e); }
+}
+
+async function nodeAction(id, action) {
+  if (!authenticated) { showKeyModal(); return; }
+  const method = action === 'delete' ? 'DELETE' : 'POST';
+  const path = action === 'delete' ? '/api/nodes/' + id + '?remove_volumes=false' : '/api/nodes/' + id + '/' + action;
+  try {
+    const r = await fetch(path, {method, headers: headers()});
+    if (action === 'repull' && !r.ok) { alert((await r.json()).error); return; }
+    if (r.status === 409) {
+      // Deleting affects routes, aliases, queued work, etc.: confirm, then force.
+      const d = await r.json();
+      const deps = (d.dependencies && d.dependencies.dependencies) || [];
+      if (deps.some(x => x.blocking)) { alert(d.error); return; }
+      const list = deps.map(x => '- ' + x.kind + ' ' + x.target + (x.detail ? ' (' + x.detail + ')' : '')).join('\n');
+      if (!confirm('Deleting this node affects:\n' + list + '\n\nDelete anyway?')) return;
+      await fetch(path + '&force=true', {method, headers: headers()});
+    }
+    setTimeout(refresh, 500);
+  } catch(e) { console.error(e); }
+}
+
+function statusClass(s) { return 'status-' + s; }
+
+function truncate(s, n) { return s && s.length > n ? s.substring(0, n) + '...' : s; }
+
+// Time left in a node's Primary Network staking period, e.g. "12d 4h".
+function stakingLeft(end) {
+  const ms = new Date(end) - Date.now();
+  if (ms <= 0) return null;
+  const d = Math.floor(ms / 86400000), h = Math.floor(ms / 3600000) % 24, m = Math.floor(ms / 60000) % 60;
+  return d > 0 ? d + 'd ' + h + 'h' : h > 0 ? h + 'h ' + m + 'm' : m + 'm';
+}
+
+function setGroupBy(v) {
+  groupBy = v;
+  localStorage.setItem('groupBy', v);
+  renderNodes(nodesList);
+}
+
+// groupByTag groups nodes under each of their tags; untagged nodes last.
+function groupByTag(nodes) {
+  const groups = {};
+  const untagged = [];
+  for (const n of nodes || []) {
+    if (!n.tags || n.tags.length === 0) { untagged.push(n); continue; }
+    for (const t of n.tags) {
+      if (!groups[t]) groups[t] = [];
+      groups[t].push(n);
+    }
+  }
+  const sorted = {};
+  for (const t of Object.keys(groups).sort()) sorted[t] = groups[t];
+  if (untagged.length) sorted['untagged'] = untagged;
+  return sorted;
+}
+
+function renderNodes(nodes) {
+  const el = document.getElementById('node-table');
+  document.getElementById('group-by').value = groupBy;
+  // Build host lookup by hostname.
+  const hostByName = {};
+  for (const h of hostsList) {
+    const label = h.labels && h.labels.hostname ? h.labels.hostname : h.name;
+    hostByName[label] = h;
+  }
+  // Seed groups from all known hosts so empty hosts still appear.
+  let groups = {};
+  for (const h of hostsList) {
+    const label = h.labels && h.labels.hostname ? h.labels.hostname : h.name;
+    groups[label] = [];
+  }
+  if (groupBy === 'tag') {
+    groups = groupByTag(nodes);
+  } else if (nodes) {
+    for (const n of nodes) {
+      const h = n.host_name || 'local';
+      if (!groups[h]) groups[h] = [];
+      groups[h].push(n);
+    }
+  }
+  if (Object.keys(groups).length === 0 && groupBy === 'tag') {
+    el.innerHTML = '<div class="empty"><h2>No nodes</h2><p>Add a node to get started.</p></div>';
+    return;
+  }
+  if (Object.keys(groups).length === 0) {
+    el.innerHTML = '<div class="empty"><h2>No hosts</h2><p>Add a host to get started.</p></div>';
+    return;
+  }
+  let html = '';
+  for (const [host, hostNodes] of Object.entries(groups)) {
+  const hi = groupBy === 'host' ? hostByName[host] : null;
+  html += '<div class="host-group">';
+  html += '<div class="host-label"><div class="host-info">';
+  if (hi) {
+    const sc = statusClass(hi.status);
+    html += '<span class="' + sc + '"><span class="status-dot"></span></span>';
+    html += '<span>' + host + '</span>';
+    if (hi.ssh_addr) html += '<span class="host-detail">' + hi.ssh_addr + '</span>';
+    else html += '<span class="host-detail">local</span>';
+    if (hi.labels) {
+      if (hi.labels.cpus) html += '<span class="host-detail">' + hi.labels.cpus + ' CPU</span>';
+      if (hi.labels.memory_mb) html += '<span class="host-detail">' + Math.round(hi.labels.memory_mb / 1024) + ' GB</span>';
+      if (hi.labels.os) html += '<span class="host-detail">' + hi.labels.os + '</span>';
+    }
+    if (hi.utilization) {
+      const u = hi.utilization, gb = 1024 * 1024 * 1024;
+      html += '<span class="host-detail">load ' + u.load1.toFixed(2) + '</span>';
+      if (u.mem_total_bytes) html += '<span class="host-detail">mem ' + Math.round(u.mem_used_bytes / u.mem_total_bytes * 100) + '%</span>';
+      if (u.disk_total_bytes) html += '<span class="host-detail">disk ' + Math.round(u.disk_free_bytes / gb) + ' GB free</span>';
+    }
+    if (hi.ssh_addr) html += '<span class="host-remove" onclick="removeHost(' + hi.id + ',\'' + hi.name + '\')">remove</span>';
+  } else {
+    html += '<span>' + host + '</span>';
+  }
+  html += '</div></div>';
+  html += '<div class="node-cards">';
+  for (const n of hostNodes) {
+    const sc = statusClass(n.status);
+    const nid = n.node_id ? '<span class="mono">' + truncate(n.node_id, 24) + '</span>' : '';
+    let actions = '';
+    if (n.status === 'running' || n.status === 'unhealthy' || n.status === 'bootstrapping') {
+      actions += '<button class="btn" onclick="nodeAction('+n.id+',\'stop\')">Stop</button>';
+      if (n.new_digest) actions += '<button class="btn" title="' + n.image + ' now resolves to ' + n.new_digest + '" onclick="if(confirm(\'Recreate ' + n.name + ' on the new ' + n.image + ' digest?\'))nodeAction('+n.id+',\'repull\')">Update</button>';
+    } else if (n.status === 'stopped' || n.status === 'failed') {
+      actions += '<button class="btn" onclick="nodeAction('+n.id+',\'start\')">Start</button>';
+    }
+    const canDelete = n.status === 'stopped' || n.status === 'failed';
+    actions += '<button class="btn btn-danger" ' + (canDelete ? 'onclick="if(confirm(\'Delete node ' + n.name + '?\'))nodeAction('+n.id+',\'delete\')"' : 'disabled style="opacity:0.4;cursor:not-allowed"') + '>Delete</button>';
+
+    html += '<div class="node-card">';
+    html += '<div class="node-card-header">';
+    html += '<span class="node-name">' + n.name + '</span>';
+    html += '<div class="node-meta">';
+    html += '<span class="' + sc + '"><span class="status-dot"></span>' + n.status + '</span>';
+    html += '<span class="mono">' + truncate(n.image, 30) + '</span>';
+    if (n.new_digest) html += '<span class="tag" title="' + n.image + ' now resolves to ' + n.new_digest + '">new digest</span>';
+    html += '<span class="tag">:' + n.staking_port + '</span>';
+    if (n.network) html += '<span class="tag">' + n.network + '</span>';
+    if (groupBy === 'tag') html += '<span class="tag">' + n.host_name + '</span>';
+    else for (const t of n.tags || []) html += '<span class="tag">#' + t + '</span>';
+    if (n.version) html += '<span class="tag" title="' + n.version + '">' + n.version.replace('avalanchego/', 'v') + '</span>';
+    if (traefikDomain && (n.status === 'running' || n.status === 'unhealthy')) {
+      const rpcUrl = 'https://' + n.name + '.' + traefikDomain;
+      html += '<a href="' + rpcUrl + '/ext/info" target="_blank" class="tag" style="color:#38bdf8;text-decoration:none" title="RPC endpoint">rpc</a>';
+    }
+    if (n.staking_end) {
+      const left = stakingLeft(n.staking_end);
+      const until = new Date(n.staking_end).toLocaleString();
+      html += left
+        ? '<span class="tag" data-staking-end="' + n.staking_end + '" title="Validating until ' + until + '">validator · ' + left + ' left</span>'
+        : '<span class="tag" title="Ended ' + until + '">staking ended</span>';
+    }
+    if (nid) html += nid;
+    html += '</div>';
+    html += '<div class="node-actions">' + actions + '</div>';
+    html += '</div>';
+
+    const l1s = n.l1s || [];
+    if (l1s.length > 0) {
+      html += '<div class="node-card-body">';
+      html += '<ul class="l1-list">';
+      for (const l of l1s) {
+        html += '<li>';
+        html += '<span>' + l.name + '</span>';
+        html += '<span class="mono">' + truncate(l.subnet_id, 16) + '</span>';
+        html += '<span class="tag">' + l.vm + '</span>';
+        html += '<span class="' + statusClass(l.status) + '"><span class="status-dot"></span>' + l.status + '</span>';
+        if (l.rpc_url) html += '<a href="' + l.rpc_url + '" target="_blank" class="tag" style="color:#38bdf8;text-decoration:none" title="Public RPC endpoint">' + l.rpc_url.replace('https://', '') + '</a>';
+        html += '</li>';
+      }
+      html += '</ul>';
+      html += '</div>';
+    }
+    html += '</div>';
+  }
+  html += '</div>';
+  html += '</div>';
+  }
+  el.innerHTML = html;
+}
+
+async function refresh() {
+  try {
+    const r = await fetch('/api/status', {headers: headers()});
+    const d = await r.json();
+    if (d.counts) {
+      document.getElementById('hosts').textContent = d.counts.hosts;
+      document.getElementById('nodes').textContent = d.counts.nodes;
+      document.getElementById('l1s').textContent = d.counts.l1s;
+      document.getElementById('events').textContent = d.counts.events;
+    }
+    authenticated = d.authenticated || false;
+    updateAuthBadge(authenticated, d.user_handle);
+    if (d.traefik_domain) traefikDomain = d.traefik_domain;
+    if (d.hosts_list) hostsList = d.hosts_list;
+    if (d.nodes) nodesList = d.nodes;
+    const skew = document.getElementById('version-skew');
+    skew.textContent = (d.version_skew || []).map(s => s.warning).join(' · ');
+    skew.style.display = d.version_skew ? 'block' : 'none';
+    renderNodes(d.nodes || []);
+  } catch(e) { console.error(e); }
+}
+
+// Live updates: refresh whenever the event stream delivers something.
+// fetch() is used instead of EventSource so the bearer header is sent.
+let streaming = false;
+let refreshTimer = null;
+function scheduleRefresh() {
+  if (refreshTimer) return;
+  refreshTimer = setTimeout(() => { refreshTimer = null; refresh(); }, 300);
+}
+async function streamEvents() {
+  if (authenticated) {
+    try {
+      const r = await fetch('/api/events/stream', {headers: headers()});
+      if (r.ok) {
+        streaming = true;
+        const reader = r.body.getReader();
+        const dec = new TextDecoder();
+        let buf = '';
+        for (;;) {
+          const {value, done} = await reader.read();
+          if (done) break;
+          buf += dec.decode(value, {stream: true});
+          const parts = buf.split('\n\n');
+          buf = parts.pop();
+          if (parts.some(p => p.includes('data:'))) scheduleRefresh();
+        }
+      }
+    } catch(e) { console.error(e); }
+  }
+  streaming = false;
+  setTimeout(streamEvents, 5000);
+}
+
+// Per-deployment settings from UI_* variables; defaults if unreachable.
+let uiConfig = {poll_interval_ms: 10000, event_stream: true, sections: ['cards', 'actions', 'nodes']};
+async function loadUIConfig() {
+  try {
+    const r = await fetch('/api/ui-config');
+    if (r.ok) uiConfig = await r.json();
+  } catch(e) { console.error(e); }
+  const show = s => uiConfig.sections.includes(s) ? '' : 'none';
+  document.getElementById('section-cards').style.display = show('cards');
+  document.getElementById('section-actions').style.display = show('actions');
+  document.getElementById('node-table').style.display = show('nodes');
+}
+
+// Initial load, then push updates; poll only while the stream is down
+// (or always, when the event stream is disabled).
+loadUIConfig().then(refresh).then(() => {
+  if (uiConfig.event_stream) streamEvents();
+  setInterval(() => { if (!streaming) refresh(); }, uiConfig.poll_interval_ms);
+});
+// Tick staking countdowns between refreshes.
+setInterval(() => {
+  for (const el of document.querySelectorAll('[data-staking-end]')) {
+    const left = stakingLeft(el.dataset.stakingEnd);
+    el.textContent = left ? 'validator · ' + left + ' left' : 'staking ended';
+  }
+}, 60000);
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/primal-host/avalauncher/internal/awssig"
+)
+
+// s3PartSize is the multipart chunk size. Parts are buffered in memory to
+// be signed; 10,000 of them cap an object at about 640 GB.
+const s3PartSize = 64 << 20
+
+// httpClient has no overall timeout: objects are streamed for as long as
+// they take, bounded by the caller's context.
+var httpClient = &http.Client{}
+
+// S3 stores objects in a bucket of Amazon S3 or a compatible service
+// (MinIO, Ceph, R2). Credentials need s3:PutObject, s3:GetObject and
+// s3:DeleteObject on the prefix.
+type S3 struct {
+	Endpoint        string // base URL; empty = AWS for Region
+	Region          string
+	Bucket          string
+	Prefix          string // prepended to every key, e.g. "avalauncher/"
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // optional, for temporary credentials
+	PathStyle       bool   // address the bucket in the path, as MinIO needs
+}
+
+// ParseS3URL reads an s3://bucket/prefix target into an S3 store without
+// credentials.
+func ParseS3URL(target string) (*S3, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 target %q (want s3://bucket/prefix)", target)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3{Bucket: u.Host, Prefix: prefix}, nil
+}
+
+func (s *S3) Name() string { return "s3://" + s.Bucket + "/" + s.Prefix }
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type s3InitiateResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteRequest struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []s3Part `xml:"Part"`
+}
+
+// Put uploads small objects in one request and larger ones as a multipart
+// upload, which is aborted if any part fails.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	buf := make([]byte, s3PartSize)
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		if _, err := s.do(ctx, http.MethodPut, key, nil, buf[:n], nil); err != nil {
+			return 0, err
+		}
+		return int64(n), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var init s3InitiateResult
+	if _, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, &init); err != nil {
+		return 0, err
+	}
+	size, err := s.putParts(ctx, key, init.UploadID, r, buf)
+	if err != nil {
+		abort, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		s.do(abort, http.MethodDelete, key, url.Values{"uploadId": {init.UploadID}}, nil, nil)
+		return size, err
+	}
+	return size, nil
+}
+
+// putParts uploads buf (already full) and the rest of r as numbered parts,
+// then completes the upload.
+func (s *S3) putParts(ctx context.Context, key, uploadID string, r io.Reader, buf []byte) (int64, error) {
+	var size int64
+	complete := s3CompleteRequest{}
+	n := len(buf)
+	for num := 1; n > 0; num++ {
+		q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadID}}
+		header, err := s.do(ctx, http.MethodPut, key, q, buf[:n], nil)
+		if err != nil {
+			return size, fmt.Errorf("part %d: %w", num, err)
+		}
+		complete.Parts = append(complete.Parts, s3Part{PartNumber: num, ETag: header.Get("ETag")})
+		size += int64(n)
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return size, err
+		}
+	}
+	body, _ := xml.Marshal(complete)
+	if _, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body, nil); err != nil {
+		return size, fmt.Errorf("complete upload: %w", err)
+	}
+	return size, nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3Status(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// request builds a signed request for key.
+func (s *S3) request(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	base := s.Endpoint
+	if base == "" {
+		base = "https://s3." + s.Region + ".amazonaws.com"
+	}
+	bu, err := url.Parse(strings.TrimRight(base, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", base, err)
+	}
+	var segs []string
+	for _, seg := range strings.Split(s.Prefix+key, "/") {
+		segs = append(segs, url.PathEscape(seg))
+	}
+	path := "/" + strings.Join(segs, "/")
+	if s.PathStyle {
+		path = "/" + s.Bucket + path
+	} else {
+		bu.Host = s.Bucket + "." + bu.Host
+	}
+	u := bu.Scheme + "://" + bu.Host + bu.Path + path
+	if len(query) > 0 {
+		u += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	awssig.Sign(req, body, awssig.Credentials{
+		AccessKeyID: s.AccessKeyID, SecretAccessKey: s.SecretAccessKey, SessionToken: s.SessionToken,
+	}, s.Region, "s3", time.Now())
+	return req, nil
+}
+
+// do sends a signed request and decodes an XML response into out (if
+// non-nil). It returns the response headers.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, body []byte, out any) (http.Header, error) {
+	req, err := s.request(ctx, method, key, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, s3Status(resp)
+	}
+	if out != nil {
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return nil, err
+		}
+		if err := xml.Unmarshal(data, out); err != nil {
+			return nil, err
+		}
+	}
+	return resp.Header, nil
+}
+
+// s3Status turns an error response into an error.
+func s3Status(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var e s3Error
+	if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+		return fmt.Errorf("s3: HTTP %d: %s: %s", resp.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Errorf("s3: HTTP %d", resp.StatusCode)
+}